@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/exasol/exasol-driver-go"
+	"github.com/exasol/exasol-driver-go/pkg/dsn"
+	"gopkg.in/yaml.v3"
+
+	"github.com/exasol/extension-manager/pkg/extensionController"
+)
+
+const (
+	dbUserEnv        = "DB_USER"
+	dbPasswordEnv    = "DB_PASSWORD"
+	dbAccessTokenEnv = "DB_ACCESS_TOKEN"
+)
+
+// manifestApplier plans and applies manifests, see [extensionController.TransactionController].
+type manifestApplier interface {
+	PlanManifest(ctx context.Context, db *sql.DB, manifest *extensionController.Manifest) (*extensionController.ApplyPlan, error)
+	ApplyManifestPlan(ctx context.Context, db *sql.DB, plan *extensionController.ApplyPlan, options extensionController.ApplyOptions) (*extensionController.ApplyResult, error)
+}
+
+// applyArgs contains the command line arguments for applying a manifest.
+type applyArgs struct {
+	manifestFile string
+	dbHost       string
+	dbPort       int
+	preview      bool
+	options      extensionController.ApplyOptions
+}
+
+// startApply applies the manifest to the database using a new controller with the given configuration.
+func startApply(config extensionController.ExtensionManagerConfig, args applyArgs) error {
+	if config.ExtensionRegistryURL == "" {
+		return errors.New("please specify extension registry with parameter '-extensionRegistryURL'")
+	}
+	manifest, err := readManifest(args.manifestFile)
+	if err != nil {
+		return err
+	}
+	controller, err := extensionController.CreateWithValidatedConfig(config)
+	if err != nil {
+		return err
+	}
+	db, err := openDatabase(args.dbHost, args.dbPort)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	ctx := extensionController.WithDatabaseIdentifier(context.Background(), fmt.Sprintf("%s:%d", args.dbHost, args.dbPort))
+	return runApply(ctx, controller, db, manifest, args, os.Stdout)
+}
+
+// readManifest reads a manifest in YAML format if the file name ends with ".yaml" or ".yml" and in JSON format otherwise.
+// Unknown fields are rejected to detect typos.
+func readManifest(fileName string) (*extensionController.Manifest, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	extension := strings.ToLower(filepath.Ext(fileName))
+	if extension == ".yaml" || extension == ".yml" {
+		if content, err = yamlToJSON(content); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %q: %w", fileName, err)
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	//nolint:exhaustruct // Omitting values by intention for deserialization
+	manifest := &extensionController.Manifest{}
+	if err := decoder.Decode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %q: %w", fileName, err)
+	}
+	return manifest, nil
+}
+
+// yamlToJSON converts YAML to JSON so that the manifest is decoded using the JSON field names in both formats.
+func yamlToJSON(content []byte) ([]byte, error) {
+	var data any
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+func openDatabase(host string, port int) (*sql.DB, error) {
+	if host == "" {
+		return nil, errors.New("please specify the database with parameter '-dbHost'")
+	}
+	var config *dsn.DSNConfigBuilder
+	if token := os.Getenv(dbAccessTokenEnv); token != "" {
+		config = exasol.NewConfigWithAccessToken(token)
+	} else {
+		config = exasol.NewConfig(os.Getenv(dbUserEnv), os.Getenv(dbPasswordEnv))
+	}
+	config.Host(host).Port(port).ValidateServerCertificate(false).Autocommit(false)
+	db, err := sql.Open("exasol", config.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a database connection. Cause: %w", err)
+	}
+	return db, nil
+}
+
+// runApply prints the plan for the manifest and applies exactly the printed plan unless args.preview is set.
+// It returns an error if a change failed.
+func runApply(ctx context.Context, applier manifestApplier, db *sql.DB, manifest *extensionController.Manifest, args applyArgs, writer io.Writer) error {
+	plan, err := applier.PlanManifest(ctx, db, manifest)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "Plan: %d change(s)\n", len(plan.Changes))
+	for _, change := range plan.Changes {
+		fmt.Fprintf(writer, "  %s\n", formatApplyChange(change))
+	}
+	if args.preview || len(plan.Changes) == 0 {
+		return nil
+	}
+	result, err := applier.ApplyManifestPlan(ctx, db, plan, args.options)
+	if err != nil {
+		return err
+	}
+	var failure error
+	for _, change := range result.Changes {
+		switch {
+		case change.Applied:
+			fmt.Fprintf(writer, "Applied %s\n", formatApplyChange(change.ApplyChange))
+			for _, warning := range change.Warnings {
+				fmt.Fprintf(writer, "  Warning: %s\n", warning)
+			}
+		case change.Error != nil:
+			fmt.Fprintf(writer, "Failed %s: %v\n", formatApplyChange(change.ApplyChange), change.Error)
+			failure = fmt.Errorf("failed to apply manifest: %w", change.Error)
+		default:
+			fmt.Fprintf(writer, "Skipped %s\n", formatApplyChange(change.ApplyChange))
+		}
+	}
+	return failure
+}
+
+func formatApplyChange(change *extensionController.ApplyChange) string {
+	switch change.Action {
+	case extensionController.ApplyActionUpgrade:
+		return fmt.Sprintf("upgrade %s %s -> %s", change.ExtensionId, change.InstalledVersion, change.Version)
+	case extensionController.ApplyActionCreateInstance, extensionController.ApplyActionDeleteInstance:
+		return fmt.Sprintf("%s %q of %s %s", change.Action, change.InstanceName, change.ExtensionId, change.Version)
+	default:
+		return fmt.Sprintf("%s %s %s", change.Action, change.ExtensionId, change.Version)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/exasol/extension-manager/pkg/extensionController"
+	"github.com/stretchr/testify/suite"
+)
+
+type ApplySuite struct {
+	suite.Suite
+	applier *applierStub
+}
+
+func TestApplySuite(t *testing.T) {
+	suite.Run(t, new(ApplySuite))
+}
+
+func (suite *ApplySuite) SetupTest() {
+	suite.applier = &applierStub{}
+}
+
+type applierStub struct {
+	plan         *extensionController.ApplyPlan
+	result       *extensionController.ApplyResult
+	err          error
+	applyCalled  bool
+	appliedPlan  *extensionController.ApplyPlan
+	applyOptions extensionController.ApplyOptions
+}
+
+func (s *applierStub) PlanManifest(ctx context.Context, db *sql.DB, manifest *extensionController.Manifest) (*extensionController.ApplyPlan, error) {
+	return s.plan, s.err
+}
+
+func (s *applierStub) ApplyManifestPlan(ctx context.Context, db *sql.DB, plan *extensionController.ApplyPlan, options extensionController.ApplyOptions) (*extensionController.ApplyResult, error) {
+	s.applyCalled = true
+	s.appliedPlan = plan
+	s.applyOptions = options
+	return s.result, s.err
+}
+
+var (
+	installChange = &extensionController.ApplyChange{Action: extensionController.ApplyActionInstall, ExtensionId: "ext", Version: "1.0.0"}
+	upgradeChange = &extensionController.ApplyChange{Action: extensionController.ApplyActionUpgrade, ExtensionId: "ext", Version: "1.0.0", InstalledVersion: "0.9.0"}
+	deleteChange  = &extensionController.ApplyChange{Action: extensionController.ApplyActionDeleteInstance, ExtensionId: "ext", Version: "0.9.0", InstanceName: "old", InstanceId: "old-id"}
+	createChange  = &extensionController.ApplyChange{Action: extensionController.ApplyActionCreateInstance, ExtensionId: "ext", Version: "1.0.0", InstanceName: "new"}
+)
+
+func (suite *ApplySuite) writeManifest(fileName, content string) string {
+	path := filepath.Join(suite.T().TempDir(), fileName)
+	suite.Require().NoError(os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+var expectedManifest = &extensionController.Manifest{Extensions: []extensionController.ManifestExtension{{ExtensionId: "ext", Version: "1.0.0",
+	Instances: []extensionController.ManifestInstance{{Name: "inst", Parameters: []extensionController.ParameterValue{{Name: "p1", Value: "v1"}}}}}}}
+
+func (suite *ApplySuite) TestReadJsonManifest() {
+	manifest, err := readManifest(suite.writeManifest("manifest.json",
+		`{"extensions":[{"extensionId":"ext","version":"1.0.0","instances":[{"name":"inst","parameters":[{"name":"p1","value":"v1"}]}]}]}`))
+	suite.Require().NoError(err)
+	suite.Equal(expectedManifest, manifest)
+}
+
+func (suite *ApplySuite) TestReadYamlManifest() {
+	manifest, err := readManifest(suite.writeManifest("manifest.yaml", `
+extensions:
+  - extensionId: ext
+    version: "1.0.0"
+    instances:
+      - name: inst
+        parameters:
+          - name: p1
+            value: v1
+`))
+	suite.Require().NoError(err)
+	suite.Equal(expectedManifest, manifest)
+}
+
+func (suite *ApplySuite) TestReadManifestRejectsUnknownFields() {
+	_, err := readManifest(suite.writeManifest("manifest.yml", "extensions:\n  - id: ext\n"))
+	suite.Require().ErrorContains(err, `json: unknown field "id"`)
+}
+
+func (suite *ApplySuite) TestReadManifestFailsForInvalidYaml() {
+	_, err := readManifest(suite.writeManifest("manifest.yaml", "extensions: [\n"))
+	suite.Require().ErrorContains(err, "failed to parse manifest")
+}
+
+func (suite *ApplySuite) TestReadManifestFailsForMissingFile() {
+	_, err := readManifest("missing.json")
+	suite.Require().EqualError(err, "failed to read manifest: open missing.json: no such file or directory")
+}
+
+func (suite *ApplySuite) TestRunApplyPreview() {
+	suite.applier.plan = &extensionController.ApplyPlan{Changes: []*extensionController.ApplyChange{installChange, deleteChange, upgradeChange, createChange}}
+	var output bytes.Buffer
+	err := runApply(context.Background(), suite.applier, nil, expectedManifest, applyArgs{preview: true}, &output)
+	suite.Require().NoError(err)
+	suite.Equal(`Plan: 4 change(s)
+  install ext 1.0.0
+  delete-instance "old" of ext 0.9.0
+  upgrade ext 0.9.0 -> 1.0.0
+  create-instance "new" of ext 1.0.0
+`, output.String())
+	suite.False(suite.applier.applyCalled)
+}
+
+func (suite *ApplySuite) TestRunApplyWithoutChanges() {
+	suite.applier.plan = &extensionController.ApplyPlan{Changes: nil}
+	var output bytes.Buffer
+	err := runApply(context.Background(), suite.applier, nil, expectedManifest, applyArgs{}, &output)
+	suite.Require().NoError(err)
+	suite.Equal("Plan: 0 change(s)\n", output.String())
+	suite.False(suite.applier.applyCalled)
+}
+
+func (suite *ApplySuite) TestRunApplyReportsFailedChange() {
+	suite.applier.plan = &extensionController.ApplyPlan{Changes: []*extensionController.ApplyChange{installChange, createChange, deleteChange}}
+	suite.applier.result = &extensionController.ApplyResult{Changes: []*extensionController.ApplyChangeResult{
+		{ApplyChange: installChange, Applied: true, Warnings: []string{"version is deprecated"}},
+		{ApplyChange: createChange, Error: errors.New("invalid parameter")},
+		{ApplyChange: deleteChange}}}
+	var output bytes.Buffer
+	options := extensionController.ApplyOptions{AcceptLicenses: true, Force: true}
+	err := runApply(context.Background(), suite.applier, nil, expectedManifest, applyArgs{options: options}, &output)
+	suite.Require().EqualError(err, "failed to apply manifest: invalid parameter")
+	suite.Equal(options, suite.applier.applyOptions)
+	suite.Same(suite.applier.plan, suite.applier.appliedPlan)
+	suite.Equal(`Plan: 3 change(s)
+  install ext 1.0.0
+  create-instance "new" of ext 1.0.0
+  delete-instance "old" of ext 0.9.0
+Applied install ext 1.0.0
+  Warning: version is deprecated
+Failed create-instance "new" of ext 1.0.0: invalid parameter
+Skipped delete-instance "old" of ext 0.9.0
+`, output.String())
+}
+
+func (suite *ApplySuite) TestRunApplyFailsPlanning() {
+	suite.applier.err = errors.New("invalid manifest")
+	var output bytes.Buffer
+	err := runApply(context.Background(), suite.applier, nil, expectedManifest, applyArgs{}, &output)
+	suite.Require().EqualError(err, "invalid manifest")
+	suite.Empty(output.String())
+}
+
+func (suite *ApplySuite) TestStartApplyRequiresRegistry() {
+	err := startApply(extensionController.ExtensionManagerConfig{}, applyArgs{manifestFile: "manifest.json"})
+	suite.Require().EqualError(err, "please specify extension registry with parameter '-extensionRegistryURL'")
+}
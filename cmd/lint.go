@@ -0,0 +1,214 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/exasol/extension-manager/pkg/backend"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionAPI/context"
+	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+	"github.com/exasol/extension-manager/pkg/parameterValidator"
+)
+
+// Names of the checks reported in [lintProblem.Check].
+const (
+	checkLoad                 = "load"
+	checkRequiredFunctions    = "required-functions"
+	checkVersions             = "installable-versions"
+	checkLatestVersion        = "latest-version"
+	checkBucketFsUploads      = "bucketfs-uploads"
+	checkParameterDefinitions = "parameter-definitions"
+	checkDependencies         = "dependencies"
+)
+
+// lintResult is the machine-readable result of linting an extension JavaScript file.
+type lintResult struct {
+	File     string        `json:"file"`     // Path of the linted file
+	Valid    bool          `json:"valid"`    // True if no problems were found
+	Problems []lintProblem `json:"problems"` // Problems found in the extension
+}
+
+// lintProblem describes a single problem found in an extension.
+type lintProblem struct {
+	Check   string `json:"check"`             // Name of the failed check, e.g. "installable-versions"
+	Version string `json:"version,omitempty"` // Extension version the problem refers to, if any
+	Message string `json:"message"`           // Human-readable description of the problem
+}
+
+// runLint lints the given extension JavaScript file and writes the result as JSON to the writer.
+// It returns an error if the extension contains problems.
+func runLint(fileName string, writer io.Writer) error {
+	result := lintExtensionFile(fileName)
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("failed to write lint result: %w", err)
+	}
+	if !result.Valid {
+		return fmt.Errorf("found %d problem(s) in extension %q", len(result.Problems), fileName)
+	}
+	return nil
+}
+
+func lintExtensionFile(fileName string) lintResult {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return newLintResult(fileName, []lintProblem{{Check: checkLoad, Message: fmt.Sprintf("failed to read file: %v", err)}})
+	}
+	return newLintResult(fileName, lintExtension(filepath.Base(fileName), string(content)))
+}
+
+func newLintResult(fileName string, problems []lintProblem) lintResult {
+	return lintResult{File: fileName, Valid: len(problems) == 0, Problems: problems}
+}
+
+func lintExtension(id, content string) []lintProblem {
+	extension, err := extensionAPI.LoadExtension(id, content)
+	if err != nil {
+		return []lintProblem{{Check: checkLoad, Message: err.Error()}}
+	}
+	problems := make([]lintProblem, 0)
+	problems = append(problems, checkFunctions(extension)...)
+	problems = append(problems, checkInstallableVersions(extension)...)
+	problems = append(problems, checkUploads(extension)...)
+	problems = append(problems, checkParameters(extension)...)
+	problems = append(problems, checkExtensionDependencies(extension)...)
+	return problems
+}
+
+func checkFunctions(extension *extensionAPI.JsExtension) []lintProblem {
+	problems := make([]lintProblem, 0)
+	for _, function := range extension.UnsupportedFunctions() {
+		problems = append(problems, lintProblem{Check: checkRequiredFunctions, Message: fmt.Sprintf("required function %q is missing", function)})
+	}
+	return problems
+}
+
+func checkInstallableVersions(extension *extensionAPI.JsExtension) []lintProblem {
+	problems := make([]lintProblem, 0)
+	if len(extension.InstallableVersions) == 0 {
+		problems = append(problems, lintProblem{Check: checkVersions, Message: "extension does not define any installable versions"})
+	}
+	versionCount := make(map[string]int)
+	latestCount := 0
+	for _, version := range extension.InstallableVersions {
+		versionCount[version.Name]++
+		if versionCount[version.Name] == 2 {
+			problems = append(problems, lintProblem{Check: checkVersions, Version: version.Name, Message: fmt.Sprintf("version %q is defined more than once", version.Name)})
+		}
+		if !isFullSemanticVersion(version.Name) {
+			problems = append(problems, lintProblem{Check: checkVersions, Version: version.Name, Message: fmt.Sprintf("version %q is not a valid semantic version", version.Name)})
+		}
+		if version.Latest {
+			latestCount++
+		}
+	}
+	if len(extension.InstallableVersions) > 0 && latestCount != 1 {
+		problems = append(problems, lintProblem{Check: checkLatestVersion, Message: fmt.Sprintf("expected exactly one version marked as latest but found %d", latestCount)})
+	}
+	return problems
+}
+
+// isFullSemanticVersion checks if the given version has the form "major.minor.patch" with optional pre-release and build suffix.
+// In contrast to [semver.IsValid] this does not accept shorthands like "1.2".
+func isFullSemanticVersion(version string) bool {
+	prefixedVersion := "v" + version
+	versionWithoutBuild, _, _ := strings.Cut(prefixedVersion, "+")
+	return semver.IsValid(prefixedVersion) && semver.Canonical(prefixedVersion) == versionWithoutBuild
+}
+
+func checkUploads(extension *extensionAPI.JsExtension) []lintProblem {
+	problems := make([]lintProblem, 0)
+	for i, upload := range extension.BucketFsUploads {
+		if upload.BucketFsFilename == "" {
+			problems = append(problems, lintProblem{Check: checkBucketFsUploads, Message: fmt.Sprintf("BucketFS upload #%d (%q) has an empty filename", i, upload.Name)})
+		}
+	}
+	return problems
+}
+
+func checkParameters(extension *extensionAPI.JsExtension) []lintProblem {
+	problems := make([]lintProblem, 0)
+	if slices.Contains(extension.UnsupportedFunctions(), "getInstanceParameters") {
+		return problems
+	}
+	extensionContext := createOfflineContext()
+	for _, version := range extension.InstallableVersions {
+		rawDefinitions, err := extension.GetParameterDefinitions(extensionContext, version.Name)
+		if err != nil {
+			problems = append(problems, lintProblem{Check: checkParameterDefinitions, Version: version.Name, Message: err.Error()})
+			continue
+		}
+		if _, err := parameterValidator.ConvertDefinitions(rawDefinitions); err != nil {
+			problems = append(problems, lintProblem{Check: checkParameterDefinitions, Version: version.Name, Message: err.Error()})
+		}
+	}
+	return problems
+}
+
+func checkExtensionDependencies(extension *extensionAPI.JsExtension) []lintProblem {
+	problems := make([]lintProblem, 0)
+	for i, dependency := range extension.Dependencies {
+		if dependency.ExtensionId == "" {
+			problems = append(problems, lintProblem{Check: checkDependencies, Message: fmt.Sprintf("dependency #%d has an empty extension ID", i)})
+		} else if dependency.ExtensionId == extension.Id {
+			problems = append(problems, lintProblem{Check: checkDependencies, Message: fmt.Sprintf("dependency #%d refers to the extension itself", i)})
+		}
+		if err := extensionAPI.ValidateVersionRange(dependency.VersionRange); err != nil {
+			problems = append(problems, lintProblem{Check: checkDependencies, Message: fmt.Sprintf("dependency #%d (%q): %v", i, dependency.ExtensionId, err)})
+		}
+	}
+	return problems
+}
+
+// createOfflineContext creates an extension context without database access.
+// Extensions that use the database while returning parameter definitions will fail with a helpful error message.
+func createOfflineContext() *context.ExtensionContext {
+	//nolint:exhaustruct // Transaction context is not used without database
+	txCtx := &transaction.TransactionContext{}
+	return context.CreateContextWithClient("EXTENSION_SCHEMA", txCtx, offlineSqlClient{}, offlineBucketFsContext{}, offlineMetadataReader{})
+}
+
+var errNoDatabase = errors.New("database access is not available in lint mode")
+
+type offlineSqlClient struct{}
+
+func (offlineSqlClient) Execute(query string, args ...any) (sql.Result, error) {
+	return nil, errNoDatabase
+}
+
+func (offlineSqlClient) Query(query string, args ...any) (*backend.QueryResult, error) {
+	return nil, errNoDatabase
+}
+
+type offlineBucketFsContext struct{}
+
+func (offlineBucketFsContext) ResolvePath(fileName string) string {
+	panic(errors.New("BucketFS access is not available in lint mode"))
+}
+
+type offlineMetadataReader struct{}
+
+func (offlineMetadataReader) ReadMetadataTables(tx *sql.Tx, schemaName string) (*exaMetadata.ExaMetadata, error) {
+	return nil, errNoDatabase
+}
+
+func (offlineMetadataReader) ReadAllScripts(tx *sql.Tx) (*exaMetadata.ExaScriptTable, error) {
+	return nil, errNoDatabase
+}
+
+func (offlineMetadataReader) GetScriptByName(tx *sql.Tx, schemaName, scriptName string) (*exaMetadata.ExaScriptRow, error) {
+	return nil, errNoDatabase
+}
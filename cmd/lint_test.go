@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kinbiko/jsonassert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LintSuite struct {
+	suite.Suite
+}
+
+func TestLintSuite(t *testing.T) {
+	suite.Run(t, new(LintSuite))
+}
+
+const validExtensionBody = `
+	name: "ext",
+	installableVersions: [{name: "1.0.0", latest: false}, {name: "1.1.0", latest: true}],
+	bucketFsUploads: [{name: "jar", bucketFsFilename: "file.jar", fileSize: 1}],
+	getInstanceParameters: function(context, version) { return [{id: "p1", name: "Param 1", type: "string"}] },
+	install: function(context, version) {},
+	uninstall: function(context, version) {},
+	upgrade: function(context) { return undefined },
+	findInstallations: function(context, metadata) { return [] },
+	addInstance: function(context, version, params) { return undefined },
+	findInstances: function(context, version) { return [] },
+	deleteInstance: function(context, version, instanceId) {}`
+
+func extensionWithBody(body string) string {
+	content := `
+	(function(){
+		global.installedExtension = {
+			extension: { $BODY$ },
+			apiVersion: "0.2.0"
+		}
+	})()`
+	return strings.Replace(content, "$BODY$", body, 1)
+}
+
+func (suite *LintSuite) TestValidExtension() {
+	suite.Empty(lintExtension("ext.js", extensionWithBody(validExtensionBody)))
+}
+
+func (suite *LintSuite) TestInvalidJavaScript() {
+	problems := lintExtension("ext.js", "invalid javascript")
+	suite.Len(problems, 1)
+	suite.Equal(checkLoad, problems[0].Check)
+	suite.Contains(problems[0].Message, `failed to run extension "ext.js"`)
+}
+
+func (suite *LintSuite) TestMissingFunctions() {
+	problems := lintExtension("ext.js", extensionWithBody(`installableVersions: [{name: "1.0.0", latest: true}]`))
+	suite.Equal([]lintProblem{
+		{Check: checkRequiredFunctions, Message: `required function "getInstanceParameters" is missing`},
+		{Check: checkRequiredFunctions, Message: `required function "install" is missing`},
+		{Check: checkRequiredFunctions, Message: `required function "uninstall" is missing`},
+		{Check: checkRequiredFunctions, Message: `required function "upgrade" is missing`},
+		{Check: checkRequiredFunctions, Message: `required function "findInstallations" is missing`},
+		{Check: checkRequiredFunctions, Message: `required function "addInstance" is missing`},
+		{Check: checkRequiredFunctions, Message: `required function "findInstances" is missing`},
+		{Check: checkRequiredFunctions, Message: `required function "deleteInstance" is missing`},
+	}, problems)
+}
+
+func (suite *LintSuite) TestInstallableVersions() {
+	tests := []struct {
+		name             string
+		versions         string
+		expectedProblems []lintProblem
+	}{
+		{name: "no versions", versions: `[]`, expectedProblems: []lintProblem{
+			{Check: checkVersions, Message: "extension does not define any installable versions"}}},
+		{name: "invalid semver", versions: `[{name: "1.0", latest: true}]`, expectedProblems: []lintProblem{
+			{Check: checkVersions, Version: "1.0", Message: `version "1.0" is not a valid semantic version`}}},
+		{name: "duplicate version", versions: `[{name: "1.0.0", latest: true}, {name: "1.0.0"}, {name: "1.0.0"}]`, expectedProblems: []lintProblem{
+			{Check: checkVersions, Version: "1.0.0", Message: `version "1.0.0" is defined more than once`}}},
+		{name: "no latest version", versions: `[{name: "1.0.0"}, {name: "1.1.0"}]`, expectedProblems: []lintProblem{
+			{Check: checkLatestVersion, Message: "expected exactly one version marked as latest but found 0"}}},
+		{name: "multiple latest versions", versions: `[{name: "1.0.0", latest: true}, {name: "1.1.0", latest: true}]`, expectedProblems: []lintProblem{
+			{Check: checkLatestVersion, Message: "expected exactly one version marked as latest but found 2"}}},
+	}
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			body := strings.Replace(validExtensionBody, `[{name: "1.0.0", latest: false}, {name: "1.1.0", latest: true}]`, test.versions, 1)
+			problems := lintExtension("ext.js", extensionWithBody(body))
+			suite.Equal(test.expectedProblems, filterProblems(problems, checkVersions, checkLatestVersion))
+		})
+	}
+}
+
+func (suite *LintSuite) TestEmptyBucketFsFilename() {
+	body := strings.Replace(validExtensionBody, `bucketFsFilename: "file.jar"`, `bucketFsFilename: ""`, 1)
+	problems := lintExtension("ext.js", extensionWithBody(body))
+	suite.Equal([]lintProblem{{Check: checkBucketFsUploads, Message: `BucketFS upload #0 ("jar") has an empty filename`}}, problems)
+}
+
+func (suite *LintSuite) TestInvalidParameterDefinition() {
+	body := strings.Replace(validExtensionBody, `{id: "p1", name: "Param 1", type: "string"}`, `{name: "Param 1", type: "string"}`, 1)
+	problems := lintExtension("ext.js", extensionWithBody(body))
+	suite.Len(problems, 2)
+	suite.Equal(checkParameterDefinitions, problems[0].Check)
+	suite.Equal("1.0.0", problems[0].Version)
+	suite.Contains(problems[0].Message, `entry "id" missing in parameter definition`)
+}
+
+func (suite *LintSuite) TestParameterDefinitionsUsingDatabase() {
+	body := strings.Replace(validExtensionBody, `return [{id: "p1", name: "Param 1", type: "string"}]`, `context.sqlClient.query("select 1"); return []`, 1)
+	problems := lintExtension("ext.js", extensionWithBody(body))
+	suite.Len(problems, 2)
+	suite.Equal(checkParameterDefinitions, problems[0].Check)
+	suite.Contains(problems[0].Message, "database access is not available in lint mode")
+}
+
+func (suite *LintSuite) TestDependencies() {
+	body := validExtensionBody + `,
+	dependencies: [{extensionId: "driver.js", versionRange: ">=1.0.0 <2.0.0"}, {extensionId: "", versionRange: ""}, {extensionId: "ext.js"}, {extensionId: "other.js", versionRange: "^1.0.0"}]`
+	problems := lintExtension("ext.js", extensionWithBody(body))
+	suite.Equal([]lintProblem{
+		{Check: checkDependencies, Message: "dependency #1 has an empty extension ID"},
+		{Check: checkDependencies, Message: "dependency #2 refers to the extension itself"},
+		{Check: checkDependencies, Message: `dependency #3 ("other.js"): invalid version range "^1.0.0": "^1.0.0" is not a valid version`},
+	}, problems)
+}
+
+func (suite *LintSuite) TestRunLintWritesJson() {
+	fileName := filepath.Join(suite.T().TempDir(), "ext.js")
+	suite.Require().NoError(os.WriteFile(fileName, []byte(extensionWithBody(validExtensionBody)), 0600))
+	var output bytes.Buffer
+	err := runLint(fileName, &output)
+	suite.Require().NoError(err)
+	jsonassert.New(suite.T()).Assertf(output.String(), `{"file": "%s", "valid": true, "problems": []}`, fileName)
+}
+
+func (suite *LintSuite) TestRunLintFailsForMissingFile() {
+	var output bytes.Buffer
+	err := runLint("missing.js", &output)
+	suite.Require().EqualError(err, `found 1 problem(s) in extension "missing.js"`)
+	jsonassert.New(suite.T()).Assertf(output.String(), `{"file": "missing.js", "valid": false, "problems": [
+		{"check": "load", "message": "failed to read file: open missing.js: no such file or directory"}]}`)
+}
+
+func filterProblems(problems []lintProblem, checks ...string) []lintProblem {
+	result := make([]lintProblem, 0)
+	for _, p := range problems {
+		for _, check := range checks {
+			if p.Check == check {
+				result = append(result, p)
+			}
+		}
+	}
+	return result
+}
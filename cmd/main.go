@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -20,17 +21,62 @@ func main() {
 	var serverAddress = flag.String("serverAddress", ":8080", `Server address, e.g. ":8080" (all network interfaces) or "localhost:8080" (only local interface)`)
 	var openAPIOutputPath = flag.String("openAPIOutputPath", "", "Generate the OpenAPI spec at the given path instead of starting the server")
 	var addCauseToInternalServerError = flag.Bool("addCauseToInternalServerError", false, "Add cause of internal server errors (status 500) to the error message. Don't use this in production!")
+	var bucketFsUploadURL = flag.String("bucketFsUploadURL", "", `URL of the bucket for uploading files required by extensions, e.g. "https://exasol-host:2581/default/". Read the passwords from environment variables `+bucketFsWritePasswordEnv+` and `+bucketFsReadPasswordEnv)
+	var uploadTimeout = flag.Duration("uploadTimeout", 10*time.Minute, "Maximum duration of downloading a file required by an extension or of a single request to BucketFS when uploading a file")
+	var verifyInstallations = flag.Bool("verifyInstallations", false, "Verify that an extension's findInstallations reports the expected version after installing or upgrading it and roll back otherwise")
+	var deprecatedVersionPolicy = flag.String("deprecatedVersionPolicy", "allow", `Policy for installing deprecated extension versions and creating instances of them: "allow", "warn" (report a warning) or "reject" (fail unless the client sets query parameter force)`)
+	var lockTimeout = flag.Duration("lockTimeout", 30*time.Second, "Maximum time to wait for another operation on the same extension in the same database before failing with status 409 (Conflict)")
+	var databaseLocking = flag.Bool("databaseLocking", false, "Serialize operations of multiple extension manager instances using a lock table in the extension schema")
+	var additionalBucketFsBasePaths = flag.String("additionalBucketFsBasePaths", "", `Comma-separated list of BucketFS base paths searched for extension files after "/buckets/bfsdefault/default/", e.g. "/buckets/bfsjars/jars/"`)
+	var extensionSchema = flag.String("extensionSchema", restAPI.EXTENSION_SCHEMA_NAME, "Default schema where the extension manager searches for and creates extensions")
+	var allowedExtensionSchemas = flag.String("allowedExtensionSchemas", "", `Comma-separated list of additional schemas that clients may select with query parameter extensionSchema, e.g. "EXA_EXTENSIONS_TEST,EXA_EXTENSIONS_STAGING"`)
+	var lintExtensionFile = flag.String("lint", "", "Statically validate the given extension JavaScript file, print the result as JSON and exit instead of starting the server")
+	var applyManifestFile = flag.String("apply", "", `Make the database match the given manifest file (JSON or YAML) and exit instead of starting the server. Read the credentials from environment variables `+dbUserEnv+` and `+dbPasswordEnv+` or `+dbAccessTokenEnv)
+	var applyPreview = flag.Bool("applyPreview", false, "Only print the changes planned by -apply without applying them")
+	var dbHost = flag.String("dbHost", "", "Host of the database for -apply")
+	var dbPort = flag.Int("dbPort", 8563, "Port of the database for -apply")
+	var acceptLicenses = flag.Bool("acceptLicenses", false, "Accept the licenses of all files that require a license agreement when using -apply")
+	var force = flag.Bool("force", false, "Use deprecated versions even if the deprecated version policy rejects them when using -apply")
 	flag.Parse()
 	log.SetLevel(log.DebugLevel)
 	log.SetFormatter(&simpleFormatter{})
-	if openAPIOutputPath != nil && *openAPIOutputPath != "" {
+	config := extensionController.ExtensionManagerConfig{
+		ExtensionRegistryURL:        *extensionRegistryURL,
+		ExtensionSchema:             *extensionSchema,
+		AllowedExtensionSchemas:     splitList(*allowedExtensionSchemas),
+		BucketFSBasePath:            "/buckets/bfsdefault/default/",
+		AdditionalBucketFSBasePaths: splitList(*additionalBucketFsBasePaths),
+		BucketFSUploadURL:           *bucketFsUploadURL,
+		BucketFSWritePassword:       os.Getenv(bucketFsWritePasswordEnv),
+		BucketFSReadPassword:        os.Getenv(bucketFsReadPasswordEnv),
+		UploadTimeout:               *uploadTimeout,
+		VerifyInstallations:         *verifyInstallations,
+		DeprecatedVersionPolicy:     extensionController.DeprecatedVersionPolicy(*deprecatedVersionPolicy),
+		LockTimeout:                 *lockTimeout,
+		DatabaseLocking:             *databaseLocking,
+	}
+	if lintExtensionFile != nil && *lintExtensionFile != "" {
+		log.SetLevel(log.WarnLevel)
+		err := runLint(*lintExtensionFile, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	} else if openAPIOutputPath != nil && *openAPIOutputPath != "" {
 		err := generateOpenAPISpec(*openAPIOutputPath)
 		if err != nil {
 			fmt.Printf("failed to generate OpenAPI to %q: %v\n", *openAPIOutputPath, err)
 			os.Exit(1)
 		}
+	} else if applyManifestFile != nil && *applyManifestFile != "" {
+		err := startApply(config, applyArgs{manifestFile: *applyManifestFile, dbHost: *dbHost, dbPort: *dbPort, preview: *applyPreview,
+			options: extensionController.ApplyOptions{AcceptLicenses: *acceptLicenses, Force: *force}})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 	} else {
-		err := startServer(*extensionRegistryURL, *serverAddress, *addCauseToInternalServerError)
+		err := startServer(config, *serverAddress, *addCauseToInternalServerError)
 		if err != nil {
 			fmt.Printf("failed to start server: %v\n", err)
 			os.Exit(1)
@@ -38,15 +84,17 @@ func main() {
 	}
 }
 
-func startServer(pathToExtensionFolder string, serverAddress string, addCauseToInternalServerError bool) error {
-	if pathToExtensionFolder == "" {
+const (
+	bucketFsWritePasswordEnv = "BUCKETFS_WRITE_PASSWORD"
+	bucketFsReadPasswordEnv  = "BUCKETFS_READ_PASSWORD"
+)
+
+func startServer(config extensionController.ExtensionManagerConfig, serverAddress string, addCauseToInternalServerError bool) error {
+	if config.ExtensionRegistryURL == "" {
 		return errors.New("please specify extension registry with parameter '-extensionRegistryURL'")
 	}
-	log.Printf("Starting extension manager with extension folder %q", pathToExtensionFolder)
-	controller, err := extensionController.CreateWithValidatedConfig(extensionController.ExtensionManagerConfig{
-		ExtensionRegistryURL: pathToExtensionFolder,
-		ExtensionSchema:      restAPI.EXTENSION_SCHEMA_NAME,
-		BucketFSBasePath:     "/buckets/bfsdefault/default/"})
+	log.Printf("Starting extension manager with extension folder %q", config.ExtensionRegistryURL)
+	controller, err := extensionController.CreateWithValidatedConfig(config)
 	if err != nil {
 		return err
 	}
@@ -100,3 +148,14 @@ type simpleFormatter struct {
 func (f *simpleFormatter) Format(entry *log.Entry) ([]byte, error) {
 	return []byte(fmt.Sprintf("%-7s %s\n", strings.ToUpper(entry.Level.String()), entry.Message)), nil
 }
+
+// splitList splits a comma-separated list, ignoring surrounding whitespace and empty entries.
+func splitList(list string) []string {
+	var result []string
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitList(t *testing.T) {
+	var tests = []struct {
+		list     string
+		expected []string
+	}{
+		{list: "", expected: nil},
+		{list: "A", expected: []string{"A"}},
+		{list: "A,B", expected: []string{"A", "B"}},
+		{list: " A , ,B, ", expected: []string{"A", "B"}},
+	}
+	for _, test := range tests {
+		t.Run(test.list, func(t *testing.T) {
+			assert.Equal(t, test.expected, splitList(test.list))
+		})
+	}
+}
@@ -11,6 +11,9 @@ func NewInternalServerError(originalError error) *APIError {
 		Status:        http.StatusInternalServerError,
 		Message:       "Internal server error",
 		RequestID:     "",
+		ErrorCode:     "",
+		ParameterID:   "",
+		Details:       nil,
 		OriginalError: originalError,
 	}
 }
@@ -32,6 +35,9 @@ func NewAPIErrorF(status int, format string, a ...interface{}) error {
 		Status:        status,
 		Message:       fmt.Sprintf(format, a...),
 		RequestID:     "",
+		ErrorCode:     "",
+		ParameterID:   "",
+		Details:       nil,
 		OriginalError: nil,
 	}
 }
@@ -41,6 +47,23 @@ func NewAPIError(status int, message string) error {
 		Status:        status,
 		Message:       message,
 		RequestID:     "",
+		ErrorCode:     "",
+		ParameterID:   "",
+		Details:       nil,
+		OriginalError: nil,
+	}
+}
+
+// NewDetailedAPIError creates a new API error with additional information like a stable error code,
+// the ID of the parameter that caused the error and arbitrary details. All additional fields are optional.
+func NewDetailedAPIError(status int, message string, errorCode string, parameterID string, details map[string]any) error {
+	return &APIError{
+		Status:        status,
+		Message:       message,
+		RequestID:     "",
+		ErrorCode:     errorCode,
+		ParameterID:   parameterID,
+		Details:       details,
 		OriginalError: nil,
 	}
 }
@@ -52,6 +75,9 @@ func NewAPIErrorWithCause(message string, cause error) error {
 			Status:        apiErr.Status,
 			Message:       fmt.Sprintf("%s: %s", message, apiErr.Message),
 			RequestID:     "",
+			ErrorCode:     apiErr.ErrorCode,
+			ParameterID:   apiErr.ParameterID,
+			Details:       apiErr.Details,
 			OriginalError: cause,
 		}
 	}
@@ -68,10 +94,13 @@ func UnwrapAPIError(err error) *APIError {
 }
 
 type APIError struct {
-	Status        int    `json:"code"`                // HTTP status code
-	Message       string `json:"message"`             // human-readable message
-	RequestID     string `json:"requestID,omitempty"` // ID to identify the request that caused this error
-	OriginalError error  `json:"-"`
+	Status        int            `json:"code"`                  // HTTP status code
+	Message       string         `json:"message"`               // human-readable message
+	RequestID     string         `json:"requestID,omitempty"`   // ID to identify the request that caused this error
+	ErrorCode     string         `json:"errorCode,omitempty"`   // stable, machine-readable error code, e.g. reported by an extension
+	ParameterID   string         `json:"parameterId,omitempty"` // ID of the parameter that caused this error
+	Details       map[string]any `json:"details,omitempty"`     // additional details about the error
+	OriginalError error          `json:"-"`
 }
 
 func (a *APIError) Error() string {
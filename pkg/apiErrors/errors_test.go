@@ -42,6 +42,25 @@ func TestNewAPIErrorWithCauseApiErrorCause(t *testing.T) {
 	assertApiError(t, err, "msg: cause", 123, cause)
 }
 
+func TestNewDetailedAPIError(t *testing.T) {
+	err := apiErrors.NewDetailedAPIError(400, "err", "E-1", "param", map[string]any{"key": "value"})
+	assertApiError(t, err, "err", 400, nil)
+	apiErr, _ := apiErrors.AsAPIError(err)
+	assert.Equal(t, "E-1", apiErr.ErrorCode)
+	assert.Equal(t, "param", apiErr.ParameterID)
+	assert.Equal(t, map[string]any{"key": "value"}, apiErr.Details)
+}
+
+func TestNewAPIErrorWithCauseKeepsDetails(t *testing.T) {
+	cause := apiErrors.NewDetailedAPIError(400, "cause", "E-1", "param", map[string]any{"key": "value"})
+	err := apiErrors.NewAPIErrorWithCause("msg", cause)
+	assertApiError(t, err, "msg: cause", 400, cause)
+	apiErr, _ := apiErrors.AsAPIError(err)
+	assert.Equal(t, "E-1", apiErr.ErrorCode)
+	assert.Equal(t, "param", apiErr.ParameterID)
+	assert.Equal(t, map[string]any{"key": "value"}, apiErr.Details)
+}
+
 func TestNewAPIErrorWithCauseNonApiErrorCause(t *testing.T) {
 	cause := errors.New("cause")
 	err := apiErrors.NewAPIErrorWithCause("msg", cause)
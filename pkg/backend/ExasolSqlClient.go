@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/exasol/extension-manager/pkg/metrics"
 	"github.com/sirupsen/logrus"
 )
 
@@ -36,7 +38,9 @@ func (c *exasolSqlClient) Execute(query string, args ...any) (sql.Result, error)
 		return nil, err
 	}
 	logrus.Tracef("Executing SQL statement %q...", query)
+	t0 := time.Now()
 	result, err := c.transaction.ExecContext(c.ctx, query, args...)
+	metrics.ObserveSqlStatement(metrics.SqlStatementExecute, t0, err)
 	if err != nil {
 		return nil, fmt.Errorf("error executing statement '%s': %w", query, err)
 	}
@@ -51,6 +55,8 @@ func (c *exasolSqlClient) Query(query string, args ...any) (result *QueryResult,
 		return nil, err
 	}
 	logrus.Tracef("Executing SQL query %q...", query)
+	t0 := time.Now()
+	defer func() { metrics.ObserveSqlStatement(metrics.SqlStatementQuery, t0, errResult) }()
 	rows, err := c.transaction.QueryContext(c.ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error executing query '%s': %w", query, err)
@@ -0,0 +1,26 @@
+package backend
+
+import "database/sql"
+
+// StatementRecorder is called for each statement before it is executed.
+type StatementRecorder func(query string, args ...any)
+
+type recordingSqlClient struct {
+	delegate SimpleSQLClient
+	recorder StatementRecorder
+}
+
+// NewRecordingSqlClient creates a new [SimpleSQLClient] that passes all statements to the given recorder
+// before executing them using the delegate. Queries are not recorded.
+func NewRecordingSqlClient(delegate SimpleSQLClient, recorder StatementRecorder) SimpleSQLClient {
+	return &recordingSqlClient{delegate: delegate, recorder: recorder}
+}
+
+func (c *recordingSqlClient) Execute(query string, args ...any) (sql.Result, error) {
+	c.recorder(query, args...)
+	return c.delegate.Execute(query, args...)
+}
+
+func (c *recordingSqlClient) Query(query string, args ...any) (*QueryResult, error) {
+	return c.delegate.Query(query, args...)
+}
@@ -0,0 +1,44 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RecordingSqlClientSuite struct {
+	suite.Suite
+	delegate *SimpleSqlClientMock
+	recorded []string
+	client   SimpleSQLClient
+}
+
+func TestRecordingSqlClientSuite(t *testing.T) {
+	suite.Run(t, new(RecordingSqlClientSuite))
+}
+
+func (suite *RecordingSqlClientSuite) SetupTest() {
+	suite.delegate = CreateSimpleSqlClientMock()
+	suite.recorded = nil
+	suite.client = NewRecordingSqlClient(suite.delegate, func(query string, args ...any) {
+		suite.recorded = append(suite.recorded, query)
+	})
+}
+
+func (suite *RecordingSqlClientSuite) TearDownTest() {
+	suite.delegate.AssertExpectations(suite.T())
+}
+
+func (suite *RecordingSqlClientSuite) TestExecuteRecordsStatement() {
+	suite.delegate.SimulateExecuteSuccess("create script", "arg")
+	_, err := suite.client.Execute("create script", "arg")
+	suite.Require().NoError(err)
+	suite.Equal([]string{"create script"}, suite.recorded)
+}
+
+func (suite *RecordingSqlClientSuite) TestQueryIsNotRecorded() {
+	suite.delegate.SimulateQuerySuccess(&QueryResult{Columns: nil, Rows: nil}, "select 1", "arg")
+	_, err := suite.client.Query("select 1", "arg")
+	suite.Require().NoError(err)
+	suite.Empty(suite.recorded)
+}
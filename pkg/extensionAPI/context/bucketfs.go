@@ -10,11 +10,13 @@ import (
 /* [impl -> dsn~extension-context-bucketfs~1]. */
 type BucketFsContext interface {
 	// ResolvePath returns an absolute path for the given filename in BucketFS.
+	// If the extension declares a bucket for an upload with this file name, the file is only searched in this bucket.
 	ResolvePath(fileName string) string
 }
 
 type bucketFsContextImpl struct {
-	txCtx *transaction.TransactionContext
+	txCtx       *transaction.TransactionContext
+	fileBuckets map[string]string // Bucket per file name, missing for files that are searched in all buckets
 }
 
 /* [impl -> dsn~resolving-files-in-bucketfs~1]. */
@@ -31,5 +33,5 @@ func (b *bucketFsContextImpl) resolvePath(fileName string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return bfsClient.FindAbsolutePath(fileName)
+	return bfsClient.FindAbsolutePath(fileName, b.fileBuckets[fileName])
 }
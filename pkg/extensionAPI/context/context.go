@@ -6,15 +6,20 @@ import (
 	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
 )
 
-func CreateContext(txCtx *transaction.TransactionContext, extensionSchemaName string) *ExtensionContext {
+// CreateContext creates the context for an extension.
+// fileBuckets maps the file names of the extension's BucketFS uploads to the bucket in which [BucketFsContext.ResolvePath] searches them.
+func CreateContext(txCtx *transaction.TransactionContext, extensionSchemaName string, fileBuckets map[string]string) *ExtensionContext {
 	var sqlClient backend.SimpleSQLClient = backend.NewSqlClient(txCtx.GetContext(), txCtx.GetTransaction())
 	var metadataReader exaMetadata.ExaMetadataReader = exaMetadata.CreateExaMetaDataReader()
-	var bfsContext BucketFsContext = &bucketFsContextImpl{txCtx: txCtx}
+	var bfsContext BucketFsContext = &bucketFsContextImpl{txCtx: txCtx, fileBuckets: fileBuckets}
 	return CreateContextWithClient(extensionSchemaName, txCtx, sqlClient, bfsContext, metadataReader)
 }
 
 func CreateContextWithClient(extensionSchemaName string, txCtx *transaction.TransactionContext,
 	client backend.SimpleSQLClient, bucketFsContext BucketFsContext, metadataReader exaMetadata.ExaMetadataReader) *ExtensionContext {
+	if recorder := txCtx.GetDryRunRecorder(); recorder != nil {
+		client = backend.NewRecordingSqlClient(client, recorder.RecordStatement)
+	}
 	return &ExtensionContext{
 		ExtensionSchemaName: extensionSchemaName,
 		SqlClient:           &contextSqlClient{client},
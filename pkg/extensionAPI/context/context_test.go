@@ -9,6 +9,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/exasol/extension-manager/pkg/backend"
 	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/exasol/extension-manager/pkg/extensionController/bfs"
 	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
 	"github.com/stretchr/testify/suite"
 )
@@ -85,6 +86,17 @@ func (suite *ContextSuite) TestSqlClientExecuteSuccess() {
 	})
 }
 
+func (suite *ContextSuite) TestSqlClientExecuteRecordsStatementsInDryRun() {
+	suite.dbMock.ExpectBegin()
+	txCtx, err := transaction.BeginTransaction(context.Background(), suite.db, BUCKETFS_BASE_PATH)
+	suite.Require().NoError(err)
+	recorder := txCtx.EnableDryRun()
+	ctx := CreateContext(txCtx, "EXT_SCHEMA", nil)
+	suite.dbMock.ExpectExec("create script").WillReturnResult(sqlmock.NewResult(1, 1))
+	ctx.SqlClient.Execute("create script", "arg")
+	suite.Equal([]transaction.RecordedStatement{{Query: "create script", Args: []any{"arg"}}}, recorder.Statements())
+}
+
 func (suite *ContextSuite) TestSqlClientExecuteFailure() {
 	ctx := suite.createContext()
 	suite.dbMock.ExpectExec("invalid").WillReturnError(errors.New("mock error"))
@@ -108,6 +120,25 @@ func (suite *ContextSuite) TestBucketFsResolvePathError() {
 	})
 }
 
+func (suite *ContextSuite) TestBucketFsResolvePathSearchesBucketOfUpload() {
+	bfsMock := bfs.CreateBucketFsMock()
+	bfsMock.SimulateAbsolutePath("file.jar", "bfsjars/jars", "/buckets/bfsjars/jars/file.jar")
+	bfsMock.SimulateAbsolutePath("other.jar", "", "/buckets/bfsdefault/default/other.jar")
+	ctx := suite.createContextWithBucketFsMock(bfsMock, map[string]string{"file.jar": "bfsjars/jars"})
+	suite.Equal("/buckets/bfsjars/jars/file.jar", ctx.BucketFs.ResolvePath("file.jar"))
+	suite.Equal("/buckets/bfsdefault/default/other.jar", ctx.BucketFs.ResolvePath("other.jar"))
+	bfsMock.AssertExpectations(suite.T())
+}
+
+func (suite *ContextSuite) TestBucketFsResolvePathFailsIfNotFoundInBucket() {
+	bfsMock := bfs.CreateBucketFsMock()
+	bfsMock.SimulateAbsolutePathError("file.jar", "bfsjars/jars", errors.New("mock error"))
+	ctx := suite.createContextWithBucketFsMock(bfsMock, map[string]string{"file.jar": "bfsjars/jars"})
+	suite.PanicsWithError(`failed to find absolute path for file "file.jar": mock error`, func() {
+		ctx.BucketFs.ResolvePath("file.jar")
+	})
+}
+
 /* [utest -> dsn~extension-context-metadata~1]. */
 func (suite *ContextSuite) TestMetadataGetScriptByName() {
 	ctx := suite.createContextWithClients()
@@ -133,7 +164,14 @@ func (suite *ContextSuite) createContext() *ExtensionContext {
 	suite.dbMock.ExpectBegin()
 	txCtx, err := transaction.BeginTransaction(context.Background(), suite.db, BUCKETFS_BASE_PATH)
 	suite.Require().NoError(err)
-	return CreateContext(txCtx, "EXT_SCHEMA")
+	return CreateContext(txCtx, "EXT_SCHEMA", nil)
+}
+
+func (suite *ContextSuite) createContextWithBucketFsMock(bfsMock *bfs.BucketFsMock, fileBuckets map[string]string) *ExtensionContext {
+	suite.dbMock.ExpectBegin()
+	txCtx, err := transaction.CreateTransactionStarterMock(suite.db, bfsMock).GetTransactionStarter()(context.Background(), suite.db, nil)
+	suite.Require().NoError(err)
+	return CreateContext(txCtx, "EXT_SCHEMA", fileBuckets)
 }
 
 func (suite *ContextSuite) createContextWithClients() *ExtensionContext {
@@ -142,3 +180,35 @@ func (suite *ContextSuite) createContextWithClients() *ExtensionContext {
 	suite.Require().NoError(err)
 	return CreateContextWithClient("EXT_SCHEMA", txCtx, nil, suite.bucketFSMock, suite.metadataReaderMock)
 }
+
+func (suite *ContextSuite) TestSynchronizedContextDelegates() {
+	ctx := NewSynchronizedContext(suite.createContextWithClients())
+	suite.Equal("EXT_SCHEMA", ctx.ExtensionSchemaName)
+	suite.bucketFSMock.SimulateResolvePath("file.txt", "/absolute/path/file.txt")
+	suite.Equal("/absolute/path/file.txt", ctx.BucketFs.ResolvePath("file.txt"))
+	suite.metadataReaderMock.SimulateGetScriptByName("script", nil)
+	suite.Nil(ctx.Metadata.GetScriptByName("script"))
+}
+
+func (suite *ContextSuite) TestSynchronizedContextReleasesLockAfterPanic() {
+	ctx := NewSynchronizedContext(suite.createContext())
+	suite.dbMock.ExpectExec("invalid").WillReturnError(errors.New("mock error"))
+	suite.dbMock.ExpectExec("create script").WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.PanicsWithError("error executing statement 'invalid': mock error", func() {
+		ctx.SqlClient.Execute("invalid")
+	})
+	suite.NotPanics(func() {
+		ctx.SqlClient.Execute("create script")
+	})
+}
+
+func (suite *ContextSuite) TestSynchronizedContextsDelegateToTheirContext() {
+	otherBucketFsMock := CreateBucketFsContextMock()
+	otherContext := suite.createContextWithClients()
+	otherContext.BucketFs = otherBucketFsMock
+	contexts := NewSynchronizedContexts([]*ExtensionContext{suite.createContextWithClients(), otherContext})
+	suite.bucketFSMock.SimulateResolvePath("file.txt", "/bucket1/file.txt")
+	otherBucketFsMock.SimulateResolvePath("file.txt", "/bucket2/file.txt")
+	suite.Equal("/bucket1/file.txt", contexts[0].BucketFs.ResolvePath("file.txt"))
+	suite.Equal("/bucket2/file.txt", contexts[1].BucketFs.ResolvePath("file.txt"))
+}
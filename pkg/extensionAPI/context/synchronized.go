@@ -0,0 +1,73 @@
+package context
+
+import (
+	"sync"
+
+	"github.com/exasol/extension-manager/pkg/backend"
+	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+)
+
+// NewSynchronizedContext returns a copy of the given context that serializes all access to the database and BucketFS.
+// This allows sharing a context with a single transaction between extensions running concurrently.
+func NewSynchronizedContext(ctx *ExtensionContext) *ExtensionContext {
+	return synchronizeContext(ctx, &sync.Mutex{})
+}
+
+// NewSynchronizedContexts works like [NewSynchronizedContext] for multiple contexts sharing a single transaction,
+// e.g. the contexts of different extensions. Access using any of the returned contexts is serialized.
+func NewSynchronizedContexts(contexts []*ExtensionContext) []*ExtensionContext {
+	mutex := &sync.Mutex{}
+	synchronized := make([]*ExtensionContext, len(contexts))
+	for i, ctx := range contexts {
+		synchronized[i] = synchronizeContext(ctx, mutex)
+	}
+	return synchronized
+}
+
+func synchronizeContext(ctx *ExtensionContext, mutex *sync.Mutex) *ExtensionContext {
+	return &ExtensionContext{
+		ExtensionSchemaName: ctx.ExtensionSchemaName,
+		SqlClient:           &synchronizedSqlClient{mutex: mutex, delegate: ctx.SqlClient},
+		BucketFs:            &synchronizedBucketFs{mutex: mutex, delegate: ctx.BucketFs},
+		Metadata:            &synchronizedMetadata{mutex: mutex, delegate: ctx.Metadata},
+	}
+}
+
+type synchronizedSqlClient struct {
+	mutex    *sync.Mutex
+	delegate ContextSqlClient
+}
+
+func (c *synchronizedSqlClient) Execute(query string, args ...any) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.delegate.Execute(query, args...)
+}
+
+func (c *synchronizedSqlClient) Query(query string, args ...any) backend.QueryResult {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.delegate.Query(query, args...)
+}
+
+type synchronizedBucketFs struct {
+	mutex    *sync.Mutex
+	delegate BucketFsContext
+}
+
+func (b *synchronizedBucketFs) ResolvePath(fileName string) string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.delegate.ResolvePath(fileName)
+}
+
+type synchronizedMetadata struct {
+	mutex    *sync.Mutex
+	delegate MetadataContext
+}
+
+func (m *synchronizedMetadata) GetScriptByName(name string) *exaMetadata.ExaScriptRow {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.delegate.GetScriptByName(name)
+}
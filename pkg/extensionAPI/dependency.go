@@ -0,0 +1,92 @@
+package extensionAPI
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// ExtensionDependency describes another extension that must be installed before an extension can be installed.
+type ExtensionDependency struct {
+	ExtensionId  string `json:"extensionId"`  // ID of the required extension, e.g. "exasol-driver.js"
+	VersionRange string `json:"versionRange"` // Accepted versions, e.g. ">=1.0.0 <2.0.0". Empty or "*" accepts any version.
+}
+
+// Matches checks if the given version is part of the dependency's version range.
+func (d ExtensionDependency) Matches(version string) (bool, error) {
+	return VersionInRange(d.VersionRange, version)
+}
+
+// VersionInRange checks if the given version is part of the given version range.
+// A range consists of space separated comparisons like ">=1.0.0 <2.0.0" that must all be fulfilled.
+// Supported operators are "=", "!=", ">", ">=", "<" and "<=". A version without operator requires an exact match.
+// An empty range or "*" accepts any version.
+func VersionInRange(versionRange, version string) (bool, error) {
+	comparisons, err := parseVersionRange(versionRange)
+	if err != nil {
+		return false, err
+	}
+	prefixedVersion := "v" + version
+	if !semver.IsValid(prefixedVersion) {
+		return false, fmt.Errorf("invalid version %q", version)
+	}
+	for _, c := range comparisons {
+		if !c.matches(prefixedVersion) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ValidateVersionRange returns an error if the given version range has an invalid format.
+func ValidateVersionRange(versionRange string) error {
+	_, err := parseVersionRange(versionRange)
+	return err
+}
+
+type versionComparison struct {
+	operator string
+	version  string
+}
+
+func (c versionComparison) matches(prefixedVersion string) bool {
+	result := semver.Compare(prefixedVersion, c.version)
+	switch c.operator {
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case "!=":
+		return result != 0
+	default:
+		return result == 0
+	}
+}
+
+func parseVersionRange(versionRange string) ([]versionComparison, error) {
+	trimmedRange := strings.TrimSpace(versionRange)
+	if trimmedRange == "" || trimmedRange == "*" {
+		return nil, nil
+	}
+	comparisons := make([]versionComparison, 0)
+	for _, part := range strings.Fields(trimmedRange) {
+		operator := ""
+		for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+			if strings.HasPrefix(part, op) {
+				operator = op
+				break
+			}
+		}
+		version := strings.TrimPrefix(part, operator)
+		if !semver.IsValid("v" + version) {
+			return nil, fmt.Errorf("invalid version range %q: %q is not a valid version", versionRange, version)
+		}
+		comparisons = append(comparisons, versionComparison{operator: operator, version: "v" + version})
+	}
+	return comparisons, nil
+}
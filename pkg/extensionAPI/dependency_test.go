@@ -0,0 +1,58 @@
+package extensionAPI
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionInRange(t *testing.T) {
+	tests := []struct {
+		versionRange string
+		version      string
+		expected     bool
+	}{
+		{"", "1.0.0", true},
+		{"*", "1.0.0", true},
+		{"1.0.0", "1.0.0", true},
+		{"1.0.0", "1.0.1", false},
+		{"=1.0.0", "1.0.0", true},
+		{"!=1.0.0", "1.0.0", false},
+		{"!=1.0.0", "1.0.1", true},
+		{">1.0.0", "1.0.0", false},
+		{">1.0.0", "1.0.1", true},
+		{">=1.0.0", "1.0.0", true},
+		{">=1.0.0", "0.9.9", false},
+		{"<2.0.0", "1.9.9", true},
+		{"<2.0.0", "2.0.0", false},
+		{"<=2.0.0", "2.0.0", true},
+		{">=1.0.0 <2.0.0", "1.5.0", true},
+		{">=1.0.0 <2.0.0", "2.0.0", false},
+		{" >=1.0.0   <2.0.0 ", "0.1.0", false},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%q contains %q", test.versionRange, test.version), func(t *testing.T) {
+			result, err := VersionInRange(test.versionRange, test.version)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestVersionInRangeInvalidRange(t *testing.T) {
+	result, err := VersionInRange(">=1.0.0 <invalid", "1.0.0")
+	assert.EqualError(t, err, `invalid version range ">=1.0.0 <invalid": "invalid" is not a valid version`)
+	assert.False(t, result)
+}
+
+func TestVersionInRangeInvalidVersion(t *testing.T) {
+	result, err := VersionInRange(">=1.0.0", "invalid")
+	assert.EqualError(t, err, `invalid version "invalid"`)
+	assert.False(t, result)
+}
+
+func TestValidateVersionRange(t *testing.T) {
+	assert.NoError(t, ValidateVersionRange(">=1.0.0 <2.0.0"))
+	assert.EqualError(t, ValidateVersionRange("~1.0.0"), `invalid version range "~1.0.0": "~1.0.0" is not a valid version`)
+}
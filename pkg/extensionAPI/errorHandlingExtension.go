@@ -2,22 +2,31 @@ package extensionAPI
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/dop251/goja"
 	"github.com/exasol/extension-manager/pkg/apiErrors"
 	"github.com/exasol/extension-manager/pkg/extensionAPI/context"
 	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/exasol/extension-manager/pkg/metrics"
+	log "github.com/sirupsen/logrus"
 )
 
 type JsExtension struct {
-	extension           *rawJsExtension
-	vm                  *goja.Runtime
-	Id                  string
-	Name                string
-	Category            string
-	Description         string
-	InstallableVersions []JsExtensionVersion
-	BucketFsUploads     []BucketFsUpload
+	extension                    *rawJsExtension
+	vm                           *goja.Runtime
+	logger                       *jsLogger
+	upgradeSupportsTargetVersion bool // True if the extension's upgrade function accepts a target version
+	Id                           string
+	Name                         string
+	Category                     string
+	Description                  string
+	InstallableVersions          []JsExtensionVersion
+	BucketFsUploads              []BucketFsUpload
+	Dependencies                 []ExtensionDependency
+	// RetryOnTransactionConflict is true if the extension declares that its functions can safely be re-run
+	// in a new transaction after Exasol rolled back the transaction because of a conflict.
+	RetryOnTransactionConflict bool
 }
 
 type JsExtensionVersion struct {
@@ -28,14 +37,26 @@ type JsExtensionVersion struct {
 
 func wrapExtension(ext *rawJsExtension, id string, vm *goja.Runtime) *JsExtension {
 	return &JsExtension{
-		extension:           ext,
-		Id:                  id,
-		vm:                  vm,
-		Name:                ext.Name,
-		Category:            ext.Category,
-		Description:         ext.Description,
-		InstallableVersions: convertVersions(ext.InstallableVersions),
-		BucketFsUploads:     ext.BucketFsUploads,
+		extension:                    ext,
+		Id:                           id,
+		vm:                           vm,
+		logger:                       nil,
+		upgradeSupportsTargetVersion: false,
+		Name:                         ext.Name,
+		Category:                     ext.Category,
+		Description:                  ext.Description,
+		InstallableVersions:          convertVersions(ext.InstallableVersions),
+		BucketFsUploads:              ext.BucketFsUploads,
+		Dependencies:                 ext.Dependencies,
+		RetryOnTransactionConflict:   ext.RetryOnTransactionConflict,
+	}
+}
+
+// SetLogListener registers a listener that receives all messages the extension logs via `console`.
+// Passing nil removes the listener.
+func (e *JsExtension) SetLogListener(listener LogListener) {
+	if e.logger != nil {
+		e.logger.listener = listener
 	}
 }
 
@@ -52,6 +73,7 @@ func (e *JsExtension) GetParameterDefinitions(context *context.ExtensionContext,
 	if e.extension.GetParameterDefinitions == nil {
 		return nil, e.unsupportedFunction("getParameterDefinitions")
 	}
+	defer e.observe("getInstanceParameters", time.Now(), &errorResult)
 	defer func() {
 		if err := recover(); err != nil {
 			errorResult = e.convertError(fmt.Sprintf("failed to get parameter definitions for extension %q", e.Id), err)
@@ -64,6 +86,7 @@ func (e *JsExtension) Install(context *context.ExtensionContext, version string)
 	if e.extension.Install == nil {
 		return e.unsupportedFunction("install")
 	}
+	defer e.observe("install", time.Now(), &errorResult)
 	defer func() {
 		if err := recover(); err != nil {
 			errorResult = e.convertError(fmt.Sprintf("failed to install extension %q", e.Id), err)
@@ -77,6 +100,7 @@ func (e *JsExtension) Uninstall(context *context.ExtensionContext, version strin
 	if e.extension.Uninstall == nil {
 		return e.unsupportedFunction("uninstall")
 	}
+	defer e.observe("uninstall", time.Now(), &errorResult)
 	defer func() {
 		if err := recover(); err != nil {
 			errorResult = e.convertError(fmt.Sprintf("failed to uninstall extension %q", e.Id), err)
@@ -86,22 +110,57 @@ func (e *JsExtension) Uninstall(context *context.ExtensionContext, version strin
 	return nil
 }
 
-func (e *JsExtension) Upgrade(context *context.ExtensionContext) (result *JsUpgradeResult, errorResult error) {
+// SupportsUpgradeToVersion returns true if the extension supports upgrading (or downgrading) to a specific version.
+func (e *JsExtension) SupportsUpgradeToVersion() bool {
+	return e.extension.Upgrade != nil && e.upgradeSupportsTargetVersion
+}
+
+// Upgrade upgrades the extension to the given target version or to the latest version if targetVersion is empty.
+func (e *JsExtension) Upgrade(context *context.ExtensionContext, targetVersion string) (result *JsUpgradeResult, errorResult error) {
 	if e.extension.Upgrade == nil {
 		return nil, e.unsupportedFunction("upgrade")
 	}
+	if targetVersion != "" && !e.upgradeSupportsTargetVersion {
+		return nil, apiErrors.NewBadRequestErrorF("extension %q does not support upgrading to a specific version", e.Id)
+	}
+	defer e.observe("upgrade", time.Now(), &errorResult)
 	defer func() {
 		if err := recover(); err != nil {
 			errorResult = e.convertError(fmt.Sprintf("failed to upgrade extension %q", e.Id), err)
 		}
 	}()
-	return e.extension.Upgrade(context), nil
+	targetVersionValue := goja.Undefined()
+	if targetVersion != "" {
+		targetVersionValue = e.vm.ToValue(targetVersion)
+	}
+	return e.extension.Upgrade(context, targetVersionValue), nil
+}
+
+// SupportsRepair returns true if the extension implements the optional repair function.
+func (e *JsExtension) SupportsRepair() bool {
+	return e.extension.Repair != nil
+}
+
+// Repair re-creates the database objects of the installed version, e.g. an adapter script dropped by a user.
+func (e *JsExtension) Repair(context *context.ExtensionContext, version string) (errorResult error) {
+	if e.extension.Repair == nil {
+		return e.unsupportedFunction("repair")
+	}
+	defer e.observe("repair", time.Now(), &errorResult)
+	defer func() {
+		if err := recover(); err != nil {
+			errorResult = e.convertError(fmt.Sprintf("failed to repair extension %q", e.Id), err)
+		}
+	}()
+	e.extension.Repair(context, version)
+	return nil
 }
 
 func (e *JsExtension) FindInstallations(context *context.ExtensionContext, metadata *exaMetadata.ExaMetadata) (installations []*JsExtInstallation, errorResult error) {
 	if e.extension.FindInstallations == nil {
 		return nil, e.unsupportedFunction("findInstallations")
 	}
+	defer e.observe("findInstallations", time.Now(), &errorResult)
 	defer func() {
 		if err := recover(); err != nil {
 			errorResult = e.convertError(fmt.Sprintf("failed to find installations for extension %q", e.Id), err)
@@ -114,6 +173,7 @@ func (e *JsExtension) AddInstance(context *context.ExtensionContext, version str
 	if e.extension.AddInstance == nil {
 		return nil, e.unsupportedFunction("addInstance")
 	}
+	defer e.observe("addInstance", time.Now(), &errorResult)
 	defer func() {
 		if err := recover(); err != nil {
 			errorResult = e.convertError(fmt.Sprintf("failed to add instance for extension %q", e.Id), err)
@@ -145,6 +205,7 @@ func (e *JsExtension) ListInstances(context *context.ExtensionContext, version s
 	if e.extension.FindInstances == nil {
 		return nil, e.unsupportedFunction("findInstances")
 	}
+	defer e.observe("findInstances", time.Now(), &errorResult)
 	defer func() {
 		if err := recover(); err != nil {
 			errorResult = e.convertError(fmt.Sprintf("failed to list instances for extension %q in version %q", e.Id, version), err)
@@ -157,6 +218,7 @@ func (e *JsExtension) DeleteInstance(context *context.ExtensionContext, extensio
 	if e.extension.DeleteInstance == nil {
 		return e.unsupportedFunction("deleteInstance")
 	}
+	defer e.observe("deleteInstance", time.Now(), &errorResult)
 	defer func() {
 		if err := recover(); err != nil {
 			errorResult = e.convertError(fmt.Sprintf("failed to delete instance %q for extension %q", instanceId, e.Id), err)
@@ -166,6 +228,12 @@ func (e *JsExtension) DeleteInstance(context *context.ExtensionContext, extensio
 	return
 }
 
+// observe records the duration and outcome of a call to an extension function.
+// Defer it before recovering from panics so that it sees the converted error.
+func (e *JsExtension) observe(function string, start time.Time, errorResult *error) {
+	metrics.ObserveJsFunction(e.Id, function, start, *errorResult)
+}
+
 func (e *JsExtension) convertError(message string, err any) error {
 	if exception, ok := err.(*goja.Exception); ok {
 		if exception.Value() == nil {
@@ -180,7 +248,14 @@ func (e *JsExtension) convertError(message string, err any) error {
 		if exportErr != nil {
 			return fmt.Errorf("failed to convert error %v of type %T (message: %q) to ApiError: %w", err, err, message, exportErr)
 		}
-		return apiErrors.NewAPIError(apiError.Status, apiError.Message)
+		if apiError.Stack != "" {
+			log.Debugf("Extension %q failed with status %d: %s\n%s", e.Id, apiError.Status, apiError.Message, apiError.Stack)
+		}
+		details, isObject := apiError.Details.(map[string]any)
+		if !isObject && apiError.Details != nil {
+			log.Debugf("Extension %q returned details of type %T instead of an object, ignoring them", e.Id, apiError.Details)
+		}
+		return apiErrors.NewDetailedAPIError(apiError.Status, apiError.Message, apiError.Code, apiError.ParameterId, details)
 	}
 	return basicError(message, err)
 }
@@ -189,11 +264,41 @@ func basicError(message string, err any) error {
 	return fmt.Errorf("%s: %v", message, err)
 }
 
+// UnsupportedFunctions returns the JavaScript names of all extension functions that the extension does not implement.
+func (e *JsExtension) UnsupportedFunctions() []string {
+	functions := []struct {
+		name        string
+		implemented bool
+	}{
+		{"getInstanceParameters", e.extension.GetParameterDefinitions != nil},
+		{"install", e.extension.Install != nil},
+		{"uninstall", e.extension.Uninstall != nil},
+		{"upgrade", e.extension.Upgrade != nil},
+		{"findInstallations", e.extension.FindInstallations != nil},
+		{"addInstance", e.extension.AddInstance != nil},
+		{"findInstances", e.extension.FindInstances != nil},
+		{"deleteInstance", e.extension.DeleteInstance != nil},
+	}
+	unsupported := make([]string, 0)
+	for _, f := range functions {
+		if !f.implemented {
+			unsupported = append(unsupported, f.name)
+		}
+	}
+	return unsupported
+}
+
 func (e *JsExtension) unsupportedFunction(functionName string) error {
 	return fmt.Errorf("extension %q does not support operation %q", e.Id, functionName)
 }
 
+// jsApiError represents an error object thrown by an extension.
+// Only status and message are mandatory, all other fields are optional.
 type jsApiError struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
+	Status      int    `json:"status"`
+	Message     string `json:"message"`
+	Code        string `json:"code"`        // Stable error code, e.g. "E-VS-S3-1"
+	ParameterId string `json:"parameterId"` // ID of the parameter that caused the error
+	Details     any    `json:"details"`     // Additional details about the error, only used if it is an object
+	Stack       string `json:"stack"`       // JavaScript stack trace, only logged and not sent to the client
 }
@@ -32,17 +32,18 @@ func (suite *ErrorHandlingExtensionSuite) SetupSuite() {
 		Category:                "category",
 		Description:             "desc",
 		InstallableVersions:     []rawJsExtensionVersion{{Name: "v1", Deprecated: true, Latest: false}, {Name: "v2", Deprecated: false, Latest: true}},
-		BucketFsUploads:         []BucketFsUpload{{Name: "uploadName", DownloadURL: "url", LicenseURL: "license", FileSize: 123, BucketFsFilename: "filename"}},
+		BucketFsUploads:         []BucketFsUpload{{Name: "uploadName", DownloadURL: "url", LicenseURL: "license", LicenseAgreementRequired: false, FileSize: 123, BucketFsFilename: "filename"}},
 		GetParameterDefinitions: nil,
 		Install:                 nil,
 		Uninstall:               nil,
 		Upgrade:                 nil,
+		Repair:                  nil,
 		FindInstallations:       nil,
 		AddInstance:             nil,
 		FindInstances:           nil,
 		DeleteInstance:          nil,
 	}
-	suite.extension = wrapExtension(suite.rawExtension, "id", newJavaScriptVm("logPrefix>"))
+	suite.extension = wrapExtension(suite.rawExtension, "id", newJavaScriptVm(createJavaScriptLogger("logPrefix>")))
 }
 
 func (suite *ErrorHandlingExtensionSuite) TestProperties() {
@@ -52,7 +53,7 @@ func (suite *ErrorHandlingExtensionSuite) TestProperties() {
 		Name:                "name",
 		Description:         "desc",
 		InstallableVersions: []JsExtensionVersion{{Name: "v1", Deprecated: true, Latest: false}, {Name: "v2", Deprecated: false, Latest: true}},
-		BucketFsUploads:     []BucketFsUpload{{Name: "uploadName", DownloadURL: "url", LicenseURL: "license", FileSize: 123, BucketFsFilename: "filename"}},
+		BucketFsUploads:     []BucketFsUpload{{Name: "uploadName", DownloadURL: "url", LicenseURL: "license", LicenseAgreementRequired: false, FileSize: 123, BucketFsFilename: "filename"}},
 		extension:           suite.rawExtension,
 		vm:                  suite.extension.vm},
 		suite.extension)
@@ -183,30 +184,93 @@ func (suite *ErrorHandlingExtensionSuite) TestUninstallUnsupported() {
 // Upgrade
 
 func (suite *ErrorHandlingExtensionSuite) TestUpgradeSuccessful() {
-	suite.rawExtension.Upgrade = func(context *context.ExtensionContext) *JsUpgradeResult {
+	suite.rawExtension.Upgrade = func(context *context.ExtensionContext, targetVersion goja.Value) *JsUpgradeResult {
 		return &JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}
 	}
-	result, err := suite.extension.Upgrade(createMockContext())
+	result, err := suite.extension.Upgrade(createMockContext(), "")
 	suite.Require().NoError(err)
 	suite.Equal(&JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}, result)
 }
 
 func (suite *ErrorHandlingExtensionSuite) TestUpgradeFails() {
-	suite.rawExtension.Upgrade = func(context *context.ExtensionContext) *JsUpgradeResult {
+	suite.rawExtension.Upgrade = func(context *context.ExtensionContext, targetVersion goja.Value) *JsUpgradeResult {
 		panic(mockErrorMessage)
 	}
-	result, err := suite.extension.Upgrade(createMockContext())
+	result, err := suite.extension.Upgrade(createMockContext(), "")
 	suite.Require().EqualError(err, `failed to upgrade extension "id": `+mockErrorMessage)
 	suite.Nil(result)
 }
 
 func (suite *ErrorHandlingExtensionSuite) TestUpgradeUnsupported() {
 	suite.rawExtension.Upgrade = nil
-	instance, err := suite.extension.Upgrade(createMockContext())
+	instance, err := suite.extension.Upgrade(createMockContext(), "")
 	suite.Require().EqualError(err, `extension "id" does not support operation "upgrade"`)
 	suite.Nil(instance)
 }
 
+func (suite *ErrorHandlingExtensionSuite) TestUpgradeToTargetVersion() {
+	var actualTargetVersion goja.Value
+	suite.rawExtension.Upgrade = func(context *context.ExtensionContext, targetVersion goja.Value) *JsUpgradeResult {
+		actualTargetVersion = targetVersion
+		return &JsUpgradeResult{PreviousVersion: "old", NewVersion: "1.0.0"}
+	}
+	suite.extension.upgradeSupportsTargetVersion = true
+	result, err := suite.extension.Upgrade(createMockContext(), "1.0.0")
+	suite.Require().NoError(err)
+	suite.Equal(&JsUpgradeResult{PreviousVersion: "old", NewVersion: "1.0.0"}, result)
+	suite.Equal("1.0.0", actualTargetVersion.Export())
+	suite.True(suite.extension.SupportsUpgradeToVersion())
+}
+
+func (suite *ErrorHandlingExtensionSuite) TestUpgradeWithoutTargetVersionPassesUndefined() {
+	var actualTargetVersion goja.Value
+	suite.rawExtension.Upgrade = func(context *context.ExtensionContext, targetVersion goja.Value) *JsUpgradeResult {
+		actualTargetVersion = targetVersion
+		return &JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}
+	}
+	suite.extension.upgradeSupportsTargetVersion = true
+	_, err := suite.extension.Upgrade(createMockContext(), "")
+	suite.Require().NoError(err)
+	suite.True(goja.IsUndefined(actualTargetVersion))
+}
+
+func (suite *ErrorHandlingExtensionSuite) TestUpgradeToTargetVersionUnsupported() {
+	suite.rawExtension.Upgrade = func(context *context.ExtensionContext, targetVersion goja.Value) *JsUpgradeResult {
+		panic("not expected")
+	}
+	suite.extension.upgradeSupportsTargetVersion = false
+	result, err := suite.extension.Upgrade(createMockContext(), "1.0.0")
+	suite.Equal(apiErrors.NewAPIError(400, `extension "id" does not support upgrading to a specific version`), err)
+	suite.Nil(result)
+	suite.False(suite.extension.SupportsUpgradeToVersion())
+}
+
+// Repair
+
+func (suite *ErrorHandlingExtensionSuite) TestRepairSuccessful() {
+	suite.rawExtension.Repair = func(context *context.ExtensionContext, version string) {
+		// empty mocked function
+	}
+	suite.True(suite.extension.SupportsRepair())
+	err := suite.extension.Repair(createMockContext(), "version")
+	suite.Require().NoError(err)
+}
+
+func (suite *ErrorHandlingExtensionSuite) TestRepairFailure() {
+	suite.rawExtension.Repair = func(context *context.ExtensionContext, version string) {
+		panic(mockErrorMessage)
+	}
+	err := suite.extension.Repair(createMockContext(), "version")
+	suite.Require().EqualError(err, `failed to repair extension "id": `+mockErrorMessage)
+}
+
+func (suite *ErrorHandlingExtensionSuite) TestRepairUnsupported() {
+	suite.rawExtension.Repair = nil
+	suite.False(suite.extension.SupportsRepair())
+	err := suite.extension.Repair(createMockContext(), "version")
+	suite.Require().EqualError(err, `extension "id" does not support operation "repair"`)
+}
+
 // SupportsListInstances
 
 func (suite *ErrorHandlingExtensionSuite) TestSupportsListInstancesIsUnsupportedWhenMethodMissing() {
@@ -379,6 +443,35 @@ func (suite *ErrorHandlingExtensionSuite) TestConvertErrorJavaScriptErrorWithSta
 	suite.Equal(apiErrors.NewAPIError(400, "jsError"), apiErr)
 }
 
+func (suite *ErrorHandlingExtensionSuite) TestConvertErrorJavaScriptErrorWithCodeParameterAndDetails() {
+	exception := suite.getGojaException(`(function() { const e = new Error('jsError'); e.status = 400; e.code = 'E-VS-1';
+		e.parameterId = 'port'; e.details = {min: 1, max: 65535}; throw e })()`)
+	err := suite.extension.convertError("msg", exception)
+	apiErr, ok := apiErrors.AsAPIError(err)
+	suite.True(ok)
+	suite.Equal(apiErrors.NewDetailedAPIError(400, "jsError", "E-VS-1", "port", map[string]any{"min": int64(1), "max": int64(65535)}), apiErr)
+}
+
+func (suite *ErrorHandlingExtensionSuite) TestConvertErrorPlainJavaScriptObjectWithStatus() {
+	exception := suite.getGojaException(`throw {status: 404, message: 'not found', code: 'E-VS-2'}`)
+	err := suite.extension.convertError("msg", exception)
+	apiErr, ok := apiErrors.AsAPIError(err)
+	suite.True(ok)
+	suite.Equal(apiErrors.NewDetailedAPIError(404, "not found", "E-VS-2", "", nil), apiErr)
+}
+
+func (suite *ErrorHandlingExtensionSuite) TestConvertErrorIgnoresNonObjectDetails() {
+	for _, details := range []string{`'text'`, `[1, 2]`, `42`, `null`} {
+		suite.Run(details, func() {
+			exception := suite.getGojaException(`throw {status: 400, message: 'msg', code: 'E-VS-3', details: ` + details + `}`)
+			err := suite.extension.convertError("msg", exception)
+			apiErr, ok := apiErrors.AsAPIError(err)
+			suite.True(ok)
+			suite.Equal(apiErrors.NewDetailedAPIError(400, "msg", "E-VS-3", "", nil), apiErr)
+		})
+	}
+}
+
 func (suite *ErrorHandlingExtensionSuite) getGojaException(javaScript string) *goja.Exception {
 	_, err := suite.extension.vm.RunString(javaScript)
 	suite.Require().Error(err)
@@ -398,3 +491,50 @@ func (suite *ErrorHandlingExtensionSuite) assertErrorStringError(err error, expe
 	suite.Equal("*errors.errorString", fmt.Sprintf("%T", err))
 	suite.Require().EqualError(err, expectedMessage)
 }
+
+// SetLogListener
+
+func (suite *ErrorHandlingExtensionSuite) TestSetLogListenerReceivesConsoleOutput() {
+	extension, err := LoadExtension("ext-id", `(function(){
+		global.installedExtension = {
+			extension: {
+				name: "ext",
+				install: function(context, version) { console.log("installing " + version); console.warn("warning"); console.error("error") }
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`)
+	suite.Require().NoError(err)
+	var messages []string
+	extension.SetLogListener(func(level, message string) {
+		messages = append(messages, level+": "+message)
+	})
+	suite.Require().NoError(extension.Install(createMockContext(), "1.0.0"))
+	suite.Equal([]string{"log: installing 1.0.0", "warn: warning", "error: error"}, messages)
+}
+
+func (suite *ErrorHandlingExtensionSuite) TestSetLogListenerWithoutLogger() {
+	suite.NotPanics(func() {
+		suite.extension.SetLogListener(func(level, message string) {})
+	})
+}
+
+// UnsupportedFunctions
+
+func (suite *ErrorHandlingExtensionSuite) TestUnsupportedFunctionsAllMissing() {
+	//nolint:exhaustruct // Empty extension is OK for this test
+	extension := wrapExtension(&rawJsExtension{}, "id", newJavaScriptVm(createJavaScriptLogger("logPrefix>")))
+	suite.Equal([]string{"getInstanceParameters", "install", "uninstall", "upgrade", "findInstallations", "addInstance", "findInstances", "deleteInstance"},
+		extension.UnsupportedFunctions())
+}
+
+func (suite *ErrorHandlingExtensionSuite) TestUnsupportedFunctionsSomeImplemented() {
+	//nolint:exhaustruct // Only some functions are required for this test
+	raw := &rawJsExtension{
+		Install:   func(context *context.ExtensionContext, version string) {},
+		Uninstall: func(context *context.ExtensionContext, version string) {},
+	}
+	extension := wrapExtension(raw, "id", newJavaScriptVm(createJavaScriptLogger("logPrefix>")))
+	suite.Equal([]string{"getInstanceParameters", "upgrade", "findInstallations", "addInstance", "findInstances", "deleteInstance"},
+		extension.UnsupportedFunctions())
+}
@@ -10,6 +10,9 @@ type ExaMetadataReader interface {
 	// ReadMetadataTables reads all metadata tables.
 	ReadMetadataTables(tx *sql.Tx, schemaName string) (*ExaMetadata, error)
 
+	// ReadAllScripts reads the scripts of all schemas visible to the database user from the SYS.EXA_ALL_SCRIPTS table.
+	ReadAllScripts(tx *sql.Tx) (*ExaScriptTable, error)
+
 	// GetScriptByName gets a row from the SYS.EXA_ALL_SCRIPTS table for the given schema and script name.
 	//
 	// Returns `(nil, nil)` when no script exists with the given name.
@@ -72,13 +75,21 @@ WHERE SCRIPT_SCHEMA=? AND SCRIPT_NAME=?`, r.metaDataSchema)
 	return row, nil
 }
 
+func (r *metaDataReaderImpl) ReadAllScripts(tx *sql.Tx) (*ExaScriptTable, error) {
+	return r.queryScripts(tx, "")
+}
+
 func (r *metaDataReaderImpl) readExaAllScriptTable(tx *sql.Tx, schemaName string) (*ExaScriptTable, error) {
+	return r.queryScripts(tx, "WHERE SCRIPT_SCHEMA=?", schemaName)
+}
+
+func (r *metaDataReaderImpl) queryScripts(tx *sql.Tx, whereClause string, args ...any) (*ExaScriptTable, error) {
 	// #nosec G201 Using schema as query parameter is not possible
 	query := fmt.Sprintf(`
 SELECT SCRIPT_SCHEMA, SCRIPT_NAME, SCRIPT_TYPE, SCRIPT_INPUT_TYPE, SCRIPT_RESULT_TYPE, SCRIPT_TEXT, SCRIPT_COMMENT
 FROM %s.EXA_ALL_SCRIPTS
-WHERE SCRIPT_SCHEMA=?`, r.metaDataSchema)
-	result, err := tx.Query(query, schemaName)
+%s`, r.metaDataSchema, whereClause)
+	result, err := tx.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %s.EXA_ALL_SCRIPTS: %w", r.metaDataSchema, err)
 	}
@@ -34,6 +34,18 @@ func (mock *ExaMetaDataReaderMock) ReadMetadataTables(tx *sql.Tx, schemaName str
 	return nil, args.Error(1)
 }
 
+func (m *ExaMetaDataReaderMock) SimulateAllSchemasScripts(scripts []ExaScriptRow) {
+	m.On("ReadAllScripts", mock.Anything).Return(&ExaScriptTable{Rows: scripts}, nil)
+}
+
+func (mock *ExaMetaDataReaderMock) ReadAllScripts(tx *sql.Tx) (*ExaScriptTable, error) {
+	args := mock.Called(tx)
+	if scripts, ok := args.Get(0).(*ExaScriptTable); ok {
+		return scripts, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *ExaMetaDataReaderMock) SimulateGetScriptByNameScriptText(scriptName string, scriptText string) {
 	script := &ExaScriptRow{
 		Schema:     "?",
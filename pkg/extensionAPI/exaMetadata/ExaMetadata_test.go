@@ -124,6 +124,32 @@ func (suite *ExaMetadataUTestSuite) TestReadExaAllVirtualSchemasTableScanFails()
 	suite.Nil(result)
 }
 
+// ReadAllScripts
+
+func (suite *ExaMetadataUTestSuite) TestReadAllScripts() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectQuery("(?m)SELECT .*FROM SYS.EXA_ALL_SCRIPTS$").WithoutArgs().
+		WillReturnRows(sqlmock.
+			NewRows([]string{"SCRIPT_SCHEMA", "SCRIPT_NAME", "SCRIPT_TYPE", "SCRIPT_INPUT_TYPE", "SCRIPT_RESULT_TYPE", "SCRIPT_TEXT", "SCRIPT_COMMENT"}).
+			AddRow("schema1", "script1", "type1", "input_type1", "result_type1", "text1", "comment1").
+			AddRow("schema2", "script2", "type2", "input_type2", "result_type2", "text2", "comment2")).
+		RowsWillBeClosed()
+	result, err := CreateExaMetaDataReader().ReadAllScripts(tx)
+	suite.Require().NoError(err)
+	suite.Equal(&ExaScriptTable{Rows: []ExaScriptRow{
+		{Schema: "schema1", Name: "script1", Type: "type1", InputType: "input_type1", ResultType: "result_type1", Text: "text1", Comment: "comment1"},
+		{Schema: "schema2", Name: "script2", Type: "type2", InputType: "input_type2", ResultType: "result_type2", Text: "text2", Comment: "comment2"},
+	}}, result)
+}
+
+func (suite *ExaMetadataUTestSuite) TestReadAllScriptsFails() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectQuery("(?m)SELECT .*FROM SYS.EXA_ALL_SCRIPTS$").WithoutArgs().WillReturnError(errors.New("mock error"))
+	result, err := CreateExaMetaDataReader().ReadAllScripts(tx)
+	suite.Require().EqualError(err, "failed to read SYS.EXA_ALL_SCRIPTS: mock error")
+	suite.Nil(result)
+}
+
 // GetScriptByName
 
 /* [utest -> dsn~extension-context-metadata~1]. */
@@ -6,6 +6,7 @@ import (
 
 	"github.com/exasol/extension-manager/pkg/extensionAPI/context"
 	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/exasol/extension-manager/pkg/metrics"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/dop251/goja"
@@ -18,7 +19,8 @@ import (
 func LoadExtension(id, content string) (*JsExtension, error) {
 	t0 := time.Now()
 	logPrefix := fmt.Sprintf("JS:%s>", id)
-	vm := newJavaScriptVm(logPrefix)
+	logger := createJavaScriptLogger(logPrefix)
+	vm := newJavaScriptVm(logger)
 	extensionJs, err := loadExtension(vm, id, content)
 	if err != nil {
 		return nil, err
@@ -28,21 +30,23 @@ func LoadExtension(id, content string) (*JsExtension, error) {
 		return nil, err
 	}
 	wrappedExtension := wrapExtension(&extensionJs.Extension, id, vm)
+	wrappedExtension.logger = logger
+	wrappedExtension.upgradeSupportsTargetVersion = getUpgradeParameterCount(vm) >= 2
+	metrics.ObserveExtensionLoad(id, t0)
 	log.Tracef("Extension %q with id %q using API version %q loaded in %dms", wrappedExtension.Name, wrappedExtension.Id, extensionJs.APIVersion, time.Since(t0).Milliseconds())
 	return wrappedExtension, nil
 }
 
-func newJavaScriptVm(logPrefix string) *goja.Runtime {
+func newJavaScriptVm(printer console.Printer) *goja.Runtime {
 	vm := goja.New()
 	vm.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
 	registry := new(require.Registry)
 	registry.Enable(vm)
-	configureLogging(registry, vm, logPrefix)
+	configureLogging(registry, vm, printer)
 	return vm
 }
 
-func configureLogging(registry *require.Registry, vm *goja.Runtime, logPrefix string) {
-	printer := createJavaScriptLogger(logPrefix)
+func configureLogging(registry *require.Registry, vm *goja.Runtime, printer console.Printer) {
 	registry.RegisterNativeModule(console.ModuleName, console.RequireWithPrinter(printer))
 	console.Enable(vm)
 }
@@ -71,6 +75,26 @@ func loadExtension(vm *goja.Runtime, id, content string) (*installedExtension, e
 	return &extension, nil
 }
 
+// getUpgradeParameterCount returns the number of parameters declared by the extension's upgrade function or 0 if it does not exist.
+// Extensions supporting upgrades to a specific version declare the additional parameter targetVersion.
+func getUpgradeParameterCount(vm *goja.Runtime) int64 {
+	upgradeFunction := getProperty(vm, vm.Get("global"), "installedExtension", "extension", "upgrade")
+	if _, isFunction := goja.AssertFunction(upgradeFunction); !isFunction {
+		return 0
+	}
+	return upgradeFunction.ToObject(vm).Get("length").ToInteger()
+}
+
+func getProperty(vm *goja.Runtime, value goja.Value, path ...string) goja.Value {
+	for _, name := range path {
+		if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+			return nil
+		}
+		value = value.ToObject(vm).Get(name)
+	}
+	return value
+}
+
 // installedExtension allows deserializing extension definitions that implement the extension-manager-interface (https://github.com/exasol/extension-manager-interface/).
 /* [impl -> dsn~extension-api~1]. */
 type installedExtension struct {
@@ -79,17 +103,20 @@ type installedExtension struct {
 }
 
 type rawJsExtension struct {
-	Name                string                  `json:"name"`
-	Category            string                  `json:"category"`
-	Description         string                  `json:"description"`
-	BucketFsUploads     []BucketFsUpload        `json:"bucketFsUploads"`
-	InstallableVersions []rawJsExtensionVersion `json:"installableVersions"`
+	Name                       string                  `json:"name"`
+	Category                   string                  `json:"category"`
+	Description                string                  `json:"description"`
+	BucketFsUploads            []BucketFsUpload        `json:"bucketFsUploads"`
+	InstallableVersions        []rawJsExtensionVersion `json:"installableVersions"`
+	Dependencies               []ExtensionDependency   `json:"dependencies"`               // Optional
+	RetryOnTransactionConflict bool                    `json:"retryOnTransactionConflict"` // Optional, true if all functions can safely be re-run after a transaction conflict
 	// [impl -> dsn~parameter-versioning~1]
 	// [impl -> dsn~configuration-parameters~1]
 	GetParameterDefinitions func(context *context.ExtensionContext, version string) []interface{}                           `json:"getInstanceParameters"`
 	Install                 func(context *context.ExtensionContext, version string)                                         `json:"install"`
 	Uninstall               func(context *context.ExtensionContext, version string)                                         `json:"uninstall"`
-	Upgrade                 func(context *context.ExtensionContext) *JsUpgradeResult                                        `json:"upgrade"`
+	Upgrade                 func(context *context.ExtensionContext, targetVersion goja.Value) *JsUpgradeResult              `json:"upgrade"`
+	Repair                  func(context *context.ExtensionContext, version string)                                         `json:"repair"` // Optional
 	FindInstallations       func(context *context.ExtensionContext, metadata *exaMetadata.ExaMetadata) []*JsExtInstallation `json:"findInstallations"`
 	AddInstance             func(context *context.ExtensionContext, version string, params *ParameterValues) *JsExtInstance `json:"addInstance"`
 	FindInstances           func(context *context.ExtensionContext, version string) []*JsExtInstance                        `json:"findInstances"`
@@ -103,17 +130,22 @@ type rawJsExtensionVersion struct {
 }
 
 type BucketFsUpload struct {
-	Name             string `json:"name"`             // Human-readable name or short description of the file
-	DownloadURL      string `json:"downloadUrl"`      // Optional
-	LicenseURL       string `json:"licenseUrl"`       // Optional
-	FileSize         int    `json:"fileSize"`         // File size in bytes. Negative if EM should ignore the file size
-	BucketFsFilename string `json:"bucketFsFilename"` // File name in BucketFS
+	Name                     string `json:"name"`                     // Human-readable name or short description of the file
+	DownloadURL              string `json:"downloadUrl"`              // Optional
+	LicenseURL               string `json:"licenseUrl"`               // Optional
+	LicenseAgreementRequired bool   `json:"licenseAgreementRequired"` // True if users must accept the license before installing the extension
+	FileSize                 int    `json:"fileSize"`                 // File size in bytes. Negative if EM should ignore the file size
+	BucketFsFilename         string `json:"bucketFsFilename"`         // File name in BucketFS
+	Bucket                   string `json:"bucket"`                   // Optional bucket containing the file, e.g. "bfsdefault/default". Empty to search all configured buckets
+	BucketFsBasePath         string `json:"-"`                        // Base path where the file was found, set by the extension manager
 }
 
 type JsExtInstallation struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Version string `json:"version"`
+	ID           string                `json:"id"`
+	Name         string                `json:"name"`
+	Version      string                `json:"version"`
+	Dependencies []ExtensionDependency `json:"-"`       // Dependencies declared by the extension, set by the extension manager
+	Scripts      []string              `json:"scripts"` // Optional names of the scripts in the extension schema that belong to the installation
 }
 
 type JsUpgradeResult struct {
@@ -64,7 +64,15 @@ func (suite *ExtensionApiSuite) TestSingleBucketFsUploads() {
 		WithRawBucketFsUpload(`[{name:"name",downloadUrl:"url",licenseUrl:"license",fileSize:123,bucketFsFilename:"filename"}]`).
 		Build().AsString()
 	extension := suite.loadExtension(extensionContent)
-	suite.Equal([]BucketFsUpload{{Name: "name", DownloadURL: "url", LicenseURL: "license", FileSize: 123, BucketFsFilename: "filename"}}, extension.BucketFsUploads)
+	suite.Equal([]BucketFsUpload{{Name: "name", DownloadURL: "url", LicenseURL: "license", LicenseAgreementRequired: false, FileSize: 123, BucketFsFilename: "filename"}}, extension.BucketFsUploads)
+}
+
+func (suite *ExtensionApiSuite) TestBucketFsUploadWithLicenseAgreementRequired() {
+	extensionContent := integrationTesting.CreateTestExtensionBuilder(suite.T()).
+		WithRawBucketFsUpload(`[{name:"name",licenseUrl:"license",licenseAgreementRequired:true,fileSize:123,bucketFsFilename:"filename"}]`).
+		Build().AsString()
+	extension := suite.loadExtension(extensionContent)
+	suite.Equal([]BucketFsUpload{{Name: "name", DownloadURL: "", LicenseURL: "license", LicenseAgreementRequired: true, FileSize: 123, BucketFsFilename: "filename"}}, extension.BucketFsUploads)
 }
 
 func (suite *ExtensionApiSuite) TestBucketFsUploadsWithMissingValues() {
@@ -72,7 +80,7 @@ func (suite *ExtensionApiSuite) TestBucketFsUploadsWithMissingValues() {
 		WithRawBucketFsUpload(`[{}]`).
 		Build().AsString()
 	extension := suite.loadExtension(extensionContent)
-	suite.Equal([]BucketFsUpload{{Name: "", DownloadURL: "", LicenseURL: "", FileSize: 0, BucketFsFilename: ""}}, extension.BucketFsUploads)
+	suite.Equal([]BucketFsUpload{{Name: "", DownloadURL: "", LicenseURL: "", LicenseAgreementRequired: false, FileSize: 0, BucketFsFilename: ""}}, extension.BucketFsUploads)
 }
 
 func (suite *ExtensionApiSuite) TestTwoBucketFsUploadsWithMissingValues() {
@@ -80,8 +88,8 @@ func (suite *ExtensionApiSuite) TestTwoBucketFsUploadsWithMissingValues() {
 		WithRawBucketFsUpload(`[{}, {}]`).
 		Build().AsString()
 	extension := suite.loadExtension(extensionContent)
-	suite.Equal([]BucketFsUpload{{Name: "", DownloadURL: "", LicenseURL: "", FileSize: 0, BucketFsFilename: ""},
-		{Name: "", DownloadURL: "", LicenseURL: "", FileSize: 0, BucketFsFilename: ""}}, extension.BucketFsUploads)
+	suite.Equal([]BucketFsUpload{{Name: "", DownloadURL: "", LicenseURL: "", LicenseAgreementRequired: false, FileSize: 0, BucketFsFilename: ""},
+		{Name: "", DownloadURL: "", LicenseURL: "", LicenseAgreementRequired: false, FileSize: 0, BucketFsFilename: ""}}, extension.BucketFsUploads)
 }
 
 func (suite *ExtensionApiSuite) TestGetParameterDefinitionsEmptyResult() {
@@ -241,7 +249,7 @@ func (suite *ExtensionApiSuite) TestUpgradeReadsMetadata() {
 		WithUpgradeFunc("const text = context.metadata.getScriptByName('script').text; return {previousVersion:'0.1.0',newVersion:text};").Build().AsString()
 	extension := suite.loadExtension(extensionContent)
 	suite.mockMetadataReader.SimulateGetScriptByNameScriptText("script", "scriptText")
-	result, err := extension.Upgrade(suite.mockContext())
+	result, err := extension.Upgrade(suite.mockContext(), "")
 	suite.Require().NoError(err)
 	suite.Equal(&JsUpgradeResult{PreviousVersion: "0.1.0", NewVersion: "scriptText"}, result)
 }
@@ -251,7 +259,7 @@ func (suite *ExtensionApiSuite) TestUpgradeReadMetadataFails() {
 		WithUpgradeFunc("const text = context.metadata.getScriptByName('script').text; return {previousVersion:'0.1.0',newVersion:text};").Build().AsString()
 	extension := suite.loadExtension(extensionContent)
 	suite.mockMetadataReader.SimulateGetScriptByNameFails("script", errors.New("mock error"))
-	result, err := extension.Upgrade(suite.mockContext())
+	result, err := extension.Upgrade(suite.mockContext(), "")
 	suite.Require().EqualError(err, `failed to upgrade extension "ext-id": failed to find script "extension_schema"."script". Caused by: mock error`)
 	suite.Nil(result)
 }
@@ -288,6 +296,64 @@ func (suite *ExtensionApiSuite) TestUsingExtensionWithMissingPropertiesWorks() {
 	suite.Equal("", extension.Description)
 	suite.Empty(extension.BucketFsUploads)
 	suite.Empty(extension.InstallableVersions)
+	suite.Empty(extension.Dependencies)
+	suite.False(extension.RetryOnTransactionConflict)
+}
+
+func (suite *ExtensionApiSuite) TestLoadExtensionWithDependencies() {
+	extension, err := LoadExtension("ext-id", `(function(){
+		global.installedExtension = {
+			extension: { dependencies: [{extensionId: "driver.js", versionRange: ">=1.0.0"}, {extensionId: "other.js"}] },
+			apiVersion: "0.2.0"
+		}
+	})()`)
+	suite.Require().NoError(err)
+	suite.Equal([]ExtensionDependency{{ExtensionId: "driver.js", VersionRange: ">=1.0.0"}, {ExtensionId: "other.js", VersionRange: ""}}, extension.Dependencies)
+}
+
+func (suite *ExtensionApiSuite) TestLoadExtensionWithRetryOnTransactionConflict() {
+	extension, err := LoadExtension("ext-id", `(function(){
+		global.installedExtension = {
+			extension: { retryOnTransactionConflict: true },
+			apiVersion: "0.2.0"
+		}
+	})()`)
+	suite.Require().NoError(err)
+	suite.True(extension.RetryOnTransactionConflict)
+}
+
+func (suite *ExtensionApiSuite) TestLoadExtensionDetectsUpgradeToTargetVersion() {
+	tests := []struct {
+		upgradeFunction string
+		expectedSupport bool
+	}{
+		{"function(context, targetVersion) { return {previousVersion: '0.1.0', newVersion: targetVersion} }", true},
+		{"function(context) { return {previousVersion: '0.1.0', newVersion: '0.2.0'} }", false},
+		{"undefined", false},
+	}
+	for _, test := range tests {
+		suite.Run(test.upgradeFunction, func() {
+			extension := suite.loadExtension(`(function(){
+				global.installedExtension = { extension: { upgrade: ` + test.upgradeFunction + ` }, apiVersion: "0.2.0" }
+			})()`)
+			suite.Equal(test.expectedSupport, extension.SupportsUpgradeToVersion())
+		})
+	}
+}
+
+func (suite *ExtensionApiSuite) TestUpgradeToTargetVersion() {
+	extension := suite.loadExtension(`(function(){
+		global.installedExtension = {
+			extension: { upgrade: function(context, targetVersion) { return {previousVersion: "0.2.0", newVersion: targetVersion === undefined ? "latest" : targetVersion} } },
+			apiVersion: "0.2.0"
+		}
+	})()`)
+	result, err := extension.Upgrade(suite.mockContext(), "0.1.0")
+	suite.Require().NoError(err)
+	suite.Equal(&JsUpgradeResult{PreviousVersion: "0.2.0", NewVersion: "0.1.0"}, result)
+	result, err = extension.Upgrade(suite.mockContext(), "")
+	suite.Require().NoError(err)
+	suite.Equal(&JsUpgradeResult{PreviousVersion: "0.2.0", NewVersion: "latest"}, result)
 }
 
 func (suite *ExtensionApiSuite) TestUsingExtensionWithMissingFunctionFailsGracefully() {
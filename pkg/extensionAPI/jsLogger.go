@@ -1,28 +1,41 @@
 package extensionAPI
 
 import (
-	"github.com/dop251/goja_nodejs/console"
 	log "github.com/sirupsen/logrus"
 )
 
 // createJavaScriptLogger creates a new [console.Printer] for handling `console.log()`, `console.warn()` and `console.error()` calls in JavaScript.
 // This implementation forwards all log messages to logrus using the appropriate log method `Print()`, `Warn()` and `Error()`.
-func createJavaScriptLogger(logPrefix string) console.Printer {
-	return &jsLogger{logPrefix: logPrefix}
+// Additionally it forwards messages to an optional [LogListener].
+func createJavaScriptLogger(logPrefix string) *jsLogger {
+	return &jsLogger{logPrefix: logPrefix, listener: nil}
 }
 
+// LogListener is called for each message logged by an extension with level "log", "warn" or "error".
+type LogListener func(level, message string)
+
 type jsLogger struct {
 	logPrefix string
+	listener  LogListener
 }
 
-func (l jsLogger) Log(s string) {
+func (l *jsLogger) Log(s string) {
 	log.Print(l.logPrefix + s)
+	l.notifyListener("log", s)
 }
 
-func (l jsLogger) Warn(s string) {
+func (l *jsLogger) Warn(s string) {
 	log.Warn(l.logPrefix + s)
+	l.notifyListener("warn", s)
 }
 
-func (l jsLogger) Error(s string) {
+func (l *jsLogger) Error(s string) {
 	log.Error(l.logPrefix + s)
+	l.notifyListener("error", s)
+}
+
+func (l *jsLogger) notifyListener(level, message string) {
+	if l.listener != nil {
+		l.listener(level, message)
+	}
 }
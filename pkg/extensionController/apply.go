@@ -0,0 +1,244 @@
+package extensionController
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+)
+
+// Manifest describes the desired state of the extensions in a database.
+// Applying it with [TransactionController.ApplyManifest] makes the database match the manifest.
+type Manifest struct {
+	// Extensions contains the desired extensions. Dependencies must be listed before the extensions that require them.
+	// Installed extensions not listed in the manifest are not changed.
+	Extensions []ManifestExtension `json:"extensions"`
+}
+
+// ManifestExtension is the desired state of a single extension in a [Manifest].
+type ManifestExtension struct {
+	ExtensionId string `json:"extensionId"`
+	Version     string `json:"version"`
+	// Instances contains all desired instances of the extension. Existing instances not listed here are deleted.
+	Instances []ManifestInstance `json:"instances,omitempty"`
+}
+
+// ManifestInstance is a desired instance of an extension in a [Manifest].
+type ManifestInstance struct {
+	// Name identifies the instance. Parameters of existing instances are not compared.
+	Name       string           `json:"name"`
+	Parameters []ParameterValue `json:"parameters"`
+}
+
+// ApplyOptions contains options for applying a [Manifest].
+type ApplyOptions struct {
+	// AcceptLicenses confirms that the user accepts the licenses of all files that require a license agreement.
+	AcceptLicenses bool
+	// Force installs deprecated versions and creates instances of them even if the configured [DeprecatedVersionPolicy] rejects them.
+	Force bool
+}
+
+// ApplyAction is the type of change applied to make a database match a [Manifest].
+type ApplyAction string
+
+const (
+	ApplyActionInstall        ApplyAction = "install"
+	ApplyActionUpgrade        ApplyAction = "upgrade"
+	ApplyActionCreateInstance ApplyAction = "create-instance"
+	ApplyActionDeleteInstance ApplyAction = "delete-instance"
+)
+
+// ApplyChange is a single change required to make a database match a [Manifest].
+type ApplyChange struct {
+	Action      ApplyAction
+	ExtensionId string
+	// Version is the version to install or upgrade to or the version of the instance.
+	Version string
+	// InstalledVersion is the version installed before an upgrade.
+	InstalledVersion string
+	// InstanceName is the name of the instance to create or delete.
+	InstanceName string
+	// InstanceId is the ID of the instance to delete.
+	InstanceId string
+	parameters []ParameterValue
+}
+
+// ApplyPlan contains the changes required to make a database match a [Manifest] in the order they are applied.
+type ApplyPlan struct {
+	Changes []*ApplyChange
+}
+
+// ApplyResult is the outcome of applying a [Manifest].
+type ApplyResult struct {
+	// Changes contains the outcome of all planned changes in the order they were applied.
+	Changes []*ApplyChangeResult
+}
+
+// ApplyChangeResult is the outcome of applying a single [ApplyChange].
+type ApplyChangeResult struct {
+	*ApplyChange
+	// Applied is true if the change was committed successfully.
+	Applied bool
+	// Error is set if the change failed. Changes after a failed change are not applied.
+	Error error
+	// Warnings for the user, e.g. because a deprecated version was installed.
+	Warnings []string
+}
+
+func (c *transactionControllerImpl) PlanManifest(ctx context.Context, db *sql.DB, manifest *Manifest) (*ApplyPlan, error) {
+	if err := validateManifest(manifest); err != nil {
+		return nil, err
+	}
+	installations, err := c.GetInstalledExtensions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	plan := &ApplyPlan{Changes: nil}
+	for _, extension := range manifest.Extensions {
+		changes, err := c.planExtension(ctx, db, extension, findInstallation(installations, extension.ExtensionId))
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, changes...)
+	}
+	return plan, nil
+}
+
+func validateManifest(manifest *Manifest) error {
+	if manifest == nil {
+		return apiErrors.NewBadRequestErrorF("missing manifest")
+	}
+	extensionIds := make(map[string]bool, len(manifest.Extensions))
+	for _, extension := range manifest.Extensions {
+		if extension.ExtensionId == "" || extension.Version == "" {
+			return apiErrors.NewBadRequestErrorF("extension ID and version are required for all extensions in the manifest")
+		}
+		if extensionIds[extension.ExtensionId] {
+			return apiErrors.NewBadRequestErrorF("extension %q is listed more than once in the manifest", extension.ExtensionId)
+		}
+		extensionIds[extension.ExtensionId] = true
+		instanceNames := make(map[string]bool, len(extension.Instances))
+		for _, instance := range extension.Instances {
+			if instance.Name == "" {
+				return apiErrors.NewBadRequestErrorF("instance name is required for all instances of extension %q in the manifest", extension.ExtensionId)
+			}
+			if instanceNames[instance.Name] {
+				return apiErrors.NewBadRequestErrorF("instance %q of extension %q is listed more than once in the manifest", instance.Name, extension.ExtensionId)
+			}
+			instanceNames[instance.Name] = true
+		}
+	}
+	return nil
+}
+
+// planExtension deletes unlisted instances before upgrading the extension, so that they are deleted by the version that created them.
+func (c *transactionControllerImpl) planExtension(ctx context.Context, db *sql.DB, extension ManifestExtension, installation *extensionAPI.JsExtInstallation) ([]*ApplyChange, error) {
+	var changes []*ApplyChange
+	var existingInstances []*extensionAPI.JsExtInstance
+	if installation == nil {
+		changes = append(changes, &ApplyChange{Action: ApplyActionInstall, ExtensionId: extension.ExtensionId, Version: extension.Version})
+	} else {
+		var err error
+		existingInstances, err = c.FindInstances(ctx, db, installation.ID, installation.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find instances of extension %q: %w", installation.ID, err)
+		}
+		for _, instance := range existingInstances {
+			if !containsManifestInstance(extension.Instances, instance.Name) {
+				changes = append(changes, &ApplyChange{Action: ApplyActionDeleteInstance, ExtensionId: extension.ExtensionId, Version: installation.Version,
+					InstanceName: instance.Name, InstanceId: instance.Id})
+			}
+		}
+		if installation.Version != extension.Version {
+			changes = append(changes, &ApplyChange{Action: ApplyActionUpgrade, ExtensionId: extension.ExtensionId, Version: extension.Version,
+				InstalledVersion: installation.Version})
+		}
+	}
+	for _, instance := range extension.Instances {
+		if !containsInstance(existingInstances, instance.Name) {
+			changes = append(changes, &ApplyChange{Action: ApplyActionCreateInstance, ExtensionId: extension.ExtensionId, Version: extension.Version,
+				InstanceName: instance.Name, parameters: instance.Parameters})
+		}
+	}
+	return changes, nil
+}
+
+func containsManifestInstance(instances []ManifestInstance, name string) bool {
+	return slices.ContainsFunc(instances, func(instance ManifestInstance) bool { return instance.Name == name })
+}
+
+func (c *transactionControllerImpl) ApplyManifest(ctx context.Context, db *sql.DB, manifest *Manifest, options ApplyOptions) (*ApplyResult, error) {
+	plan, err := c.PlanManifest(ctx, db, manifest)
+	if err != nil {
+		return nil, err
+	}
+	return c.ApplyManifestPlan(ctx, db, plan, options)
+}
+
+func (c *transactionControllerImpl) ApplyManifestPlan(ctx context.Context, db *sql.DB, plan *ApplyPlan, options ApplyOptions) (*ApplyResult, error) {
+	if plan == nil {
+		return nil, apiErrors.NewBadRequestErrorF("missing plan")
+	}
+	log.Infof("Applying %d changes", len(plan.Changes))
+	result := &ApplyResult{Changes: make([]*ApplyChangeResult, 0, len(plan.Changes))}
+	failed := false
+	for _, change := range plan.Changes {
+		changeResult := &ApplyChangeResult{ApplyChange: change, Applied: false, Error: nil, Warnings: nil}
+		result.Changes = append(result.Changes, changeResult)
+		if failed {
+			continue
+		}
+		changeResult.Warnings, changeResult.Error = c.applyChange(ctx, db, change, options)
+		if changeResult.Error != nil {
+			log.Warnf("Applying %s for extension %q failed, skipping remaining changes: %v", change.Action, change.ExtensionId, changeResult.Error)
+			failed = true
+			continue
+		}
+		changeResult.Applied = true
+	}
+	return result, nil
+}
+
+func (c *transactionControllerImpl) applyChange(ctx context.Context, db *sql.DB, change *ApplyChange, options ApplyOptions) ([]string, error) {
+	switch change.Action {
+	case ApplyActionInstall:
+		result, err := c.InstallExtensionWithResult(ctx, db, change.ExtensionId, change.Version,
+			InstallOptions{InstallDependencies: false, AcceptLicenses: options.AcceptLicenses, Force: options.Force})
+		if err != nil {
+			return nil, err
+		}
+		return result.Warnings, nil
+	case ApplyActionUpgrade:
+		_, err := c.UpgradeExtensionToVersion(ctx, db, change.ExtensionId, change.Version)
+		return nil, err
+	case ApplyActionCreateInstance:
+		result, err := c.createInstance(ctx, db, change.ExtensionId, change.Version, change.parameters, CreateInstanceOptions{Force: options.Force},
+			func(instance *extensionAPI.JsExtInstance) error { return verifyInstanceName(change, instance) })
+		if err != nil {
+			return nil, err
+		}
+		return result.Warnings, nil
+	case ApplyActionDeleteInstance:
+		return nil, c.DeleteInstance(ctx, db, change.ExtensionId, change.Version, change.InstanceId)
+	default:
+		return nil, fmt.Errorf("unsupported apply action %q", change.Action)
+	}
+}
+
+// verifyInstanceName checks that the extension named the new instance as listed in the manifest.
+// Extensions derive the name from the parameters, so a different name would create a new instance on every apply.
+func verifyInstanceName(change *ApplyChange, instance *extensionAPI.JsExtInstance) error {
+	if instance == nil {
+		return fmt.Errorf("extension %q returned no instance", change.ExtensionId)
+	}
+	if instance.Name != change.InstanceName {
+		return apiErrors.NewBadRequestErrorF("extension %q named the new instance %q instead of %q as listed in the manifest, change the name in the manifest to match the parameters",
+			change.ExtensionId, instance.Name, change.InstanceName)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package extensionController
+
+import (
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/stretchr/testify/mock"
+)
+
+// PlanManifest
+
+func applyTestManifest() *Manifest {
+	return &Manifest{Extensions: []ManifestExtension{
+		{ExtensionId: "ext2", Version: "2.0.0", Instances: []ManifestInstance{{Name: "a", Parameters: []ParameterValue{{Name: "host", Value: "h"}}}}},
+		{ExtensionId: "ext1", Version: "1.0.0", Instances: []ManifestInstance{{Name: "existing"}, {Name: "b"}}}}}
+}
+
+func (suite *extCtrlUnitTestSuite) simulateApplyTarget() {
+	suite.mockCtrl.On("GetAllInstallations", mock.Anything).Return([]*extensionAPI.JsExtInstallation{
+		{ID: "ext1", Name: "Extension 1", Version: "0.9.0"}, {ID: "unlisted", Name: "Unlisted", Version: "1.0.0"}}, nil)
+	suite.mockCtrl.On("FindInstances", mock.Anything, "ext1", "0.9.0").Return([]*extensionAPI.JsExtInstance{{Id: "id", Name: "existing"}, {Id: "old-id", Name: "old"}}, nil)
+	for range 2 {
+		suite.dbMock.ExpectBegin()
+		suite.dbMock.ExpectRollback()
+	}
+}
+
+func (suite *extCtrlUnitTestSuite) TestPlanManifest() {
+	suite.simulateApplyTarget()
+	plan, err := suite.ctrl.PlanManifest(mockContext(), suite.db, applyTestManifest())
+	suite.Require().NoError(err)
+	suite.Equal(&ApplyPlan{Changes: []*ApplyChange{
+		{Action: ApplyActionInstall, ExtensionId: "ext2", Version: "2.0.0"},
+		{Action: ApplyActionCreateInstance, ExtensionId: "ext2", Version: "2.0.0", InstanceName: "a", parameters: []ParameterValue{{Name: "host", Value: "h"}}},
+		{Action: ApplyActionDeleteInstance, ExtensionId: "ext1", Version: "0.9.0", InstanceName: "old", InstanceId: "old-id"},
+		{Action: ApplyActionUpgrade, ExtensionId: "ext1", Version: "1.0.0", InstalledVersion: "0.9.0"},
+		{Action: ApplyActionCreateInstance, ExtensionId: "ext1", Version: "1.0.0", InstanceName: "b"}}}, plan)
+}
+
+func (suite *extCtrlUnitTestSuite) TestPlanManifestFailsFindingInstances() {
+	suite.mockCtrl.On("GetAllInstallations", mock.Anything).Return([]*extensionAPI.JsExtInstallation{{ID: "ext1", Name: "Extension 1", Version: "0.9.0"}}, nil)
+	suite.mockCtrl.On("FindInstances", mock.Anything, "ext1", "0.9.0").Return(nil, mockError)
+	for range 2 {
+		suite.dbMock.ExpectBegin()
+		suite.dbMock.ExpectRollback()
+	}
+	plan, err := suite.ctrl.PlanManifest(mockContext(), suite.db, applyTestManifest())
+	suite.Require().EqualError(err, `failed to find instances of extension "ext1": mock error`)
+	suite.Nil(plan)
+}
+
+func (suite *extCtrlUnitTestSuite) TestPlanManifestInvalidManifest() {
+	var tests = []struct {
+		name          string
+		manifest      *Manifest
+		expectedError string
+	}{
+		{name: "missing manifest", manifest: nil, expectedError: "missing manifest"},
+		{name: "missing version", manifest: &Manifest{Extensions: []ManifestExtension{{ExtensionId: "ext"}}},
+			expectedError: "extension ID and version are required for all extensions in the manifest"},
+		{name: "duplicate extension", manifest: &Manifest{Extensions: []ManifestExtension{{ExtensionId: "ext", Version: "1.0.0"}, {ExtensionId: "ext", Version: "2.0.0"}}},
+			expectedError: `extension "ext" is listed more than once in the manifest`},
+		{name: "missing instance name", manifest: &Manifest{Extensions: []ManifestExtension{{ExtensionId: "ext", Version: "1.0.0", Instances: []ManifestInstance{{Name: ""}}}}},
+			expectedError: `instance name is required for all instances of extension "ext" in the manifest`},
+		{name: "duplicate instance", manifest: &Manifest{Extensions: []ManifestExtension{{ExtensionId: "ext", Version: "1.0.0", Instances: []ManifestInstance{{Name: "a"}, {Name: "a"}}}}},
+			expectedError: `instance "a" of extension "ext" is listed more than once in the manifest`},
+	}
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			plan, err := suite.ctrl.PlanManifest(mockContext(), suite.db, test.manifest)
+			suite.Require().EqualError(err, test.expectedError)
+			suite.Equal(400, apiErrors.UnwrapAPIError(err).Status)
+			suite.Nil(plan)
+		})
+	}
+}
+
+// ApplyManifest
+
+func (suite *extCtrlUnitTestSuite) TestApplyManifest() {
+	suite.simulateApplyTarget()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "ext2", "2.0.0", InstallOptions{InstallDependencies: false, AcceptLicenses: true, Force: false}).Return(nil)
+	suite.mockCtrl.On("GetParameterDefinitions", "ext2", "2.0.0").Return(nil, nil)
+	suite.mockCtrl.On("CreateInstance", mock.Anything, "ext2", "2.0.0", []ParameterValue{{Name: "host", Value: "h"}}, CreateInstanceOptions{Force: false}).
+		Return(&extensionAPI.JsExtInstance{Id: "a-id", Name: "a"}, nil)
+	suite.mockCtrl.On("DeleteInstance", mock.Anything, "ext1", "0.9.0", "old-id").Return(nil)
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "ext1", "1.0.0").Return(nil, mockError)
+	for range 3 {
+		suite.dbMock.ExpectBegin()
+		suite.dbMock.ExpectCommit()
+	}
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	result, err := suite.ctrl.ApplyManifest(mockContext(), suite.db, applyTestManifest(), ApplyOptions{AcceptLicenses: true})
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Changes, 5)
+	suite.assertApplyChangeResult(result.Changes[0], ApplyActionInstall, true, "")
+	suite.assertApplyChangeResult(result.Changes[1], ApplyActionCreateInstance, true, "")
+	suite.assertApplyChangeResult(result.Changes[2], ApplyActionDeleteInstance, true, "")
+	suite.assertApplyChangeResult(result.Changes[3], ApplyActionUpgrade, false, mockErrorMsg)
+	suite.assertApplyChangeResult(result.Changes[4], ApplyActionCreateInstance, false, "")
+	suite.Len(suite.auditLogMock.written, 3)
+}
+
+func (suite *extCtrlUnitTestSuite) TestApplyManifestWithoutChanges() {
+	suite.simulateApplyTarget()
+	manifest := &Manifest{Extensions: []ManifestExtension{{ExtensionId: "ext1", Version: "0.9.0", Instances: []ManifestInstance{{Name: "existing"}, {Name: "old"}}}}}
+	result, err := suite.ctrl.ApplyManifest(mockContext(), suite.db, manifest, ApplyOptions{})
+	suite.Require().NoError(err)
+	suite.Empty(result.Changes)
+}
+
+func (suite *extCtrlUnitTestSuite) TestApplyManifestFailsIfExtensionNamesInstanceDifferently() {
+	suite.mockCtrl.On("GetAllInstallations", mock.Anything).Return([]*extensionAPI.JsExtInstallation{}, nil)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	suite.mockCtrl.On("GetParameterDefinitions", "ext", "1.0.0").Return(nil, nil)
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "ext", "1.0.0", InstallOptions{}).Return(nil)
+	suite.mockCtrl.On("CreateInstance", mock.Anything, "ext", "1.0.0", []ParameterValue{{Name: "schema", Value: "OTHER"}}, CreateInstanceOptions{Force: false}).
+		Return(&extensionAPI.JsExtInstance{Id: "other-id", Name: "OTHER"}, nil)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectCommit()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	manifest := &Manifest{Extensions: []ManifestExtension{{ExtensionId: "ext", Version: "1.0.0",
+		Instances: []ManifestInstance{{Name: "SCHEMA", Parameters: []ParameterValue{{Name: "schema", Value: "OTHER"}}}}}}}
+	result, err := suite.ctrl.ApplyManifest(mockContext(), suite.db, manifest, ApplyOptions{})
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Changes, 2)
+	suite.assertApplyChangeResult(result.Changes[0], ApplyActionInstall, true, "")
+	suite.assertApplyChangeResult(result.Changes[1], ApplyActionCreateInstance, false,
+		`extension "ext" named the new instance "OTHER" instead of "SCHEMA" as listed in the manifest, change the name in the manifest to match the parameters`)
+	suite.Equal(400, apiErrors.UnwrapAPIError(result.Changes[1].Error).Status)
+	suite.Require().Len(suite.auditLogMock.writtenSeparately, 1)
+	suite.Equal(AuditOutcomeFailure, suite.auditLogMock.writtenSeparately[0].Outcome)
+}
+
+// ApplyManifestPlan
+
+func (suite *extCtrlUnitTestSuite) TestApplyManifestPlanAppliesGivenPlanWithoutPlanningAgain() {
+	plan := &ApplyPlan{Changes: []*ApplyChange{{Action: ApplyActionDeleteInstance, ExtensionId: "ext1", Version: "0.9.0", InstanceName: "old", InstanceId: "old-id"}}}
+	suite.mockCtrl.On("DeleteInstance", mock.Anything, "ext1", "0.9.0", "old-id").Return(nil)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectCommit()
+	result, err := suite.ctrl.ApplyManifestPlan(mockContext(), suite.db, plan, ApplyOptions{})
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Changes, 1)
+	suite.assertApplyChangeResult(result.Changes[0], ApplyActionDeleteInstance, true, "")
+	suite.Same(plan.Changes[0], result.Changes[0].ApplyChange)
+}
+
+func (suite *extCtrlUnitTestSuite) TestApplyManifestPlanFailsForMissingPlan() {
+	result, err := suite.ctrl.ApplyManifestPlan(mockContext(), suite.db, nil, ApplyOptions{})
+	suite.Require().EqualError(err, "missing plan")
+	suite.Nil(result)
+}
+
+func (suite *extCtrlUnitTestSuite) assertApplyChangeResult(result *ApplyChangeResult, expectedAction ApplyAction, expectedApplied bool, expectedError string) {
+	suite.T().Helper()
+	suite.Equal(expectedAction, result.Action)
+	suite.Equal(expectedApplied, result.Applied)
+	if expectedError == "" {
+		suite.NoError(result.Error)
+	} else {
+		suite.EqualError(result.Error, expectedError)
+	}
+}
@@ -0,0 +1,256 @@
+package extensionController
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/exasol/extension-manager/pkg/parameterValidator"
+)
+
+// auditLogTable is the name of the table in the extension schema that contains the audit trail of all mutating operations.
+const auditLogTable = "EXTENSION_AUDIT_LOG"
+
+// redactedValue replaces values of secret parameters in the audit log.
+const redactedValue = "***"
+
+// AuditOperation is the type of operation recorded in the audit log.
+type AuditOperation string
+
+const (
+	AuditOperationInstall        AuditOperation = "INSTALL"
+	AuditOperationUninstall      AuditOperation = "UNINSTALL"
+	AuditOperationUpgrade        AuditOperation = "UPGRADE"
+	AuditOperationRepair         AuditOperation = "REPAIR"
+	AuditOperationCreateInstance AuditOperation = "CREATE_INSTANCE"
+	AuditOperationDeleteInstance AuditOperation = "DELETE_INSTANCE"
+)
+
+// AuditOutcome is the result of an operation recorded in the audit log.
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "SUCCESS"
+	AuditOutcomeFailure AuditOutcome = "FAILURE"
+)
+
+// AuditEntry is a single entry of the audit log.
+type AuditEntry struct {
+	Timestamp        time.Time
+	DbUser           string // Database user that executed the operation, set by the database when writing the entry
+	Operation        AuditOperation
+	ExtensionId      string
+	ExtensionVersion string
+	InstanceId       string
+	Parameters       []ParameterValue // Parameter values with secrets redacted
+	Outcome          AuditOutcome
+	ErrorMessage     string
+}
+
+// AuditLogFilter restricts the entries returned when reading the audit log. Empty fields match all entries.
+type AuditLogFilter struct {
+	ExtensionId string
+	Operation   AuditOperation
+	DbUser      string
+	Outcome     AuditOutcome
+	From        time.Time // Only entries at or after this time
+	To          time.Time // Only entries before this time
+	Limit       int       // Maximum number of entries, 0 for no limit
+}
+
+// auditLog writes and reads the audit trail of mutating operations.
+// It allows injecting a mock implementation in unit tests.
+type auditLog interface {
+	// write adds an entry to the audit log in the given schema using the given transaction,
+	// so that it is only stored when the operation is committed.
+	// The context identifies the database for creating the audit log table only once.
+	write(ctx context.Context, tx *sql.Tx, schema string, entry AuditEntry) error
+	// writeInNewTransaction adds an entry using a new transaction. This is used for failed operations that were rolled back.
+	writeInNewTransaction(ctx context.Context, db *sql.DB, schema string, entry AuditEntry) error
+	// find returns all entries of the audit log in the given schema matching the filter, newest first.
+	find(tx *sql.Tx, schema string, filter AuditLogFilter) ([]AuditEntry, error)
+}
+
+// dbAuditLog stores the audit log in a table of the extension schema.
+type dbAuditLog struct {
+	tables createdTables // Databases and schemas containing the audit log table
+}
+
+func newDbAuditLog() auditLog {
+	return &dbAuditLog{tables: createdTables{}}
+}
+
+func (l *dbAuditLog) write(ctx context.Context, tx *sql.Tx, schema string, entry AuditEntry) error {
+	parameters, err := json.Marshal(entry.Parameters)
+	if err != nil {
+		return fmt.Errorf("failed to serialize parameters for audit log: %w", err)
+	}
+	createTable := func() error { return l.ensureTableExists(tx, schema) }
+	insert := func() error {
+		if err := l.insert(tx, schema, entry, parameters); err != nil {
+			return fmt.Errorf("failed to write audit log entry: %w", err)
+		}
+		return nil
+	}
+	return l.tables.execWithTable(ctx, schema, createTable, insert)
+}
+
+func (l *dbAuditLog) insert(tx *sql.Tx, schema string, entry AuditEntry, parameters []byte) error {
+	query := fmt.Sprintf(`INSERT INTO "%s"."%s" (EVENT_TIME, DB_USER, OPERATION, EXTENSION_ID, EXTENSION_VERSION, INSTANCE_ID, PARAMETERS, OUTCOME, ERROR_MESSAGE) VALUES (?, CURRENT_USER, ?, ?, ?, ?, ?, ?, ?)`,
+		schema, auditLogTable)
+	_, err := tx.Exec(query, entry.Timestamp, string(entry.Operation), entry.ExtensionId, entry.ExtensionVersion, entry.InstanceId,
+		string(parameters), string(entry.Outcome), entry.ErrorMessage)
+	return err
+}
+
+func (l *dbAuditLog) writeInNewTransaction(ctx context.Context, db *sql.DB, schema string, entry AuditEntry) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for audit log: %w", err)
+	}
+	if err := l.write(ctx, tx, schema, entry); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit log entry: %w", err)
+	}
+	return nil
+}
+
+func (l *dbAuditLog) ensureTableExists(tx *sql.Tx, schema string) error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS "%s"."%s" (
+	EVENT_TIME TIMESTAMP NOT NULL,
+	DB_USER VARCHAR(128) NOT NULL,
+	OPERATION VARCHAR(50) NOT NULL,
+	EXTENSION_ID VARCHAR(200) NOT NULL,
+	EXTENSION_VERSION VARCHAR(100),
+	INSTANCE_ID VARCHAR(200),
+	PARAMETERS VARCHAR(2000000),
+	OUTCOME VARCHAR(20) NOT NULL,
+	ERROR_MESSAGE VARCHAR(2000000))`, schema, auditLogTable)
+	if _, err := tx.Exec(query); err != nil {
+		return fmt.Errorf("failed to create audit log table: %w", err)
+	}
+	return nil
+}
+
+func (l *dbAuditLog) find(tx *sql.Tx, schema string, filter AuditLogFilter) ([]AuditEntry, error) {
+	exists, err := l.tableExists(tx, schema)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []AuditEntry{}, nil
+	}
+	query, args := l.buildFindQuery(schema, filter)
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer rows.Close()
+	entries := make([]AuditEntry, 0)
+	for rows.Next() {
+		entry, err := readAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+func (l *dbAuditLog) tableExists(tx *sql.Tx, schema string) (bool, error) {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM SYS.EXA_ALL_TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?", schema, auditLogTable).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if audit log table exists: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (l *dbAuditLog) buildFindQuery(schema string, filter AuditLogFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	addCondition := func(condition string, value any) {
+		conditions = append(conditions, condition)
+		args = append(args, value)
+	}
+	if filter.ExtensionId != "" {
+		addCondition("EXTENSION_ID = ?", filter.ExtensionId)
+	}
+	if filter.Operation != "" {
+		addCondition("OPERATION = ?", string(filter.Operation))
+	}
+	if filter.DbUser != "" {
+		addCondition("DB_USER = ?", filter.DbUser)
+	}
+	if filter.Outcome != "" {
+		addCondition("OUTCOME = ?", string(filter.Outcome))
+	}
+	if !filter.From.IsZero() {
+		addCondition("EVENT_TIME >= ?", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		addCondition("EVENT_TIME < ?", filter.To.UTC())
+	}
+	query := fmt.Sprintf(`SELECT EVENT_TIME, DB_USER, OPERATION, EXTENSION_ID, EXTENSION_VERSION, INSTANCE_ID, PARAMETERS, OUTCOME, ERROR_MESSAGE FROM "%s"."%s"`, schema, auditLogTable)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY EVENT_TIME DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+	return query, args
+}
+
+func readAuditEntry(rows *sql.Rows) (AuditEntry, error) {
+	var entry AuditEntry
+	var operation, outcome string
+	var extensionVersion, instanceId, parameters, errorMessage sql.NullString
+	err := rows.Scan(&entry.Timestamp, &entry.DbUser, &operation, &entry.ExtensionId, &extensionVersion, &instanceId, &parameters, &outcome, &errorMessage)
+	if err != nil {
+		return AuditEntry{}, fmt.Errorf("failed to read audit log entry: %w", err)
+	}
+	entry.Operation = AuditOperation(operation)
+	entry.Outcome = AuditOutcome(outcome)
+	entry.ExtensionVersion = extensionVersion.String
+	entry.InstanceId = instanceId.String
+	entry.ErrorMessage = errorMessage.String
+	if parameters.Valid && parameters.String != "" && parameters.String != "null" {
+		if err := json.Unmarshal([]byte(parameters.String), &entry.Parameters); err != nil {
+			return AuditEntry{}, fmt.Errorf("failed to parse parameters of audit log entry: %w", err)
+		}
+	}
+	return entry, nil
+}
+
+// redactParameters replaces the values of all parameters that are defined as secret.
+// If definitions is nil because they could not be loaded, all values are redacted.
+func redactParameters(definitions []parameterValidator.ParameterDefinition, values []ParameterValue) []ParameterValue {
+	redacted := make([]ParameterValue, 0, len(values))
+	for _, value := range values {
+		if definitions == nil || isSecretParameter(definitions, value.Name) {
+			value.Value = redactedValue
+		}
+		redacted = append(redacted, value)
+	}
+	return redacted
+}
+
+func isSecretParameter(definitions []parameterValidator.ParameterDefinition, id string) bool {
+	for _, definition := range definitions {
+		if definition.Id == id {
+			secret, _ := definition.RawDefinition["secret"].(bool)
+			return secret
+		}
+	}
+	return false
+}
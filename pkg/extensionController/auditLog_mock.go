@@ -0,0 +1,44 @@
+//nolint:unused // Mock functions are actually used in tests
+package extensionController
+
+import (
+	"context"
+	"database/sql"
+)
+
+// auditLogMock records all entries in memory instead of writing them to the database.
+type auditLogMock struct {
+	written           []AuditEntry
+	writtenSeparately []AuditEntry
+	writeErr          error
+	findResult        []AuditEntry
+	findErr           error
+	lastFilter        AuditLogFilter
+	lastSchema        string
+}
+
+func createAuditLogMock() *auditLogMock {
+	//nolint:exhaustruct // Empty struct is OK for Mock
+	return &auditLogMock{}
+}
+
+func (m *auditLogMock) write(ctx context.Context, tx *sql.Tx, schema string, entry AuditEntry) error {
+	m.lastSchema = schema
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+	m.written = append(m.written, entry)
+	return nil
+}
+
+func (m *auditLogMock) writeInNewTransaction(ctx context.Context, db *sql.DB, schema string, entry AuditEntry) error {
+	m.lastSchema = schema
+	m.writtenSeparately = append(m.writtenSeparately, entry)
+	return nil
+}
+
+func (m *auditLogMock) find(tx *sql.Tx, schema string, filter AuditLogFilter) ([]AuditEntry, error) {
+	m.lastFilter = filter
+	m.lastSchema = schema
+	return m.findResult, m.findErr
+}
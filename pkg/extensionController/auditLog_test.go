@@ -0,0 +1,237 @@
+package extensionController
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/exasol/extension-manager/pkg/parameterValidator"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditLogSuite struct {
+	suite.Suite
+	db       *sql.DB
+	dbMock   sqlmock.Sqlmock
+	auditLog auditLog
+}
+
+func TestAuditLogSuite(t *testing.T) {
+	suite.Run(t, new(AuditLogSuite))
+}
+
+var auditTimestamp = time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+const (
+	createAuditTableRegexp = `CREATE TABLE IF NOT EXISTS "ext_schema"\."EXTENSION_AUDIT_LOG"`
+	insertAuditEntry       = `INSERT INTO "ext_schema"."EXTENSION_AUDIT_LOG" (EVENT_TIME, DB_USER, OPERATION, EXTENSION_ID, EXTENSION_VERSION, INSTANCE_ID, PARAMETERS, OUTCOME, ERROR_MESSAGE) VALUES (?, CURRENT_USER, ?, ?, ?, ?, ?, ?, ?)`
+	selectAuditEntries     = `SELECT EVENT_TIME, DB_USER, OPERATION, EXTENSION_ID, EXTENSION_VERSION, INSTANCE_ID, PARAMETERS, OUTCOME, ERROR_MESSAGE FROM "ext_schema"."EXTENSION_AUDIT_LOG"`
+	auditTableExists       = `SELECT COUNT(*) FROM SYS.EXA_ALL_TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`
+)
+
+func (suite *AuditLogSuite) SetupTest() {
+	db, dbMock, err := sqlmock.New()
+	suite.Require().NoError(err)
+	suite.db = db
+	suite.dbMock = dbMock
+	suite.dbMock.MatchExpectationsInOrder(true)
+	suite.auditLog = newDbAuditLog()
+}
+
+func (suite *AuditLogSuite) AfterTest(suiteName, testName string) {
+	suite.NoError(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *AuditLogSuite) TestWrite() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).
+		WithArgs(auditTimestamp, "CREATE_INSTANCE", "extId", "1.0.0", "instId", `[{"name":"password","value":"***"}]`, "SUCCESS", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err := suite.auditLog.write(context.Background(), tx, "ext_schema", AuditEntry{Timestamp: auditTimestamp, DbUser: "", Operation: AuditOperationCreateInstance, ExtensionId: "extId",
+		ExtensionVersion: "1.0.0", InstanceId: "instId", Parameters: []ParameterValue{{Name: "password", Value: "***"}}, Outcome: AuditOutcomeSuccess, ErrorMessage: ""})
+	suite.Require().NoError(err)
+}
+
+func (suite *AuditLogSuite) TestWriteFailsCreatingTable() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnError(mockError)
+	err := suite.auditLog.write(context.Background(), tx, "ext_schema", AuditEntry{Timestamp: auditTimestamp, Operation: AuditOperationInstall, ExtensionId: "extId", Outcome: AuditOutcomeSuccess})
+	suite.Require().EqualError(err, "failed to create audit log table: mock error")
+}
+
+func (suite *AuditLogSuite) TestWriteFailsInserting() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnError(mockError)
+	err := suite.auditLog.write(context.Background(), tx, "ext_schema", AuditEntry{Timestamp: auditTimestamp, Operation: AuditOperationInstall, ExtensionId: "extId", Outcome: AuditOutcomeSuccess})
+	suite.Require().EqualError(err, "failed to write audit log entry: mock error")
+}
+
+func (suite *AuditLogSuite) TestWriteCreatesTableOnlyOnce() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnResult(sqlmock.NewResult(0, 1))
+	entry := AuditEntry{Timestamp: auditTimestamp, Operation: AuditOperationInstall, ExtensionId: "extId", Outcome: AuditOutcomeSuccess}
+	suite.Require().NoError(suite.auditLog.write(context.Background(), tx, "ext_schema", entry))
+	suite.Require().NoError(suite.auditLog.write(context.Background(), tx, "ext_schema", entry))
+}
+
+func (suite *AuditLogSuite) TestWriteCreatesTableForEachSchema() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(`CREATE TABLE IF NOT EXISTS "other_schema"\."EXTENSION_AUDIT_LOG"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "other_schema"."EXTENSION_AUDIT_LOG"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	entry := AuditEntry{Timestamp: auditTimestamp, Operation: AuditOperationInstall, ExtensionId: "extId", Outcome: AuditOutcomeSuccess}
+	suite.Require().NoError(suite.auditLog.write(context.Background(), tx, "ext_schema", entry))
+	suite.Require().NoError(suite.auditLog.write(context.Background(), tx, "other_schema", entry))
+}
+
+func (suite *AuditLogSuite) TestWriteCreatesTableForEachDatabase() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnResult(sqlmock.NewResult(0, 1))
+	entry := AuditEntry{Timestamp: auditTimestamp, Operation: AuditOperationInstall, ExtensionId: "extId", Outcome: AuditOutcomeSuccess}
+	suite.Require().NoError(suite.auditLog.write(WithDatabaseIdentifier(context.Background(), "db1:8563"), tx, "ext_schema", entry))
+	suite.Require().NoError(suite.auditLog.write(WithDatabaseIdentifier(context.Background(), "db2:8563"), tx, "ext_schema", entry))
+}
+
+func (suite *AuditLogSuite) TestWriteCreatesTableAgainIfTableNotFound() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnError(objectNotFoundError)
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnResult(sqlmock.NewResult(0, 1))
+	entry := AuditEntry{Timestamp: auditTimestamp, Operation: AuditOperationInstall, ExtensionId: "extId", Outcome: AuditOutcomeSuccess}
+	suite.Require().NoError(suite.auditLog.write(context.Background(), tx, "ext_schema", entry))
+	suite.Require().NoError(suite.auditLog.write(context.Background(), tx, "ext_schema", entry))
+}
+
+func (suite *AuditLogSuite) TestWriteDoesNotCreateTableAgainForOtherInsertErrors() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnError(mockError)
+	entry := AuditEntry{Timestamp: auditTimestamp, Operation: AuditOperationInstall, ExtensionId: "extId", Outcome: AuditOutcomeSuccess}
+	suite.Require().NoError(suite.auditLog.write(context.Background(), tx, "ext_schema", entry))
+	suite.Require().EqualError(suite.auditLog.write(context.Background(), tx, "ext_schema", entry), "failed to write audit log entry: mock error")
+}
+
+func (suite *AuditLogSuite) TestWriteDoesNotCacheFailedTableCreation() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnError(mockError)
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).WillReturnResult(sqlmock.NewResult(0, 1))
+	entry := AuditEntry{Timestamp: auditTimestamp, Operation: AuditOperationInstall, ExtensionId: "extId", Outcome: AuditOutcomeSuccess}
+	suite.Require().EqualError(suite.auditLog.write(context.Background(), tx, "ext_schema", entry), "failed to write audit log entry: mock error")
+	suite.Require().NoError(suite.auditLog.write(context.Background(), tx, "ext_schema", entry))
+}
+
+func (suite *AuditLogSuite) TestWriteInNewTransaction() {
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(insertAuditEntry)).
+		WithArgs(auditTimestamp, "INSTALL", "extId", "1.0.0", "", "null", "FAILURE", "failed").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectCommit()
+	err := suite.auditLog.writeInNewTransaction(context.Background(), suite.db, "ext_schema", AuditEntry{Timestamp: auditTimestamp, Operation: AuditOperationInstall,
+		ExtensionId: "extId", ExtensionVersion: "1.0.0", Outcome: AuditOutcomeFailure, ErrorMessage: "failed"})
+	suite.Require().NoError(err)
+}
+
+func (suite *AuditLogSuite) TestWriteInNewTransactionRollsBackOnFailure() {
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(createAuditTableRegexp).WillReturnError(mockError)
+	suite.dbMock.ExpectRollback()
+	err := suite.auditLog.writeInNewTransaction(context.Background(), suite.db, "ext_schema", AuditEntry{Timestamp: auditTimestamp, Operation: AuditOperationInstall,
+		ExtensionId: "extId", Outcome: AuditOutcomeFailure})
+	suite.Require().EqualError(err, "failed to create audit log table: mock error")
+}
+
+func (suite *AuditLogSuite) TestFindReturnsEmptyListIfTableDoesNotExist() {
+	tx := suite.beginTransaction()
+	suite.dbMock.ExpectQuery(regexp.QuoteMeta(auditTableExists)).WithArgs("ext_schema", "EXTENSION_AUDIT_LOG").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(0))
+	entries, err := suite.auditLog.find(tx, "ext_schema", AuditLogFilter{})
+	suite.Require().NoError(err)
+	suite.Empty(entries)
+}
+
+func (suite *AuditLogSuite) TestFindWithoutFilter() {
+	tx := suite.beginTransaction()
+	suite.simulateTableExists()
+	suite.dbMock.ExpectQuery(regexp.QuoteMeta(selectAuditEntries + " ORDER BY EVENT_TIME DESC")).WillReturnRows(suite.auditRows().
+		AddRow(auditTimestamp, "SYS", "CREATE_INSTANCE", "extId", "1.0.0", "instId", `[{"name":"p","value":"v"}]`, "SUCCESS", nil).
+		AddRow(auditTimestamp, "ADMIN", "UNINSTALL", "extId", "1.0.0", nil, "null", "FAILURE", "failed"))
+	entries, err := suite.auditLog.find(tx, "ext_schema", AuditLogFilter{})
+	suite.Require().NoError(err)
+	suite.Equal([]AuditEntry{
+		{Timestamp: auditTimestamp, DbUser: "SYS", Operation: AuditOperationCreateInstance, ExtensionId: "extId", ExtensionVersion: "1.0.0", InstanceId: "instId",
+			Parameters: []ParameterValue{{Name: "p", Value: "v"}}, Outcome: AuditOutcomeSuccess, ErrorMessage: ""},
+		{Timestamp: auditTimestamp, DbUser: "ADMIN", Operation: AuditOperationUninstall, ExtensionId: "extId", ExtensionVersion: "1.0.0", InstanceId: "",
+			Parameters: nil, Outcome: AuditOutcomeFailure, ErrorMessage: "failed"},
+	}, entries)
+}
+
+func (suite *AuditLogSuite) TestFindWithFilter() {
+	tx := suite.beginTransaction()
+	suite.simulateTableExists()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	suite.dbMock.ExpectQuery(regexp.QuoteMeta(selectAuditEntries+
+		" WHERE EXTENSION_ID = ? AND OPERATION = ? AND DB_USER = ? AND OUTCOME = ? AND EVENT_TIME >= ? AND EVENT_TIME < ? ORDER BY EVENT_TIME DESC LIMIT 5")).
+		WithArgs("extId", "INSTALL", "SYS", "SUCCESS", from, to).
+		WillReturnRows(suite.auditRows())
+	entries, err := suite.auditLog.find(tx, "ext_schema", AuditLogFilter{ExtensionId: "extId", Operation: AuditOperationInstall, DbUser: "SYS", Outcome: AuditOutcomeSuccess,
+		From: from, To: to, Limit: 5})
+	suite.Require().NoError(err)
+	suite.Empty(entries)
+}
+
+func (suite *AuditLogSuite) TestFindFails() {
+	tx := suite.beginTransaction()
+	suite.simulateTableExists()
+	suite.dbMock.ExpectQuery(regexp.QuoteMeta(selectAuditEntries)).WillReturnError(mockError)
+	entries, err := suite.auditLog.find(tx, "ext_schema", AuditLogFilter{})
+	suite.Require().EqualError(err, "failed to read audit log: mock error")
+	suite.Nil(entries)
+}
+
+func (suite *AuditLogSuite) TestRedactParameters() {
+	definitions := []parameterValidator.ParameterDefinition{
+		{Id: "user", Name: "User", RawDefinition: map[string]any{"id": "user", "secret": false}},
+		{Id: "password", Name: "Password", RawDefinition: map[string]any{"id": "password", "secret": true}}}
+	values := []ParameterValue{{Name: "user", Value: "admin"}, {Name: "password", Value: "pwd"}, {Name: "unknown", Value: "value"}}
+	suite.Equal([]ParameterValue{{Name: "user", Value: "admin"}, {Name: "password", Value: "***"}, {Name: "unknown", Value: "value"}},
+		redactParameters(definitions, values))
+	suite.Equal("pwd", values[1].Value, "original values unchanged")
+}
+
+func (suite *AuditLogSuite) TestRedactAllParametersWithoutDefinitions() {
+	suite.Equal([]ParameterValue{{Name: "user", Value: "***"}}, redactParameters(nil, []ParameterValue{{Name: "user", Value: "admin"}}))
+}
+
+func (suite *AuditLogSuite) beginTransaction() *sql.Tx {
+	suite.dbMock.ExpectBegin()
+	tx, err := suite.db.Begin()
+	suite.Require().NoError(err)
+	return tx
+}
+
+func (suite *AuditLogSuite) simulateTableExists() {
+	suite.dbMock.ExpectQuery(regexp.QuoteMeta(auditTableExists)).WithArgs("ext_schema", "EXTENSION_AUDIT_LOG").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT"}).AddRow(1))
+}
+
+func (suite *AuditLogSuite) auditRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"EVENT_TIME", "DB_USER", "OPERATION", "EXTENSION_ID", "EXTENSION_VERSION", "INSTANCE_ID", "PARAMETERS", "OUTCOME", "ERROR_MESSAGE"})
+}
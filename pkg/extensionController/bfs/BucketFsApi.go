@@ -6,21 +6,26 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/exasol/extension-manager/pkg/metrics"
 	"github.com/sirupsen/logrus"
 )
 
 // BucketFsAPI allows access to BucketFS.
 // Users must call the [BucketFsAPI.Close] method to release resources after using the BucketFS API.
 type BucketFsAPI interface {
-	// ListFiles lists all files in the configured directory recursively.
+	// ListFiles lists all files in the configured directories recursively, ordered by base path and path.
 	ListFiles() ([]BfsFile, error)
 
 	// FindAbsolutePath searches for a file with the given name in BucketFS and returns its absolute path.
-	// If multiple files with the same name exist in different folders, this picks an arbitrary file and returns its path.
+	// This searches the configured base paths of the given bucket in order and returns the first match.
+	// If the bucket is empty, this searches all configured base paths. See [MatchesBucket] for the format of the bucket.
+	// If multiple files with the same name exist in different folders of a base path, this picks an arbitrary file and returns its path.
 	// If no file with the given name exists, this will return an error.
-	FindAbsolutePath(fileName string) (string, error)
+	FindAbsolutePath(fileName string, bucket string) (string, error)
 
 	// Close removes any resources used by the BucketFS API like Exasol UDF SCRIPTS.
 	Close() error
@@ -28,9 +33,25 @@ type BucketFsAPI interface {
 
 // BfsFile represents a file in BucketFS.
 type BfsFile struct {
-	Path string // Absolute path in BucketFS, starting with the base path, e.g. "/buckets/bfsdefault/default/"
-	Name string // File name
-	Size int    // File size in bytes
+	Path     string // Absolute path in BucketFS, starting with the base path, e.g. "/buckets/bfsdefault/default/"
+	Name     string // File name
+	Size     int    // File size in bytes
+	BasePath string // Base path where the file was found, e.g. "/buckets/bfsdefault/default/"
+}
+
+// IsInBucket returns true if the file is located in the given bucket or if the bucket is empty.
+// See [MatchesBucket] for the format of the bucket.
+func (f BfsFile) IsInBucket(bucket string) bool {
+	return bucket == "" || MatchesBucket(f.BasePath, bucket)
+}
+
+// MatchesBucket returns true if the given base path belongs to the given bucket.
+// The bucket is either a complete base path like "/buckets/bfsdefault/default/"
+// or its last components like "bfsdefault/default" (service and bucket name) or "default" (bucket name).
+func MatchesBucket(basePath, bucket string) bool {
+	basePath = "/" + strings.Trim(basePath, "/")
+	bucket = strings.Trim(bucket, "/")
+	return bucket != "" && (basePath == "/"+bucket || strings.HasSuffix(basePath, "/"+bucket))
 }
 
 // CreateBucketFsAPI creates an instance of BucketFsAPI.
@@ -39,7 +60,14 @@ type BfsFile struct {
 // Call the [BucketFsAPI.Close] method to release resources after using the BucketFS API.
 /* [impl -> dsn~configure-bucketfs-path~1]. */
 func CreateBucketFsAPI(bucketFsBasePath string, ctx context.Context, db *sql.DB) (BucketFsAPI, error) {
-	if bucketFsBasePath == "" {
+	return CreateBucketFsAPIForBasePaths([]string{bucketFsBasePath}, ctx, db)
+}
+
+// CreateBucketFsAPIForBasePaths creates an instance of BucketFsAPI that searches the given base paths in order.
+//
+// Call the [BucketFsAPI.Close] method to release resources after using the BucketFS API.
+func CreateBucketFsAPIForBasePaths(bucketFsBasePaths []string, ctx context.Context, db *sql.DB) (BucketFsAPI, error) {
+	if len(bucketFsBasePaths) == 0 || slices.Contains(bucketFsBasePaths, "") {
 		return nil, errors.New("bucketFsBasePath is empty")
 	}
 	transaction, err := db.BeginTx(ctx, nil)
@@ -51,49 +79,104 @@ func CreateBucketFsAPI(bucketFsBasePath string, ctx context.Context, db *sql.DB)
 		_ = transaction.Rollback()
 		return nil, err
 	}
-	return &bucketFsAPIImpl{bucketFsBasePath: bucketFsBasePath, udfScriptName: udfScriptName, transaction: transaction}, nil
+	return &bucketFsAPIImpl{bucketFsBasePaths: bucketFsBasePaths, udfScriptName: udfScriptName, transaction: transaction}, nil
 }
 
 type bucketFsAPIImpl struct {
-	bucketFsBasePath string
-	udfScriptName    string
-	transaction      *sql.Tx
+	bucketFsBasePaths []string
+	udfScriptName     string
+	transaction       *sql.Tx
 }
 
 /* [impl -> dsn~extension-components~1]. */
-func (bfs bucketFsAPIImpl) ListFiles() ([]BfsFile, error) {
+func (bfs bucketFsAPIImpl) ListFiles() (result []BfsFile, errResult error) {
 	t0 := time.Now()
+	defer func() { metrics.ObserveBucketFsListing(t0, errResult) }()
 	statement, err := bfs.transaction.Prepare("SELECT " + bfs.udfScriptName + "(?) ORDER BY FULL_PATH") //nolint:gosec // SQL string concatenation is safe here
 	if err != nil {
 		return nil, fmt.Errorf("failed to create prepared statement for listing files. Cause: %w", err)
 	}
 	defer statement.Close()
-	result, err := statement.Query(bfs.bucketFsBasePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list files. Cause: %w", err)
+	var files []BfsFile
+	for _, basePath := range bfs.bucketFsBasePaths {
+		filesInBasePath, err := listFiles(statement, basePath)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, filesInBasePath...)
 	}
-	defer result.Close()
-	files, err := readQueryResult(result)
 	if logrus.IsLevelEnabled(logrus.TraceLevel) {
 		for _, file := range files {
 			logrus.Tracef("- Found file %q with size %d", file.Path, file.Size)
 		}
 	}
-	logrus.Debugf("Listed %d files under %q in %dms", len(files), bfs.bucketFsBasePath, time.Since(t0).Milliseconds())
-	return files, err
+	logrus.Debugf("Listed %d files under %q in %dms", len(files), bfs.bucketFsBasePaths, time.Since(t0).Milliseconds())
+	return files, nil
+}
+
+func listFiles(statement *sql.Stmt, basePath string) ([]BfsFile, error) {
+	result, err := statement.Query(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files. Cause: %w", err)
+	}
+	defer result.Close()
+	files, err := readQueryResult(result)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		files[i].BasePath = basePath
+	}
+	return files, nil
 }
 
 /* [impl -> dsn~resolving-files-in-bucketfs~1]. */
 /* [impl -> dsn~extension-context-bucketfs~1]. */
-func (bfs bucketFsAPIImpl) FindAbsolutePath(fileName string) (string, error) {
+func (bfs bucketFsAPIImpl) FindAbsolutePath(fileName string, bucket string) (string, error) {
 	t0 := time.Now()
+	basePaths := bfs.basePathsInBucket(bucket)
+	if len(basePaths) == 0 {
+		return "", fmt.Errorf("bucket %q of file %q does not match any configured BucketFS base path %q", bucket, fileName, bfs.bucketFsBasePaths)
+	}
 	query := fmt.Sprintf(`SELECT FULL_PATH FROM (SELECT %s(?)) WHERE FILE_NAME = ? ORDER BY FULL_PATH LIMIT 1`, bfs.udfScriptName) //nolint:gosec // SQL string concatenation is safe here
 	statement, err := bfs.transaction.Prepare(query)
 	if err != nil {
 		return "", fmt.Errorf("failed to create prepared statement for running list files UDF. Cause: %w", err)
 	}
 	defer statement.Close()
-	result, err := statement.Query(bfs.bucketFsBasePath, fileName)
+	for _, basePath := range basePaths {
+		absolutePath, err := findAbsolutePath(statement, basePath, fileName)
+		if err != nil {
+			return "", err
+		}
+		if absolutePath != "" {
+			logrus.Tracef("Found absolute path %q for file %q under %q in %dms", absolutePath, fileName, basePath, time.Since(t0).Milliseconds())
+			return absolutePath, nil
+		}
+	}
+	if bucket != "" {
+		return "", fmt.Errorf("file %q not found in bucket %q of BucketFS", fileName, bucket)
+	}
+	return "", fmt.Errorf("file %q not found in BucketFS", fileName)
+}
+
+// basePathsInBucket returns the configured base paths belonging to the given bucket or all base paths if the bucket is empty.
+func (bfs bucketFsAPIImpl) basePathsInBucket(bucket string) []string {
+	if bucket == "" {
+		return bfs.bucketFsBasePaths
+	}
+	var basePaths []string
+	for _, basePath := range bfs.bucketFsBasePaths {
+		if MatchesBucket(basePath, bucket) {
+			basePaths = append(basePaths, basePath)
+		}
+	}
+	return basePaths
+}
+
+// findAbsolutePath returns the absolute path of the given file under the given base path or an empty string if it does not exist.
+func findAbsolutePath(statement *sql.Stmt, basePath, fileName string) (string, error) {
+	result, err := statement.Query(basePath, fileName)
 	if err != nil {
 		return "", fmt.Errorf("failed to find absolute path in BucketFS using UDF. Cause: %w", err)
 	}
@@ -102,14 +185,13 @@ func (bfs bucketFsAPIImpl) FindAbsolutePath(fileName string) (string, error) {
 		if result.Err() != nil {
 			return "", fmt.Errorf("failed iterating absolute path results. Cause: %w", result.Err())
 		}
-		return "", fmt.Errorf("file %q not found in BucketFS", fileName)
+		return "", nil
 	}
 	var absolutePath string
 	err = result.Scan(&absolutePath)
 	if err != nil {
 		return "", fmt.Errorf("failed reading absolute path. Cause: %w", err)
 	}
-	logrus.Tracef("Found absolute path %q for file %q in %dms", absolutePath, fileName, time.Since(t0).Milliseconds())
 	return absolutePath, nil
 }
 
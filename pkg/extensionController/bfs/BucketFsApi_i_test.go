@@ -116,7 +116,7 @@ func (suite *BucketFsClientITestSuite) listFiles() ([]bfs.BfsFile, error) {
 }
 
 func (suite *BucketFsClientITestSuite) findAbsolutePath(fileName string) (string, error) {
-	return suite.bfsClient.FindAbsolutePath(fileName)
+	return suite.bfsClient.FindAbsolutePath(fileName, "")
 }
 
 func (suite *BucketFsClientITestSuite) createBucketFsClient() bfs.BucketFsAPI {
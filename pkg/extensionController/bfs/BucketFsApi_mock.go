@@ -21,12 +21,12 @@ func (m *BucketFsMock) SimulateFilesError(err error) {
 	m.On("ListFiles").Return(nil, err)
 }
 
-func (m *BucketFsMock) SimulateAbsolutePath(fileName, absolutePath string) {
-	m.On("FindAbsolutePath", fileName).Return(absolutePath, nil)
+func (m *BucketFsMock) SimulateAbsolutePath(fileName, bucket, absolutePath string) {
+	m.On("FindAbsolutePath", fileName, bucket).Return(absolutePath, nil)
 }
 
-func (m *BucketFsMock) SimulateAbsolutePathError(fileName string, err error) {
-	m.On("FindAbsolutePath", fileName).Return("", err)
+func (m *BucketFsMock) SimulateAbsolutePathError(fileName, bucket string, err error) {
+	m.On("FindAbsolutePath", fileName, bucket).Return("", err)
 }
 
 func (m *BucketFsMock) SimulateCloseSuccess() {
@@ -45,8 +45,8 @@ func (mock *BucketFsMock) ListFiles() ([]BfsFile, error) {
 	return nil, args.Error(1)
 }
 
-func (mock *BucketFsMock) FindAbsolutePath(fileName string) (absolutePath string, retErr error) {
-	args := mock.Called(fileName)
+func (mock *BucketFsMock) FindAbsolutePath(fileName string, bucket string) (absolutePath string, retErr error) {
+	args := mock.Called(fileName, bucket)
 	return args.String(0), args.Error(1)
 }
 
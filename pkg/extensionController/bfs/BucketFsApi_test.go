@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -80,6 +81,14 @@ func (suite *BucketFsClientUTestSuite) TestCreateBucketFsAPIFailsInvalidBasePath
 	suite.Nil(client)
 }
 
+func (suite *BucketFsClientUTestSuite) TestCreateBucketFsAPIForBasePathsFailsInvalidBasePaths() {
+	for _, basePaths := range [][]string{nil, {}, {"/base/", ""}} {
+		client, err := CreateBucketFsAPIForBasePaths(basePaths, context.Background(), suite.db)
+		suite.Require().EqualError(err, "bucketFsBasePath is empty")
+		suite.Nil(client)
+	}
+}
+
 // ListFiles
 
 /* [utest -> dsn~configure-bucketfs-path~1]. */
@@ -93,7 +102,33 @@ func (suite *BucketFsClientUTestSuite) TestListFiles() {
 		RowsWillBeClosed()
 	result, err := client.ListFiles()
 	suite.Require().NoError(err)
-	suite.Equal([]BfsFile{{Name: "file1.txt", Path: "/base/file1.txt", Size: 10}, {Name: "file2.txt", Path: "/base2/file2.txt", Size: 20}}, result)
+	suite.Equal([]BfsFile{{Name: "file1.txt", Path: "/base/file1.txt", Size: 10, BasePath: BUCKETFS_BASE_PATH},
+		{Name: "file2.txt", Path: "/base2/file2.txt", Size: 20, BasePath: BUCKETFS_BASE_PATH}}, result)
+}
+
+func (suite *BucketFsClientUTestSuite) TestListFilesInMultipleBasePaths() {
+	client := suite.createBucketFsClientForBasePaths("/base1/", "/base2/")
+	statement := suite.dbMock.ExpectPrepare(`SELECT "INTERNAL_.* ORDER BY FULL_PATH`).WillBeClosed()
+	statement.ExpectQuery().WithArgs("/base1/").WillReturnRows(sqlmock.NewRows([]string{"FILE_NAME", "FULL_PATH", "SIZE"}).
+		AddRow("file1.txt", "/base1/file1.txt", 10)).RowsWillBeClosed()
+	statement.ExpectQuery().WithArgs("/base2/").WillReturnRows(sqlmock.NewRows([]string{"FILE_NAME", "FULL_PATH", "SIZE"}).
+		AddRow("file1.txt", "/base2/file1.txt", 10).
+		AddRow("file2.txt", "/base2/file2.txt", 20)).RowsWillBeClosed()
+	result, err := client.ListFiles()
+	suite.Require().NoError(err)
+	suite.Equal([]BfsFile{{Name: "file1.txt", Path: "/base1/file1.txt", Size: 10, BasePath: "/base1/"},
+		{Name: "file1.txt", Path: "/base2/file1.txt", Size: 10, BasePath: "/base2/"},
+		{Name: "file2.txt", Path: "/base2/file2.txt", Size: 20, BasePath: "/base2/"}}, result)
+}
+
+func (suite *BucketFsClientUTestSuite) TestListFilesInMultipleBasePathsFails() {
+	client := suite.createBucketFsClientForBasePaths("/base1/", "/base2/")
+	statement := suite.dbMock.ExpectPrepare(`SELECT "INTERNAL_.* ORDER BY FULL_PATH`).WillBeClosed()
+	statement.ExpectQuery().WithArgs("/base1/").WillReturnRows(sqlmock.NewRows([]string{"FILE_NAME", "FULL_PATH", "SIZE"})).RowsWillBeClosed()
+	statement.ExpectQuery().WithArgs("/base2/").WillReturnError(mockError)
+	result, err := client.ListFiles()
+	suite.Require().EqualError(err, "failed to list files. Cause: mock error")
+	suite.Nil(result)
 }
 
 func (suite *BucketFsClientUTestSuite) TestListFilesPrepareQueryFails() {
@@ -137,15 +172,69 @@ func (suite *BucketFsClientUTestSuite) TestFindAbsolutePath() {
 		WillBeClosed().
 		ExpectQuery().WithArgs(BUCKETFS_BASE_PATH, FILE_NAME).WillReturnRows(sqlmock.NewRows([]string{"FULL_PATH"}).AddRow("/abs/path/file.txt")).
 		RowsWillBeClosed()
-	result, err := client.FindAbsolutePath(FILE_NAME)
+	result, err := client.FindAbsolutePath(FILE_NAME, "")
 	suite.Require().NoError(err)
 	suite.Equal("/abs/path/file.txt", result)
 }
 
+func (suite *BucketFsClientUTestSuite) TestFindAbsolutePathSearchesBasePathsInOrder() {
+	client := suite.createBucketFsClientForBasePaths("/base1/", "/base2/", "/base3/")
+	statement := suite.dbMock.ExpectPrepare(`SELECT FULL_PATH FROM.*`).WillBeClosed()
+	statement.ExpectQuery().WithArgs("/base1/", FILE_NAME).WillReturnRows(sqlmock.NewRows([]string{"FULL_PATH"})).RowsWillBeClosed()
+	statement.ExpectQuery().WithArgs("/base2/", FILE_NAME).WillReturnRows(sqlmock.NewRows([]string{"FULL_PATH"}).AddRow("/base2/file.txt")).RowsWillBeClosed()
+	result, err := client.FindAbsolutePath(FILE_NAME, "")
+	suite.Require().NoError(err)
+	suite.Equal("/base2/file.txt", result)
+}
+
+func (suite *BucketFsClientUTestSuite) TestFindAbsolutePathNotFoundInAnyBasePath() {
+	client := suite.createBucketFsClientForBasePaths("/base1/", "/base2/")
+	statement := suite.dbMock.ExpectPrepare(`SELECT FULL_PATH FROM.*`).WillBeClosed()
+	statement.ExpectQuery().WithArgs("/base1/", FILE_NAME).WillReturnRows(sqlmock.NewRows([]string{"FULL_PATH"})).RowsWillBeClosed()
+	statement.ExpectQuery().WithArgs("/base2/", FILE_NAME).WillReturnRows(sqlmock.NewRows([]string{"FULL_PATH"})).RowsWillBeClosed()
+	result, err := client.FindAbsolutePath(FILE_NAME, "")
+	suite.Require().EqualError(err, `file "file.txt" not found in BucketFS`)
+	suite.Equal("", result)
+}
+
+func (suite *BucketFsClientUTestSuite) TestFindAbsolutePathSearchesOnlyBasePathsOfBucket() {
+	client := suite.createBucketFsClientForBasePaths("/buckets/bfsdefault/default/", "/buckets/bfsjars/jars/")
+	statement := suite.dbMock.ExpectPrepare(`SELECT FULL_PATH FROM.*`).WillBeClosed()
+	statement.ExpectQuery().WithArgs("/buckets/bfsjars/jars/", FILE_NAME).WillReturnRows(sqlmock.NewRows([]string{"FULL_PATH"}).AddRow("/buckets/bfsjars/jars/file.txt")).RowsWillBeClosed()
+	result, err := client.FindAbsolutePath(FILE_NAME, "bfsjars/jars")
+	suite.Require().NoError(err)
+	suite.Equal("/buckets/bfsjars/jars/file.txt", result)
+}
+
+func (suite *BucketFsClientUTestSuite) TestFindAbsolutePathWithoutBucketReturnsFileInFirstBasePath() {
+	client := suite.createBucketFsClientForBasePaths("/buckets/bfsdefault/default/", "/buckets/bfsjars/jars/")
+	statement := suite.dbMock.ExpectPrepare(`SELECT FULL_PATH FROM.*`).WillBeClosed()
+	statement.ExpectQuery().WithArgs("/buckets/bfsdefault/default/", FILE_NAME).WillReturnRows(sqlmock.NewRows([]string{"FULL_PATH"}).AddRow("/buckets/bfsdefault/default/file.txt")).RowsWillBeClosed()
+	result, err := client.FindAbsolutePath(FILE_NAME, "")
+	suite.Require().NoError(err)
+	suite.Equal("/buckets/bfsdefault/default/file.txt", result)
+}
+
+func (suite *BucketFsClientUTestSuite) TestFindAbsolutePathNotFoundInBucket() {
+	client := suite.createBucketFsClientForBasePaths("/buckets/bfsdefault/default/", "/buckets/bfsjars/jars/")
+	statement := suite.dbMock.ExpectPrepare(`SELECT FULL_PATH FROM.*`).WillBeClosed()
+	statement.ExpectQuery().WithArgs("/buckets/bfsjars/jars/", FILE_NAME).WillReturnRows(sqlmock.NewRows([]string{"FULL_PATH"})).RowsWillBeClosed()
+	result, err := client.FindAbsolutePath(FILE_NAME, "jars")
+	suite.Require().EqualError(err, `file "file.txt" not found in bucket "jars" of BucketFS`)
+	suite.Equal("", result)
+}
+
+func (suite *BucketFsClientUTestSuite) TestFindAbsolutePathFailsForUnknownBucket() {
+	client := suite.createBucketFsClientForBasePaths("/buckets/bfsdefault/default/", "/buckets/bfsjars/jars/")
+	result, err := client.FindAbsolutePath(FILE_NAME, "unknown")
+	suite.Require().EqualError(err, `bucket "unknown" of file "file.txt" does not match any configured BucketFS base path ["/buckets/bfsdefault/default/" "/buckets/bfsjars/jars/"]`)
+	suite.Equal("", result)
+}
+
 func (suite *BucketFsClientUTestSuite) TestFindAbsolutePathPrepareQueryFails() {
 	client := suite.createBucketFsClientHandleError()
 	suite.dbMock.ExpectPrepare(`SELECT FULL_PATH FROM.*`).WillReturnError(mockError)
-	result, err := client.FindAbsolutePath(FILE_NAME)
+	result, err := client.FindAbsolutePath(FILE_NAME, "")
 	suite.Require().EqualError(err, "failed to create prepared statement for running list files UDF. Cause: mock error")
 	suite.Equal("", result)
 }
@@ -155,7 +244,7 @@ func (suite *BucketFsClientUTestSuite) TestFindAbsolutePathExecuteQueryFails() {
 	suite.dbMock.ExpectPrepare(`SELECT FULL_PATH FROM.*`).
 		WillBeClosed().
 		ExpectQuery().WithArgs(BUCKETFS_BASE_PATH, FILE_NAME).WillReturnError(mockError)
-	result, err := client.FindAbsolutePath(FILE_NAME)
+	result, err := client.FindAbsolutePath(FILE_NAME, "")
 	suite.Require().EqualError(err, "failed to find absolute path in BucketFS using UDF. Cause: mock error")
 	suite.Equal("", result)
 }
@@ -165,7 +254,7 @@ func (suite *BucketFsClientUTestSuite) TestFindAbsolutePathNoResult() {
 	suite.dbMock.ExpectPrepare(`SELECT FULL_PATH FROM.*`).
 		WillBeClosed().
 		ExpectQuery().WithArgs(BUCKETFS_BASE_PATH, FILE_NAME).WillReturnRows(sqlmock.NewRows([]string{"FULL_PATH"})).RowsWillBeClosed()
-	result, err := client.FindAbsolutePath(FILE_NAME)
+	result, err := client.FindAbsolutePath(FILE_NAME, "")
 	suite.Require().EqualError(err, `file "file.txt" not found in BucketFS`)
 	suite.Equal("", result)
 }
@@ -176,7 +265,7 @@ func (suite *BucketFsClientUTestSuite) TestFindAbsolutePathRowError() {
 		WillBeClosed().
 		ExpectQuery().WithArgs(BUCKETFS_BASE_PATH, FILE_NAME).WillReturnRows(sqlmock.NewRows([]string{"FULL_PATH"}).AddRow("/abs/path/file.txt").RowError(0, mockError)).
 		RowsWillBeClosed()
-	result, err := client.FindAbsolutePath(FILE_NAME)
+	result, err := client.FindAbsolutePath(FILE_NAME, "")
 	suite.Require().EqualError(err, "failed iterating absolute path results. Cause: mock error")
 	suite.Equal("", result)
 }
@@ -187,7 +276,7 @@ func (suite *BucketFsClientUTestSuite) TestFindAbsolutePathWrongResultColumnCoun
 		WillBeClosed().
 		ExpectQuery().WithArgs(BUCKETFS_BASE_PATH, FILE_NAME).WillReturnRows(sqlmock.NewRows([]string{"FULL_PATH", "Additional Column"}).AddRow("/abs/path/file.txt", "a")).
 		RowsWillBeClosed()
-	result, err := client.FindAbsolutePath(FILE_NAME)
+	result, err := client.FindAbsolutePath(FILE_NAME, "")
 	suite.Require().EqualError(err, `failed reading absolute path. Cause: sql: expected 2 destination arguments in Scan, not 1`)
 	suite.Equal("", result)
 }
@@ -214,9 +303,49 @@ func (suite *BucketFsClientUTestSuite) createBucketFsClientHandleError() BucketF
 	return bfsClient
 }
 
+func (suite *BucketFsClientUTestSuite) createBucketFsClientForBasePaths(basePaths ...string) BucketFsAPI {
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("CREATE SCHEMA INTERNAL_\\d+").WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec("(?m)CREATE OR REPLACE PYTHON3 SCALAR SCRIPT.*").WillReturnResult(sqlmock.NewResult(0, 1))
+	bfsClient, err := CreateBucketFsAPIForBasePaths(basePaths, context.Background(), suite.db)
+	suite.Require().NoError(err)
+	return bfsClient
+}
+
 func (suite *BucketFsClientUTestSuite) createBucketFsClient() (BucketFsAPI, error) {
 	suite.dbMock.ExpectBegin()
 	suite.dbMock.ExpectExec("CREATE SCHEMA INTERNAL_\\d+").WillReturnResult(sqlmock.NewResult(0, 1))
 	suite.dbMock.ExpectExec("(?m)CREATE OR REPLACE PYTHON3 SCALAR SCRIPT.*").WillReturnResult(sqlmock.NewResult(0, 1))
 	return CreateBucketFsAPI(BUCKETFS_BASE_PATH, context.Background(), suite.db)
 }
+
+// MatchesBucket
+
+func TestMatchesBucket(t *testing.T) {
+	var tests = []struct {
+		basePath string
+		bucket   string
+		expected bool
+	}{
+		{basePath: "/buckets/bfsdefault/default/", bucket: "/buckets/bfsdefault/default/", expected: true},
+		{basePath: "/buckets/bfsdefault/default/", bucket: "/buckets/bfsdefault/default", expected: true},
+		{basePath: "/buckets/bfsdefault/default/", bucket: "bfsdefault/default", expected: true},
+		{basePath: "/buckets/bfsdefault/default", bucket: "default", expected: true},
+		{basePath: "/buckets/bfsdefault/default/", bucket: "bfsjars/default", expected: false},
+		{basePath: "/buckets/bfsdefault/default/", bucket: "fault", expected: false},
+		{basePath: "/buckets/bfsdefault/default/", bucket: "", expected: false},
+		{basePath: "/buckets/bfsdefault/default/", bucket: "/", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.basePath+" "+test.bucket, func(t *testing.T) {
+			assert.Equal(t, test.expected, MatchesBucket(test.basePath, test.bucket))
+		})
+	}
+}
+
+func TestIsInBucket(t *testing.T) {
+	file := BfsFile{Path: "/buckets/bfsjars/jars/file.jar", Name: "file.jar", Size: 1, BasePath: "/buckets/bfsjars/jars/"}
+	assert.True(t, file.IsInBucket(""))
+	assert.True(t, file.IsInBucket("bfsjars/jars"))
+	assert.False(t, file.IsInBucket("bfsdefault/default"))
+}
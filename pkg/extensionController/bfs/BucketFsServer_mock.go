@@ -0,0 +1,96 @@
+package bfs
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// BucketFsServerMock is a local HTTP stand-in for the BucketFS HTTP interface of a single bucket.
+// It stores uploaded files in memory and verifies the write password.
+// Call [BucketFsServerMock.Close] after using it.
+type BucketFsServerMock struct {
+	server        *httptest.Server
+	writePassword string
+	mutex         sync.Mutex
+	files         map[string][]byte
+	// UploadSizeOverride simulates a corrupt upload by truncating stored files to the given size if not negative.
+	UploadSizeOverride int
+}
+
+// CreateBucketFsServerMock starts a new BucketFS stand-in using the given write password.
+func CreateBucketFsServerMock(writePassword string) *BucketFsServerMock {
+	//nolint:exhaustruct // Default values for server and mutex are OK
+	mock := &BucketFsServerMock{writePassword: writePassword, files: make(map[string][]byte), UploadSizeOverride: -1}
+	mock.server = httptest.NewServer(http.HandlerFunc(mock.handle))
+	return mock
+}
+
+// URL returns the URL of the simulated bucket.
+func (m *BucketFsServerMock) URL() string {
+	return m.server.URL + "/default/"
+}
+
+// Close stops the server.
+func (m *BucketFsServerMock) Close() {
+	m.server.Close()
+}
+
+// SimulateFile adds a file to the bucket.
+func (m *BucketFsServerMock) SimulateFile(fileName string, content []byte) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.files[fileName] = content
+}
+
+// GetFile returns the content of the given file and true if the file exists in the bucket.
+func (m *BucketFsServerMock) GetFile(fileName string) ([]byte, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	content, ok := m.files[fileName]
+	return content, ok
+}
+
+func (m *BucketFsServerMock) handle(writer http.ResponseWriter, request *http.Request) {
+	fileName := strings.TrimPrefix(request.URL.Path, "/default/")
+	switch request.Method {
+	case http.MethodGet:
+		content, ok := m.GetFile(fileName)
+		if !ok {
+			http.Error(writer, "file not found", http.StatusNotFound)
+			return
+		}
+		_, _ = writer.Write(content)
+	case http.MethodPut:
+		if !m.isWriteAuthorized(request) {
+			http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		content, err := io.ReadAll(request.Body)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if m.UploadSizeOverride >= 0 && m.UploadSizeOverride < len(content) {
+			content = content[:m.UploadSizeOverride]
+		}
+		m.SimulateFile(fileName, content)
+	case http.MethodDelete:
+		if !m.isWriteAuthorized(request) {
+			http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		m.mutex.Lock()
+		delete(m.files, fileName)
+		m.mutex.Unlock()
+	default:
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (m *BucketFsServerMock) isWriteAuthorized(request *http.Request) bool {
+	user, password, ok := request.BasicAuth()
+	return ok && user == bucketFsWriteUser && password == m.writePassword
+}
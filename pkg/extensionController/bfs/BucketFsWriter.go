@@ -0,0 +1,166 @@
+package bfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BucketFsWriter allows uploading files to a bucket using the HTTP interface of BucketFS.
+type BucketFsWriter interface {
+	// Upload writes the content to the file with the given name in the bucket and returns the number of bytes sent.
+	Upload(ctx context.Context, fileName string, content io.Reader) (int, error)
+
+	// FileSize returns the size in bytes of the file with the given name in the bucket.
+	FileSize(ctx context.Context, fileName string) (int, error)
+
+	// Delete removes the file with the given name from the bucket.
+	Delete(ctx context.Context, fileName string) error
+}
+
+// BucketFsWriterConfig contains the configuration for accessing a bucket via HTTP.
+type BucketFsWriterConfig struct {
+	// URL of the bucket, e.g. "https://exasol-host:2581/default/".
+	URL string
+	// Write password of the bucket.
+	WritePassword string
+	// Read password of the bucket. Leave empty for public buckets.
+	ReadPassword string
+	// HTTP client used for requests. Uses a client with a timeout of 10 minutes if nil.
+	HTTPClient *http.Client
+}
+
+// defaultTimeout is the timeout for requests if [BucketFsWriterConfig.HTTPClient] is not set.
+const defaultTimeout = 10 * time.Minute
+
+const (
+	bucketFsWriteUser = "w"
+	bucketFsReadUser  = "r"
+)
+
+// CreateBucketFsWriter creates a new [BucketFsWriter] for the given configuration.
+func CreateBucketFsWriter(config BucketFsWriterConfig) (BucketFsWriter, error) {
+	if config.URL == "" {
+		return nil, errors.New("BucketFS URL is empty")
+	}
+	bucketUrl, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BucketFS URL %q: %w", config.URL, err)
+	}
+	if bucketUrl.Scheme != "http" && bucketUrl.Scheme != "https" {
+		return nil, fmt.Errorf("invalid BucketFS URL %q: scheme must be http or https", config.URL)
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout} //nolint:exhaustruct // Default values for other options are OK
+	}
+	return &bucketFsWriterImpl{bucketUrl: strings.TrimSuffix(config.URL, "/"), writePassword: config.WritePassword, readPassword: config.ReadPassword, client: client}, nil
+}
+
+type bucketFsWriterImpl struct {
+	bucketUrl     string
+	writePassword string
+	readPassword  string
+	client        *http.Client
+}
+
+func (w *bucketFsWriterImpl) Upload(ctx context.Context, fileName string, content io.Reader) (int, error) {
+	t0 := time.Now()
+	counter := &countingReader{reader: content, count: 0}
+	request, err := w.newRequest(ctx, http.MethodPut, fileName, counter)
+	if err != nil {
+		return 0, err
+	}
+	request.SetBasicAuth(bucketFsWriteUser, w.writePassword)
+	if err := w.execute(request); err != nil {
+		return 0, fmt.Errorf("failed to upload file %q to BucketFS: %w", fileName, err)
+	}
+	logrus.Debugf("Uploaded %d bytes to BucketFS file %q in %dms", counter.count, fileName, time.Since(t0).Milliseconds())
+	return counter.count, nil
+}
+
+func (w *bucketFsWriterImpl) FileSize(ctx context.Context, fileName string) (int, error) {
+	request, err := w.newRequest(ctx, http.MethodGet, fileName, nil)
+	if err != nil {
+		return 0, err
+	}
+	if w.readPassword != "" {
+		request.SetBasicAuth(bucketFsReadUser, w.readPassword)
+	}
+	response, err := w.client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file %q from BucketFS: %w", fileName, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to read file %q from BucketFS: %w", fileName, unexpectedStatus(response))
+	}
+	if response.ContentLength >= 0 {
+		// Closing the unread body aborts the transfer, so large files are not downloaded again
+		return int(response.ContentLength), nil
+	}
+	size, err := io.Copy(io.Discard, response.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file %q from BucketFS: %w", fileName, err)
+	}
+	return int(size), nil
+}
+
+func (w *bucketFsWriterImpl) Delete(ctx context.Context, fileName string) error {
+	request, err := w.newRequest(ctx, http.MethodDelete, fileName, nil)
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(bucketFsWriteUser, w.writePassword)
+	if err := w.execute(request); err != nil {
+		return fmt.Errorf("failed to delete file %q from BucketFS: %w", fileName, err)
+	}
+	return nil
+}
+
+func (w *bucketFsWriterImpl) newRequest(ctx context.Context, method, fileName string, body io.Reader) (*http.Request, error) {
+	if fileName == "" || strings.Contains(fileName, "..") {
+		return nil, fmt.Errorf("invalid BucketFS file name %q", fileName)
+	}
+	fileUrl := w.bucketUrl + "/" + url.PathEscape(fileName)
+	request, err := http.NewRequestWithContext(ctx, method, fileUrl, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for BucketFS file %q: %w", fileName, err)
+	}
+	return request, nil
+}
+
+func (w *bucketFsWriterImpl) execute(request *http.Request) error {
+	response, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return unexpectedStatus(response)
+	}
+	return nil
+}
+
+func unexpectedStatus(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("unexpected status %q: %s", response.Status, strings.TrimSpace(string(body)))
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += n
+	return n, err
+}
@@ -0,0 +1,112 @@
+package bfs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const WRITE_PASSWORD = "writePassword"
+
+type BucketFsWriterUTestSuite struct {
+	suite.Suite
+	server *BucketFsServerMock
+	writer BucketFsWriter
+}
+
+func TestBucketFsWriterUTestSuite(t *testing.T) {
+	suite.Run(t, new(BucketFsWriterUTestSuite))
+}
+
+func (suite *BucketFsWriterUTestSuite) SetupTest() {
+	suite.server = CreateBucketFsServerMock(WRITE_PASSWORD)
+	suite.writer = suite.createWriter(WRITE_PASSWORD)
+}
+
+func (suite *BucketFsWriterUTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *BucketFsWriterUTestSuite) createWriter(writePassword string) BucketFsWriter {
+	writer, err := CreateBucketFsWriter(BucketFsWriterConfig{URL: suite.server.URL(), WritePassword: writePassword, ReadPassword: "", HTTPClient: nil})
+	suite.Require().NoError(err)
+	return writer
+}
+
+func (suite *BucketFsWriterUTestSuite) TestCreateFailsForInvalidUrl() {
+	tests := []struct {
+		url           string
+		expectedError string
+	}{
+		{"", "BucketFS URL is empty"},
+		{"ftp://host/bucket", `invalid BucketFS URL "ftp://host/bucket": scheme must be http or https`},
+		{"http://host:invalid/", `invalid BucketFS URL "http://host:invalid/": parse "http://host:invalid/": invalid port ":invalid" after host`},
+	}
+	for _, test := range tests {
+		suite.Run(test.url, func() {
+			writer, err := CreateBucketFsWriter(BucketFsWriterConfig{URL: test.url, WritePassword: "", ReadPassword: "", HTTPClient: nil})
+			suite.EqualError(err, test.expectedError)
+			suite.Nil(writer)
+		})
+	}
+}
+
+func (suite *BucketFsWriterUTestSuite) TestUpload() {
+	size, err := suite.writer.Upload(context.Background(), FILE_NAME, strings.NewReader("content"))
+	suite.Require().NoError(err)
+	suite.Equal(7, size)
+	content, ok := suite.server.GetFile(FILE_NAME)
+	suite.True(ok)
+	suite.Equal("content", string(content))
+}
+
+func (suite *BucketFsWriterUTestSuite) TestUploadWrongPassword() {
+	size, err := suite.createWriter("wrong").Upload(context.Background(), FILE_NAME, strings.NewReader("content"))
+	suite.EqualError(err, `failed to upload file "file.txt" to BucketFS: unexpected status "401 Unauthorized": Unauthorized`)
+	suite.Zero(size)
+}
+
+func (suite *BucketFsWriterUTestSuite) TestUploadInvalidFileName() {
+	size, err := suite.writer.Upload(context.Background(), "../file.txt", strings.NewReader("content"))
+	suite.EqualError(err, `invalid BucketFS file name "../file.txt"`)
+	suite.Zero(size)
+}
+
+func (suite *BucketFsWriterUTestSuite) TestFileSize() {
+	suite.server.SimulateFile(FILE_NAME, []byte("12345"))
+	size, err := suite.writer.FileSize(context.Background(), FILE_NAME)
+	suite.Require().NoError(err)
+	suite.Equal(5, size)
+}
+
+func (suite *BucketFsWriterUTestSuite) TestFileSizeWithoutContentLength() {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("123"))
+		writer.(http.Flusher).Flush()
+		_, _ = writer.Write([]byte("45"))
+	}))
+	defer server.Close()
+	writer, err := CreateBucketFsWriter(BucketFsWriterConfig{URL: server.URL, WritePassword: "", ReadPassword: "", HTTPClient: nil})
+	suite.Require().NoError(err)
+	size, err := writer.FileSize(context.Background(), FILE_NAME)
+	suite.Require().NoError(err)
+	suite.Equal(5, size)
+}
+
+func (suite *BucketFsWriterUTestSuite) TestFileSizeMissingFile() {
+	size, err := suite.writer.FileSize(context.Background(), FILE_NAME)
+	suite.EqualError(err, `failed to read file "file.txt" from BucketFS: unexpected status "404 Not Found": file not found`)
+	suite.Zero(size)
+}
+
+func (suite *BucketFsWriterUTestSuite) TestDelete() {
+	suite.server.SimulateFile(FILE_NAME, []byte("12345"))
+	err := suite.writer.Delete(context.Background(), FILE_NAME)
+	suite.Require().NoError(err)
+	_, ok := suite.server.GetFile(FILE_NAME)
+	suite.False(ok)
+}
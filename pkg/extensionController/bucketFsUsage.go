@@ -0,0 +1,126 @@
+package extensionController
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/exasol/extension-manager/pkg/extensionController/bfs"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+)
+
+// BucketFsFileUsageType classifies how a file in BucketFS is used.
+type BucketFsFileUsageType string
+
+const (
+	// BucketFsFileReferencedByScript is a file referenced by a script in any schema.
+	BucketFsFileReferencedByScript BucketFsFileUsageType = "referenced-by-script"
+	// BucketFsFileRequiredByExtension is a file not referenced by any script but required by an available extension.
+	BucketFsFileRequiredByExtension BucketFsFileUsageType = "required-by-extension"
+	// BucketFsFileUnreferenced is a file neither referenced by a script nor required by an available extension.
+	BucketFsFileUnreferenced BucketFsFileUsageType = "unreferenced"
+)
+
+// BucketFsFileUsage describes how a file in BucketFS is used.
+type BucketFsFileUsage struct {
+	Path  string // Absolute path in BucketFS
+	Name  string // File name
+	Size  int    // File size in bytes
+	Usage BucketFsFileUsageType
+	// Scripts contains the qualified names of the scripts that reference the file, e.g. "SCHEMA.SCRIPT".
+	Scripts []string
+	// Extensions contains the IDs of the extensions that require the file.
+	Extensions []string
+}
+
+// BucketFsUsageReport contains the usage of all files in the configured BucketFS base paths.
+type BucketFsUsageReport struct {
+	Files []BucketFsFileUsage
+	// TotalSize is the size of all files in bytes.
+	TotalSize int
+	// UnreferencedSize is the size of all unreferenced files in bytes. Deleting these files frees this amount of space.
+	UnreferencedSize int
+}
+
+func (c *controllerImpl) GetBucketFsUsage(txCtx *transaction.TransactionContext, bfsFiles []bfs.BfsFile) (*BucketFsUsageReport, error) {
+	scripts, err := c.metaDataReader.ReadAllScripts(txCtx.GetTransaction())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scripts. Cause: %w", err)
+	}
+	extensions, err := c.getAllExtensions()
+	if err != nil {
+		return nil, err
+	}
+	report := &BucketFsUsageReport{Files: make([]BucketFsFileUsage, 0, len(bfsFiles)), TotalSize: 0, UnreferencedSize: 0}
+	for _, file := range bfsFiles {
+		usage := getFileUsage(file, scripts, extensions)
+		report.TotalSize += file.Size
+		if usage.Usage == BucketFsFileUnreferenced {
+			report.UnreferencedSize += file.Size
+		}
+		report.Files = append(report.Files, usage)
+	}
+	log.Debugf("Found %d files in BucketFS with %d bytes, %d bytes unreferenced", len(report.Files), report.TotalSize, report.UnreferencedSize)
+	return report, nil
+}
+
+func getFileUsage(file bfs.BfsFile, scripts *exaMetadata.ExaScriptTable, extensions []*extensionAPI.JsExtension) BucketFsFileUsage {
+	usage := BucketFsFileUsage{Path: file.Path, Name: file.Name, Size: file.Size, Usage: BucketFsFileUnreferenced,
+		Scripts: findReferencingScripts(file, scripts), Extensions: findRequiringExtensions(file, extensions)}
+	if len(usage.Scripts) > 0 {
+		usage.Usage = BucketFsFileReferencedByScript
+	} else if len(usage.Extensions) > 0 {
+		usage.Usage = BucketFsFileRequiredByExtension
+	}
+	return usage
+}
+
+// findReferencingScripts returns the qualified names of all scripts whose text contains the absolute path of the file.
+// This finds "%jar" directives as well as paths used in scripts of other languages.
+func findReferencingScripts(file bfs.BfsFile, scripts *exaMetadata.ExaScriptTable) []string {
+	var scriptNames []string
+	for _, script := range scripts.Rows {
+		if containsPath(script.Text, file.Path) {
+			scriptNames = append(scriptNames, script.Schema+"."+script.Name)
+		}
+	}
+	return scriptNames
+}
+
+// containsPath returns true if the text contains the complete path, i.e. not followed or preceded by other characters
+// of a path. This avoids that "/buckets/a.jar" matches "/buckets/a.jar.bak".
+func containsPath(text, path string) bool {
+	for offset := 0; offset < len(text); {
+		index := strings.Index(text[offset:], path)
+		if index < 0 {
+			return false
+		}
+		start := offset + index
+		end := start + len(path)
+		if (start == 0 || !isPathCharacter(text[start-1])) && (end == len(text) || !isPathCharacter(text[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+func isPathCharacter(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') || strings.IndexByte("._-/", char) >= 0
+}
+
+func findRequiringExtensions(file bfs.BfsFile, extensions []*extensionAPI.JsExtension) []string {
+	var extensionIds []string
+	for _, extension := range extensions {
+		for _, upload := range extension.BucketFsUploads {
+			if fileMatches(upload, file) {
+				extensionIds = append(extensionIds, extension.Id)
+				break
+			}
+		}
+	}
+	return extensionIds
+}
@@ -0,0 +1,96 @@
+package extensionController
+
+import (
+	"testing"
+
+	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/exasol/extension-manager/pkg/extensionController/bfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const usageTestExtension = `(function(){
+	global.installedExtension = {
+		extension: {
+			name: "Usage Extension",
+			installableVersions: [{name: "2.0.0", latest: true}],
+			bucketFsUploads: [{name: "jar", bucketFsFilename: "usage-2.0.0.jar", fileSize: 5}],
+			findInstallations: function(context, metadata) { return [] }
+		},
+		apiVersion: "0.2.0"
+	}
+})()`
+
+func (suite *ControllerUTestSuite) simulateBucketFsUsageTransactions(files []bfs.BfsFile) {
+	suite.dbMock.ExpectBegin()
+	suite.bucketFsMock.SimulateFiles(files)
+	suite.bucketFsMock.SimulateCloseSuccess()
+	suite.dbMock.ExpectRollback()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+}
+
+func (suite *ControllerUTestSuite) TestGetBucketFsUsage() {
+	suite.writeFile("usage.js", usageTestExtension)
+	suite.metaDataMock.SimulateAllSchemasScripts([]exaMetadata.ExaScriptRow{
+		{Schema: EXTENSION_SCHEMA, Name: "ADAPTER", Text: "%jar /buckets/bfsdefault/default/usage-1.0.0.jar;"},
+		{Schema: "OTHER_SCHEMA", Name: "IMPORT", Text: "%jar /buckets/bfsdefault/default/usage-1.0.0.jar;"}})
+	suite.simulateBucketFsUsageTransactions([]bfs.BfsFile{
+		{Name: "usage-1.0.0.jar", Path: "/buckets/bfsdefault/default/usage-1.0.0.jar", Size: 3},
+		{Name: "usage-2.0.0.jar", Path: "/buckets/bfsdefault/default/usage-2.0.0.jar", Size: 5},
+		{Name: "old.jar", Path: "/buckets/bfsdefault/default/old.jar", Size: 7}})
+	report, err := suite.controller.GetBucketFsUsage(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Equal(&BucketFsUsageReport{Files: []BucketFsFileUsage{
+		{Path: "/buckets/bfsdefault/default/usage-1.0.0.jar", Name: "usage-1.0.0.jar", Size: 3, Usage: BucketFsFileReferencedByScript, Scripts: []string{EXTENSION_SCHEMA + ".ADAPTER", "OTHER_SCHEMA.IMPORT"}},
+		{Path: "/buckets/bfsdefault/default/usage-2.0.0.jar", Name: "usage-2.0.0.jar", Size: 5, Usage: BucketFsFileRequiredByExtension, Extensions: []string{"usage.js"}},
+		{Path: "/buckets/bfsdefault/default/old.jar", Name: "old.jar", Size: 7, Usage: BucketFsFileUnreferenced}},
+		TotalSize: 15, UnreferencedSize: 7}, report)
+}
+
+func (suite *ControllerUTestSuite) TestGetBucketFsUsageIgnoresFileWithWrongSize() {
+	suite.writeFile("usage.js", usageTestExtension)
+	suite.metaDataMock.SimulateAllSchemasScripts([]exaMetadata.ExaScriptRow{})
+	suite.simulateBucketFsUsageTransactions([]bfs.BfsFile{{Name: "usage-2.0.0.jar", Path: "/buckets/bfsdefault/default/usage-2.0.0.jar", Size: 4}})
+	report, err := suite.controller.GetBucketFsUsage(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Equal([]BucketFsFileUsage{{Path: "/buckets/bfsdefault/default/usage-2.0.0.jar", Name: "usage-2.0.0.jar", Size: 4, Usage: BucketFsFileUnreferenced}}, report.Files)
+	suite.Equal(4, report.UnreferencedSize)
+}
+
+func (suite *ControllerUTestSuite) TestGetBucketFsUsageDoesNotMatchPathPrefix() {
+	suite.writeFile("usage.js", usageTestExtension)
+	suite.metaDataMock.SimulateAllSchemasScripts([]exaMetadata.ExaScriptRow{
+		{Schema: EXTENSION_SCHEMA, Name: "ADAPTER", Text: "%jar /buckets/bfsdefault/default/old.jar.bak;"}})
+	suite.simulateBucketFsUsageTransactions([]bfs.BfsFile{{Name: "old.jar", Path: "/buckets/bfsdefault/default/old.jar", Size: 7}})
+	report, err := suite.controller.GetBucketFsUsage(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Equal([]BucketFsFileUsage{{Path: "/buckets/bfsdefault/default/old.jar", Name: "old.jar", Size: 7, Usage: BucketFsFileUnreferenced}}, report.Files)
+}
+
+func (suite *ControllerUTestSuite) TestGetBucketFsUsageFailsReadingScripts() {
+	suite.metaDataMock.On("ReadAllScripts", mock.Anything).Return(nil, mockError)
+	suite.simulateBucketFsUsageTransactions([]bfs.BfsFile{})
+	report, err := suite.controller.GetBucketFsUsage(mockContext(), suite.db)
+	suite.Require().EqualError(err, "failed to read scripts. Cause: "+mockErrorMsg)
+	suite.Nil(report)
+}
+
+func (suite *ControllerUTestSuite) TestGetBucketFsUsageFailsStartingTransaction() {
+	suite.simulateTransactionBeginFails(mockError)
+	report, err := suite.controller.GetBucketFsUsage(mockContext(), suite.db)
+	suite.Require().EqualError(err, beginTransactionFailedErrorMsg)
+	suite.Nil(report)
+}
+
+func TestContainsPath(t *testing.T) {
+	const path = "/buckets/bfsdefault/default/a.jar"
+	assert.True(t, containsPath(path, path))
+	assert.True(t, containsPath("%jar "+path+";", path))
+	assert.True(t, containsPath("open('"+path+"')", path))
+	assert.True(t, containsPath("%jar "+path+".bak:"+path+";", path))
+	assert.False(t, containsPath("%jar "+path+".bak;", path))
+	assert.False(t, containsPath("%jar "+path+"-old;", path))
+	assert.False(t, containsPath("%jar /old"+path+";", path))
+	assert.False(t, containsPath("", path))
+}
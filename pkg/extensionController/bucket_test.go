@@ -0,0 +1,86 @@
+package extensionController
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionController/bfs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMatchesBucket(t *testing.T) {
+	file := bfs.BfsFile{Name: "file.jar", Path: "/buckets/bfsjars/jars/file.jar", Size: 3, BasePath: "/buckets/bfsjars/jars/"}
+	var tests = []struct {
+		name     string
+		bucket   string
+		expected bool
+	}{
+		{name: "no bucket", bucket: "", expected: true},
+		{name: "matching bucket", bucket: "bfsjars/jars", expected: true},
+		{name: "matching base path", bucket: "/buckets/bfsjars/jars/", expected: true},
+		{name: "other bucket", bucket: "bfsdefault/default", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			upload := extensionAPI.BucketFsUpload{BucketFsFilename: "file.jar", FileSize: 3, Bucket: test.bucket}
+			assert.Equal(t, test.expected, fileMatches(upload, file))
+		})
+	}
+}
+
+func TestFindFileInBfsReturnsFirstBasePath(t *testing.T) {
+	files := []bfs.BfsFile{
+		{Name: "file.jar", Path: "/buckets/bfsdefault/default/file.jar", Size: 3, BasePath: "/buckets/bfsdefault/default/"},
+		{Name: "file.jar", Path: "/buckets/bfsjars/jars/file.jar", Size: 3, BasePath: "/buckets/bfsjars/jars/"}}
+	file := findFileInBfs(files, extensionAPI.BucketFsUpload{BucketFsFilename: "file.jar", FileSize: 3})
+	assert.Equal(t, &files[0], file)
+	file = findFileInBfs(files, extensionAPI.BucketFsUpload{BucketFsFilename: "file.jar", FileSize: 3, Bucket: "bfsjars/jars"})
+	assert.Equal(t, &files[1], file)
+	assert.Nil(t, findFileInBfs(files, extensionAPI.BucketFsUpload{BucketFsFilename: "file.jar", FileSize: 3, Bucket: "bfsother/other"}))
+}
+
+func TestConfigBucketFsBasePaths(t *testing.T) {
+	config := ExtensionManagerConfig{BucketFSBasePath: "/buckets/bfsdefault/default/", AdditionalBucketFSBasePaths: []string{"/buckets/bfsjars/jars/"}}
+	assert.Equal(t, []string{"/buckets/bfsdefault/default/", "/buckets/bfsjars/jars/"}, config.bucketFsBasePaths())
+}
+
+func bucketTestExtension(bucket string) string {
+	return strings.Replace(`(function(){
+		global.installedExtension = {
+			extension: {
+				name: "Bucket Extension",
+				installableVersions: [{name: "1.0.0", latest: true}],
+				bucketFsUploads: [{name: "jar", bucketFsFilename: "file.jar", fileSize: 3, bucket: "$BUCKET$"}]
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`, "$BUCKET$", bucket, 1)
+}
+
+func (suite *ControllerUTestSuite) TestGetAllExtensionsReportsMatchingBucket() {
+	suite.writeFile("bucket.js", bucketTestExtension("bfsjars/jars"))
+	suite.dbMock.ExpectBegin()
+	suite.bucketFsMock.SimulateFiles([]bfs.BfsFile{
+		{Name: "file.jar", Path: "/buckets/bfsdefault/default/file.jar", Size: 3, BasePath: "/buckets/bfsdefault/default/"},
+		{Name: "file.jar", Path: "/buckets/bfsjars/jars/file.jar", Size: 3, BasePath: "/buckets/bfsjars/jars/"}})
+	suite.bucketFsMock.SimulateCloseSuccess()
+	suite.dbMock.ExpectRollback()
+	extensions, err := suite.controller.GetAllExtensions(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Require().Len(extensions, 1)
+	suite.Equal([]extensionAPI.BucketFsUpload{{Name: "jar", BucketFsFilename: "file.jar", FileSize: 3, Bucket: "bfsjars/jars", BucketFsBasePath: "/buckets/bfsjars/jars/"}},
+		extensions[0].BucketFsUploads)
+}
+
+func (suite *ControllerUTestSuite) TestGetAllExtensionsIgnoresFileInOtherBucket() {
+	suite.writeFile("bucket.js", bucketTestExtension("bfsjars/jars"))
+	suite.dbMock.ExpectBegin()
+	suite.bucketFsMock.SimulateFiles([]bfs.BfsFile{
+		{Name: "file.jar", Path: "/buckets/bfsdefault/default/file.jar", Size: 3, BasePath: "/buckets/bfsdefault/default/"}})
+	suite.bucketFsMock.SimulateCloseSuccess()
+	suite.dbMock.ExpectRollback()
+	extensions, err := suite.controller.GetAllExtensions(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Empty(extensions)
+}
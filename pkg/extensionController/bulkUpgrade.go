@@ -0,0 +1,187 @@
+package extensionController
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+)
+
+// BulkUpgradeOptions control how [TransactionController.UpgradeExtensions] upgrades multiple extensions.
+type BulkUpgradeOptions struct {
+	// SingleTransaction upgrades all extensions in one transaction. If one upgrade fails, all upgrades are rolled back.
+	// If false, each extension is upgraded in its own transaction and a failure does not affect other extensions.
+	SingleTransaction bool
+}
+
+// BulkUpgradeResult is the outcome of upgrading a single extension during a bulk upgrade.
+type BulkUpgradeResult struct {
+	ExtensionId     string
+	PreviousVersion string
+	NewVersion      string
+	// AlreadyUpToDate is true if the extension reported that the latest version is already installed.
+	AlreadyUpToDate bool
+	// Error is nil if the upgrade was committed successfully.
+	Error error
+}
+
+func (c *transactionControllerImpl) UpgradeExtensions(ctx context.Context, db *sql.DB, extensionIds []string, options BulkUpgradeOptions) ([]*BulkUpgradeResult, error) {
+	if len(extensionIds) == 0 {
+		var err error
+		extensionIds, err = c.findInstalledExtensionIds(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+	}
+	log.Infof("Upgrading %d extensions (single transaction: %t)", len(extensionIds), options.SingleTransaction)
+	if options.SingleTransaction {
+		return c.upgradeInSingleTransaction(ctx, db, extensionIds)
+	}
+	results := make([]*BulkUpgradeResult, 0, len(extensionIds))
+	for _, extensionId := range extensionIds {
+		upgradeResult, err := c.UpgradeExtension(ctx, db, extensionId)
+		result := &BulkUpgradeResult{ExtensionId: extensionId, PreviousVersion: "", NewVersion: "", AlreadyUpToDate: false, Error: nil}
+		if upgradeResult != nil {
+			result.PreviousVersion = upgradeResult.PreviousVersion
+			result.NewVersion = upgradeResult.NewVersion
+		}
+		if isAlreadyUpToDateError(err) {
+			result.AlreadyUpToDate = true
+		} else {
+			result.Error = err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// findInstalledExtensionIds returns the IDs of all installed extensions without duplicates.
+func (c *transactionControllerImpl) findInstalledExtensionIds(ctx context.Context, db *sql.DB) ([]string, error) {
+	installations, err := c.GetInstalledExtensions(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find installed extensions: %w", err)
+	}
+	extensionIds := make([]string, 0, len(installations))
+	for _, installation := range installations {
+		if !slices.Contains(extensionIds, installation.ID) {
+			extensionIds = append(extensionIds, installation.ID)
+		}
+	}
+	return extensionIds, nil
+}
+
+func (c *transactionControllerImpl) upgradeInSingleTransaction(ctx context.Context, db *sql.DB, extensionIds []string) ([]*BulkUpgradeResult, error) {
+	release, err := c.lock(ctx, db, extensionIds...)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	txCtx, err := c.beginTransaction(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer txCtx.Rollback()
+	results, entries, err := c.upgradeAll(txCtx, extensionIds)
+	if err == nil {
+		err = c.writeAuditEntries(txCtx, entries)
+	}
+	if err == nil {
+		err = txCtx.Commit()
+	}
+	if err != nil {
+		c.markRolledBack(ctx, db, results, entries, err)
+	}
+	return results, nil
+}
+
+// upgradeAll upgrades the given extensions in order and stops at the first failure.
+// Results for extensions that were not upgraded because of the failure have an error.
+func (c *transactionControllerImpl) upgradeAll(txCtx *transaction.TransactionContext, extensionIds []string) ([]*BulkUpgradeResult, []*AuditEntry, error) {
+	results := make([]*BulkUpgradeResult, 0, len(extensionIds))
+	entries := make([]*AuditEntry, 0, len(extensionIds))
+	var failure error
+	for _, extensionId := range extensionIds {
+		result := &BulkUpgradeResult{ExtensionId: extensionId, PreviousVersion: "", NewVersion: "", AlreadyUpToDate: false, Error: nil}
+		results = append(results, result)
+		if failure != nil {
+			result.Error = apiErrors.NewAPIErrorWithCause("skipped", failure)
+			continue
+		}
+		entry := newAuditEntry(AuditOperationUpgrade, extensionId, "")
+		entry.Timestamp = time.Now().UTC()
+		upgradeResult, err := c.controller.UpgradeExtension(txCtx, extensionId, "")
+		if isAlreadyUpToDateError(err) {
+			result.AlreadyUpToDate = true
+			continue
+		}
+		entries = append(entries, entry)
+		if err != nil {
+			entry.ExtensionVersion = c.resolveUpgradeVersion(extensionId, "")
+			result.Error = err
+			failure = apiErrors.NewAPIErrorWithCause(fmt.Sprintf("upgrade of extension %q failed", extensionId), err)
+			continue
+		}
+		if upgradeResult != nil {
+			result.PreviousVersion = upgradeResult.PreviousVersion
+			result.NewVersion = upgradeResult.NewVersion
+			entry.ExtensionVersion = upgradeResult.NewVersion
+		}
+	}
+	return results, entries, failure
+}
+
+func (c *transactionControllerImpl) writeAuditEntries(txCtx *transaction.TransactionContext, entries []*AuditEntry) error {
+	for _, entry := range entries {
+		if err := c.auditLog.write(txCtx.GetContext(), txCtx.GetTransaction(), c.extensionSchema(txCtx.GetContext()), *entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markRolledBack adds the given failure to all results that do not have an error yet
+// and records the failed upgrades in the audit log.
+func (c *transactionControllerImpl) markRolledBack(ctx context.Context, db *sql.DB, results []*BulkUpgradeResult, entries []*AuditEntry, failure error) {
+	for _, result := range results {
+		if result.Error == nil && !result.AlreadyUpToDate {
+			result.Error = apiErrors.NewAPIErrorWithCause("rolled back", failure)
+		}
+	}
+	for _, entry := range entries {
+		entry.Outcome = AuditOutcomeFailure
+		entry.ErrorMessage = findResultError(results, entry.ExtensionId)
+		if err := c.auditLog.writeInNewTransaction(ctx, db, c.extensionSchema(ctx), *entry); err != nil {
+			log.Warnf("Failed to record failed %s operation for extension %q in audit log: %v", entry.Operation, entry.ExtensionId, err)
+		}
+	}
+}
+
+func findResultError(results []*BulkUpgradeResult, extensionId string) string {
+	for _, result := range results {
+		if result.ExtensionId == extensionId && result.Error != nil {
+			return result.Error.Error()
+		}
+	}
+	return ""
+}
+
+// isUpgradeNotRequired returns true if the operation is an upgrade that did not change anything
+// because the extension is already up to date.
+func isUpgradeNotRequired(entry *AuditEntry, err error) bool {
+	return entry.Operation == AuditOperationUpgrade && isAlreadyUpToDateError(err)
+}
+
+// isAlreadyUpToDateError returns true if the extension reported that the latest version is already installed
+// by throwing an error with status 412 (precondition failed).
+func isAlreadyUpToDateError(err error) bool {
+	var apiErr *apiErrors.APIError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusPreconditionFailed
+}
@@ -0,0 +1,127 @@
+package extensionController
+
+import (
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/stretchr/testify/mock"
+)
+
+var alreadyUpToDateError = apiErrors.NewAPIError(412, "latest version already installed")
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeExtensionsUpgradesAllInstalledExtensions() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("GetAllInstallations", mock.Anything).Return([]*extensionAPI.JsExtInstallation{
+		{ID: "ext1", Name: "ext1", Version: "1.0.0"}, {ID: "ext2", Name: "ext2", Version: "2.0.0"}, {ID: "ext1", Name: "ext1 copy", Version: "1.0.0"}}, nil)
+	suite.dbMock.ExpectRollback()
+	suite.expectUpgradeInOwnTransaction("ext1", &extensionAPI.JsUpgradeResult{PreviousVersion: "1.0.0", NewVersion: "1.1.0"}, nil)
+	suite.expectUpgradeInOwnTransaction("ext2", nil, alreadyUpToDateError)
+	results, err := suite.ctrl.UpgradeExtensions(mockContext(), suite.db, nil, BulkUpgradeOptions{SingleTransaction: false})
+	suite.Require().NoError(err)
+	suite.Equal([]*BulkUpgradeResult{
+		{ExtensionId: "ext1", PreviousVersion: "1.0.0", NewVersion: "1.1.0", AlreadyUpToDate: false, Error: nil},
+		{ExtensionId: "ext2", PreviousVersion: "", NewVersion: "", AlreadyUpToDate: true, Error: nil},
+	}, results)
+	suite.Require().Len(suite.auditLogMock.written, 1)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationUpgrade, ExtensionId: "ext1", ExtensionVersion: "1.1.0", Outcome: AuditOutcomeSuccess}, suite.auditLogMock.written[0])
+	suite.Empty(suite.auditLogMock.writtenSeparately, "already up to date extension not recorded as failure")
+}
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeExtensionsFailsFindingInstallations() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("GetAllInstallations", mock.Anything).Return(nil, mockError)
+	suite.dbMock.ExpectRollback()
+	results, err := suite.ctrl.UpgradeExtensions(mockContext(), suite.db, nil, BulkUpgradeOptions{SingleTransaction: false})
+	suite.Require().EqualError(err, "failed to find installed extensions: "+mockErrorMsg)
+	suite.Nil(results)
+}
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeExtensionsInOwnTransactionsContinuesAfterFailure() {
+	suite.expectUpgradeInOwnTransaction("ext1", nil, mockError)
+	suite.expectUpgradeInOwnTransaction("ext2", &extensionAPI.JsUpgradeResult{PreviousVersion: "1.0.0", NewVersion: "1.1.0"}, nil)
+	results, err := suite.ctrl.UpgradeExtensions(mockContext(), suite.db, []string{"ext1", "ext2"}, BulkUpgradeOptions{SingleTransaction: false})
+	suite.Require().NoError(err)
+	suite.Equal([]*BulkUpgradeResult{
+		{ExtensionId: "ext1", PreviousVersion: "", NewVersion: "", AlreadyUpToDate: false, Error: mockError},
+		{ExtensionId: "ext2", PreviousVersion: "1.0.0", NewVersion: "1.1.0", AlreadyUpToDate: false, Error: nil},
+	}, results)
+	suite.Len(suite.auditLogMock.written, 1)
+	suite.Len(suite.auditLogMock.writtenSeparately, 1)
+}
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeExtensionsInSingleTransaction() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "ext1", "").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "1.0.0", NewVersion: "1.1.0"}, nil)
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "ext2", "").Return(nil, alreadyUpToDateError)
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "ext3", "").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "2.0.0", NewVersion: "3.0.0"}, nil)
+	suite.dbMock.ExpectCommit()
+	results, err := suite.ctrl.UpgradeExtensions(mockContext(), suite.db, []string{"ext1", "ext2", "ext3"}, BulkUpgradeOptions{SingleTransaction: true})
+	suite.Require().NoError(err)
+	suite.Equal([]*BulkUpgradeResult{
+		{ExtensionId: "ext1", PreviousVersion: "1.0.0", NewVersion: "1.1.0", AlreadyUpToDate: false, Error: nil},
+		{ExtensionId: "ext2", PreviousVersion: "", NewVersion: "", AlreadyUpToDate: true, Error: nil},
+		{ExtensionId: "ext3", PreviousVersion: "2.0.0", NewVersion: "3.0.0", AlreadyUpToDate: false, Error: nil},
+	}, results)
+	suite.Require().Len(suite.auditLogMock.written, 2)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationUpgrade, ExtensionId: "ext1", ExtensionVersion: "1.1.0", Outcome: AuditOutcomeSuccess}, suite.auditLogMock.written[0])
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationUpgrade, ExtensionId: "ext3", ExtensionVersion: "3.0.0", Outcome: AuditOutcomeSuccess}, suite.auditLogMock.written[1])
+	suite.Empty(suite.auditLogMock.writtenSeparately)
+}
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeExtensionsInSingleTransactionWithoutResult() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "ext1", "").Return(nil, nil)
+	suite.dbMock.ExpectCommit()
+	results, err := suite.ctrl.UpgradeExtensions(mockContext(), suite.db, []string{"ext1"}, BulkUpgradeOptions{SingleTransaction: true})
+	suite.Require().NoError(err)
+	suite.Equal([]*BulkUpgradeResult{{ExtensionId: "ext1", PreviousVersion: "", NewVersion: "", AlreadyUpToDate: false, Error: nil}}, results)
+	suite.Require().Len(suite.auditLogMock.written, 1)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationUpgrade, ExtensionId: "ext1", ExtensionVersion: "", Outcome: AuditOutcomeSuccess}, suite.auditLogMock.written[0])
+}
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeExtensionsInSingleTransactionRollsBackAllAfterFailure() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "ext1", "").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "1.0.0", NewVersion: "1.1.0"}, nil)
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "ext2", "").Return(nil, mockError)
+	suite.mockCtrl.On("GetLatestVersion", "ext2").Return("2.1.0", nil)
+	suite.dbMock.ExpectRollback()
+	results, err := suite.ctrl.UpgradeExtensions(mockContext(), suite.db, []string{"ext1", "ext2", "ext3"}, BulkUpgradeOptions{SingleTransaction: true})
+	suite.Require().NoError(err)
+	suite.Require().Len(results, 3)
+	suite.EqualError(results[0].Error, `rolled back: upgrade of extension "ext2" failed: mock error`)
+	suite.Equal(mockError, results[1].Error)
+	suite.EqualError(results[2].Error, `skipped: upgrade of extension "ext2" failed: mock error`)
+	suite.Empty(suite.auditLogMock.written)
+	suite.Require().Len(suite.auditLogMock.writtenSeparately, 2)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationUpgrade, ExtensionId: "ext1", ExtensionVersion: "1.1.0", Outcome: AuditOutcomeFailure,
+		ErrorMessage: `rolled back: upgrade of extension "ext2" failed: mock error`}, suite.auditLogMock.writtenSeparately[0])
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationUpgrade, ExtensionId: "ext2", ExtensionVersion: "2.1.0", Outcome: AuditOutcomeFailure,
+		ErrorMessage: mockErrorMsg}, suite.auditLogMock.writtenSeparately[1])
+}
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeExtensionsInSingleTransactionCommitFails() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "ext1", "").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "1.0.0", NewVersion: "1.1.0"}, nil)
+	suite.dbMock.ExpectCommit().WillReturnError(mockError)
+	results, err := suite.ctrl.UpgradeExtensions(mockContext(), suite.db, []string{"ext1"}, BulkUpgradeOptions{SingleTransaction: true})
+	suite.Require().NoError(err)
+	suite.Require().Len(results, 1)
+	suite.EqualError(results[0].Error, "rolled back: "+mockErrorMsg)
+}
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeExtensionsInSingleTransactionFailsStartingTransaction() {
+	suite.dbMock.ExpectBegin().WillReturnError(mockError)
+	results, err := suite.ctrl.UpgradeExtensions(mockContext(), suite.db, []string{"ext1"}, BulkUpgradeOptions{SingleTransaction: true})
+	suite.Require().EqualError(err, beginMockTransactionFailedErrorMsg)
+	suite.Nil(results)
+}
+
+func (suite *extCtrlUnitTestSuite) expectUpgradeInOwnTransaction(extensionId string, result *extensionAPI.JsUpgradeResult, err error) {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, extensionId, "").Return(result, err).Once()
+	if err == nil {
+		suite.dbMock.ExpectCommit()
+	} else {
+		suite.mockCtrl.On("GetLatestVersion", extensionId).Return("latest", nil).Once()
+		suite.dbMock.ExpectRollback()
+	}
+}
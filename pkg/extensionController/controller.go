@@ -2,6 +2,8 @@ package extensionController
 
 import (
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -26,25 +28,64 @@ type controller interface {
 	// GetAllInstallations searches for installations of any extensions.
 	GetAllInstallations(txCtx *transaction.TransactionContext) ([]*extensionAPI.JsExtInstallation, error)
 
+	// GetAvailableUpdates compares all installed extensions with the versions available in the registry.
+	GetAvailableUpdates(txCtx *transaction.TransactionContext, bfsFiles []bfs.BfsFile) ([]*ExtensionUpdate, error)
+
+	// DetectDrift compares the scripts and virtual schemas in the database with the installations and instances reported by all extensions.
+	DetectDrift(txCtx *transaction.TransactionContext, bfsFiles []bfs.BfsFile) (*DriftReport, error)
+
+	// GetBucketFsUsage classifies the files in BucketFS by their usage in scripts and extensions.
+	GetBucketFsUsage(txCtx *transaction.TransactionContext, bfsFiles []bfs.BfsFile) (*BucketFsUsageReport, error)
+
+	// CheckReadiness checks that the registry index can be loaded and that the parameter validator can be initialized.
+	CheckReadiness() []ReadinessCheck
+
+	// GetBucketFsUploads returns the files that an extension requires in BucketFS.
+	GetBucketFsUploads(extensionId string) ([]extensionAPI.BucketFsUpload, error)
+
+	// RecordLicenseAcceptance records that the user accepted the licenses of the given files that require a license agreement.
+	RecordLicenseAcceptance(txCtx *transaction.TransactionContext, extensionId string, uploads []extensionAPI.BucketFsUpload) error
+
+	// GetLatestVersion returns the latest installable version of an extension.
+	GetLatestVersion(extensionId string) (string, error)
+
+	// IsRetryOnTransactionConflictAllowed returns true if the extension declares that its operations can be retried
+	// in a new transaction after a transaction conflict. If includeDependencies is true, all extensions it depends on
+	// directly or indirectly must declare this, too, because the operation may install them in the same transaction.
+	IsRetryOnTransactionConflictAllowed(extensionId string, includeDependencies bool) (bool, error)
+
+	// GetDependencyIds returns the IDs of all extensions the given extension depends on directly or indirectly.
+	GetDependencyIds(extensionId string) ([]string, error)
+
 	// GetParameterDefinitions returns the parameter definitions required for installing a given extension version.
 	GetParameterDefinitions(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) ([]parameterValidator.ParameterDefinition, error)
 
 	// InstallExtension installs an extension.
 	// extensionId is the ID of the extension to install
 	// extensionVersion is the version of the extension to install
-	InstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) error
+	// options control how dependencies and deprecated versions are handled
+	InstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, options InstallOptions) error
 
-	// UninstallExtension removes an extension.
+	// UninstallExtension removes an extension and returns the instances deleted before uninstalling.
 	// extensionId is the ID of the extension to uninstall
 	// extensionVersion is the version of the extension to uninstall
-	UninstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) error
+	// options control if existing instances are deleted
+	UninstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, options UninstallOptions) ([]*extensionAPI.JsExtInstance, error)
 
-	// UpgradeExtension upgrades an installed extension to the latest version.
-	// extensionId is the ID of the extension to uninstall
-	UpgradeExtension(txCtx *transaction.TransactionContext, extensionId string) (*extensionAPI.JsUpgradeResult, error)
+	// UpgradeExtension upgrades an installed extension to the latest version or to the given target version.
+	// extensionId is the ID of the extension to upgrade
+	// targetVersion is the version to upgrade (or downgrade) to, empty for the latest version
+	UpgradeExtension(txCtx *transaction.TransactionContext, extensionId string, targetVersion string) (*extensionAPI.JsUpgradeResult, error)
+
+	// RepairExtension re-creates the database objects of an installed extension using its repair function or,
+	// if the extension does not provide one, its install function.
+	// extensionId is the ID of the extension to repair
+	// extensionVersion is the installed version of the extension
+	RepairExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) error
 
 	// CreateInstance creates a new instance of an extension, e.g. a virtual schema and returns it's name.
-	CreateInstance(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, parameterValues []ParameterValue) (*extensionAPI.JsExtInstance, error)
+	// options control how deprecated versions are handled
+	CreateInstance(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, parameterValues []ParameterValue, options CreateInstanceOptions) (*extensionAPI.JsExtInstance, error)
 
 	// FindInstances returns a list of all instances for the given version.
 	FindInstances(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) ([]*extensionAPI.JsExtInstance, error)
@@ -57,6 +98,7 @@ type controllerImpl struct {
 	registry       registry.Registry
 	config         ExtensionManagerConfig
 	metaDataReader exaMetadata.ExaMetadataReader
+	licenseTables  createdTables // Schemas containing the license acceptance table
 }
 
 func createImpl(config ExtensionManagerConfig) controller {
@@ -64,6 +106,7 @@ func createImpl(config ExtensionManagerConfig) controller {
 		registry:       registry.NewRegistry(config.ExtensionRegistryURL),
 		metaDataReader: exaMetadata.CreateExaMetaDataReader(),
 		config:         config,
+		licenseTables:  createdTables{},
 	}
 }
 
@@ -76,7 +119,9 @@ func (c *controllerImpl) GetAllExtensions(bfsFiles []bfs.BfsFile) ([]*Extension,
 	var extensions []*Extension
 	for _, jsExtension := range jsExtensions {
 		if c.requiredFilesAvailable(jsExtension, bfsFiles) {
-			extensions = append(extensions, convertExtension(jsExtension))
+			extension := convertExtension(jsExtension)
+			extension.BucketFsUploads = addBucketFsBasePaths(extension.BucketFsUploads, bfsFiles)
+			extensions = append(extensions, extension)
 		}
 	}
 	log.Infof("Found %d of %d extensions with required files (%d files available in total)", len(extensions), len(jsExtensions), len(bfsFiles))
@@ -89,7 +134,24 @@ func convertExtension(jsExtension *extensionAPI.JsExtension) *Extension {
 		Name:                jsExtension.Name,
 		Category:            jsExtension.Category,
 		Description:         jsExtension.Description,
-		InstallableVersions: jsExtension.InstallableVersions}
+		InstallableVersions: jsExtension.InstallableVersions,
+		Dependencies:        jsExtension.Dependencies,
+		BucketFsUploads:     jsExtension.BucketFsUploads}
+}
+
+// addBucketFsBasePaths returns a copy of the uploads with the base paths where the files were found.
+func addBucketFsBasePaths(uploads []extensionAPI.BucketFsUpload, bfsFiles []bfs.BfsFile) []extensionAPI.BucketFsUpload {
+	if len(uploads) == 0 {
+		return uploads
+	}
+	result := make([]extensionAPI.BucketFsUpload, 0, len(uploads))
+	for _, upload := range uploads {
+		if file := findFileInBfs(bfsFiles, upload); file != nil {
+			upload.BucketFsBasePath = file.BasePath
+		}
+		result = append(result, upload)
+	}
+	return result
 }
 
 func (c *controllerImpl) requiredFilesAvailable(extension *extensionAPI.JsExtension, bfsFiles []bfs.BfsFile) bool {
@@ -103,19 +165,29 @@ func (c *controllerImpl) requiredFilesAvailable(extension *extensionAPI.JsExtens
 }
 
 func existsFileInBfs(bfsFiles []bfs.BfsFile, requiredFile extensionAPI.BucketFsUpload) bool {
-	for _, existingFile := range bfsFiles {
-		if fileMatches(requiredFile, existingFile) {
-			return true
+	return findFileInBfs(bfsFiles, requiredFile) != nil
+}
+
+// findFileInBfs returns the first file matching the required file. BucketFS files are ordered by base path,
+// so this returns the file from the first base path that contains it.
+func findFileInBfs(bfsFiles []bfs.BfsFile, requiredFile extensionAPI.BucketFsUpload) *bfs.BfsFile {
+	for i := range bfsFiles {
+		if fileMatches(requiredFile, bfsFiles[i]) {
+			return &bfsFiles[i]
 		}
 	}
 	log.Tracef("Required file %q of size %db not found", requiredFile.Name, requiredFile.FileSize)
-	return false
+	return nil
 }
 
 func fileMatches(requiredFile extensionAPI.BucketFsUpload, existingFile bfs.BfsFile) bool {
 	if requiredFile.BucketFsFilename != existingFile.Name {
 		return false
 	}
+	if !existingFile.IsInBucket(requiredFile.Bucket) {
+		log.Tracef("Ignoring file %q in base path %q, expected bucket %q", existingFile.Path, existingFile.BasePath, requiredFile.Bucket)
+		return false
+	}
 	if requiredFile.FileSize < 0 {
 		log.Tracef("Found required file %q of size %db ignoring file size", existingFile.Name, existingFile.Size)
 		return true
@@ -134,13 +206,16 @@ func (c *controllerImpl) getAllExtensions() ([]*extensionAPI.JsExtension, error)
 	if err != nil {
 		return nil, err
 	}
-	extensions := make([]*extensionAPI.JsExtension, 0, len(extensionIds))
-	for _, id := range extensionIds {
-		extension, err := c.loadExtensionById(id)
+	extensions := make([]*extensionAPI.JsExtension, len(extensionIds))
+	errs := runParallel(len(extensionIds), func(index int) error {
+		var err error
+		extensions[index], err = c.loadExtensionById(extensionIds[index])
+		return err
+	})
+	for index, err := range errs {
 		if err != nil {
-			return nil, fmt.Errorf("failed to load extension %q: %w", id, err)
+			return nil, fmt.Errorf("failed to load extension %q: %w", extensionIds[index], err)
 		}
-		extensions = append(extensions, extension)
 	}
 	log.Debugf("Loaded %d extensions JS files in %dms", len(extensions), time.Since(t0).Milliseconds())
 	return extensions, nil
@@ -158,8 +233,21 @@ func (c *controllerImpl) loadExtensionById(id string) (*extensionAPI.JsExtension
 	return extension, nil
 }
 
+// loadExtension loads an extension for use in the given transaction.
+// In a dry-run the extension's log output is forwarded to the transaction's [transaction.DryRunRecorder].
+func (c *controllerImpl) loadExtension(txCtx *transaction.TransactionContext, id string) (*extensionAPI.JsExtension, error) {
+	extension, err := c.loadExtensionById(id)
+	if err != nil {
+		return nil, err
+	}
+	if recorder := txCtx.GetDryRunRecorder(); recorder != nil {
+		extension.SetLogListener(recorder.RecordLogMessage)
+	}
+	return extension, nil
+}
+
 func (c *controllerImpl) GetAllInstallations(txCtx *transaction.TransactionContext) ([]*extensionAPI.JsExtInstallation, error) {
-	metadata, err := c.metaDataReader.ReadMetadataTables(txCtx.GetTransaction(), c.config.ExtensionSchema)
+	metadata, err := c.metaDataReader.ReadMetadataTables(txCtx.GetTransaction(), c.extensionSchema(txCtx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read metadata tables. Cause: %w", err)
 	}
@@ -167,14 +255,31 @@ func (c *controllerImpl) GetAllInstallations(txCtx *transaction.TransactionConte
 	if err != nil {
 		return nil, err
 	}
-	extensionContext := c.createExtensionContext(txCtx)
+	return c.findInstallations(txCtx, metadata, extensions)
+}
+
+// findInstallations calls findInstallations of all extensions concurrently and returns the installations in the order of the extensions.
+// The extensions share the metadata read-only and access the database one after another.
+func (c *controllerImpl) findInstallations(txCtx *transaction.TransactionContext, metadata *exaMetadata.ExaMetadata, extensions []*extensionAPI.JsExtension) ([]*extensionAPI.JsExtInstallation, error) {
+	extensionContexts := make([]*context.ExtensionContext, len(extensions))
+	for index, extension := range extensions {
+		extensionContexts[index] = c.createExtensionContext(txCtx, extension)
+	}
+	extensionContexts = context.NewSynchronizedContexts(extensionContexts)
+	installationsPerExtension := make([][]*extensionAPI.JsExtInstallation, len(extensions))
+	errs := runParallel(len(extensions), func(index int) error {
+		var err error
+		installationsPerExtension[index], err = extensions[index].FindInstallations(extensionContexts[index], metadata)
+		return err
+	})
 	var allInstallations []*extensionAPI.JsExtInstallation
-	for _, extension := range extensions {
-		installations, err := extension.FindInstallations(extensionContext, metadata)
-		if err != nil {
-			return nil, apiErrors.NewAPIErrorWithCause(fmt.Sprintf("failed to find installations for extension %q", extension.Name), err)
+	for index, extension := range extensions {
+		if errs[index] != nil {
+			return nil, apiErrors.NewAPIErrorWithCause(fmt.Sprintf("failed to find installations for extension %q", extension.Name), errs[index])
 		}
+		installations := installationsPerExtension[index]
 		addExtensionId(extension.Id, installations)
+		addDependencies(extension.Dependencies, installations)
 		c.logInstallations(extension, installations)
 		allInstallations = append(allInstallations, installations...)
 	}
@@ -187,6 +292,12 @@ func addExtensionId(extensionID string, installations []*extensionAPI.JsExtInsta
 	}
 }
 
+func addDependencies(dependencies []extensionAPI.ExtensionDependency, installations []*extensionAPI.JsExtInstallation) {
+	for _, i := range installations {
+		i.Dependencies = dependencies
+	}
+}
+
 func (*controllerImpl) logInstallations(extension *extensionAPI.JsExtension, installations []*extensionAPI.JsExtInstallation) {
 	if len(installations) == 0 {
 		log.Debugf("Found no installations for extension %q", extension.Id)
@@ -200,12 +311,81 @@ func (*controllerImpl) logInstallations(extension *extensionAPI.JsExtension, ins
 	}
 }
 
-func (c *controllerImpl) GetParameterDefinitions(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) ([]parameterValidator.ParameterDefinition, error) {
+func (c *controllerImpl) GetBucketFsUploads(extensionId string) ([]extensionAPI.BucketFsUpload, error) {
+	extension, err := c.loadExtensionById(extensionId)
+	if err != nil {
+		return nil, extensionLoadingFailed(extensionId, err)
+	}
+	return extension.BucketFsUploads, nil
+}
+
+func (c *controllerImpl) GetLatestVersion(extensionId string) (string, error) {
 	extension, err := c.loadExtensionById(extensionId)
+	if err != nil {
+		return "", extensionLoadingFailed(extensionId, err)
+	}
+	return findLatestVersion(extension.InstallableVersions), nil
+}
+
+func (c *controllerImpl) IsRetryOnTransactionConflictAllowed(extensionId string, includeDependencies bool) (bool, error) {
+	return c.isRetryOnTransactionConflictAllowed(extensionId, includeDependencies, nil)
+}
+
+// isRetryOnTransactionConflictAllowed checks the given extension and optionally its dependencies.
+// dependencyChain contains the IDs of the extensions already being checked and prevents endless recursion for cyclic dependencies.
+func (c *controllerImpl) isRetryOnTransactionConflictAllowed(extensionId string, includeDependencies bool, dependencyChain []string) (bool, error) {
+	extension, err := c.loadExtensionById(extensionId)
+	if err != nil {
+		return false, extensionLoadingFailed(extensionId, err)
+	}
+	if !extension.RetryOnTransactionConflict || !includeDependencies {
+		return extension.RetryOnTransactionConflict, nil
+	}
+	dependencyChain = append(dependencyChain, extensionId)
+	for _, dependency := range extension.Dependencies {
+		if slices.Contains(dependencyChain, dependency.ExtensionId) {
+			continue
+		}
+		allowed, err := c.isRetryOnTransactionConflictAllowed(dependency.ExtensionId, true, dependencyChain)
+		if err != nil || !allowed {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (c *controllerImpl) GetDependencyIds(extensionId string) ([]string, error) {
+	collected, err := c.collectDependencyIds(extensionId, []string{extensionId})
+	if err != nil {
+		return nil, err
+	}
+	return collected[1:], nil
+}
+
+// collectDependencyIds adds the IDs of the dependencies of the given extension to collected unless they are already contained.
+func (c *controllerImpl) collectDependencyIds(extensionId string, collected []string) ([]string, error) {
+	extension, err := c.loadExtensionById(extensionId)
+	if err != nil {
+		return nil, extensionLoadingFailed(extensionId, err)
+	}
+	for _, dependency := range extension.Dependencies {
+		if slices.Contains(collected, dependency.ExtensionId) {
+			continue
+		}
+		collected, err = c.collectDependencyIds(dependency.ExtensionId, append(collected, dependency.ExtensionId))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return collected, nil
+}
+
+func (c *controllerImpl) GetParameterDefinitions(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) ([]parameterValidator.ParameterDefinition, error) {
+	extension, err := c.loadExtension(txCtx, extensionId)
 	if err != nil {
 		return nil, extensionLoadingFailed(extensionId, err)
 	}
-	rawDefinitions, err := extension.GetParameterDefinitions(c.createExtensionContext(txCtx), extensionVersion)
+	rawDefinitions, err := extension.GetParameterDefinitions(c.createExtensionContext(txCtx, extension), extensionVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -216,8 +396,8 @@ func (c *controllerImpl) GetParameterDefinitions(txCtx *transaction.TransactionC
 	return definitions, nil
 }
 
-func (c *controllerImpl) InstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) error {
-	extension, err := c.loadExtensionById(extensionId)
+func (c *controllerImpl) InstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, options InstallOptions) error {
+	extension, err := c.loadExtension(txCtx, extensionId)
 	if err != nil {
 		return extensionLoadingFailed(extensionId, err)
 	}
@@ -225,20 +405,70 @@ func (c *controllerImpl) InstallExtension(txCtx *transaction.TransactionContext,
 	if err != nil {
 		return err
 	}
-	return extension.Install(c.createExtensionContext(txCtx), extensionVersion)
+	return newDependencyInstaller(c, txCtx, options).install(extension, extensionVersion, nil)
 }
 
-func (c *controllerImpl) UninstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) error {
-	extension, err := c.loadExtensionById(extensionId)
+func (c *controllerImpl) UninstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, options UninstallOptions) ([]*extensionAPI.JsExtInstance, error) {
+	extension, err := c.loadExtension(txCtx, extensionId)
 	if err != nil {
-		return extensionLoadingFailed(extensionId, err)
+		return nil, extensionLoadingFailed(extensionId, err)
+	}
+	extensionCtx := c.createExtensionContext(txCtx, extension)
+	var deletedInstances []*extensionAPI.JsExtInstance
+	if options.Cascade {
+		deletedInstances, err = c.deleteAllInstances(extension, extensionCtx, extensionVersion)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err = c.verifyNoInstances(extension, extensionCtx, extensionVersion)
+		if err != nil {
+			return nil, fmt.Errorf("cannot uninstall extension because instances remain: %w", err)
+		}
+	}
+	err = c.verifyNoDependents(txCtx, extensionId)
+	if err != nil {
+		return nil, err
+	}
+	err = extension.Uninstall(extensionCtx, extensionVersion)
+	if err != nil {
+		return nil, err
+	}
+	return deletedInstances, nil
+}
+
+// deleteAllInstances deletes all instances of the given extension version and returns the deleted instances.
+func (*controllerImpl) deleteAllInstances(extension *extensionAPI.JsExtension, extensionCtx *context.ExtensionContext, extensionVersion string) ([]*extensionAPI.JsExtInstance, error) {
+	if !extension.SupportsListInstances(extensionCtx, extensionVersion) {
+		return nil, nil
+	}
+	instances, err := extension.ListInstances(extensionCtx, extensionVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find instances for deleting them: %w", err)
 	}
-	extensionCtx := c.createExtensionContext(txCtx)
-	err = c.verifyNoInstances(extension, extensionCtx, extensionVersion)
+	for _, instance := range instances {
+		log.Infof("Deleting instance %q of extension %q before uninstalling it", instance.Name, extension.Id)
+		err = extension.DeleteInstance(extensionCtx, extensionVersion, instance.Id)
+		if err != nil {
+			return nil, apiErrors.NewAPIErrorWithCause(fmt.Sprintf("failed to delete instance %q", instance.Name), err)
+		}
+	}
+	return instances, nil
+}
+
+func (c *controllerImpl) verifyNoDependents(txCtx *transaction.TransactionContext, extensionId string) error {
+	dependents, err := c.findInstalledDependents(txCtx, extensionId)
 	if err != nil {
-		return fmt.Errorf("cannot uninstall extension because instances remain: %w", err)
+		return fmt.Errorf("failed to check extensions depending on %q: %w", extensionId, err)
 	}
-	return extension.Uninstall(extensionCtx, extensionVersion)
+	if len(dependents) > 0 {
+		names := make([]string, 0, len(dependents))
+		for _, dependent := range dependents {
+			names = append(names, fmt.Sprintf("%s (%s)", dependent.ID, dependent.Version))
+		}
+		return apiErrors.NewBadRequestErrorF("cannot uninstall extension because %d installed extension(s) depend on it: %s", len(dependents), strings.Join(names, ", "))
+	}
+	return nil
 }
 
 func (*controllerImpl) verifyNoInstances(extension *extensionAPI.JsExtension, extensionCtx *context.ExtensionContext, extensionVersion string) error {
@@ -268,19 +498,79 @@ func concatInstanceNames(instances []*extensionAPI.JsExtInstance) string {
 }
 
 /* [impl -> dsn~upgrade-extension~1]. */
-func (c *controllerImpl) UpgradeExtension(txCtx *transaction.TransactionContext, extensionId string) (*extensionAPI.JsUpgradeResult, error) {
-	extension, err := c.loadExtensionById(extensionId)
+func (c *controllerImpl) UpgradeExtension(txCtx *transaction.TransactionContext, extensionId string, targetVersion string) (*extensionAPI.JsUpgradeResult, error) {
+	extension, err := c.loadExtension(txCtx, extensionId)
 	if err != nil {
 		return nil, extensionLoadingFailed(extensionId, err)
 	}
-	return extension.Upgrade(c.createExtensionContext(txCtx))
+	if err := verifyTargetVersion(extension, targetVersion); err != nil {
+		return nil, err
+	}
+	result, err := extension.Upgrade(c.createExtensionContext(txCtx, extension), targetVersion)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("extension %q returned no upgrade result", extensionId)
+	}
+	if err := c.verifyInstallation(txCtx, extension, result.NewVersion, "upgrade"); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-func (c *controllerImpl) CreateInstance(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, parameterValues []ParameterValue) (*extensionAPI.JsExtInstance, error) {
-	extension, err := c.loadExtensionById(extensionId)
+// verifyTargetVersion checks that the extension supports upgrading to the given target version.
+// An empty target version means upgrading to the latest version and is always allowed.
+func verifyTargetVersion(extension *extensionAPI.JsExtension, targetVersion string) error {
+	if targetVersion == "" {
+		return nil
+	}
+	if !extension.SupportsUpgradeToVersion() {
+		return apiErrors.NewBadRequestErrorF("extension %q does not support upgrading to a specific version", extension.Id)
+	}
+	for _, version := range extension.InstallableVersions {
+		if version.Name == targetVersion {
+			return nil
+		}
+	}
+	return apiErrors.NewBadRequestErrorF("version %q of extension %q is not installable", targetVersion, extension.Id)
+}
+
+// verifyInstallation checks that findInstallations of the extension reports the expected version after the given operation.
+// This detects extensions that report success without installing anything. The check is skipped if disabled in the configuration.
+func (c *controllerImpl) verifyInstallation(txCtx *transaction.TransactionContext, extension *extensionAPI.JsExtension, expectedVersion string, operation string) error {
+	if !c.config.VerifyInstallations {
+		return nil
+	}
+	metadata, err := c.metaDataReader.ReadMetadataTables(txCtx.GetTransaction(), c.extensionSchema(txCtx))
+	if err != nil {
+		return fmt.Errorf("failed to read metadata tables for verifying %s of extension %q. Cause: %w", operation, extension.Id, err)
+	}
+	installations, err := extension.FindInstallations(c.createExtensionContext(txCtx, extension), metadata)
+	if err != nil {
+		return apiErrors.NewAPIErrorWithCause(fmt.Sprintf("failed to find installations for verifying %s of extension %q", operation, extension.Id), err)
+	}
+	foundVersions := make([]string, 0, len(installations))
+	for _, installation := range installations {
+		if installation.Version == expectedVersion {
+			log.Debugf("Verified %s of extension %q in version %q", operation, extension.Id, expectedVersion)
+			return nil
+		}
+		foundVersions = append(foundVersions, installation.Version)
+	}
+	return apiErrors.NewAPIErrorF(http.StatusInternalServerError, "verification of %s failed: extension %q reported success but version %q was not found, found versions: [%s]",
+		operation, extension.Id, expectedVersion, strings.Join(foundVersions, ", "))
+}
+
+func (c *controllerImpl) CreateInstance(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, parameterValues []ParameterValue, options CreateInstanceOptions) (*extensionAPI.JsExtInstance, error) {
+	extension, err := c.loadExtension(txCtx, extensionId)
 	if err != nil {
 		return nil, extensionLoadingFailed(extensionId, err)
 	}
+	err = c.checkDeprecatedVersion(txCtx, extension, extensionVersion, "create an instance of", options.Force)
+	if err != nil {
+		return nil, err
+	}
 	err = c.ensureSchemaExists(txCtx)
 	if err != nil {
 		return nil, err
@@ -291,7 +581,7 @@ func (c *controllerImpl) CreateInstance(txCtx *transaction.TransactionContext, e
 		return nil, err
 	}
 
-	extensionContext := c.createExtensionContext(txCtx)
+	extensionContext := c.createExtensionContext(txCtx, extension)
 	instance, err := extension.AddInstance(extensionContext, extensionVersion, &params)
 	if err != nil {
 		return nil, err
@@ -324,27 +614,38 @@ func convertParameters(parameterValues []ParameterValue) extensionAPI.ParameterV
 }
 
 func (c *controllerImpl) DeleteInstance(txCtx *transaction.TransactionContext, extensionId, extensionVersion, instanceId string) error {
-	extension, err := c.loadExtensionById(extensionId)
+	extension, err := c.loadExtension(txCtx, extensionId)
 	if err != nil {
 		return extensionLoadingFailed(extensionId, err)
 	}
-	return extension.DeleteInstance(c.createExtensionContext(txCtx), extensionVersion, instanceId)
+	return extension.DeleteInstance(c.createExtensionContext(txCtx, extension), extensionVersion, instanceId)
 }
 
 func (c *controllerImpl) FindInstances(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) ([]*extensionAPI.JsExtInstance, error) {
-	extension, err := c.loadExtensionById(extensionId)
+	extension, err := c.loadExtension(txCtx, extensionId)
 	if err != nil {
 		return nil, extensionLoadingFailed(extensionId, err)
 	}
-	return extension.ListInstances(c.createExtensionContext(txCtx), extensionVersion)
+	return extension.ListInstances(c.createExtensionContext(txCtx, extension), extensionVersion)
 }
 
-func (c *controllerImpl) createExtensionContext(txCtx *transaction.TransactionContext) *context.ExtensionContext {
-	return context.CreateContext(txCtx, c.config.ExtensionSchema)
+func (c *controllerImpl) createExtensionContext(txCtx *transaction.TransactionContext, extension *extensionAPI.JsExtension) *context.ExtensionContext {
+	return context.CreateContext(txCtx, c.extensionSchema(txCtx), uploadBuckets(extension.BucketFsUploads))
+}
+
+// uploadBuckets returns the buckets of all uploads that declare a bucket by their file name.
+func uploadBuckets(uploads []extensionAPI.BucketFsUpload) map[string]string {
+	buckets := make(map[string]string)
+	for _, upload := range uploads {
+		if upload.Bucket != "" {
+			buckets[upload.BucketFsFilename] = upload.Bucket
+		}
+	}
+	return buckets
 }
 
 func (c *controllerImpl) ensureSchemaExists(txCtx *transaction.TransactionContext) error {
-	_, err := txCtx.GetTransaction().Exec(fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS "%s"`, c.config.ExtensionSchema))
+	_, err := txCtx.GetTransaction().Exec(fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS "%s"`, c.extensionSchema(txCtx)))
 	if err != nil {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
@@ -26,6 +26,37 @@ func (mock *mockControllerImpl) GetAllExtensions(bfsFiles []bfs.BfsFile) ([]*Ext
 	return nil, args.Error(1)
 }
 
+func (mock *mockControllerImpl) GetBucketFsUploads(extensionId string) ([]extensionAPI.BucketFsUpload, error) {
+	args := mock.Called(extensionId)
+	if result, ok := args.Get(0).([]extensionAPI.BucketFsUpload); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (mock *mockControllerImpl) RecordLicenseAcceptance(txCtx *transaction.TransactionContext, extensionId string, uploads []extensionAPI.BucketFsUpload) error {
+	args := mock.Called(txCtx, extensionId, uploads)
+	return args.Error(0)
+}
+
+func (mock *mockControllerImpl) GetLatestVersion(extensionId string) (string, error) {
+	args := mock.Called(extensionId)
+	return args.String(0), args.Error(1)
+}
+
+func (mock *mockControllerImpl) IsRetryOnTransactionConflictAllowed(extensionId string, includeDependencies bool) (bool, error) {
+	args := mock.Called(extensionId, includeDependencies)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mock *mockControllerImpl) GetDependencyIds(extensionId string) ([]string, error) {
+	args := mock.Called(extensionId)
+	if result, ok := args.Get(0).([]string); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (mock *mockControllerImpl) GetParameterDefinitions(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) ([]parameterValidator.ParameterDefinition, error) {
 	args := mock.Called(extensionId, extensionVersion)
 	if result, ok := args.Get(0).([]parameterValidator.ParameterDefinition); ok {
@@ -34,6 +65,43 @@ func (mock *mockControllerImpl) GetParameterDefinitions(txCtx *transaction.Trans
 	return nil, args.Error(1)
 }
 
+func (mock *mockControllerImpl) GetAvailableUpdates(txCtx *transaction.TransactionContext, bfsFiles []bfs.BfsFile) ([]*ExtensionUpdate, error) {
+	args := mock.Called(txCtx, bfsFiles)
+	if result, ok := args.Get(0).([]*ExtensionUpdate); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (mock *mockControllerImpl) DetectDrift(txCtx *transaction.TransactionContext, bfsFiles []bfs.BfsFile) (*DriftReport, error) {
+	args := mock.Called(txCtx, bfsFiles)
+	if result, ok := args.Get(0).(*DriftReport); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (mock *mockControllerImpl) GetBucketFsUsage(txCtx *transaction.TransactionContext, bfsFiles []bfs.BfsFile) (*BucketFsUsageReport, error) {
+	args := mock.Called(txCtx, bfsFiles)
+	if result, ok := args.Get(0).(*BucketFsUsageReport); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (mock *mockControllerImpl) RepairExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) error {
+	args := mock.Called(txCtx, extensionId, extensionVersion)
+	return args.Error(0)
+}
+
+func (mock *mockControllerImpl) CheckReadiness() []ReadinessCheck {
+	args := mock.Called()
+	if result, ok := args.Get(0).([]ReadinessCheck); ok {
+		return result
+	}
+	return nil
+}
+
 func (mock *mockControllerImpl) GetAllInstallations(txCtx *transaction.TransactionContext) ([]*extensionAPI.JsExtInstallation, error) {
 	args := mock.Called(txCtx)
 	if result, ok := args.Get(0).([]*extensionAPI.JsExtInstallation); ok {
@@ -42,26 +110,29 @@ func (mock *mockControllerImpl) GetAllInstallations(txCtx *transaction.Transacti
 	return nil, args.Error(1)
 }
 
-func (mock *mockControllerImpl) InstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) error {
-	args := mock.Called(txCtx, extensionId, extensionVersion)
+func (mock *mockControllerImpl) InstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, options InstallOptions) error {
+	args := mock.Called(txCtx, extensionId, extensionVersion, options)
 	return args.Error(0)
 }
 
-func (mock *mockControllerImpl) UninstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) error {
-	args := mock.Called(txCtx, extensionId, extensionVersion)
-	return args.Error(0)
+func (mock *mockControllerImpl) UninstallExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, options UninstallOptions) ([]*extensionAPI.JsExtInstance, error) {
+	args := mock.Called(txCtx, extensionId, extensionVersion, options)
+	if result, ok := args.Get(0).([]*extensionAPI.JsExtInstance); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
-func (mock *mockControllerImpl) UpgradeExtension(txCtx *transaction.TransactionContext, extensionId string) (*extensionAPI.JsUpgradeResult, error) {
-	args := mock.Called(txCtx, extensionId)
+func (mock *mockControllerImpl) UpgradeExtension(txCtx *transaction.TransactionContext, extensionId string, targetVersion string) (*extensionAPI.JsUpgradeResult, error) {
+	args := mock.Called(txCtx, extensionId, targetVersion)
 	if result, ok := args.Get(0).(*extensionAPI.JsUpgradeResult); ok {
 		return result, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (mock *mockControllerImpl) CreateInstance(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, parameterValues []ParameterValue) (*extensionAPI.JsExtInstance, error) {
-	args := mock.Called(txCtx, extensionId, extensionVersion, parameterValues)
+func (mock *mockControllerImpl) CreateInstance(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, parameterValues []ParameterValue, options CreateInstanceOptions) (*extensionAPI.JsExtInstance, error) {
+	args := mock.Called(txCtx, extensionId, extensionVersion, parameterValues, options)
 	if result, ok := args.Get(0).(*extensionAPI.JsExtInstance); ok {
 		return result, args.Error(1)
 	}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -19,6 +20,7 @@ import (
 	"github.com/exasol/extension-manager/pkg/integrationTesting"
 	"github.com/exasol/extension-manager/pkg/parameterValidator"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -75,6 +77,8 @@ func (suite *ControllerUTestSuite) createController() {
 		config:             config,
 		controller:         ctrl,
 		transactionStarter: suite.transactionStarterMock.GetTransactionStarter(),
+		auditLog:           createAuditLogMock(),
+		locks:              newOperationLock(config),
 	}
 }
 
@@ -101,7 +105,8 @@ func (suite *ControllerUTestSuite) TestGetAllExtensionsAndBucketFSContainsJar()
 	extensions, err := suite.controller.GetAllExtensions(mockContext(), suite.db)
 	suite.Require().NoError(err)
 	suite.Equal([]*Extension{{Name: "MyDemoExtension", Id: "testing-extension.js", Category: "Demo category", Description: "An extension for testing.",
-		InstallableVersions: []extensionAPI.JsExtensionVersion{{Name: "0.1.0", Latest: true, Deprecated: false}}}}, extensions)
+		InstallableVersions: []extensionAPI.JsExtensionVersion{{Name: "0.1.0", Latest: true, Deprecated: false}},
+		BucketFsUploads:     []extensionAPI.BucketFsUpload{{Name: "extension jar", DownloadURL: "", LicenseURL: "", LicenseAgreementRequired: false, FileSize: 3, BucketFsFilename: "my-extension.1.2.3.jar"}}}}, extensions)
 }
 
 func (suite *ControllerUTestSuite) TestGetAllExtensionsFailsStartingTransaction() {
@@ -157,7 +162,8 @@ func (suite *ControllerUTestSuite) TestGetAllExtensionsIgnoresFileSizeForNegativ
 	extensions, err := suite.controller.GetAllExtensions(mockContext(), suite.db)
 	suite.Require().NoError(err)
 	suite.Equal([]*Extension{{Name: "MyDemoExtension", Id: "testing-extension.js", Category: "Demo category", Description: "An extension for testing.",
-		InstallableVersions: []extensionAPI.JsExtensionVersion{{Name: "0.1.0", Latest: true, Deprecated: false}}}}, extensions)
+		InstallableVersions: []extensionAPI.JsExtensionVersion{{Name: "0.1.0", Latest: true, Deprecated: false}},
+		BucketFsUploads:     []extensionAPI.BucketFsUpload{{Name: "extension jar", DownloadURL: "", LicenseURL: "", LicenseAgreementRequired: false, FileSize: -1, BucketFsFilename: "my-extension.1.2.3.jar"}}}}, extensions)
 }
 
 func (suite *ControllerUTestSuite) TestGetAllExtensionsFailsForInvalidExtension() {
@@ -510,6 +516,75 @@ func (suite *ControllerUTestSuite) TestUninstallFailsListingInstances() {
 	suite.Require().ErrorContains(err, `failed to check existing instances: failed to list instances for extension "testing-extension.js" in version "ver": Error: mock js error`)
 }
 
+// cascadeTestExtension creates the JavaScript of an extension with the given instances that records deleting instances and uninstalling.
+func cascadeTestExtension(findInstances string) string {
+	return strings.Replace(`(function(){
+		global.installedExtension = {
+			extension: {
+				name: "cascade",
+				installableVersions: [{name: "1.0.0", latest: true}],
+				findInstances: function(context, version) { $FIND_INSTANCES$ },
+				deleteInstance: function(context, version, instanceId) { context.sqlClient.execute("delete " + instanceId) },
+				uninstall: function(context, version) { context.sqlClient.execute("uninstall " + version) }
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`, "$FIND_INSTANCES$", findInstances, 1)
+}
+
+func (suite *ControllerUTestSuite) TestUninstallWithCascadeDeletesInstances() {
+	suite.writeFile("cascade.js", cascadeTestExtension(`return [{id: "inst1", name: "instance1"}, {id: "inst2", name: "instance2"}]`))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("delete inst1").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("delete inst2").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("uninstall 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	result, err := suite.controller.UninstallExtensionWithOptions(mockContext(), suite.db, "cascade.js", "1.0.0", UninstallOptions{Cascade: true})
+	suite.Require().NoError(err)
+	suite.Equal(&UninstallResult{ExtensionId: "cascade.js", ExtensionVersion: "1.0.0",
+		DeletedInstances: []*extensionAPI.JsExtInstance{{Id: "inst1", Name: "instance1"}, {Id: "inst2", Name: "instance2"}}}, result)
+}
+
+func (suite *ControllerUTestSuite) TestUninstallWithCascadeWithoutInstances() {
+	suite.writeFile("cascade.js", cascadeTestExtension(`return []`))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("uninstall 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	result, err := suite.controller.UninstallExtensionWithOptions(mockContext(), suite.db, "cascade.js", "1.0.0", UninstallOptions{Cascade: true})
+	suite.Require().NoError(err)
+	suite.Empty(result.DeletedInstances)
+}
+
+func (suite *ControllerUTestSuite) TestUninstallWithCascadeWhenInstancesNotSupported() {
+	suite.writeFile("cascade.js", cascadeTestExtension(`const error = new Error("Finding instances not supported"); error.status = 404; throw error`))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("uninstall 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	result, err := suite.controller.UninstallExtensionWithOptions(mockContext(), suite.db, "cascade.js", "1.0.0", UninstallOptions{Cascade: true})
+	suite.Require().NoError(err)
+	suite.Empty(result.DeletedInstances)
+}
+
+func (suite *ControllerUTestSuite) TestUninstallWithCascadeRollsBackWhenDeletingInstanceFails() {
+	suite.writeFile("cascade.js", cascadeTestExtension(`return [{id: "inst1", name: "instance1"}, {id: "inst2", name: "instance2"}]`))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("delete inst1").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("delete inst2").WillReturnError(mockError)
+	suite.dbMock.ExpectRollback()
+	result, err := suite.controller.UninstallExtensionWithOptions(mockContext(), suite.db, "cascade.js", "1.0.0", UninstallOptions{Cascade: true})
+	suite.Require().ErrorContains(err, `failed to delete instance "instance2": failed to delete instance "inst2" for extension "cascade.js": error executing statement 'delete inst2': mock error`)
+	suite.Nil(result)
+}
+
+func (suite *ControllerUTestSuite) TestUninstallWithCascadeFailsListingInstances() {
+	suite.writeFile("cascade.js", cascadeTestExtension(`throw new Error("mock js error")`))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	result, err := suite.controller.UninstallExtensionWithOptions(mockContext(), suite.db, "cascade.js", "1.0.0", UninstallOptions{Cascade: true})
+	suite.Require().ErrorContains(err, `failed to find instances for deleting them: failed to list instances for extension "cascade.js" in version "1.0.0": Error: mock js error`)
+	suite.Nil(result)
+}
+
 // Upgrade
 
 func (suite *ControllerUTestSuite) TestUpgradeFailsForUnknownExtensionId() {
@@ -572,6 +647,156 @@ func (suite *ControllerUTestSuite) TestUpgradeFails() {
 	}
 }
 
+func (suite *ControllerUTestSuite) TestUpgradeFailsWithoutResult() {
+	integrationTesting.CreateTestExtensionBuilder(suite.T()).
+		WithUpgradeFunc("context.sqlClient.execute(`upgrade extension`); return undefined;").
+		Build().
+		WriteToFile(path.Join(suite.tempExtensionRepo, EXTENSION_ID))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("upgrade extension").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	result, err := suite.controller.UpgradeExtension(mockContext(), suite.db, EXTENSION_ID)
+	suite.assertNonApiError(err, `extension "testing-extension.js" returned no upgrade result`)
+	suite.Nil(result)
+}
+
+// Installation verification
+
+const verifiedExtensionId = "verified.js"
+
+// verifiedTestExtension creates the JavaScript of an extension that reports the given installations independent of install and upgrade.
+func verifiedTestExtension(installations string) string {
+	return strings.Replace(`(function(){
+		global.installedExtension = {
+			extension: {
+				name: "verified",
+				installableVersions: [{name: "1.0.0"}, {name: "2.0.0", latest: true}],
+				findInstallations: function(context, metadata) { return $INSTALLATIONS$ },
+				install: function(context, version) { context.sqlClient.execute("install " + version) },
+				upgrade: function(context) { context.sqlClient.execute("upgrade"); return { previousVersion: "1.0.0", newVersion: "2.0.0" } }
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`, "$INSTALLATIONS$", installations, 1)
+}
+
+func (suite *ControllerUTestSuite) enableInstallationVerification(installations string) {
+	suite.writeFile(verifiedExtensionId, verifiedTestExtension(installations))
+	suite.controller.controller.(*controllerImpl).config.VerifyInstallations = true
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{})
+}
+
+func (suite *ControllerUTestSuite) TestInstallVerificationSucceeds() {
+	suite.enableInstallationVerification(`[{name: "verified", version: "0.9.0"}, {name: "verified", version: "1.0.0"}]`)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	err := suite.controller.InstallExtension(mockContext(), suite.db, verifiedExtensionId, "1.0.0")
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerUTestSuite) TestInstallVerificationFailsRollsBack() {
+	suite.enableInstallationVerification(`[{name: "verified", version: "0.9.0"}]`)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.InstallExtension(mockContext(), suite.db, verifiedExtensionId, "1.0.0")
+	suite.assertApiError(err, 500, `verification of install failed: extension "verified.js" reported success but version "1.0.0" was not found, found versions: [0.9.0]`)
+}
+
+func (suite *ControllerUTestSuite) TestInstallVerificationFailsReadingMetadata() {
+	suite.writeFile(verifiedExtensionId, verifiedTestExtension(`[]`))
+	suite.controller.controller.(*controllerImpl).config.VerifyInstallations = true
+	suite.metaDataMock.On("ReadMetadataTables", mock.Anything, EXTENSION_SCHEMA).Return(nil, mockError)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.InstallExtension(mockContext(), suite.db, verifiedExtensionId, "1.0.0")
+	suite.assertNonApiError(err, `failed to read metadata tables for verifying install of extension "verified.js". Cause: mock error`)
+}
+
+func (suite *ControllerUTestSuite) TestInstallWithoutVerificationIgnoresMissingInstallation() {
+	suite.writeFile(verifiedExtensionId, verifiedTestExtension(`[]`))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	err := suite.controller.InstallExtension(mockContext(), suite.db, verifiedExtensionId, "1.0.0")
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerUTestSuite) TestUpgradeVerificationSucceeds() {
+	suite.enableInstallationVerification(`[{name: "verified", version: "2.0.0"}]`)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("upgrade").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	result, err := suite.controller.UpgradeExtension(mockContext(), suite.db, verifiedExtensionId)
+	suite.Require().NoError(err)
+	suite.Equal(&extensionAPI.JsUpgradeResult{PreviousVersion: "1.0.0", NewVersion: "2.0.0"}, result)
+}
+
+func (suite *ControllerUTestSuite) TestUpgradeVerificationFailsRollsBack() {
+	suite.enableInstallationVerification(`[{name: "verified", version: "1.0.0"}]`)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("upgrade").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	result, err := suite.controller.UpgradeExtension(mockContext(), suite.db, verifiedExtensionId)
+	suite.assertApiError(err, 500, `verification of upgrade failed: extension "verified.js" reported success but version "2.0.0" was not found, found versions: [1.0.0]`)
+	suite.Nil(result)
+}
+
+// Upgrade to target version
+
+const targetedUpgradeExtensionId = "targeted.js"
+
+// targetedUpgradeTestExtension creates the JavaScript of an extension using the given upgrade function.
+func targetedUpgradeTestExtension(upgradeFunction string) string {
+	return strings.Replace(`(function(){
+		global.installedExtension = {
+			extension: {
+				name: "targeted",
+				installableVersions: [{name: "1.0.0"}, {name: "2.0.0", latest: true}],
+				upgrade: $UPGRADE$
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`, "$UPGRADE$", upgradeFunction, 1)
+}
+
+func (suite *ControllerUTestSuite) TestUpgradeToTargetVersionSucceeds() {
+	suite.writeFile(targetedUpgradeExtensionId, targetedUpgradeTestExtension(
+		`function(context, targetVersion) { context.sqlClient.execute("downgrade to " + targetVersion); return { previousVersion: "2.0.0", newVersion: targetVersion } }`))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("downgrade to 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	result, err := suite.controller.UpgradeExtensionToVersion(mockContext(), suite.db, targetedUpgradeExtensionId, "1.0.0")
+	suite.Require().NoError(err)
+	suite.Equal(&extensionAPI.JsUpgradeResult{PreviousVersion: "2.0.0", NewVersion: "1.0.0"}, result)
+}
+
+func (suite *ControllerUTestSuite) TestUpgradeToTargetVersionFailsForUnknownVersion() {
+	suite.writeFile(targetedUpgradeExtensionId, targetedUpgradeTestExtension(
+		`function(context, targetVersion) { throw new Error("not expected") }`))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	result, err := suite.controller.UpgradeExtensionToVersion(mockContext(), suite.db, targetedUpgradeExtensionId, "3.0.0")
+	suite.assertApiError(err, 400, `version "3.0.0" of extension "targeted.js" is not installable`)
+	suite.Nil(result)
+}
+
+func (suite *ControllerUTestSuite) TestUpgradeToTargetVersionFailsWhenNotSupported() {
+	suite.writeFile(targetedUpgradeExtensionId, targetedUpgradeTestExtension(
+		`function(context) { throw new Error("not expected") }`))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	result, err := suite.controller.UpgradeExtensionToVersion(mockContext(), suite.db, targetedUpgradeExtensionId, "1.0.0")
+	suite.assertApiError(err, 400, `extension "targeted.js" does not support upgrading to a specific version`)
+	suite.Nil(result)
+}
+
 // CreateInstance
 
 /* [utest -> dsn~parameter-types~1]. */
@@ -0,0 +1,44 @@
+package extensionController
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// createdTables remembers the databases and schemas in which a table of the extension manager was already created,
+// so that CREATE TABLE IF NOT EXISTS is not executed in every operation transaction. Concurrent DDL statements
+// cause transaction conflicts in Exasol.
+//
+// A table created in a transaction that is rolled back later is still remembered. Statements using the table then fail
+// because the table does not exist, so the table is created again.
+type createdTables struct {
+	tables sync.Map
+}
+
+// execWithTable runs the given statements using the table and creates the table first unless it was already created
+// in the database and schema of the context. If the table does not exist anymore, it is created again and the statements are repeated.
+func (t *createdTables) execWithTable(ctx context.Context, schema string, createTable func() error, statements func() error) error {
+	key := getDatabaseIdentifier(ctx) + "/" + schema
+	if _, created := t.tables.Load(key); created {
+		err := statements()
+		if !isObjectNotFound(err) {
+			return err
+		}
+		t.tables.Delete(key)
+	}
+	if err := createTable(); err != nil {
+		return err
+	}
+	if err := statements(); err != nil {
+		return err
+	}
+	t.tables.Store(key, true)
+	return nil
+}
+
+// isObjectNotFound returns true if the error was caused by a table that does not exist.
+// Exasol reports this with error "object <schema>.<table> not found".
+func isObjectNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "object ") && strings.Contains(err.Error(), " not found")
+}
@@ -0,0 +1,91 @@
+package extensionController
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var objectNotFoundError = errors.New(`[42000] object "EXT_SCHEMA"."TABLE" not found [line 1, column 13]`)
+
+// tableStatementsRecorder counts the calls of the functions passed to [createdTables.execWithTable].
+type tableStatementsRecorder struct {
+	createCalls    int
+	statementCalls int
+	statementErrs  []error // Errors returned by the statements in the order of the calls, nil if exhausted
+}
+
+func (r *tableStatementsRecorder) createTable() error {
+	r.createCalls++
+	return nil
+}
+
+func (r *tableStatementsRecorder) statements() error {
+	r.statementCalls++
+	if len(r.statementErrs) == 0 {
+		return nil
+	}
+	err := r.statementErrs[0]
+	r.statementErrs = r.statementErrs[1:]
+	return err
+}
+
+func (r *tableStatementsRecorder) exec(tables *createdTables, ctx context.Context, schema string) error {
+	return tables.execWithTable(ctx, schema, r.createTable, r.statements)
+}
+
+func TestCreatedTablesCreatesTableOnlyOnce(t *testing.T) {
+	tables := &createdTables{}
+	recorder := &tableStatementsRecorder{}
+	require.NoError(t, recorder.exec(tables, context.Background(), "schema"))
+	require.NoError(t, recorder.exec(tables, context.Background(), "schema"))
+	assert.Equal(t, 1, recorder.createCalls)
+	assert.Equal(t, 2, recorder.statementCalls)
+}
+
+func TestCreatedTablesCreatesTableForEachDatabaseAndSchema(t *testing.T) {
+	tables := &createdTables{}
+	recorder := &tableStatementsRecorder{}
+	require.NoError(t, recorder.exec(tables, WithDatabaseIdentifier(context.Background(), "db1:8563"), "schema"))
+	require.NoError(t, recorder.exec(tables, WithDatabaseIdentifier(context.Background(), "db2:8563"), "schema"))
+	require.NoError(t, recorder.exec(tables, WithDatabaseIdentifier(context.Background(), "db1:8563"), "other_schema"))
+	require.NoError(t, recorder.exec(tables, WithDatabaseIdentifier(context.Background(), "db1:8563"), "schema"))
+	assert.Equal(t, 3, recorder.createCalls)
+}
+
+func TestCreatedTablesCreatesTableAgainIfNotFound(t *testing.T) {
+	tables := &createdTables{}
+	recorder := &tableStatementsRecorder{}
+	require.NoError(t, recorder.exec(tables, context.Background(), "schema"))
+	recorder.statementErrs = []error{objectNotFoundError}
+	require.NoError(t, recorder.exec(tables, context.Background(), "schema"))
+	assert.Equal(t, 2, recorder.createCalls)
+	assert.Equal(t, 3, recorder.statementCalls)
+}
+
+func TestCreatedTablesDoesNotCreateTableAgainForOtherErrors(t *testing.T) {
+	tables := &createdTables{}
+	recorder := &tableStatementsRecorder{}
+	require.NoError(t, recorder.exec(tables, context.Background(), "schema"))
+	recorder.statementErrs = []error{mockError}
+	require.ErrorIs(t, recorder.exec(tables, context.Background(), "schema"), mockError)
+	assert.Equal(t, 1, recorder.createCalls)
+	assert.Equal(t, 2, recorder.statementCalls)
+}
+
+func TestCreatedTablesDoesNotRememberFailedStatements(t *testing.T) {
+	tables := &createdTables{}
+	recorder := &tableStatementsRecorder{statementErrs: []error{mockError}}
+	require.ErrorIs(t, recorder.exec(tables, context.Background(), "schema"), mockError)
+	require.NoError(t, recorder.exec(tables, context.Background(), "schema"))
+	assert.Equal(t, 2, recorder.createCalls)
+}
+
+func TestIsObjectNotFound(t *testing.T) {
+	assert.True(t, isObjectNotFound(objectNotFoundError))
+	assert.False(t, isObjectNotFound(mockError))
+	assert.False(t, isObjectNotFound(nil))
+}
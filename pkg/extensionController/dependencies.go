@@ -0,0 +1,182 @@
+package extensionController
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/mod/semver"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+)
+
+// dependencyInstaller installs an extension after verifying that all of its dependencies are installed.
+// If enabled in the options, it installs missing dependencies first.
+type dependencyInstaller struct {
+	controller *controllerImpl
+	txCtx      *transaction.TransactionContext
+	options    InstallOptions
+	// installedVersions maps extension IDs to their installed versions. It is read lazily when the first dependency is checked.
+	installedVersions map[string]string
+}
+
+func newDependencyInstaller(controller *controllerImpl, txCtx *transaction.TransactionContext, options InstallOptions) *dependencyInstaller {
+	return &dependencyInstaller{controller: controller, txCtx: txCtx, options: options, installedVersions: nil}
+}
+
+// install installs the given extension version. installChain contains the IDs of extensions currently being installed
+// and allows detecting cyclic dependencies.
+func (i *dependencyInstaller) install(extension *extensionAPI.JsExtension, extensionVersion string, installChain []string) error {
+	installChain = append(installChain, extension.Id)
+	for _, dependency := range extension.Dependencies {
+		if err := i.ensureDependencyInstalled(extension, dependency, installChain); err != nil {
+			return err
+		}
+	}
+	if err := i.controller.checkDeprecatedVersion(i.txCtx, extension, extensionVersion, "install", i.options.Force); err != nil {
+		return err
+	}
+	if err := i.controller.acceptLicenses(i.txCtx, extension, extensionVersion, i.options); err != nil {
+		return err
+	}
+	if err := extension.Install(i.controller.createExtensionContext(i.txCtx, extension), extensionVersion); err != nil {
+		return err
+	}
+	if err := i.controller.verifyInstallation(i.txCtx, extension, extensionVersion, "install"); err != nil {
+		return err
+	}
+	if i.installedVersions != nil {
+		i.installedVersions[extension.Id] = extensionVersion
+	}
+	return nil
+}
+
+func (i *dependencyInstaller) ensureDependencyInstalled(extension *extensionAPI.JsExtension, dependency extensionAPI.ExtensionDependency, installChain []string) error {
+	if slices.Contains(installChain, dependency.ExtensionId) {
+		return apiErrors.NewBadRequestErrorF("cyclic dependency between extensions: %s -> %s", strings.Join(installChain, " -> "), dependency.ExtensionId)
+	}
+	installedVersion, installed, err := i.getInstalledVersion(dependency.ExtensionId)
+	if err != nil {
+		return err
+	}
+	if installed {
+		return verifyInstalledDependency(extension, dependency, installedVersion)
+	}
+	if !i.options.InstallDependencies {
+		return apiErrors.NewBadRequestErrorF("extension %q requires extension %q%s which is not installed", extension.Id, dependency.ExtensionId, formatVersionRange(dependency.VersionRange))
+	}
+	return i.installDependency(extension, dependency, installChain)
+}
+
+func verifyInstalledDependency(extension *extensionAPI.JsExtension, dependency extensionAPI.ExtensionDependency, installedVersion string) error {
+	matches, err := dependency.Matches(installedVersion)
+	if err != nil {
+		return fmt.Errorf("failed to check dependency %q of extension %q: %w", dependency.ExtensionId, extension.Id, err)
+	}
+	if !matches {
+		return apiErrors.NewBadRequestErrorF("extension %q requires extension %q%s but version %q is installed",
+			extension.Id, dependency.ExtensionId, formatVersionRange(dependency.VersionRange), installedVersion)
+	}
+	log.Debugf("Dependency %q of extension %q is installed in version %q", dependency.ExtensionId, extension.Id, installedVersion)
+	return nil
+}
+
+func (i *dependencyInstaller) installDependency(extension *extensionAPI.JsExtension, dependency extensionAPI.ExtensionDependency, installChain []string) error {
+	dependencyExtension, err := i.controller.loadExtension(i.txCtx, dependency.ExtensionId)
+	if err != nil {
+		return fmt.Errorf("failed to load dependency %q of extension %q: %w", dependency.ExtensionId, extension.Id, err)
+	}
+	version, err := findBestMatchingVersion(dependencyExtension, dependency.VersionRange)
+	if err != nil {
+		return err
+	}
+	log.Infof("Installing dependency %q in version %q required by extension %q", dependency.ExtensionId, version, extension.Id)
+	return i.install(dependencyExtension, version, installChain)
+}
+
+func (i *dependencyInstaller) getInstalledVersion(extensionId string) (version string, installed bool, err error) {
+	if i.installedVersions == nil {
+		installations, err := i.controller.GetAllInstallations(i.txCtx)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to check installed dependencies: %w", err)
+		}
+		i.installedVersions = make(map[string]string)
+		for _, installation := range installations {
+			i.installedVersions[installation.ID] = installation.Version
+		}
+	}
+	version, installed = i.installedVersions[extensionId]
+	return version, installed, nil
+}
+
+// findBestMatchingVersion returns the highest installable version of the extension that is part of the given range.
+// Deprecated versions are only used if no other version matches.
+func findBestMatchingVersion(extension *extensionAPI.JsExtension, versionRange string) (string, error) {
+	bestVersion := ""
+	bestDeprecated := true
+	for _, version := range extension.InstallableVersions {
+		matches, err := extensionAPI.VersionInRange(versionRange, version.Name)
+		if err != nil || !matches {
+			continue
+		}
+		if bestVersion == "" || (bestDeprecated && !version.Deprecated) ||
+			(bestDeprecated == version.Deprecated && semver.Compare("v"+version.Name, "v"+bestVersion) > 0) {
+			bestVersion = version.Name
+			bestDeprecated = version.Deprecated
+		}
+	}
+	if bestVersion == "" {
+		return "", apiErrors.NewBadRequestErrorF("extension %q has no installable version%s", extension.Id, formatVersionRange(versionRange))
+	}
+	return bestVersion, nil
+}
+
+// findInstalledDependents returns the installations of other extensions that depend on the given extension.
+func (c *controllerImpl) findInstalledDependents(txCtx *transaction.TransactionContext, extensionId string) ([]*extensionAPI.JsExtInstallation, error) {
+	extensions, err := c.getAllExtensions()
+	if err != nil {
+		return nil, err
+	}
+	if !anyExtensionDependsOn(extensions, extensionId) {
+		return nil, nil
+	}
+	installations, err := c.GetAllInstallations(txCtx)
+	if err != nil {
+		return nil, err
+	}
+	var dependents []*extensionAPI.JsExtInstallation
+	for _, installation := range installations {
+		if installation.ID != extensionId && dependsOn(installation.Dependencies, extensionId) {
+			dependents = append(dependents, installation)
+		}
+	}
+	return dependents, nil
+}
+
+func anyExtensionDependsOn(extensions []*extensionAPI.JsExtension, extensionId string) bool {
+	for _, extension := range extensions {
+		if extension.Id != extensionId && dependsOn(extension.Dependencies, extensionId) {
+			return true
+		}
+	}
+	return false
+}
+
+func dependsOn(dependencies []extensionAPI.ExtensionDependency, extensionId string) bool {
+	for _, dependency := range dependencies {
+		if dependency.ExtensionId == extensionId {
+			return true
+		}
+	}
+	return false
+}
+
+func formatVersionRange(versionRange string) string {
+	if strings.TrimSpace(versionRange) == "" || strings.TrimSpace(versionRange) == "*" {
+		return ""
+	}
+	return fmt.Sprintf(" in version range %q", versionRange)
+}
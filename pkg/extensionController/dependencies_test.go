@@ -0,0 +1,146 @@
+package extensionController
+
+import (
+	"strings"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+)
+
+const (
+	driverExtensionId = "driver.js"
+	vsExtensionId     = "virtual-schema.js"
+)
+
+// dependencyTestExtension creates the JavaScript of an extension that logs install and uninstall calls as SQL statements.
+func dependencyTestExtension(name, versions, dependencies, installations string) string {
+	content := `(function(){
+		global.installedExtension = {
+			extension: {
+				name: "$NAME$",
+				installableVersions: $VERSIONS$,
+				dependencies: $DEPENDENCIES$,
+				findInstallations: function(context, metadata) { return $INSTALLATIONS$ },
+				install: function(context, version) { console.log("installing $NAME$"); context.sqlClient.execute("install $NAME$ " + version) },
+				uninstall: function(context, version) { context.sqlClient.execute("uninstall $NAME$ " + version) }
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`
+	return strings.NewReplacer("$NAME$", name, "$VERSIONS$", versions, "$DEPENDENCIES$", dependencies, "$INSTALLATIONS$", installations).Replace(content)
+}
+
+func (suite *ControllerUTestSuite) writeDependencyTestExtensions(driverInstallations, vsDependencies string) {
+	suite.writeFile(driverExtensionId, dependencyTestExtension("driver",
+		`[{name: "1.0.0"}, {name: "1.5.0", deprecated: true}, {name: "1.4.0", latest: true}, {name: "2.0.0"}]`, `[]`, driverInstallations))
+	suite.writeFile(vsExtensionId, dependencyTestExtension("vs", `[{name: "0.1.0", latest: true}]`, vsDependencies, `[]`))
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{})
+}
+
+func (suite *ControllerUTestSuite) TestInstallWithDependencyAlreadyInstalled() {
+	suite.writeDependencyTestExtensions(`[{name: "driver", version: "1.2.0"}]`, `[{extensionId: "driver.js", versionRange: ">=1.0.0 <2.0.0"}]`)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install vs 0.1.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	err := suite.controller.InstallExtension(mockContext(), suite.db, vsExtensionId, "0.1.0")
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerUTestSuite) TestInstallFailsForMissingDependency() {
+	suite.writeDependencyTestExtensions(`[]`, `[{extensionId: "driver.js", versionRange: ">=1.0.0 <2.0.0"}]`)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.InstallExtension(mockContext(), suite.db, vsExtensionId, "0.1.0")
+	suite.assertApiError(err, 400, `extension "virtual-schema.js" requires extension "driver.js" in version range ">=1.0.0 <2.0.0" which is not installed`)
+}
+
+func (suite *ControllerUTestSuite) TestInstallFailsForDependencyWithWrongVersion() {
+	suite.writeDependencyTestExtensions(`[{name: "driver", version: "2.0.0"}]`, `[{extensionId: "driver.js", versionRange: ">=1.0.0 <2.0.0"}]`)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.InstallExtension(mockContext(), suite.db, vsExtensionId, "0.1.0")
+	suite.assertApiError(err, 400, `extension "virtual-schema.js" requires extension "driver.js" in version range ">=1.0.0 <2.0.0" but version "2.0.0" is installed`)
+}
+
+func (suite *ControllerUTestSuite) TestInstallWithOptionsInstallsMissingDependencyFirst() {
+	suite.writeDependencyTestExtensions(`[]`, `[{extensionId: "driver.js", versionRange: ">=1.0.0 <2.0.0"}]`)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install driver 1.4.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install vs 0.1.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	err := suite.controller.InstallExtensionWithOptions(mockContext(), suite.db, vsExtensionId, "0.1.0", InstallOptions{InstallDependencies: true})
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerUTestSuite) TestDryRunInstallRecordsStatementsOfDependencies() {
+	suite.writeDependencyTestExtensions(`[]`, `[{extensionId: "driver.js", versionRange: ">=1.0.0 <2.0.0"}]`)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install driver 1.4.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install vs 0.1.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	result, err := suite.controller.DryRunInstallExtension(mockContext(), suite.db, vsExtensionId, "0.1.0", InstallOptions{InstallDependencies: true, AcceptLicenses: false})
+	suite.Require().NoError(err)
+	suite.Equal(&DryRunResult{
+		Statements:  []transaction.RecordedStatement{{Query: "install driver 1.4.0", Args: []any{}}, {Query: "install vs 0.1.0", Args: []any{}}},
+		LogMessages: []transaction.RecordedLogMessage{{Level: "log", Message: "installing driver"}, {Level: "log", Message: "installing vs"}},
+	}, result)
+}
+
+func (suite *ControllerUTestSuite) TestInstallWithOptionsFailsWithoutMatchingDependencyVersion() {
+	suite.writeDependencyTestExtensions(`[]`, `[{extensionId: "driver.js", versionRange: ">=3.0.0"}]`)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.InstallExtensionWithOptions(mockContext(), suite.db, vsExtensionId, "0.1.0", InstallOptions{InstallDependencies: true})
+	suite.assertApiError(err, 400, `extension "driver.js" has no installable version in version range ">=3.0.0"`)
+}
+
+func (suite *ControllerUTestSuite) TestInstallWithOptionsFailsForCyclicDependency() {
+	suite.writeFile(driverExtensionId, dependencyTestExtension("driver", `[{name: "1.0.0", latest: true}]`, `[{extensionId: "virtual-schema.js"}]`, `[]`))
+	suite.writeFile(vsExtensionId, dependencyTestExtension("vs", `[{name: "0.1.0", latest: true}]`, `[{extensionId: "driver.js"}]`, `[]`))
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{})
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.InstallExtensionWithOptions(mockContext(), suite.db, vsExtensionId, "0.1.0", InstallOptions{InstallDependencies: true})
+	suite.assertApiError(err, 400, "cyclic dependency between extensions: virtual-schema.js -> driver.js -> virtual-schema.js")
+}
+
+func (suite *ControllerUTestSuite) TestUninstallFailsWhenOtherExtensionDependsOnIt() {
+	suite.writeFile(driverExtensionId, dependencyTestExtension("driver", `[{name: "1.0.0", latest: true}]`, `[]`, `[{name: "driver", version: "1.0.0"}]`))
+	suite.writeFile(vsExtensionId, dependencyTestExtension("vs", `[{name: "0.1.0", latest: true}]`, `[{extensionId: "driver.js"}]`, `[{name: "vs", version: "0.1.0"}]`))
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{})
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.UninstallExtension(mockContext(), suite.db, driverExtensionId, "1.0.0")
+	suite.assertApiError(err, 400, "cannot uninstall extension because 1 installed extension(s) depend on it: virtual-schema.js (0.1.0)")
+}
+
+func (suite *ControllerUTestSuite) TestUninstallSucceedsWhenDependentExtensionNotInstalled() {
+	suite.writeDependencyTestExtensions(`[{name: "driver", version: "1.0.0"}]`, `[{extensionId: "driver.js"}]`)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("uninstall driver 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	err := suite.controller.UninstallExtension(mockContext(), suite.db, driverExtensionId, "1.0.0")
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerUTestSuite) TestGetInstalledExtensionsContainsDependencies() {
+	suite.writeDependencyTestExtensions(`[{name: "driver", version: "1.0.0"}]`, `[{extensionId: "driver.js", versionRange: ">=1.0.0"}]`)
+	suite.writeFile(vsExtensionId, dependencyTestExtension("vs", `[{name: "0.1.0", latest: true}]`, `[{extensionId: "driver.js", versionRange: ">=1.0.0"}]`, `[{name: "vs", version: "0.1.0"}]`))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	installations, err := suite.controller.GetInstalledExtensions(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Equal([]*extensionAPI.JsExtInstallation{
+		{ID: driverExtensionId, Name: "driver", Version: "1.0.0", Dependencies: []extensionAPI.ExtensionDependency{}},
+		{ID: vsExtensionId, Name: "vs", Version: "0.1.0", Dependencies: []extensionAPI.ExtensionDependency{{ExtensionId: driverExtensionId, VersionRange: ">=1.0.0"}}},
+	}, installations)
+}
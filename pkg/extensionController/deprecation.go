@@ -0,0 +1,55 @@
+package extensionController
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+)
+
+// DeprecatedVersionPolicy defines how the extension manager handles deprecated extension versions
+// when installing an extension or creating an instance.
+type DeprecatedVersionPolicy string
+
+const (
+	// DeprecatedVersionPolicyAllow allows using deprecated versions without warning. This is the default.
+	DeprecatedVersionPolicyAllow DeprecatedVersionPolicy = "allow"
+	// DeprecatedVersionPolicyWarn allows using deprecated versions but reports a warning.
+	DeprecatedVersionPolicyWarn DeprecatedVersionPolicy = "warn"
+	// DeprecatedVersionPolicyReject rejects using deprecated versions unless the user forces it.
+	DeprecatedVersionPolicyReject DeprecatedVersionPolicy = "reject"
+)
+
+func validateDeprecatedVersionPolicy(policy DeprecatedVersionPolicy) error {
+	switch policy {
+	case "", DeprecatedVersionPolicyAllow, DeprecatedVersionPolicyWarn, DeprecatedVersionPolicyReject:
+		return nil
+	default:
+		return fmt.Errorf("invalid DeprecatedVersionPolicy %q, expected one of %q, %q or %q", policy,
+			DeprecatedVersionPolicyAllow, DeprecatedVersionPolicyWarn, DeprecatedVersionPolicyReject)
+	}
+}
+
+// checkDeprecatedVersion applies the configured [DeprecatedVersionPolicy] to the given extension version.
+// It adds a warning to the transaction if the version is deprecated and returns an error if the policy rejects it.
+// force allows using a deprecated version even if the policy rejects it.
+func (c *controllerImpl) checkDeprecatedVersion(txCtx *transaction.TransactionContext, extension *extensionAPI.JsExtension, extensionVersion string, operation string, force bool) error {
+	if !isDeprecatedVersion(extension.InstallableVersions, extensionVersion) {
+		return nil
+	}
+	switch c.config.DeprecatedVersionPolicy {
+	case DeprecatedVersionPolicyReject:
+		if !force {
+			return apiErrors.NewBadRequestErrorF("version %q of extension %q is deprecated, use force to %s it anyway", extensionVersion, extension.Id, operation)
+		}
+		txCtx.AddWarning(fmt.Sprintf("version %q of extension %q is deprecated, forced to %s it anyway", extensionVersion, extension.Id, operation))
+	case DeprecatedVersionPolicyWarn:
+		txCtx.AddWarning(fmt.Sprintf("version %q of extension %q is deprecated, please upgrade to a newer version", extensionVersion, extension.Id))
+	default:
+		log.Debugf("Using deprecated version %q of extension %q", extensionVersion, extension.Id)
+	}
+	return nil
+}
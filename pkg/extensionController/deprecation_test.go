@@ -0,0 +1,105 @@
+package extensionController
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const deprecatedExtensionId = "deprecated.js"
+
+const deprecatedTestExtension = `(function(){
+	global.installedExtension = {
+		extension: {
+			name: "deprecated",
+			installableVersions: [{name: "1.0.0", deprecated: true}, {name: "2.0.0", latest: true}],
+			install: function(context, version) { context.sqlClient.execute("install " + version) },
+			getInstanceParameters: function(context, version) { return [] },
+			addInstance: function(context, version, params) { return {id: "instId", name: "instance_" + version} }
+		},
+		apiVersion: "0.2.0"
+	}
+})()`
+
+func (suite *ControllerUTestSuite) useDeprecatedVersionPolicy(policy DeprecatedVersionPolicy) {
+	suite.writeFile(deprecatedExtensionId, deprecatedTestExtension)
+	suite.controller.controller.(*controllerImpl).config.DeprecatedVersionPolicy = policy
+}
+
+func (suite *ControllerUTestSuite) expectInstall(version string) {
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install " + version).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+}
+
+func (suite *ControllerUTestSuite) TestInstallDeprecatedVersionAllowedByDefault() {
+	suite.useDeprecatedVersionPolicy("")
+	suite.expectInstall("1.0.0")
+	result, err := suite.controller.InstallExtensionWithResult(mockContext(), suite.db, deprecatedExtensionId, "1.0.0", InstallOptions{})
+	suite.Require().NoError(err)
+	suite.Empty(result.Warnings)
+}
+
+func (suite *ControllerUTestSuite) TestInstallDeprecatedVersionWithWarnPolicy() {
+	suite.useDeprecatedVersionPolicy(DeprecatedVersionPolicyWarn)
+	suite.expectInstall("1.0.0")
+	result, err := suite.controller.InstallExtensionWithResult(mockContext(), suite.db, deprecatedExtensionId, "1.0.0", InstallOptions{})
+	suite.Require().NoError(err)
+	suite.Equal([]string{`version "1.0.0" of extension "deprecated.js" is deprecated, please upgrade to a newer version`}, result.Warnings)
+}
+
+func (suite *ControllerUTestSuite) TestInstallDeprecatedVersionWithRejectPolicyFails() {
+	suite.useDeprecatedVersionPolicy(DeprecatedVersionPolicyReject)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	result, err := suite.controller.InstallExtensionWithResult(mockContext(), suite.db, deprecatedExtensionId, "1.0.0", InstallOptions{})
+	suite.assertApiError(err, 400, `version "1.0.0" of extension "deprecated.js" is deprecated, use force to install it anyway`)
+	suite.Nil(result)
+}
+
+func (suite *ControllerUTestSuite) TestInstallDeprecatedVersionWithRejectPolicyAndForce() {
+	suite.useDeprecatedVersionPolicy(DeprecatedVersionPolicyReject)
+	suite.expectInstall("1.0.0")
+	result, err := suite.controller.InstallExtensionWithResult(mockContext(), suite.db, deprecatedExtensionId, "1.0.0", InstallOptions{Force: true})
+	suite.Require().NoError(err)
+	suite.Equal([]string{`version "1.0.0" of extension "deprecated.js" is deprecated, forced to install it anyway`}, result.Warnings)
+}
+
+func (suite *ControllerUTestSuite) TestInstallNonDeprecatedVersionWithRejectPolicy() {
+	suite.useDeprecatedVersionPolicy(DeprecatedVersionPolicyReject)
+	suite.expectInstall("2.0.0")
+	result, err := suite.controller.InstallExtensionWithResult(mockContext(), suite.db, deprecatedExtensionId, "2.0.0", InstallOptions{})
+	suite.Require().NoError(err)
+	suite.Empty(result.Warnings)
+}
+
+func (suite *ControllerUTestSuite) TestDryRunInstallDeprecatedVersionWithWarnPolicy() {
+	suite.useDeprecatedVersionPolicy(DeprecatedVersionPolicyWarn)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	result, err := suite.controller.DryRunInstallExtension(mockContext(), suite.db, deprecatedExtensionId, "1.0.0", InstallOptions{})
+	suite.Require().NoError(err)
+	suite.Equal([]string{`version "1.0.0" of extension "deprecated.js" is deprecated, please upgrade to a newer version`}, result.Warnings)
+}
+
+func (suite *ControllerUTestSuite) TestCreateInstanceOfDeprecatedVersionWithRejectPolicyFails() {
+	suite.useDeprecatedVersionPolicy(DeprecatedVersionPolicyReject)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	result, err := suite.controller.CreateInstanceWithOptions(mockContext(), suite.db, deprecatedExtensionId, "1.0.0", []ParameterValue{}, CreateInstanceOptions{})
+	suite.assertApiError(err, 400, `version "1.0.0" of extension "deprecated.js" is deprecated, use force to create an instance of it anyway`)
+	suite.Nil(result)
+}
+
+func (suite *ControllerUTestSuite) TestCreateInstanceOfDeprecatedVersionWithRejectPolicyAndForce() {
+	suite.useDeprecatedVersionPolicy(DeprecatedVersionPolicyReject)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	result, err := suite.controller.CreateInstanceWithOptions(mockContext(), suite.db, deprecatedExtensionId, "1.0.0", []ParameterValue{}, CreateInstanceOptions{Force: true})
+	suite.Require().NoError(err)
+	suite.Equal("instance_1.0.0", result.Instance.Name)
+	suite.Equal([]string{`version "1.0.0" of extension "deprecated.js" is deprecated, forced to create an instance of it anyway`}, result.Warnings)
+}
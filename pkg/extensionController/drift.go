@@ -0,0 +1,235 @@
+package extensionController
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/exasol/extension-manager/pkg/extensionController/bfs"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+)
+
+// DriftFindingType is the type of a problem found by [TransactionController.DetectDrift].
+type DriftFindingType string
+
+const (
+	// DriftOrphanedScript is a script in the extension schema that belongs to no known extension.
+	DriftOrphanedScript DriftFindingType = "orphaned-script"
+	// DriftOrphanedVirtualSchema is a virtual schema using an adapter script in the extension schema that belongs to no instance of a known extension.
+	DriftOrphanedVirtualSchema DriftFindingType = "orphaned-virtual-schema"
+	// DriftMissingAdapterScript is a virtual schema whose adapter script does not exist.
+	DriftMissingAdapterScript DriftFindingType = "missing-adapter-script"
+	// DriftMissingBucketFsFile is a script that references a file which does not exist in BucketFS.
+	DriftMissingBucketFsFile DriftFindingType = "missing-bucketfs-file"
+)
+
+// DriftFinding is a database object that does not match the installations reported by the known extensions.
+type DriftFinding struct {
+	Type DriftFindingType
+	// ObjectName is the name of the script or virtual schema.
+	ObjectName string
+	// Reference is the missing adapter script (schema and name) or the missing BucketFS file (absolute path). It is empty for orphaned objects.
+	Reference string
+	Message   string
+}
+
+// DriftReport contains the result of comparing the database objects with the installations reported by the known extensions.
+type DriftReport struct {
+	Findings []DriftFinding
+	// Warnings for the user, e.g. because the instances of an extension could not be found. The findings may then be incomplete.
+	Warnings []string
+}
+
+// jarPathPattern matches the "%jar" directives of Java scripts. A directive may contain multiple paths separated by colons.
+var jarPathPattern = regexp.MustCompile(`(?m)^\s*%jar\s+([^;]+);`)
+
+func (c *controllerImpl) DetectDrift(txCtx *transaction.TransactionContext, bfsFiles []bfs.BfsFile) (*DriftReport, error) {
+	schema := c.extensionSchema(txCtx)
+	metadata, err := c.metaDataReader.ReadMetadataTables(txCtx.GetTransaction(), schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata tables. Cause: %w", err)
+	}
+	extensions, err := c.getAllExtensions()
+	if err != nil {
+		return nil, err
+	}
+	installations, err := c.findInstallations(txCtx, metadata, extensions)
+	if err != nil {
+		return nil, err
+	}
+	report := &DriftReport{Findings: nil, Warnings: nil}
+	instances := c.findAllInstances(txCtx, extensions, installations, report)
+	c.checkScripts(metadata, installations, bfsFiles, report)
+	if err := c.checkVirtualSchemas(txCtx, schema, metadata, instances, report); err != nil {
+		return nil, err
+	}
+	log.Infof("Found %d drift findings for %d installations", len(report.Findings), len(installations))
+	return report, nil
+}
+
+// findAllInstances returns the instances of all installations. Errors are reported as warnings.
+func (c *controllerImpl) findAllInstances(txCtx *transaction.TransactionContext, extensions []*extensionAPI.JsExtension, installations []*extensionAPI.JsExtInstallation,
+	report *DriftReport) []*extensionAPI.JsExtInstance {
+	var allInstances []*extensionAPI.JsExtInstance
+	for _, installation := range installations {
+		extension := findExtension(extensions, installation.ID)
+		if extension == nil {
+			continue
+		}
+		extensionContext := c.createExtensionContext(txCtx, extension)
+		if !extension.SupportsListInstances(extensionContext, installation.Version) {
+			continue
+		}
+		instances, err := extension.ListInstances(extensionContext, installation.Version)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("Failed to find instances of extension %q: %v", installation.ID, err))
+			continue
+		}
+		allInstances = append(allInstances, instances...)
+	}
+	return allInstances
+}
+
+func findExtension(extensions []*extensionAPI.JsExtension, id string) *extensionAPI.JsExtension {
+	for _, extension := range extensions {
+		if extension.Id == id {
+			return extension
+		}
+	}
+	return nil
+}
+
+func (c *controllerImpl) checkScripts(metadata *exaMetadata.ExaMetadata, installations []*extensionAPI.JsExtInstallation, bfsFiles []bfs.BfsFile, report *DriftReport) {
+	checkOrphans := allInstallationsReportScripts(installations, report)
+	for _, script := range metadata.AllScripts.Rows {
+		jarPaths := findJarPaths(script.Text)
+		if checkOrphans && !isScriptClaimed(script.Name, installations) {
+			report.Findings = append(report.Findings, DriftFinding{Type: DriftOrphanedScript, ObjectName: script.Name, Reference: "",
+				Message: fmt.Sprintf("Script %q belongs to no known extension", script.Name)})
+		}
+		for _, jarPath := range jarPaths {
+			if c.isVerifiableBucketFsPath(jarPath) && !containsBfsPath(bfsFiles, jarPath) {
+				report.Findings = append(report.Findings, DriftFinding{Type: DriftMissingBucketFsFile, ObjectName: script.Name, Reference: jarPath,
+					Message: fmt.Sprintf("Script %q references file %q which does not exist in BucketFS", script.Name, jarPath)})
+			}
+		}
+	}
+}
+
+func findJarPaths(scriptText string) []string {
+	var paths []string
+	for _, match := range jarPathPattern.FindAllStringSubmatch(scriptText, -1) {
+		for _, jarPath := range strings.Split(match[1], ":") {
+			if jarPath = strings.TrimSpace(jarPath); jarPath != "" {
+				paths = append(paths, jarPath)
+			}
+		}
+	}
+	return paths
+}
+
+// allInstallationsReportScripts returns true if all installations report their scripts. Otherwise the scripts of an installation
+// can't be distinguished from orphaned scripts, so orphaned scripts are not checked and a warning is added to the report.
+func allInstallationsReportScripts(installations []*extensionAPI.JsExtInstallation, report *DriftReport) bool {
+	var extensionIds []string
+	for _, installation := range installations {
+		if installation.Scripts == nil {
+			extensionIds = append(extensionIds, fmt.Sprintf("%q", installation.ID))
+		}
+	}
+	if len(extensionIds) == 0 {
+		return true
+	}
+	report.Warnings = append(report.Warnings, fmt.Sprintf("Findings are incomplete: orphaned scripts are not checked because extensions %s don't report their scripts",
+		strings.Join(extensionIds, ", ")))
+	return false
+}
+
+// isScriptClaimed returns true if an installation reports the script.
+func isScriptClaimed(scriptName string, installations []*extensionAPI.JsExtInstallation) bool {
+	for _, installation := range installations {
+		for _, name := range installation.Scripts {
+			if strings.EqualFold(name, scriptName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isVerifiableBucketFsPath returns true if the path is located in one of the configured BucketFS base paths.
+// The extension manager can't list files in other locations.
+func (c *controllerImpl) isVerifiableBucketFsPath(absolutePath string) bool {
+	for _, basePath := range c.config.bucketFsBasePaths() {
+		if strings.HasPrefix(absolutePath, strings.TrimSuffix(basePath, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsBfsPath(bfsFiles []bfs.BfsFile, absolutePath string) bool {
+	for _, file := range bfsFiles {
+		if file.Path == absolutePath {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVirtualSchemas checks virtual schemas that belong to an instance or use an adapter script in the extension schema.
+// Other virtual schemas are not managed by the extension manager.
+func (c *controllerImpl) checkVirtualSchemas(txCtx *transaction.TransactionContext, schema string, metadata *exaMetadata.ExaMetadata,
+	instances []*extensionAPI.JsExtInstance, report *DriftReport) error {
+	for _, virtualSchema := range metadata.AllVirtualSchemas.Rows {
+		claimed := isVirtualSchemaClaimed(virtualSchema.Name, instances)
+		inExtensionSchema := strings.EqualFold(virtualSchema.AdapterScriptSchema, schema)
+		if !claimed && !inExtensionSchema {
+			continue
+		}
+		if !claimed {
+			report.Findings = append(report.Findings, DriftFinding{Type: DriftOrphanedVirtualSchema, ObjectName: virtualSchema.Name, Reference: "",
+				Message: fmt.Sprintf("Virtual schema %q belongs to no instance of a known extension", virtualSchema.Name)})
+		}
+		exists, err := c.adapterScriptExists(txCtx, virtualSchema, inExtensionSchema, metadata)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			adapterScript := virtualSchema.AdapterScriptSchema + "." + virtualSchema.AdapterScriptName
+			report.Findings = append(report.Findings, DriftFinding{Type: DriftMissingAdapterScript, ObjectName: virtualSchema.Name, Reference: adapterScript,
+				Message: fmt.Sprintf("Virtual schema %q uses adapter script %q which does not exist", virtualSchema.Name, adapterScript)})
+		}
+	}
+	return nil
+}
+
+func isVirtualSchemaClaimed(virtualSchemaName string, instances []*extensionAPI.JsExtInstance) bool {
+	for _, instance := range instances {
+		if strings.EqualFold(instance.Name, virtualSchemaName) || strings.EqualFold(instance.Id, virtualSchemaName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *controllerImpl) adapterScriptExists(txCtx *transaction.TransactionContext, virtualSchema exaMetadata.ExaVirtualSchemaRow, inExtensionSchema bool,
+	metadata *exaMetadata.ExaMetadata) (bool, error) {
+	if inExtensionSchema {
+		for _, script := range metadata.AllScripts.Rows {
+			if script.Name == virtualSchema.AdapterScriptName {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	script, err := c.metaDataReader.GetScriptByName(txCtx.GetTransaction(), virtualSchema.AdapterScriptSchema, virtualSchema.AdapterScriptName)
+	if err != nil {
+		return false, fmt.Errorf("failed to find adapter script of virtual schema %q: %w", virtualSchema.Name, err)
+	}
+	return script != nil, nil
+}
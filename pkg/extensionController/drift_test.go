@@ -0,0 +1,147 @@
+package extensionController
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/exasol/extension-manager/pkg/extensionController/bfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFindJarPaths(t *testing.T) {
+	var tests = []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "no jar", text: "CREATE PYTHON3 SCALAR SCRIPT s() RETURNS INT AS\nreturn 1\n/", expected: nil},
+		{name: "single jar", text: "%scriptclass com.exasol.Adapter;\n%jar /buckets/bfsdefault/default/a.jar;\n", expected: []string{"/buckets/bfsdefault/default/a.jar"}},
+		{name: "multiple directives", text: "  %jar /buckets/b/a.jar;\n%jar /buckets/b/c.jar ;", expected: []string{"/buckets/b/a.jar", "/buckets/b/c.jar"}},
+		{name: "multiple paths", text: "%jar /buckets/b/a.jar:/buckets/b/c.jar;", expected: []string{"/buckets/b/a.jar", "/buckets/b/c.jar"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, findJarPaths(test.text))
+		})
+	}
+}
+
+func TestIsScriptClaimed(t *testing.T) {
+	installations := []*extensionAPI.JsExtInstallation{{ID: "ext1", Scripts: []string{"ADAPTER"}}, {ID: "ext2", Scripts: []string{}}}
+	assert.True(t, isScriptClaimed("adapter", installations))
+	assert.False(t, isScriptClaimed("OTHER", installations))
+}
+
+func TestAllInstallationsReportScripts(t *testing.T) {
+	report := &DriftReport{}
+	assert.True(t, allInstallationsReportScripts([]*extensionAPI.JsExtInstallation{{ID: "ext1", Scripts: []string{}}}, report))
+	assert.Empty(t, report.Warnings)
+	assert.False(t, allInstallationsReportScripts([]*extensionAPI.JsExtInstallation{{ID: "ext1", Scripts: []string{}}, {ID: "ext2"}, {ID: "ext3"}}, report))
+	assert.Equal(t, []string{`Findings are incomplete: orphaned scripts are not checked because extensions "ext2", "ext3" don't report their scripts`}, report.Warnings)
+}
+
+func driftTestExtension(findInstances string) string {
+	return driftTestExtensionWithInstallation(`{name: "Drift Extension", version: "1.0.0", scripts: ["CLAIMED"]}`, findInstances)
+}
+
+func driftTestExtensionWithInstallation(installation, findInstances string) string {
+	return strings.NewReplacer("$INSTALLATION$", installation, "$FIND_INSTANCES$", findInstances).Replace(`(function(){
+		global.installedExtension = {
+			extension: {
+				name: "Drift Extension",
+				installableVersions: [{name: "1.0.0", latest: true}],
+				bucketFsUploads: [{name: "jar", bucketFsFilename: "drift-1.0.0.jar", fileSize: 3}],
+				findInstallations: function(context, metadata) { return [$INSTALLATION$] },
+				findInstances: function(context, version) { $FIND_INSTANCES$ }
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`)
+}
+
+func (suite *ControllerUTestSuite) simulateDriftTransactions() {
+	suite.controller.controller.(*controllerImpl).config.BucketFSBasePath = "/buckets/bfsdefault/default/"
+	suite.dbMock.ExpectBegin()
+	suite.bucketFsMock.SimulateFiles([]bfs.BfsFile{{Name: "drift-1.0.0.jar", Path: "/buckets/bfsdefault/default/drift-1.0.0.jar", Size: 3}})
+	suite.bucketFsMock.SimulateCloseSuccess()
+	suite.dbMock.ExpectRollback()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+}
+
+func driftTestMetadata() exaMetadata.ExaMetadata {
+	return exaMetadata.ExaMetadata{
+		AllScripts: exaMetadata.ExaScriptTable{Rows: []exaMetadata.ExaScriptRow{
+			{Schema: EXTENSION_SCHEMA, Name: "CLAIMED", Text: "%jar /buckets/bfsdefault/default/missing.jar;"},
+			{Schema: EXTENSION_SCHEMA, Name: "STALE", Text: "%jar /buckets/bfsdefault/default/drift-1.0.0.jar;"},
+			{Schema: EXTENSION_SCHEMA, Name: "ORPHAN", Text: "%jar /buckets/bfsother/other/unknown.jar;"}}},
+		AllVirtualSchemas: exaMetadata.ExaVirtualSchemasTable{Rows: []exaMetadata.ExaVirtualSchemaRow{
+			{Name: "VS_OK", AdapterScriptSchema: EXTENSION_SCHEMA, AdapterScriptName: "CLAIMED"},
+			{Name: "VS_ORPHAN", AdapterScriptSchema: EXTENSION_SCHEMA, AdapterScriptName: "ORPHAN"},
+			{Name: "VS_BROKEN", AdapterScriptSchema: EXTENSION_SCHEMA, AdapterScriptName: "DROPPED"},
+			{Name: "VS_EXTERNAL", AdapterScriptSchema: "OTHER_SCHEMA", AdapterScriptName: "GONE"},
+			{Name: "UNRELATED", AdapterScriptSchema: "OTHER_SCHEMA", AdapterScriptName: "ADAPTER"}}}}
+}
+
+func (suite *ControllerUTestSuite) TestDetectDrift() {
+	suite.writeFile("drift.js", driftTestExtension(`return [{id: "VS_OK", name: "VS_OK"}, {id: "vs_external", name: "vs_external"}]`))
+	suite.metaDataMock.SimulateExaMetaData(driftTestMetadata())
+	suite.metaDataMock.On("GetScriptByName", mock.Anything, "OTHER_SCHEMA", "GONE").Return(nil, nil)
+	suite.simulateDriftTransactions()
+	report, err := suite.controller.DetectDrift(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Equal(&DriftReport{Findings: []DriftFinding{
+		{Type: DriftMissingBucketFsFile, ObjectName: "CLAIMED", Reference: "/buckets/bfsdefault/default/missing.jar",
+			Message: `Script "CLAIMED" references file "/buckets/bfsdefault/default/missing.jar" which does not exist in BucketFS`},
+		{Type: DriftOrphanedScript, ObjectName: "STALE", Message: `Script "STALE" belongs to no known extension`},
+		{Type: DriftOrphanedScript, ObjectName: "ORPHAN", Message: `Script "ORPHAN" belongs to no known extension`},
+		{Type: DriftOrphanedVirtualSchema, ObjectName: "VS_ORPHAN", Message: `Virtual schema "VS_ORPHAN" belongs to no instance of a known extension`},
+		{Type: DriftOrphanedVirtualSchema, ObjectName: "VS_BROKEN", Message: `Virtual schema "VS_BROKEN" belongs to no instance of a known extension`},
+		{Type: DriftMissingAdapterScript, ObjectName: "VS_BROKEN", Reference: "test.DROPPED", Message: `Virtual schema "VS_BROKEN" uses adapter script "test.DROPPED" which does not exist`},
+		{Type: DriftMissingAdapterScript, ObjectName: "VS_EXTERNAL", Reference: "OTHER_SCHEMA.GONE",
+			Message: `Virtual schema "VS_EXTERNAL" uses adapter script "OTHER_SCHEMA.GONE" which does not exist`}}}, report)
+}
+
+func (suite *ControllerUTestSuite) TestDetectDriftSkipsOrphanedScriptsIfInstallationDoesNotReportScripts() {
+	suite.writeFile("drift.js", driftTestExtensionWithInstallation(`{name: "Drift Extension", version: "1.0.0"}`, `return []`))
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{
+		AllScripts: exaMetadata.ExaScriptTable{Rows: []exaMetadata.ExaScriptRow{{Schema: EXTENSION_SCHEMA, Name: "SCRIPT", Text: "%jar /buckets/bfsdefault/default/missing.jar;"}}}})
+	suite.simulateDriftTransactions()
+	report, err := suite.controller.DetectDrift(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Equal(&DriftReport{Findings: []DriftFinding{
+		{Type: DriftMissingBucketFsFile, ObjectName: "SCRIPT", Reference: "/buckets/bfsdefault/default/missing.jar",
+			Message: `Script "SCRIPT" references file "/buckets/bfsdefault/default/missing.jar" which does not exist in BucketFS`}},
+		Warnings: []string{`Findings are incomplete: orphaned scripts are not checked because extensions "drift.js" don't report their scripts`}}, report)
+}
+
+func (suite *ControllerUTestSuite) TestDetectDriftReportsWarningWhenFindingInstancesFails() {
+	suite.writeFile("drift.js", driftTestExtension(`throw new Error("instances failed")`))
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{
+		AllScripts: exaMetadata.ExaScriptTable{Rows: []exaMetadata.ExaScriptRow{{Schema: EXTENSION_SCHEMA, Name: "CLAIMED"}}}})
+	suite.simulateDriftTransactions()
+	report, err := suite.controller.DetectDrift(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Empty(report.Findings)
+	suite.Require().Len(report.Warnings, 1)
+	suite.Contains(report.Warnings[0], `Failed to find instances of extension "drift.js"`)
+}
+
+func (suite *ControllerUTestSuite) TestDetectDriftFailsReadingMetadata() {
+	suite.writeFile("drift.js", driftTestExtension(`return []`))
+	suite.metaDataMock.On("ReadMetadataTables", mock.Anything, EXTENSION_SCHEMA).Return(nil, mockError)
+	suite.simulateDriftTransactions()
+	report, err := suite.controller.DetectDrift(mockContext(), suite.db)
+	suite.Require().EqualError(err, "failed to read metadata tables. Cause: "+mockErrorMsg)
+	suite.Nil(report)
+}
+
+func (suite *ControllerUTestSuite) TestDetectDriftFailsStartingTransaction() {
+	suite.simulateTransactionBeginFails(mockError)
+	report, err := suite.controller.DetectDrift(mockContext(), suite.db)
+	suite.Require().EqualError(err, beginTransactionFailedErrorMsg)
+	suite.Nil(report)
+}
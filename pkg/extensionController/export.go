@@ -0,0 +1,392 @@
+package extensionController
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/parameterValidator"
+)
+
+// exportFormatVersion is the version of the [ExportDocument] format written by [TransactionController.ExportInstallations].
+const exportFormatVersion = 1
+
+// ExportDocument is a portable description of the extensions installed in a database and their instances.
+// It can be imported into another database using [TransactionController.ImportInstallations].
+type ExportDocument struct {
+	// FormatVersion is the version of the document format.
+	FormatVersion int `json:"formatVersion"`
+	// Extensions contains the installed extensions, dependencies before the extensions that require them.
+	Extensions []ExportedExtension `json:"extensions"`
+	// Warnings for the user, e.g. because the parameters of an instance are unknown.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ExportedExtension is an installed extension in an [ExportDocument].
+type ExportedExtension struct {
+	ExtensionId string             `json:"extensionId"`
+	Name        string             `json:"name"`
+	Version     string             `json:"version"`
+	Instances   []ExportedInstance `json:"instances,omitempty"`
+}
+
+// ExportedInstance is an instance of an extension in an [ExportDocument].
+type ExportedInstance struct {
+	Name       string           `json:"name"`
+	Parameters []ParameterValue `json:"parameters"`
+	// SecretParameters contains the names of parameters omitted from the export because they are secret.
+	// Their values must be supplied with [ImportOptions.Secrets] when importing the instance.
+	SecretParameters []string `json:"secretParameters,omitempty"`
+	// Incomplete is true if the parameters of the instance are unknown because its creation is not found in the audit log.
+	// Such an instance can't be imported and must be created manually.
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// ImportOptions contains options for importing an [ExportDocument].
+type ImportOptions struct {
+	// Secrets contains the values of secret parameters omitted from the export.
+	Secrets []ImportSecret
+	// AcceptLicenses confirms that the user accepts the licenses of all files that require a license agreement.
+	AcceptLicenses bool
+	// Force installs deprecated versions and creates instances of them even if the configured [DeprecatedVersionPolicy] rejects them.
+	Force bool
+}
+
+// ImportSecret is the value of a secret parameter of an instance.
+type ImportSecret struct {
+	ExtensionId  string
+	InstanceName string
+	Name         string
+	Value        string
+}
+
+// ImportAction is the type of change applied when importing an [ExportDocument].
+type ImportAction string
+
+const (
+	ImportActionInstall        ImportAction = "install"
+	ImportActionUpgrade        ImportAction = "upgrade"
+	ImportActionCreateInstance ImportAction = "create-instance"
+)
+
+// ImportChange is a single change required for importing an [ExportDocument].
+type ImportChange struct {
+	Action      ImportAction
+	ExtensionId string
+	// Version is the version to install or upgrade to or the version of the new instance.
+	Version string
+	// InstalledVersion is the version installed before an upgrade.
+	InstalledVersion string
+	// InstanceName is the name of the new instance.
+	InstanceName string
+	// MissingSecrets contains the names of secret parameters of the new instance that were not supplied.
+	MissingSecrets []string
+	// Incomplete is true if the parameters of the new instance are unknown, see [ExportedInstance.Incomplete].
+	Incomplete bool
+	parameters []ParameterValue
+}
+
+// ImportPlan contains the changes required for importing an [ExportDocument] in the order they are applied.
+// Extensions and instances that already exist are not changed.
+type ImportPlan struct {
+	Changes []*ImportChange
+}
+
+// ImportResult is the outcome of importing an [ExportDocument].
+type ImportResult struct {
+	// Changes contains the outcome of all planned changes in the order they were applied.
+	Changes []*ImportChangeResult
+}
+
+// ImportChangeResult is the outcome of applying a single [ImportChange].
+type ImportChangeResult struct {
+	*ImportChange
+	// Applied is true if the change was committed successfully.
+	Applied bool
+	// Error is set if the change failed. Changes after a failed change are not applied.
+	Error error
+	// Warnings for the user, e.g. because a deprecated version was installed.
+	Warnings []string
+}
+
+func (c *transactionControllerImpl) ExportInstallations(ctx context.Context, db *sql.DB) (*ExportDocument, error) {
+	installations, err := c.GetInstalledExtensions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	document := &ExportDocument{FormatVersion: exportFormatVersion, Extensions: nil, Warnings: nil}
+	for _, installation := range sortByDependencies(installations) {
+		extension, warnings, err := c.exportExtension(ctx, db, installation)
+		if err != nil {
+			return nil, err
+		}
+		document.Extensions = append(document.Extensions, *extension)
+		document.Warnings = append(document.Warnings, warnings...)
+	}
+	log.Infof("Exported %d installed extensions", len(document.Extensions))
+	return document, nil
+}
+
+func (c *transactionControllerImpl) exportExtension(ctx context.Context, db *sql.DB, installation *extensionAPI.JsExtInstallation) (*ExportedExtension, []string, error) {
+	instances, err := c.FindInstances(ctx, db, installation.ID, installation.Version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find instances of extension %q: %w", installation.ID, err)
+	}
+	extension := &ExportedExtension{ExtensionId: installation.ID, Name: installation.Name, Version: installation.Version, Instances: nil}
+	if len(instances) == 0 {
+		return extension, nil, nil
+	}
+	creations, err := c.findInstanceCreations(ctx, db, installation.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	definitions, err := c.GetParameterDefinitions(ctx, db, installation.ID, installation.Version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get parameter definitions of extension %q: %w", installation.ID, err)
+	}
+	var warnings []string
+	for _, instance := range instances {
+		entry, ok := creations[instance.Id]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("Parameters of instance %q of extension %q are not found in the audit log, the instance must be created manually", instance.Name, installation.ID))
+			extension.Instances = append(extension.Instances, ExportedInstance{Name: instance.Name, Parameters: []ParameterValue{}, SecretParameters: nil, Incomplete: true})
+			continue
+		}
+		extension.Instances = append(extension.Instances, exportInstance(instance, entry, definitions))
+	}
+	return extension, warnings, nil
+}
+
+// findInstanceCreations returns the latest successful creation of each instance of the given extension from the audit log.
+func (c *transactionControllerImpl) findInstanceCreations(ctx context.Context, db *sql.DB, extensionId string) (map[string]AuditEntry, error) {
+	//nolint:exhaustruct // Empty fields match all entries
+	entries, err := c.GetAuditLog(ctx, db, AuditLogFilter{ExtensionId: extensionId, Operation: AuditOperationCreateInstance, Outcome: AuditOutcomeSuccess})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read instance parameters of extension %q from audit log: %w", extensionId, err)
+	}
+	creations := make(map[string]AuditEntry)
+	for _, entry := range entries {
+		if _, exists := creations[entry.InstanceId]; !exists {
+			creations[entry.InstanceId] = entry
+		}
+	}
+	return creations, nil
+}
+
+// exportInstance omits the values of parameters defined as secret, which are redacted in the audit log.
+func exportInstance(instance *extensionAPI.JsExtInstance, entry AuditEntry, definitions []parameterValidator.ParameterDefinition) ExportedInstance {
+	exported := ExportedInstance{Name: instance.Name, Parameters: []ParameterValue{}, SecretParameters: nil, Incomplete: false}
+	for _, parameter := range entry.Parameters {
+		if isSecretParameter(definitions, parameter.Name) {
+			exported.SecretParameters = append(exported.SecretParameters, parameter.Name)
+		} else {
+			exported.Parameters = append(exported.Parameters, parameter)
+		}
+	}
+	return exported
+}
+
+// sortByDependencies orders the installations so that dependencies come before the extensions that require them.
+// Apart from that the order is unchanged.
+func sortByDependencies(installations []*extensionAPI.JsExtInstallation) []*extensionAPI.JsExtInstallation {
+	installed := make(map[string]*extensionAPI.JsExtInstallation, len(installations))
+	for _, installation := range installations {
+		installed[installation.ID] = installation
+	}
+	sorted := make([]*extensionAPI.JsExtInstallation, 0, len(installations))
+	visited := make(map[string]bool, len(installations))
+	var visit func(installation *extensionAPI.JsExtInstallation)
+	visit = func(installation *extensionAPI.JsExtInstallation) {
+		if visited[installation.ID] {
+			return
+		}
+		visited[installation.ID] = true
+		for _, dependency := range installation.Dependencies {
+			if dependencyInstallation, ok := installed[dependency.ExtensionId]; ok {
+				visit(dependencyInstallation)
+			}
+		}
+		sorted = append(sorted, installation)
+	}
+	for _, installation := range installations {
+		visit(installation)
+	}
+	return sorted
+}
+
+func (c *transactionControllerImpl) PreviewImport(ctx context.Context, db *sql.DB, document *ExportDocument, options ImportOptions) (*ImportPlan, error) {
+	if err := validateExportDocument(document); err != nil {
+		return nil, err
+	}
+	installations, err := c.GetInstalledExtensions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	plan := &ImportPlan{Changes: nil}
+	for _, extension := range document.Extensions {
+		changes, err := c.planExtensionImport(ctx, db, extension, findInstallation(installations, extension.ExtensionId), options.Secrets)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, changes...)
+	}
+	return plan, nil
+}
+
+func validateExportDocument(document *ExportDocument) error {
+	if document == nil {
+		return apiErrors.NewBadRequestErrorF("missing export document")
+	}
+	if document.FormatVersion != exportFormatVersion {
+		return apiErrors.NewBadRequestErrorF("unsupported export format version %d, expected %d", document.FormatVersion, exportFormatVersion)
+	}
+	for _, extension := range document.Extensions {
+		if extension.ExtensionId == "" || extension.Version == "" {
+			return apiErrors.NewBadRequestErrorF("extension ID and version are required for all extensions in the export document")
+		}
+	}
+	return nil
+}
+
+func findInstallation(installations []*extensionAPI.JsExtInstallation, extensionId string) *extensionAPI.JsExtInstallation {
+	for _, installation := range installations {
+		if installation.ID == extensionId {
+			return installation
+		}
+	}
+	return nil
+}
+
+func (c *transactionControllerImpl) planExtensionImport(ctx context.Context, db *sql.DB, extension ExportedExtension, installation *extensionAPI.JsExtInstallation, secrets []ImportSecret) ([]*ImportChange, error) {
+	var changes []*ImportChange
+	var existingInstances []*extensionAPI.JsExtInstance
+	if installation == nil {
+		changes = append(changes, &ImportChange{Action: ImportActionInstall, ExtensionId: extension.ExtensionId, Version: extension.Version})
+	} else {
+		if installation.Version != extension.Version {
+			changes = append(changes, &ImportChange{Action: ImportActionUpgrade, ExtensionId: extension.ExtensionId, Version: extension.Version,
+				InstalledVersion: installation.Version})
+		}
+		var err error
+		existingInstances, err = c.FindInstances(ctx, db, installation.ID, installation.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find instances of extension %q: %w", installation.ID, err)
+		}
+	}
+	for _, instance := range extension.Instances {
+		if containsInstance(existingInstances, instance.Name) {
+			continue
+		}
+		parameters, missingSecrets := addSecrets(extension.ExtensionId, instance, secrets)
+		changes = append(changes, &ImportChange{Action: ImportActionCreateInstance, ExtensionId: extension.ExtensionId, Version: extension.Version,
+			InstanceName: instance.Name, MissingSecrets: missingSecrets, Incomplete: instance.Incomplete, parameters: parameters})
+	}
+	return changes, nil
+}
+
+func containsInstance(instances []*extensionAPI.JsExtInstance, name string) bool {
+	return slices.ContainsFunc(instances, func(instance *extensionAPI.JsExtInstance) bool { return instance.Name == name })
+}
+
+// addSecrets returns the parameters of the instance including the supplied secrets and the names of secrets that are missing.
+func addSecrets(extensionId string, instance ExportedInstance, secrets []ImportSecret) ([]ParameterValue, []string) {
+	parameters := slices.Clone(instance.Parameters)
+	var missingSecrets []string
+	for _, name := range instance.SecretParameters {
+		index := slices.IndexFunc(secrets, func(secret ImportSecret) bool {
+			return secret.ExtensionId == extensionId && secret.InstanceName == instance.Name && secret.Name == name
+		})
+		if index < 0 {
+			missingSecrets = append(missingSecrets, name)
+			continue
+		}
+		parameters = append(parameters, ParameterValue{Name: name, Value: secrets[index].Value})
+	}
+	return parameters, missingSecrets
+}
+
+func (c *transactionControllerImpl) ImportInstallations(ctx context.Context, db *sql.DB, document *ExportDocument, options ImportOptions) (*ImportResult, error) {
+	plan, err := c.PreviewImport(ctx, db, document, options)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMissingSecrets(plan); err != nil {
+		return nil, err
+	}
+	if err := checkIncompleteInstances(plan); err != nil {
+		return nil, err
+	}
+	log.Infof("Importing %d changes", len(plan.Changes))
+	result := &ImportResult{Changes: make([]*ImportChangeResult, 0, len(plan.Changes))}
+	failed := false
+	for _, change := range plan.Changes {
+		changeResult := &ImportChangeResult{ImportChange: change, Applied: false, Error: nil, Warnings: nil}
+		result.Changes = append(result.Changes, changeResult)
+		if failed {
+			continue
+		}
+		changeResult.Warnings, changeResult.Error = c.applyImportChange(ctx, db, change, options)
+		if changeResult.Error != nil {
+			log.Warnf("Import of %s for extension %q failed, skipping remaining changes: %v", change.Action, change.ExtensionId, changeResult.Error)
+			failed = true
+			continue
+		}
+		changeResult.Applied = true
+	}
+	return result, nil
+}
+
+func checkMissingSecrets(plan *ImportPlan) error {
+	var missing []string
+	for _, change := range plan.Changes {
+		for _, name := range change.MissingSecrets {
+			missing = append(missing, fmt.Sprintf("%s/%s/%s", change.ExtensionId, change.InstanceName, name))
+		}
+	}
+	if len(missing) > 0 {
+		return apiErrors.NewBadRequestErrorF("missing values for secret parameters: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func checkIncompleteInstances(plan *ImportPlan) error {
+	var incomplete []string
+	for _, change := range plan.Changes {
+		if change.Incomplete {
+			incomplete = append(incomplete, fmt.Sprintf("%s/%s", change.ExtensionId, change.InstanceName))
+		}
+	}
+	if len(incomplete) > 0 {
+		return apiErrors.NewBadRequestErrorF("parameters of instances are unknown, create them manually before importing: %s", strings.Join(incomplete, ", "))
+	}
+	return nil
+}
+
+func (c *transactionControllerImpl) applyImportChange(ctx context.Context, db *sql.DB, change *ImportChange, options ImportOptions) ([]string, error) {
+	switch change.Action {
+	case ImportActionInstall:
+		result, err := c.InstallExtensionWithResult(ctx, db, change.ExtensionId, change.Version,
+			InstallOptions{InstallDependencies: false, AcceptLicenses: options.AcceptLicenses, Force: options.Force})
+		if err != nil {
+			return nil, err
+		}
+		return result.Warnings, nil
+	case ImportActionUpgrade:
+		_, err := c.UpgradeExtensionToVersion(ctx, db, change.ExtensionId, change.Version)
+		return nil, err
+	case ImportActionCreateInstance:
+		result, err := c.CreateInstanceWithOptions(ctx, db, change.ExtensionId, change.Version, change.parameters, CreateInstanceOptions{Force: options.Force})
+		if err != nil {
+			return nil, err
+		}
+		return result.Warnings, nil
+	default:
+		return nil, fmt.Errorf("unsupported import action %q", change.Action)
+	}
+}
@@ -0,0 +1,245 @@
+package extensionController
+
+import (
+	"testing"
+	"time"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/parameterValidator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSortByDependencies(t *testing.T) {
+	ext1 := &extensionAPI.JsExtInstallation{ID: "ext1", Dependencies: []extensionAPI.ExtensionDependency{{ExtensionId: "ext3"}, {ExtensionId: "missing"}}}
+	ext2 := &extensionAPI.JsExtInstallation{ID: "ext2"}
+	ext3 := &extensionAPI.JsExtInstallation{ID: "ext3", Dependencies: []extensionAPI.ExtensionDependency{{ExtensionId: "ext2"}}}
+	ext4 := &extensionAPI.JsExtInstallation{ID: "ext4"}
+	assert.Equal(t, []*extensionAPI.JsExtInstallation{ext2, ext3, ext1, ext4}, sortByDependencies([]*extensionAPI.JsExtInstallation{ext1, ext2, ext3, ext4}))
+}
+
+func TestSortByDependenciesWithCycle(t *testing.T) {
+	ext1 := &extensionAPI.JsExtInstallation{ID: "ext1", Dependencies: []extensionAPI.ExtensionDependency{{ExtensionId: "ext2"}}}
+	ext2 := &extensionAPI.JsExtInstallation{ID: "ext2", Dependencies: []extensionAPI.ExtensionDependency{{ExtensionId: "ext1"}}}
+	assert.Equal(t, []*extensionAPI.JsExtInstallation{ext2, ext1}, sortByDependencies([]*extensionAPI.JsExtInstallation{ext1, ext2}))
+}
+
+// ExportInstallations
+
+func (suite *extCtrlUnitTestSuite) TestExportInstallations() {
+	suite.mockCtrl.On("GetAllInstallations", mock.Anything).Return([]*extensionAPI.JsExtInstallation{
+		{ID: "ext1", Name: "Extension 1", Version: "1.0.0", Dependencies: []extensionAPI.ExtensionDependency{{ExtensionId: "ext2"}}},
+		{ID: "ext2", Name: "Extension 2", Version: "2.0.0"}}, nil)
+	suite.mockCtrl.On("FindInstances", mock.Anything, "ext2", "2.0.0").Return([]*extensionAPI.JsExtInstance{}, nil)
+	suite.mockCtrl.On("FindInstances", mock.Anything, "ext1", "1.0.0").Return([]*extensionAPI.JsExtInstance{{Id: "id1", Name: "inst1"}, {Id: "id2", Name: "inst2"}}, nil)
+	suite.mockCtrl.On("GetParameterDefinitions", "ext1", "1.0.0").Return(exportTestParameterDefinitions(), nil)
+	suite.auditLogMock.findResult = []AuditEntry{
+		{Timestamp: time.Now(), InstanceId: "id1", Parameters: []ParameterValue{{Name: "host", Value: "new-host"}, {Name: "mask", Value: "***"}, {Name: "password", Value: redactedValue}}},
+		{Timestamp: time.Now().Add(-time.Hour), InstanceId: "id1", Parameters: []ParameterValue{{Name: "host", Value: "old-host"}}}}
+	for range 5 {
+		suite.dbMock.ExpectBegin()
+		suite.dbMock.ExpectRollback()
+	}
+	document, err := suite.ctrl.ExportInstallations(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Equal(&ExportDocument{FormatVersion: 1, Extensions: []ExportedExtension{
+		{ExtensionId: "ext2", Name: "Extension 2", Version: "2.0.0"},
+		{ExtensionId: "ext1", Name: "Extension 1", Version: "1.0.0", Instances: []ExportedInstance{
+			{Name: "inst1", Parameters: []ParameterValue{{Name: "host", Value: "new-host"}, {Name: "mask", Value: "***"}}, SecretParameters: []string{"password"}},
+			{Name: "inst2", Parameters: []ParameterValue{}, Incomplete: true}}}},
+		Warnings: []string{`Parameters of instance "inst2" of extension "ext1" are not found in the audit log, the instance must be created manually`}}, document)
+	suite.Equal(AuditLogFilter{ExtensionId: "ext1", Operation: AuditOperationCreateInstance, Outcome: AuditOutcomeSuccess}, suite.auditLogMock.lastFilter)
+}
+
+func exportTestParameterDefinitions() []parameterValidator.ParameterDefinition {
+	return []parameterValidator.ParameterDefinition{
+		{Id: "host", Name: "Host", RawDefinition: map[string]any{"id": "host"}},
+		{Id: "mask", Name: "Mask", RawDefinition: map[string]any{"id": "mask", "secret": false}},
+		{Id: "password", Name: "Password", RawDefinition: map[string]any{"id": "password", "secret": true}}}
+}
+
+func (suite *extCtrlUnitTestSuite) TestExportInstallationsFailsGettingParameterDefinitions() {
+	suite.mockCtrl.On("GetAllInstallations", mock.Anything).Return([]*extensionAPI.JsExtInstallation{{ID: "ext1", Name: "Extension 1", Version: "1.0.0"}}, nil)
+	suite.mockCtrl.On("FindInstances", mock.Anything, "ext1", "1.0.0").Return([]*extensionAPI.JsExtInstance{{Id: "id1", Name: "inst1"}}, nil)
+	suite.mockCtrl.On("GetParameterDefinitions", "ext1", "1.0.0").Return(nil, mockError)
+	for range 4 {
+		suite.dbMock.ExpectBegin()
+		suite.dbMock.ExpectRollback()
+	}
+	document, err := suite.ctrl.ExportInstallations(mockContext(), suite.db)
+	suite.Require().EqualError(err, `failed to get parameter definitions of extension "ext1": mock error`)
+	suite.Nil(document)
+}
+
+func (suite *extCtrlUnitTestSuite) TestExportInstallationsFailsFindingInstallations() {
+	suite.mockCtrl.On("GetAllInstallations", mock.Anything).Return(nil, mockError)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	document, err := suite.ctrl.ExportInstallations(mockContext(), suite.db)
+	suite.Require().EqualError(err, mockErrorMsg)
+	suite.Nil(document)
+}
+
+func (suite *extCtrlUnitTestSuite) TestExportInstallationsFailsFindingInstances() {
+	suite.mockCtrl.On("GetAllInstallations", mock.Anything).Return([]*extensionAPI.JsExtInstallation{{ID: "ext1", Name: "Extension 1", Version: "1.0.0"}}, nil)
+	suite.mockCtrl.On("FindInstances", mock.Anything, "ext1", "1.0.0").Return(nil, mockError)
+	for range 2 {
+		suite.dbMock.ExpectBegin()
+		suite.dbMock.ExpectRollback()
+	}
+	document, err := suite.ctrl.ExportInstallations(mockContext(), suite.db)
+	suite.Require().EqualError(err, `failed to find instances of extension "ext1": mock error`)
+	suite.Nil(document)
+}
+
+func (suite *extCtrlUnitTestSuite) TestExportInstallationsFailsReadingAuditLog() {
+	suite.mockCtrl.On("GetAllInstallations", mock.Anything).Return([]*extensionAPI.JsExtInstallation{{ID: "ext1", Name: "Extension 1", Version: "1.0.0"}}, nil)
+	suite.mockCtrl.On("FindInstances", mock.Anything, "ext1", "1.0.0").Return([]*extensionAPI.JsExtInstance{{Id: "id1", Name: "inst1"}}, nil)
+	suite.auditLogMock.findErr = mockError
+	for range 3 {
+		suite.dbMock.ExpectBegin()
+		suite.dbMock.ExpectRollback()
+	}
+	document, err := suite.ctrl.ExportInstallations(mockContext(), suite.db)
+	suite.Require().EqualError(err, `failed to read instance parameters of extension "ext1" from audit log: mock error`)
+	suite.Nil(document)
+}
+
+// PreviewImport
+
+func importTestDocument() *ExportDocument {
+	return &ExportDocument{FormatVersion: 1, Extensions: []ExportedExtension{
+		{ExtensionId: "ext2", Version: "2.0.0", Instances: []ExportedInstance{
+			{Name: "a", Parameters: []ParameterValue{{Name: "host", Value: "h"}}, SecretParameters: []string{"password"}}}},
+		{ExtensionId: "ext1", Version: "1.0.0", Instances: []ExportedInstance{
+			{Name: "existing", Parameters: []ParameterValue{}},
+			{Name: "b", Parameters: []ParameterValue{}, SecretParameters: []string{"token"}}}}}}
+}
+
+func (suite *extCtrlUnitTestSuite) simulateImportTarget() {
+	suite.mockCtrl.On("GetAllInstallations", mock.Anything).Return([]*extensionAPI.JsExtInstallation{{ID: "ext1", Name: "Extension 1", Version: "0.9.0"}}, nil)
+	suite.mockCtrl.On("FindInstances", mock.Anything, "ext1", "0.9.0").Return([]*extensionAPI.JsExtInstance{{Id: "id", Name: "existing"}}, nil)
+	for range 2 {
+		suite.dbMock.ExpectBegin()
+		suite.dbMock.ExpectRollback()
+	}
+}
+
+func (suite *extCtrlUnitTestSuite) TestPreviewImport() {
+	suite.simulateImportTarget()
+	plan, err := suite.ctrl.PreviewImport(mockContext(), suite.db, importTestDocument(),
+		ImportOptions{Secrets: []ImportSecret{{ExtensionId: "ext2", InstanceName: "a", Name: "password", Value: "secret"}}})
+	suite.Require().NoError(err)
+	suite.Equal(&ImportPlan{Changes: []*ImportChange{
+		{Action: ImportActionInstall, ExtensionId: "ext2", Version: "2.0.0"},
+		{Action: ImportActionCreateInstance, ExtensionId: "ext2", Version: "2.0.0", InstanceName: "a",
+			parameters: []ParameterValue{{Name: "host", Value: "h"}, {Name: "password", Value: "secret"}}},
+		{Action: ImportActionUpgrade, ExtensionId: "ext1", Version: "1.0.0", InstalledVersion: "0.9.0"},
+		{Action: ImportActionCreateInstance, ExtensionId: "ext1", Version: "1.0.0", InstanceName: "b", MissingSecrets: []string{"token"},
+			parameters: []ParameterValue{}}}}, plan)
+}
+
+func (suite *extCtrlUnitTestSuite) TestPreviewImportInvalidDocument() {
+	var tests = []struct {
+		name          string
+		document      *ExportDocument
+		expectedError string
+	}{
+		{name: "missing document", document: nil, expectedError: "missing export document"},
+		{name: "wrong version", document: &ExportDocument{FormatVersion: 2}, expectedError: "unsupported export format version 2, expected 1"},
+		{name: "missing version", document: &ExportDocument{FormatVersion: 1, Extensions: []ExportedExtension{{ExtensionId: "ext"}}},
+			expectedError: "extension ID and version are required for all extensions in the export document"},
+	}
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			plan, err := suite.ctrl.PreviewImport(mockContext(), suite.db, test.document, ImportOptions{})
+			suite.Require().EqualError(err, test.expectedError)
+			suite.Equal(400, apiErrors.UnwrapAPIError(err).Status)
+			suite.Nil(plan)
+		})
+	}
+}
+
+// ImportInstallations
+
+func (suite *extCtrlUnitTestSuite) TestImportFailsForMissingSecrets() {
+	suite.simulateImportTarget()
+	result, err := suite.ctrl.ImportInstallations(mockContext(), suite.db, importTestDocument(), ImportOptions{})
+	suite.Require().EqualError(err, "missing values for secret parameters: ext2/a/password, ext1/b/token")
+	suite.Nil(result)
+}
+
+func (suite *extCtrlUnitTestSuite) TestPreviewImportIncludesIncompleteInstance() {
+	suite.simulateImportTarget()
+	document := &ExportDocument{FormatVersion: 1, Extensions: []ExportedExtension{
+		{ExtensionId: "ext1", Version: "0.9.0", Instances: []ExportedInstance{{Name: "unknown", Parameters: []ParameterValue{}, Incomplete: true}}}}}
+	plan, err := suite.ctrl.PreviewImport(mockContext(), suite.db, document, ImportOptions{})
+	suite.Require().NoError(err)
+	suite.Equal(&ImportPlan{Changes: []*ImportChange{{Action: ImportActionCreateInstance, ExtensionId: "ext1", Version: "0.9.0", InstanceName: "unknown",
+		Incomplete: true, parameters: []ParameterValue{}}}}, plan)
+}
+
+func (suite *extCtrlUnitTestSuite) TestImportFailsForIncompleteInstances() {
+	suite.simulateImportTarget()
+	document := &ExportDocument{FormatVersion: 1, Extensions: []ExportedExtension{
+		{ExtensionId: "ext1", Version: "0.9.0", Instances: []ExportedInstance{{Name: "unknown", Parameters: []ParameterValue{}, Incomplete: true}}}}}
+	result, err := suite.ctrl.ImportInstallations(mockContext(), suite.db, document, ImportOptions{})
+	suite.Require().EqualError(err, "parameters of instances are unknown, create them manually before importing: ext1/unknown")
+	suite.Equal(400, apiErrors.UnwrapAPIError(err).Status)
+	suite.Nil(result)
+}
+
+func (suite *extCtrlUnitTestSuite) TestImportSkipsExistingIncompleteInstance() {
+	suite.simulateImportTarget()
+	document := &ExportDocument{FormatVersion: 1, Extensions: []ExportedExtension{
+		{ExtensionId: "ext1", Version: "0.9.0", Instances: []ExportedInstance{{Name: "existing", Parameters: []ParameterValue{}, Incomplete: true}}}}}
+	result, err := suite.ctrl.ImportInstallations(mockContext(), suite.db, document, ImportOptions{})
+	suite.Require().NoError(err)
+	suite.Empty(result.Changes)
+}
+
+func (suite *extCtrlUnitTestSuite) TestImportInstallations() {
+	suite.simulateImportTarget()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "ext2", "2.0.0", InstallOptions{InstallDependencies: false, AcceptLicenses: true, Force: false}).Return(nil)
+	suite.mockCtrl.On("GetParameterDefinitions", "ext2", "2.0.0").Return(nil, nil)
+	suite.mockCtrl.On("CreateInstance", mock.Anything, "ext2", "2.0.0", []ParameterValue{{Name: "host", Value: "h"}, {Name: "password", Value: "secret"}}, CreateInstanceOptions{Force: false}).
+		Return(&extensionAPI.JsExtInstance{Id: "a-id", Name: "a"}, nil)
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "ext1", "1.0.0").Return(nil, mockError)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectCommit()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectCommit()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	result, err := suite.ctrl.ImportInstallations(mockContext(), suite.db, importTestDocument(), ImportOptions{AcceptLicenses: true, Secrets: []ImportSecret{
+		{ExtensionId: "ext2", InstanceName: "a", Name: "password", Value: "secret"},
+		{ExtensionId: "ext1", InstanceName: "b", Name: "token", Value: "secret-token"}}})
+	suite.Require().NoError(err)
+	suite.Require().Len(result.Changes, 4)
+	suite.assertImportChangeResult(result.Changes[0], ImportActionInstall, true, "")
+	suite.assertImportChangeResult(result.Changes[1], ImportActionCreateInstance, true, "")
+	suite.assertImportChangeResult(result.Changes[2], ImportActionUpgrade, false, mockErrorMsg)
+	suite.assertImportChangeResult(result.Changes[3], ImportActionCreateInstance, false, "")
+	suite.Len(suite.auditLogMock.written, 2)
+}
+
+func (suite *extCtrlUnitTestSuite) TestImportInstallationsWithoutChanges() {
+	suite.simulateImportTarget()
+	document := &ExportDocument{FormatVersion: 1, Extensions: []ExportedExtension{
+		{ExtensionId: "ext1", Version: "0.9.0", Instances: []ExportedInstance{{Name: "existing", Parameters: []ParameterValue{}}}}}}
+	result, err := suite.ctrl.ImportInstallations(mockContext(), suite.db, document, ImportOptions{})
+	suite.Require().NoError(err)
+	suite.Empty(result.Changes)
+}
+
+func (suite *extCtrlUnitTestSuite) assertImportChangeResult(result *ImportChangeResult, expectedAction ImportAction, expectedApplied bool, expectedError string) {
+	suite.T().Helper()
+	suite.Equal(expectedAction, result.Action)
+	suite.Equal(expectedApplied, result.Applied)
+	if expectedError == "" {
+		suite.NoError(result.Error)
+	} else {
+		suite.EqualError(result.Error, expectedError)
+	}
+}
@@ -0,0 +1,102 @@
+package extensionController
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+)
+
+// licenseAcceptanceTable is the name of the table in the extension schema that records accepted licenses for audit.
+const licenseAcceptanceTable = "EXTENSION_LICENSE_ACCEPTANCE"
+
+// acceptLicenses verifies that the user accepted the licenses of all files of the extension that require a license agreement
+// and records the acceptance in the extension schema.
+func (c *controllerImpl) acceptLicenses(txCtx *transaction.TransactionContext, extension *extensionAPI.JsExtension, extensionVersion string, options InstallOptions) error {
+	uploads := getUploadsRequiringLicenseAgreement(extension.BucketFsUploads)
+	if len(uploads) == 0 {
+		return nil
+	}
+	if !options.AcceptLicenses {
+		return licenseNotAcceptedError(extension.Id, uploads)
+	}
+	return c.recordLicenseAcceptance(txCtx, extension.Id, extensionVersion, uploads)
+}
+
+// RecordLicenseAcceptance records that the user accepted the licenses of the given files that require a license agreement
+// for the latest version of the extension. The extension schema is created if necessary.
+func (c *controllerImpl) RecordLicenseAcceptance(txCtx *transaction.TransactionContext, extensionId string, uploads []extensionAPI.BucketFsUpload) error {
+	uploads = getUploadsRequiringLicenseAgreement(uploads)
+	if len(uploads) == 0 {
+		return nil
+	}
+	extension, err := c.loadExtensionById(extensionId)
+	if err != nil {
+		return extensionLoadingFailed(extensionId, err)
+	}
+	if err := c.ensureSchemaExists(txCtx); err != nil {
+		return err
+	}
+	return c.recordLicenseAcceptance(txCtx, extensionId, findLatestVersion(extension.InstallableVersions), uploads)
+}
+
+func (c *controllerImpl) recordLicenseAcceptance(txCtx *transaction.TransactionContext, extensionId, extensionVersion string, uploads []extensionAPI.BucketFsUpload) error {
+	acceptedAt := time.Now().UTC()
+	insertAcceptances := func() error {
+		for _, upload := range uploads {
+			query := fmt.Sprintf(`INSERT INTO "%s"."%s" (EXTENSION_ID, EXTENSION_VERSION, FILE_NAME, LICENSE_URL, ACCEPTED_BY, ACCEPTED_AT) VALUES (?, ?, ?, ?, CURRENT_USER, ?)`,
+				c.extensionSchema(txCtx), licenseAcceptanceTable)
+			_, err := txCtx.GetTransaction().Exec(query, extensionId, extensionVersion, upload.BucketFsFilename, upload.LicenseURL, acceptedAt)
+			if err != nil {
+				return fmt.Errorf("failed to record license acceptance for file %q: %w", upload.BucketFsFilename, err)
+			}
+		}
+		return nil
+	}
+	createTable := func() error { return c.ensureLicenseAcceptanceTableExists(txCtx) }
+	if err := c.licenseTables.execWithTable(txCtx.GetContext(), c.extensionSchema(txCtx), createTable, insertAcceptances); err != nil {
+		return err
+	}
+	log.Infof("Recorded acceptance of %d license(s) for extension %q in version %q", len(uploads), extensionId, extensionVersion)
+	return nil
+}
+
+func getUploadsRequiringLicenseAgreement(uploads []extensionAPI.BucketFsUpload) []extensionAPI.BucketFsUpload {
+	var result []extensionAPI.BucketFsUpload
+	for _, upload := range uploads {
+		if upload.LicenseAgreementRequired {
+			result = append(result, upload)
+		}
+	}
+	return result
+}
+
+func licenseNotAcceptedError(extensionId string, uploads []extensionAPI.BucketFsUpload) error {
+	fileNames := make([]string, 0, len(uploads))
+	licenses := make([]map[string]any, 0, len(uploads))
+	for _, upload := range uploads {
+		fileNames = append(fileNames, upload.BucketFsFilename)
+		licenses = append(licenses, map[string]any{"fileName": upload.BucketFsFilename, "licenseUrl": upload.LicenseURL})
+	}
+	message := fmt.Sprintf("extension %q requires accepting the license of file(s) %s", extensionId, strings.Join(fileNames, ", "))
+	return apiErrors.NewDetailedAPIError(400, message, "", "", map[string]any{"licenses": licenses})
+}
+
+func (c *controllerImpl) ensureLicenseAcceptanceTableExists(txCtx *transaction.TransactionContext) error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS "%s"."%s" (
+	EXTENSION_ID VARCHAR(200) NOT NULL,
+	EXTENSION_VERSION VARCHAR(100) NOT NULL,
+	FILE_NAME VARCHAR(500) NOT NULL,
+	LICENSE_URL VARCHAR(2000),
+	ACCEPTED_BY VARCHAR(128) NOT NULL,
+	ACCEPTED_AT TIMESTAMP NOT NULL)`, c.extensionSchema(txCtx), licenseAcceptanceTable)
+	if _, err := txCtx.GetTransaction().Exec(query); err != nil {
+		return fmt.Errorf("failed to create license acceptance table: %w", err)
+	}
+	return nil
+}
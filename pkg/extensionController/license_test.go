@@ -0,0 +1,95 @@
+package extensionController
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+)
+
+const licensedExtensionId = "licensed.js"
+
+const licensedExtension = `(function(){
+	global.installedExtension = {
+		extension: {
+			name: "licensed",
+			installableVersions: [{name: "1.0.0", latest: true}],
+			bucketFsUploads: [
+				{name: "driver", bucketFsFilename: "driver.jar", fileSize: 10, licenseUrl: "https://example.com/license", licenseAgreementRequired: true},
+				{name: "adapter", bucketFsFilename: "adapter.jar", fileSize: 20}],
+			findInstallations: function(context, metadata) { return [] },
+			install: function(context, version) { context.sqlClient.execute("install licensed " + version) }
+		},
+		apiVersion: "0.2.0"
+	}
+})()`
+
+func (suite *ControllerUTestSuite) TestInstallFailsWithoutAcceptingLicense() {
+	suite.writeFile(licensedExtensionId, licensedExtension)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.InstallExtension(mockContext(), suite.db, licensedExtensionId, "1.0.0")
+	suite.assertApiError(err, 400, `extension "licensed.js" requires accepting the license of file(s) driver.jar`)
+	apiError, _ := apiErrors.AsAPIError(err)
+	suite.Equal(map[string]any{"licenses": []map[string]any{{"fileName": "driver.jar", "licenseUrl": "https://example.com/license"}}}, apiError.Details)
+}
+
+func (suite *ControllerUTestSuite) TestInstallRecordsAcceptedLicense() {
+	suite.writeFile(licensedExtensionId, licensedExtension)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "test"."EXTENSION_LICENSE_ACCEPTANCE"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "test"."EXTENSION_LICENSE_ACCEPTANCE" (EXTENSION_ID, EXTENSION_VERSION, FILE_NAME, LICENSE_URL, ACCEPTED_BY, ACCEPTED_AT) VALUES (?, ?, ?, ?, CURRENT_USER, ?)`)).
+		WithArgs(licensedExtensionId, "1.0.0", "driver.jar", "https://example.com/license", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec("install licensed 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	err := suite.controller.InstallExtensionWithOptions(mockContext(), suite.db, licensedExtensionId, "1.0.0", InstallOptions{InstallDependencies: false, AcceptLicenses: true})
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerUTestSuite) TestInstallWithoutLicenseAgreementDoesNotRecordAcceptance() {
+	suite.writeFile(licensedExtensionId, strings.Replace(licensedExtension, ", licenseAgreementRequired: true", "", 1))
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install licensed 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	err := suite.controller.InstallExtension(mockContext(), suite.db, licensedExtensionId, "1.0.0")
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerUTestSuite) TestRecordLicenseAcceptanceForUploadedFiles() {
+	suite.writeFile(licensedExtensionId, licensedExtension)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "test"."EXTENSION_LICENSE_ACCEPTANCE"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "test"."EXTENSION_LICENSE_ACCEPTANCE"`)).
+		WithArgs(licensedExtensionId, "1.0.0", "driver.jar", "https://example.com/license", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectRollback()
+	txCtx, err := suite.controller.beginTransaction(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	defer txCtx.Rollback()
+	err = suite.controller.controller.RecordLicenseAcceptance(txCtx, licensedExtensionId, []extensionAPI.BucketFsUpload{
+		{Name: "driver", BucketFsFilename: "driver.jar", FileSize: 10, LicenseURL: "https://example.com/license", LicenseAgreementRequired: true},
+		{Name: "adapter", BucketFsFilename: "adapter.jar", FileSize: 20}})
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerUTestSuite) TestRecordLicenseAcceptanceCreatesTableOnlyOnce() {
+	suite.writeFile(licensedExtensionId, licensedExtension)
+	uploads := []extensionAPI.BucketFsUpload{{Name: "driver", BucketFsFilename: "driver.jar", FileSize: 10, LicenseURL: "https://example.com/license", LicenseAgreementRequired: true}}
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "test"."EXTENSION_LICENSE_ACCEPTANCE"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "test"."EXTENSION_LICENSE_ACCEPTANCE"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "test"."EXTENSION_LICENSE_ACCEPTANCE"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectRollback()
+	txCtx, err := suite.controller.beginTransaction(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	defer txCtx.Rollback()
+	suite.Require().NoError(suite.controller.controller.RecordLicenseAcceptance(txCtx, licensedExtensionId, uploads))
+	suite.Require().NoError(suite.controller.controller.RecordLicenseAcceptance(txCtx, licensedExtensionId, uploads))
+}
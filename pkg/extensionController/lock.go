@@ -0,0 +1,356 @@
+package extensionController
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+)
+
+// defaultLockTimeout is the maximum time to wait for a lock if [ExtensionManagerConfig.LockTimeout] is not set.
+const defaultLockTimeout = 30 * time.Second
+
+// lockTable is the name of the table in the extension schema that contains locks of running operations
+// if [ExtensionManagerConfig.DatabaseLocking] is enabled.
+const lockTable = "EXTENSION_LOCKS"
+
+// dbLockExpiry is the time after which a lock row is considered stale if it was not renewed,
+// e.g. because the extension manager holding it crashed.
+const dbLockExpiry = 10 * time.Minute
+
+// dbLockRenewInterval is the interval in which a lock row is renewed while the operation holding it is running.
+const dbLockRenewInterval = dbLockExpiry / 5
+
+type databaseIdentifierKey struct{}
+
+// WithDatabaseIdentifier returns a new context that identifies the database used by operations,
+// e.g. "host:port". Mutating operations on the same extension in the same database are serialized.
+// Operations without database identifier are serialized with all other operations without identifier.
+func WithDatabaseIdentifier(ctx context.Context, databaseIdentifier string) context.Context {
+	return context.WithValue(ctx, databaseIdentifierKey{}, databaseIdentifier)
+}
+
+func getDatabaseIdentifier(ctx context.Context) string {
+	if identifier, ok := ctx.Value(databaseIdentifierKey{}).(string); ok {
+		return identifier
+	}
+	return ""
+}
+
+// lock verifies the extension schema selected in the context and locks the given extensions in this schema.
+func (c *transactionControllerImpl) lock(ctx context.Context, db *sql.DB, extensionIds ...string) (release func(), err error) {
+	if err := c.checkExtensionSchema(ctx); err != nil {
+		return nil, err
+	}
+	return c.locks.acquire(ctx, db, c.extensionSchema(ctx), extensionIds...)
+}
+
+// operationLock serializes mutating operations on the same extension in the same database.
+// It always uses an in-process lock and optionally a lock row in the database for multiple extension manager instances.
+type operationLock struct {
+	local   *keyedMutex
+	db      *dbLock // nil if database locking is disabled
+	timeout time.Duration
+}
+
+func newOperationLock(config ExtensionManagerConfig) *operationLock {
+	timeout := config.LockTimeout
+	if timeout <= 0 {
+		timeout = defaultLockTimeout
+	}
+	var db *dbLock
+	if config.DatabaseLocking {
+		db = newDbLock()
+	}
+	return &operationLock{local: newKeyedMutex(), db: db, timeout: timeout}
+}
+
+// acquire locks the given extensions in the given extension schema and returns a function that releases all locks.
+// If a lock is not available within the configured timeout, acquire returns an error with status 409 (Conflict).
+func (l *operationLock) acquire(ctx context.Context, db *sql.DB, schema string, extensionIds ...string) (release func(), err error) {
+	deadline := time.Now().Add(l.timeout)
+	// Lock in a stable order to avoid deadlocks between operations on multiple extensions
+	extensionIds = slices.Compact(slices.Sorted(slices.Values(extensionIds)))
+	var releaseFunctions []func()
+	release = func() {
+		for i := len(releaseFunctions) - 1; i >= 0; i-- {
+			releaseFunctions[i]()
+		}
+	}
+	for _, extensionId := range extensionIds {
+		releaseExtension, err := l.acquireExtension(ctx, db, schema, extensionId, deadline)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		releaseFunctions = append(releaseFunctions, releaseExtension)
+	}
+	return release, nil
+}
+
+func (l *operationLock) acquireExtension(ctx context.Context, db *sql.DB, schema string, extensionId string, deadline time.Time) (func(), error) {
+	localKey := getDatabaseIdentifier(ctx) + "/" + schema + "/" + extensionId
+	if err := l.local.lock(ctx, localKey, deadline); err != nil {
+		return nil, lockError(extensionId, err)
+	}
+	if l.db == nil {
+		return func() { l.local.unlock(localKey) }, nil
+	}
+	if err := l.db.lock(ctx, db, schema, extensionId, deadline); err != nil {
+		l.local.unlock(localKey)
+		return nil, lockError(extensionId, err)
+	}
+	stopRenewal := l.db.renewPeriodically(ctx, db, schema, extensionId)
+	return func() {
+		stopRenewal()
+		l.db.unlock(ctx, db, schema, extensionId)
+		l.local.unlock(localKey)
+	}, nil
+}
+
+var errLockTimeout = errors.New("lock timeout")
+
+func lockError(extensionId string, err error) error {
+	if errors.Is(err, errLockTimeout) {
+		return apiErrors.NewAPIErrorF(http.StatusConflict, "another operation on extension %q is in progress, please try again later", extensionId)
+	}
+	return fmt.Errorf("failed to lock extension %q: %w", extensionId, err)
+}
+
+// keyedMutex is an in-process lock for string keys that supports waiting with a deadline.
+type keyedMutex struct {
+	mutex sync.Mutex
+	locks map[string]*keyedMutexEntry
+}
+
+type keyedMutexEntry struct {
+	semaphore chan struct{}
+	users     int // Number of goroutines holding or waiting for the lock
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{mutex: sync.Mutex{}, locks: make(map[string]*keyedMutexEntry)}
+}
+
+func (m *keyedMutex) lock(ctx context.Context, key string, deadline time.Time) error {
+	entry := m.getEntry(key)
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case entry.semaphore <- struct{}{}:
+		return nil
+	case <-timer.C:
+		m.releaseEntry(key)
+		return errLockTimeout
+	case <-ctx.Done():
+		m.releaseEntry(key)
+		return ctx.Err()
+	}
+}
+
+func (m *keyedMutex) unlock(key string) {
+	m.mutex.Lock()
+	entry := m.locks[key]
+	m.mutex.Unlock()
+	<-entry.semaphore
+	m.releaseEntry(key)
+}
+
+func (m *keyedMutex) getEntry(key string) *keyedMutexEntry {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	entry, ok := m.locks[key]
+	if !ok {
+		entry = &keyedMutexEntry{semaphore: make(chan struct{}, 1), users: 0}
+		m.locks[key] = entry
+	}
+	entry.users++
+	return entry
+}
+
+func (m *keyedMutex) releaseEntry(key string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	entry := m.locks[key]
+	entry.users--
+	if entry.users == 0 {
+		delete(m.locks, key)
+	}
+}
+
+// dbLock uses rows in the lock table of the extension schema to serialize operations of multiple extension manager instances.
+// Each lock is inserted, renewed and deleted in a separate transaction, so that other instances see it while the operation is running.
+type dbLock struct {
+	owner         string
+	pollInterval  time.Duration
+	renewInterval time.Duration
+	// createdTables contains the database identifiers and schemas in which the lock table was already created,
+	// so that it is not created again for every attempt to acquire a lock.
+	createdTables sync.Map
+}
+
+func newDbLock() *dbLock {
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())
+	return &dbLock{owner: owner, pollInterval: 500 * time.Millisecond, renewInterval: dbLockRenewInterval, createdTables: sync.Map{}}
+}
+
+func (l *dbLock) lock(ctx context.Context, db *sql.DB, schema string, key string, deadline time.Time) error {
+	tableKey := getDatabaseIdentifier(ctx) + "/" + schema
+	if err := l.ensureTableExists(ctx, db, schema, tableKey); err != nil {
+		return err
+	}
+	for {
+		acquired, err := l.tryLock(ctx, db, schema, key)
+		if err != nil {
+			// The table may have been dropped, so create it again next time.
+			l.createdTables.Delete(tableKey)
+			return err
+		}
+		if acquired {
+			return nil
+		}
+		if time.Now().Add(l.pollInterval).After(deadline) {
+			return errLockTimeout
+		}
+		log.Debugf("Waiting for database lock of extension %q", key)
+		select {
+		case <-time.After(l.pollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// tryLock inserts the lock row if it does not exist yet and returns true if the lock was acquired.
+// Stale locks older than [dbLockExpiry] are removed first.
+func (l *dbLock) tryLock(ctx context.Context, db *sql.DB, schema string, key string) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction for lock: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	now := time.Now().UTC()
+	query := fmt.Sprintf(`DELETE FROM "%s"."%s" WHERE LOCK_KEY = ? AND EXPIRES_AT < ?`, schema, lockTable)
+	if _, err := tx.Exec(query, key, now); err != nil {
+		return false, fmt.Errorf("failed to remove stale lock: %w", err)
+	}
+	query = fmt.Sprintf(`INSERT INTO "%[1]s"."%[2]s" (LOCK_KEY, OWNER, ACQUIRED_AT, EXPIRES_AT) SELECT ?, ?, ?, ? FROM DUAL WHERE NOT EXISTS (SELECT 1 FROM "%[1]s"."%[2]s" WHERE LOCK_KEY = ?)`,
+		schema, lockTable)
+	result, err := tx.Exec(query, key, l.owner, now, now.Add(dbLockExpiry), key)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert lock: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to insert lock: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+	if err := tx.Commit(); err != nil {
+		if isTransactionConflict(err) {
+			log.Debugf("Transaction conflict while acquiring lock for %q: %v", key, err)
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to commit lock: %w", err)
+	}
+	return true, nil
+}
+
+// renewPeriodically extends the expiry of the lock row until the returned function is called,
+// so that other instances do not remove the lock of a long running operation as stale.
+func (l *dbLock) renewPeriodically(ctx context.Context, db *sql.DB, schema string, key string) (stop func()) {
+	stopped := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(l.renewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := l.renew(ctx, db, schema, key); err != nil {
+					log.Warnf("Failed to renew database lock of extension %q: %v", key, err)
+				}
+			case <-stopped:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stopped)
+		<-finished
+	}
+}
+
+func (l *dbLock) renew(ctx context.Context, db *sql.DB, schema string, key string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	query := fmt.Sprintf(`UPDATE "%s"."%s" SET EXPIRES_AT = ? WHERE LOCK_KEY = ? AND OWNER = ?`, schema, lockTable)
+	if _, err := tx.Exec(query, time.Now().UTC().Add(dbLockExpiry), key, l.owner); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (l *dbLock) unlock(ctx context.Context, db *sql.DB, schema string, key string) {
+	if err := l.deleteLock(ctx, db, schema, key); err != nil {
+		log.Warnf("Failed to release database lock of extension %q, it expires after %v: %v", key, dbLockExpiry, err)
+	}
+}
+
+func (l *dbLock) deleteLock(ctx context.Context, db *sql.DB, schema string, key string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	query := fmt.Sprintf(`DELETE FROM "%s"."%s" WHERE LOCK_KEY = ? AND OWNER = ?`, schema, lockTable)
+	if _, err := tx.Exec(query, key, l.owner); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ensureTableExists creates the lock table in a separate transaction unless it was already created for the given key.
+func (l *dbLock) ensureTableExists(ctx context.Context, db *sql.DB, schema string, tableKey string) error {
+	if _, created := l.createdTables.Load(tableKey); created {
+		return nil
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for lock table: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec(fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS "%s"`, schema)); err != nil {
+		return fmt.Errorf("failed to create schema for lock table: %w", err)
+	}
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS "%s"."%s" (
+	LOCK_KEY VARCHAR(200) NOT NULL,
+	OWNER VARCHAR(200) NOT NULL,
+	ACQUIRED_AT TIMESTAMP NOT NULL,
+	EXPIRES_AT TIMESTAMP NOT NULL)`, schema, lockTable)
+	if _, err := tx.Exec(query); err != nil {
+		return fmt.Errorf("failed to create lock table: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit lock table: %w", err)
+	}
+	l.createdTables.Store(tableKey, true)
+	return nil
+}
@@ -0,0 +1,307 @@
+package extensionController
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/stretchr/testify/suite"
+)
+
+type LockSuite struct {
+	suite.Suite
+	db     *sql.DB
+	dbMock sqlmock.Sqlmock
+}
+
+func TestLockSuite(t *testing.T) {
+	suite.Run(t, new(LockSuite))
+}
+
+const (
+	createLockSchemaRegexp = `CREATE SCHEMA IF NOT EXISTS "ext_schema"`
+	createLockTableRegexp  = `CREATE TABLE IF NOT EXISTS "ext_schema"\."EXTENSION_LOCKS"`
+	deleteStaleLockRegexp  = `DELETE FROM "ext_schema"\."EXTENSION_LOCKS" WHERE LOCK_KEY = \? AND EXPIRES_AT < \?`
+	insertLockRegexp       = `INSERT INTO "ext_schema"\."EXTENSION_LOCKS" \(LOCK_KEY, OWNER, ACQUIRED_AT, EXPIRES_AT\) SELECT`
+	deleteLock             = `DELETE FROM "ext_schema"."EXTENSION_LOCKS" WHERE LOCK_KEY = ? AND OWNER = ?`
+	renewLock              = `UPDATE "ext_schema"."EXTENSION_LOCKS" SET EXPIRES_AT = ? WHERE LOCK_KEY = ? AND OWNER = ?`
+)
+
+func (suite *LockSuite) SetupTest() {
+	db, dbMock, err := sqlmock.New()
+	suite.Require().NoError(err)
+	suite.db = db
+	suite.dbMock = dbMock
+	suite.dbMock.MatchExpectationsInOrder(true)
+}
+
+func (suite *LockSuite) AfterTest(suiteName, testName string) {
+	suite.NoError(suite.dbMock.ExpectationsWereMet())
+}
+
+func (suite *LockSuite) TestDefaultTimeout() {
+	suite.Equal(defaultLockTimeout, newOperationLock(ExtensionManagerConfig{}).timeout)
+}
+
+func (suite *LockSuite) TestAcquireAndRelease() {
+	lock := suite.createLocalLock(time.Second)
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	release()
+	release, err = lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	release()
+	suite.Empty(lock.local.locks)
+}
+
+func (suite *LockSuite) TestAcquireFailsWithConflictAfterTimeout() {
+	lock := suite.createLocalLock(50 * time.Millisecond)
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	defer release()
+	_, err = lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().EqualError(err, `another operation on extension "ext" is in progress, please try again later`)
+	suite.Equal(409, apiErrors.UnwrapAPIError(err).Status)
+}
+
+func (suite *LockSuite) TestAcquireWaitsForRelease() {
+	lock := suite.createLocalLock(time.Second)
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		release()
+	}()
+	release, err = lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	release()
+}
+
+func (suite *LockSuite) TestAcquireFailsWhenContextCancelled() {
+	lock := suite.createLocalLock(time.Second)
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	defer release()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = lock.acquire(ctx, suite.db, "ext_schema", "ext")
+	suite.Require().EqualError(err, `failed to lock extension "ext": context canceled`)
+}
+
+func (suite *LockSuite) TestDifferentExtensionsDoNotBlock() {
+	lock := suite.createLocalLock(50 * time.Millisecond)
+	release1, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext1")
+	suite.Require().NoError(err)
+	defer release1()
+	release2, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext2")
+	suite.Require().NoError(err)
+	release2()
+}
+
+func (suite *LockSuite) TestDifferentDatabasesDoNotBlock() {
+	lock := suite.createLocalLock(50 * time.Millisecond)
+	release1, err := lock.acquire(WithDatabaseIdentifier(context.Background(), "db1:8563"), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	defer release1()
+	release2, err := lock.acquire(WithDatabaseIdentifier(context.Background(), "db2:8563"), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	release2()
+}
+
+func (suite *LockSuite) TestAcquireMultipleReleasesAllOnConflict() {
+	lock := suite.createLocalLock(50 * time.Millisecond)
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext2")
+	suite.Require().NoError(err)
+	_, err = lock.acquire(context.Background(), suite.db, "ext_schema", "ext3", "ext1", "ext2")
+	suite.Require().EqualError(err, `another operation on extension "ext2" is in progress, please try again later`)
+	release()
+	release, err = lock.acquire(context.Background(), suite.db, "ext_schema", "ext1", "ext2", "ext3", "ext1")
+	suite.Require().NoError(err)
+	release()
+	suite.Empty(lock.local.locks)
+}
+
+func (suite *LockSuite) TestDatabaseLockAcquireAndRelease() {
+	lock := suite.createDatabaseLock(time.Second)
+	suite.expectCreateLockTable()
+	suite.expectTryLock(1)
+	suite.dbMock.ExpectCommit()
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	suite.expectDeleteLock()
+	release()
+}
+
+func (suite *LockSuite) TestDatabaseLockRetriesUntilAvailable() {
+	lock := suite.createDatabaseLock(time.Second)
+	suite.expectCreateLockTable()
+	suite.expectTryLock(0)
+	suite.dbMock.ExpectRollback()
+	suite.expectTryLock(1)
+	suite.dbMock.ExpectCommit()
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	suite.expectDeleteLock()
+	release()
+}
+
+func (suite *LockSuite) TestDatabaseLockRetriesAfterTransactionConflict() {
+	lock := suite.createDatabaseLock(time.Second)
+	suite.expectCreateLockTable()
+	suite.expectTryLock(1)
+	suite.dbMock.ExpectCommit().WillReturnError(errors.New("GlobalTransactionRollback msg: Transaction collision"))
+	suite.expectTryLock(1)
+	suite.dbMock.ExpectCommit()
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	suite.expectDeleteLock()
+	release()
+}
+
+func (suite *LockSuite) TestDatabaseLockFailsWithConflictAfterTimeout() {
+	lock := suite.createDatabaseLock(30 * time.Millisecond)
+	lock.db.pollInterval = time.Second
+	suite.expectCreateLockTable()
+	suite.expectTryLock(0)
+	suite.dbMock.ExpectRollback()
+	_, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().EqualError(err, `another operation on extension "ext" is in progress, please try again later`)
+	suite.Empty(lock.local.locks)
+}
+
+func (suite *LockSuite) TestDatabaseLockCreatesTableOnlyOnce() {
+	lock := suite.createDatabaseLock(time.Second)
+	suite.expectCreateLockTable()
+	suite.expectTryLock(1)
+	suite.dbMock.ExpectCommit()
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	suite.expectDeleteLock()
+	release()
+	suite.expectTryLock(1)
+	suite.dbMock.ExpectCommit()
+	release, err = lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	suite.expectDeleteLock()
+	release()
+}
+
+func (suite *LockSuite) TestDatabaseLockCreatesTableAgainAfterFailure() {
+	lock := suite.createDatabaseLock(time.Second)
+	suite.expectCreateLockTable()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(deleteStaleLockRegexp).WillReturnError(mockError)
+	suite.dbMock.ExpectRollback()
+	_, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().EqualError(err, `failed to lock extension "ext": failed to remove stale lock: mock error`)
+	suite.expectCreateLockTable()
+	suite.expectTryLock(1)
+	suite.dbMock.ExpectCommit()
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	suite.expectDeleteLock()
+	release()
+}
+
+func (suite *LockSuite) TestDatabaseLockRenewsLockWhileHeld() {
+	lock := suite.createDatabaseLock(time.Second)
+	lock.db.renewInterval = 50 * time.Millisecond
+	suite.expectCreateLockTable()
+	suite.expectTryLock(1)
+	suite.dbMock.ExpectCommit()
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(renewLock)).WithArgs(sqlmock.AnyArg(), "ext", "owner").WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectCommit()
+	suite.Eventually(func() bool { return suite.dbMock.ExpectationsWereMet() == nil }, time.Second, time.Millisecond)
+	suite.expectDeleteLock()
+	release()
+}
+
+func (suite *LockSuite) TestDatabaseLockRenewalFailureIsIgnored() {
+	lock := suite.createDatabaseLock(time.Second)
+	lock.db.renewInterval = 50 * time.Millisecond
+	suite.expectCreateLockTable()
+	suite.expectTryLock(1)
+	suite.dbMock.ExpectCommit()
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(renewLock)).WillReturnError(mockError)
+	suite.dbMock.ExpectRollback()
+	suite.Eventually(func() bool { return suite.dbMock.ExpectationsWereMet() == nil }, time.Second, time.Millisecond)
+	suite.expectDeleteLock()
+	release()
+	suite.Empty(lock.local.locks)
+}
+
+func (suite *LockSuite) TestDatabaseLockFailsCreatingTable() {
+	lock := suite.createDatabaseLock(time.Second)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(createLockSchemaRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(createLockTableRegexp).WillReturnError(mockError)
+	suite.dbMock.ExpectRollback()
+	_, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().EqualError(err, `failed to lock extension "ext": failed to create lock table: mock error`)
+	suite.Empty(lock.local.locks)
+}
+
+func (suite *LockSuite) TestDatabaseLockReleaseFailureIsIgnored() {
+	lock := suite.createDatabaseLock(time.Second)
+	suite.expectCreateLockTable()
+	suite.expectTryLock(1)
+	suite.dbMock.ExpectCommit()
+	release, err := lock.acquire(context.Background(), suite.db, "ext_schema", "ext")
+	suite.Require().NoError(err)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(deleteLock)).WillReturnError(mockError)
+	suite.dbMock.ExpectRollback()
+	release()
+	suite.Empty(lock.local.locks)
+}
+
+func (suite *LockSuite) createLocalLock(timeout time.Duration) *operationLock {
+	return newOperationLock(ExtensionManagerConfig{ExtensionSchema: "ext_schema", LockTimeout: timeout})
+}
+
+func (suite *LockSuite) createDatabaseLock(timeout time.Duration) *operationLock {
+	lock := newOperationLock(ExtensionManagerConfig{ExtensionSchema: "ext_schema", LockTimeout: timeout, DatabaseLocking: true})
+	lock.db.owner = "owner"
+	lock.db.pollInterval = 10 * time.Millisecond
+	return lock
+}
+
+func (suite *LockSuite) expectCreateLockTable() {
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(createLockSchemaRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(createLockTableRegexp).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+}
+
+func (suite *LockSuite) expectTryLock(rowsAffected int64) {
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(deleteStaleLockRegexp).WithArgs("ext", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec(insertLockRegexp).WithArgs("ext", "owner", sqlmock.AnyArg(), sqlmock.AnyArg(), "ext").WillReturnResult(sqlmock.NewResult(0, rowsAffected))
+}
+
+func (suite *LockSuite) expectDeleteLock() {
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(regexp.QuoteMeta(deleteLock)).WithArgs("ext", "owner").WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.dbMock.ExpectCommit()
+}
+
+func (suite *LockSuite) TestDifferentSchemasDoNotBlock() {
+	lock := suite.createLocalLock(50 * time.Millisecond)
+	release1, err := lock.acquire(context.Background(), suite.db, "schema1", "ext")
+	suite.Require().NoError(err)
+	defer release1()
+	release2, err := lock.acquire(context.Background(), suite.db, "schema2", "ext")
+	suite.Require().NoError(err)
+	release2()
+}
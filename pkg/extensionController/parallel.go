@@ -0,0 +1,29 @@
+package extensionController
+
+import "sync"
+
+// maxParallelExtensions limits the number of extensions that are loaded or queried concurrently.
+const maxParallelExtensions = 8
+
+// runParallel calls the given function for each index in [0, count) using at most maxParallelExtensions goroutines.
+// It returns the errors indexed like the input, so that callers can process results and errors in a deterministic order.
+func runParallel(count int, function func(index int) error) []error {
+	errs := make([]error, count)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(count, maxParallelExtensions) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				errs[index] = function(index)
+			}
+		}()
+	}
+	for index := range count {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,122 @@
+package extensionController
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunParallelReturnsErrorsInInputOrder(t *testing.T) {
+	errs := runParallel(20, func(index int) error {
+		time.Sleep(time.Duration(20-index) * time.Millisecond)
+		if index%3 == 0 {
+			return fmt.Errorf("error %d", index)
+		}
+		return nil
+	})
+	assert.Len(t, errs, 20)
+	for index, err := range errs {
+		if index%3 == 0 {
+			assert.EqualError(t, err, fmt.Sprintf("error %d", index))
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestRunParallelLimitsConcurrency(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	runParallel(50, func(index int) error {
+		current := running.Add(1)
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	})
+	assert.LessOrEqual(t, maxRunning.Load(), int32(maxParallelExtensions))
+	assert.Greater(t, maxRunning.Load(), int32(1))
+}
+
+func TestRunParallelWithoutItems(t *testing.T) {
+	assert.Empty(t, runParallel(0, func(index int) error { return nil }))
+}
+
+func findInstallationsTestExtension(findInstallations string) string {
+	return strings.Replace(`(function(){
+		global.installedExtension = {
+			extension: {
+				name: "Discovery Extension",
+				installableVersions: [{name: "1.0.0", latest: true}],
+				findInstallations: function(context, metadata) { $FIND_INSTALLATIONS$ }
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`, "$FIND_INSTALLATIONS$", findInstallations, 1)
+}
+
+func (suite *ControllerUTestSuite) TestGetAllInstallationsKeepsExtensionOrder() {
+	const extensionCount = 12
+	expected := make([]*extensionAPI.JsExtInstallation, 0, extensionCount)
+	for i := range extensionCount {
+		id := fmt.Sprintf("ext-%02d.js", i)
+		suite.writeFile(id, findInstallationsTestExtension(fmt.Sprintf(`return metadata.allScripts.rows.map(row => ({name: row.name + "-%d", version: "1.0.0"}))`, i)))
+		expected = append(expected, &extensionAPI.JsExtInstallation{ID: id, Name: fmt.Sprintf("script-%d", i), Version: "1.0.0"})
+	}
+	//nolint:exhaustruct // Non-exhaustive struct is fine here
+	suite.metaDataMock.SimulateExaAllScripts([]exaMetadata.ExaScriptRow{{Schema: "schema", Name: "script"}})
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	installations, err := suite.controller.GetInstalledExtensions(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Equal(expected, installations)
+}
+
+func (suite *ControllerUTestSuite) TestGetAllInstallationsSerializesDatabaseAccess() {
+	const extensionCount = 4
+	for i := range extensionCount {
+		suite.writeFile(fmt.Sprintf("ext-%d.js", i), findInstallationsTestExtension(`
+			const result = context.sqlClient.query("select version");
+			return [{name: "db", version: result.rows[0][0]}]`))
+	}
+	//nolint:exhaustruct // Non-exhaustive struct is fine here
+	suite.metaDataMock.SimulateExaAllScripts([]exaMetadata.ExaScriptRow{})
+	suite.dbMock.MatchExpectationsInOrder(false)
+	suite.dbMock.ExpectBegin()
+	for range extensionCount {
+		suite.dbMock.ExpectQuery("select version").WillReturnRows(sqlmock.NewRows([]string{"VERSION"}).AddRow("1.0.0"))
+	}
+	suite.dbMock.ExpectRollback()
+	installations, err := suite.controller.GetInstalledExtensions(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Len(installations, extensionCount)
+	for i, installation := range installations {
+		suite.Equal(fmt.Sprintf("ext-%d.js", i), installation.ID)
+		suite.Equal("1.0.0", installation.Version)
+	}
+}
+
+func (suite *ControllerUTestSuite) TestGetAllInstallationsReportsFirstFailingExtension() {
+	suite.writeFile("ext-0.js", findInstallationsTestExtension(`return []`))
+	suite.writeFile("ext-1.js", findInstallationsTestExtension(`throw new Error("failure 1")`))
+	suite.writeFile("ext-2.js", findInstallationsTestExtension(`throw new Error("failure 2")`))
+	//nolint:exhaustruct // Non-exhaustive struct is fine here
+	suite.metaDataMock.SimulateExaAllScripts([]exaMetadata.ExaScriptRow{})
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	installations, err := suite.controller.GetInstalledExtensions(mockContext(), suite.db)
+	suite.Require().ErrorContains(err, `failed to find installations for extension "Discovery Extension"`)
+	suite.Require().ErrorContains(err, "failure 1")
+	suite.Nil(installations)
+}
@@ -0,0 +1,38 @@
+package extensionController
+
+import (
+	"fmt"
+
+	"github.com/exasol/extension-manager/pkg/parameterValidator"
+)
+
+const (
+	ReadinessCheckRegistry           = "registry"
+	ReadinessCheckParameterValidator = "parameter-validator"
+)
+
+// ReadinessCheck is the result of checking a component required for serving requests.
+type ReadinessCheck struct {
+	Name    string // Name of the checked component, e.g. "registry"
+	Ready   bool
+	Message string // Details, e.g. the number of extensions or the cause of the failure
+}
+
+func (c *controllerImpl) CheckReadiness() []ReadinessCheck {
+	return []ReadinessCheck{c.checkRegistry(), checkParameterValidator()}
+}
+
+func (c *controllerImpl) checkRegistry() ReadinessCheck {
+	extensionIds, err := c.registry.FindExtensions()
+	if err != nil {
+		return ReadinessCheck{Name: ReadinessCheckRegistry, Ready: false, Message: fmt.Sprintf("failed to load registry index: %v", err)}
+	}
+	return ReadinessCheck{Name: ReadinessCheckRegistry, Ready: true, Message: fmt.Sprintf("found %d extensions", len(extensionIds))}
+}
+
+func checkParameterValidator() ReadinessCheck {
+	if _, err := parameterValidator.New(); err != nil {
+		return ReadinessCheck{Name: ReadinessCheckParameterValidator, Ready: false, Message: fmt.Sprintf("failed to create parameter validator: %v", err)}
+	}
+	return ReadinessCheck{Name: ReadinessCheckParameterValidator, Ready: true, Message: "initialized"}
+}
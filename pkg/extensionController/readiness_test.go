@@ -0,0 +1,24 @@
+package extensionController
+
+import (
+	"github.com/exasol/extension-manager/pkg/extensionController/registry"
+)
+
+func (suite *ControllerUTestSuite) TestCheckReadiness() {
+	suite.writeFile("ext1.js", "")
+	suite.writeFile("ext2.js", "")
+	suite.Equal([]ReadinessCheck{
+		{Name: ReadinessCheckRegistry, Ready: true, Message: "found 2 extensions"},
+		{Name: ReadinessCheckParameterValidator, Ready: true, Message: "initialized"}},
+		suite.controller.CheckReadiness())
+}
+
+func (suite *ControllerUTestSuite) TestCheckReadinessFailsLoadingRegistryIndex() {
+	suite.controller.controller.(*controllerImpl).registry = registry.NewRegistry("http://localhost:1/registry.json")
+	checks := suite.controller.CheckReadiness()
+	suite.Require().Len(checks, 2)
+	suite.Equal(ReadinessCheckRegistry, checks[0].Name)
+	suite.False(checks[0].Ready)
+	suite.Contains(checks[0].Message, `failed to load registry index: failed to load index from "http://localhost:1/registry.json"`)
+	suite.True(checks[1].Ready)
+}
@@ -6,21 +6,24 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/exasol/extension-manager/pkg/apiErrors"
 	"github.com/exasol/extension-manager/pkg/extensionController/registry/index"
+	"github.com/exasol/extension-manager/pkg/metrics"
 	log "github.com/sirupsen/logrus"
 )
 
 func newHttpRegistry(url string) Registry {
 	log.Debugf("Creating HTTP registry for %q", url)
-	return &httpRegistry{url: url, index: nil}
+	return &httpRegistry{url: url, index: nil, mutex: sync.Mutex{}}
 }
 
 type httpRegistry struct {
 	url   string
 	index *index.RegistryIndex
+	mutex sync.Mutex // Protects lazy loading of the index when extensions are read concurrently
 }
 
 /* [impl -> dsn~extension-registry~1] */
@@ -35,6 +38,8 @@ func (h *httpRegistry) FindExtensions() ([]string, error) {
 
 /* [impl -> dsn~extension-registry.cache~1]. */
 func (h *httpRegistry) getIndex() (*index.RegistryIndex, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
 	if h.index == nil {
 		index, err := loadIndex(h.url)
 		if err != nil {
@@ -45,8 +50,9 @@ func (h *httpRegistry) getIndex() (*index.RegistryIndex, error) {
 	return h.index, nil
 }
 
-func loadIndex(url string) (*index.RegistryIndex, error) {
+func loadIndex(url string) (result *index.RegistryIndex, errResult error) {
 	t0 := time.Now()
+	defer func() { metrics.ObserveRegistryFetch(metrics.RegistryResourceIndex, t0, errResult) }()
 	response, err := getResponse(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load index from %q: %w", url, err)
@@ -86,7 +92,9 @@ func (h *httpRegistry) ReadExtension(id string) (string, error) {
 		return "", apiErrors.NewNotFoundErrorF("extension %q not found", id)
 	}
 
+	t0 := time.Now()
 	extContent, err := getUrlContent(ext.URL)
+	metrics.ObserveRegistryFetch(metrics.RegistryResourceExtension, t0, err)
 	if err != nil {
 		return "", fmt.Errorf("failed to load extension %q: %w", id, err)
 	}
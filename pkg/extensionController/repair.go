@@ -0,0 +1,51 @@
+package extensionController
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+)
+
+func (c *controllerImpl) RepairExtension(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string) error {
+	extension, err := c.loadExtension(txCtx, extensionId)
+	if err != nil {
+		return extensionLoadingFailed(extensionId, err)
+	}
+	if err := c.verifyInstalledVersion(txCtx, extension, extensionVersion); err != nil {
+		return err
+	}
+	extensionContext := c.createExtensionContext(txCtx, extension)
+	if extension.SupportsRepair() {
+		err = extension.Repair(extensionContext, extensionVersion)
+	} else {
+		log.Debugf("Extension %q does not support repair, re-running install for version %q", extensionId, extensionVersion)
+		err = extension.Install(extensionContext, extensionVersion)
+	}
+	if err != nil {
+		return err
+	}
+	return c.verifyInstallation(txCtx, extension, extensionVersion, "repair")
+}
+
+// verifyInstalledVersion checks that findInstallations of the extension reports the given version.
+// This prevents repairing an extension that is not installed, bypassing license acceptance and dependencies.
+func (c *controllerImpl) verifyInstalledVersion(txCtx *transaction.TransactionContext, extension *extensionAPI.JsExtension, extensionVersion string) error {
+	metadata, err := c.metaDataReader.ReadMetadataTables(txCtx.GetTransaction(), c.extensionSchema(txCtx))
+	if err != nil {
+		return fmt.Errorf("failed to read metadata tables. Cause: %w", err)
+	}
+	installations, err := extension.FindInstallations(c.createExtensionContext(txCtx, extension), metadata)
+	if err != nil {
+		return apiErrors.NewAPIErrorWithCause(fmt.Sprintf("failed to find installations of extension %q", extension.Id), err)
+	}
+	for _, installation := range installations {
+		if installation.Version == extensionVersion {
+			return nil
+		}
+	}
+	return apiErrors.NewNotFoundErrorF("extension %q is not installed in version %q", extension.Id, extensionVersion)
+}
@@ -0,0 +1,81 @@
+package extensionController
+
+import (
+	"strings"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/stretchr/testify/mock"
+)
+
+const repairExtensionId = "repair.js"
+
+// repairTestExtension creates the JavaScript of an extension installed in version 1.0.0 with the given additional functions.
+func repairTestExtension(functions string) string {
+	return strings.Replace(`(function(){
+		global.installedExtension = {
+			extension: {
+				name: "repair",
+				installableVersions: [{name: "1.0.0", latest: true}],
+				findInstallations: function(context, metadata) { return [{name: "repair", version: "1.0.0"}] },
+				install: function(context, version) { context.sqlClient.execute("install " + version) },
+				$FUNCTIONS$
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`, "$FUNCTIONS$", functions, 1)
+}
+
+func (suite *ControllerUTestSuite) TestRepairCallsRepairFunction() {
+	suite.writeFile(repairExtensionId, repairTestExtension(`repair: function(context, version) { context.sqlClient.execute("repair " + version) }`))
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{})
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("repair 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	err := suite.controller.RepairExtension(mockContext(), suite.db, repairExtensionId, "1.0.0")
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerUTestSuite) TestRepairFallsBackToInstall() {
+	suite.writeFile(repairExtensionId, repairTestExtension(""))
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{})
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec("install 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	err := suite.controller.RepairExtension(mockContext(), suite.db, repairExtensionId, "1.0.0")
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerUTestSuite) TestRepairFailsRollsBack() {
+	suite.writeFile(repairExtensionId, repairTestExtension(`repair: function(context, version) { throw new Error("repair failed") }`))
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{})
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.RepairExtension(mockContext(), suite.db, repairExtensionId, "1.0.0")
+	suite.Require().ErrorContains(err, `failed to repair extension "repair.js": Error: repair failed`)
+}
+
+func (suite *ControllerUTestSuite) TestRepairFailsForVersionNotInstalled() {
+	suite.writeFile(repairExtensionId, repairTestExtension(""))
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{})
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.RepairExtension(mockContext(), suite.db, repairExtensionId, "0.9.0")
+	suite.assertApiError(err, 404, `extension "repair.js" is not installed in version "0.9.0"`)
+}
+
+func (suite *ControllerUTestSuite) TestRepairFailsReadingMetadata() {
+	suite.writeFile(repairExtensionId, repairTestExtension(""))
+	suite.metaDataMock.On("ReadMetadataTables", mock.Anything, EXTENSION_SCHEMA).Return(nil, mockError)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.RepairExtension(mockContext(), suite.db, repairExtensionId, "1.0.0")
+	suite.assertNonApiError(err, "failed to read metadata tables. Cause: "+mockErrorMsg)
+}
+
+func (suite *ControllerUTestSuite) TestRepairFailsForUnknownExtensionId() {
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.RepairExtension(mockContext(), suite.db, "unknown-extension-id", "1.0.0")
+	suite.Require().ErrorContains(err, `failed to load extension "unknown-extension-id"`)
+}
@@ -0,0 +1,65 @@
+package extensionController
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// retryPolicy defines how often an operation is retried after a transaction conflict.
+type retryPolicy struct {
+	maxAttempts    int           // Maximum number of attempts including the first one, values < 2 disable retries
+	initialBackoff time.Duration // Delay before the first retry, doubled for each further retry
+	maxBackoff     time.Duration // Upper bound for the delay between two attempts
+}
+
+var defaultRetryPolicy = retryPolicy{maxAttempts: 4, initialBackoff: 200 * time.Millisecond, maxBackoff: 2 * time.Second}
+
+// backoff returns the delay before the given retry, starting with 1 for the first retry.
+func (p retryPolicy) backoff(retry int) time.Duration {
+	delay := p.initialBackoff
+	for i := 1; i < retry && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.maxBackoff)
+}
+
+// isTransactionConflict returns true if the error was caused by a conflict with a concurrent transaction.
+// Exasol rolls back one of the conflicting transactions with error "GlobalTransactionRollback".
+func isTransactionConflict(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "GlobalTransactionRollback")
+}
+
+// runWithRetry runs the given operation and retries it after transaction conflicts if the extension allows this.
+// If includeDependencies is true, the operation may also install dependencies of the extension, so they must allow retries, too.
+// The operation must start a new transaction for each attempt.
+func (c *transactionControllerImpl) runWithRetry(ctx context.Context, entry *AuditEntry, includeDependencies bool, operation func() (started bool, err error)) (bool, error) {
+	for attempt := 1; ; attempt++ {
+		started, err := operation()
+		if !isTransactionConflict(err) || attempt >= c.retry.maxAttempts || !c.isRetryAllowed(entry.ExtensionId, includeDependencies) {
+			return started, err
+		}
+		delay := c.retry.backoff(attempt)
+		log.Warnf("Transaction conflict during %s operation for extension %q, retrying in %v (attempt %d of %d): %v",
+			entry.Operation, entry.ExtensionId, delay, attempt+1, c.retry.maxAttempts, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return started, err
+		}
+	}
+}
+
+func (c *transactionControllerImpl) isRetryAllowed(extensionId string, includeDependencies bool) bool {
+	allowed, err := c.controller.IsRetryOnTransactionConflictAllowed(extensionId, includeDependencies)
+	if err != nil {
+		log.Warnf("Failed to check if extension %q allows retrying after transaction conflicts: %v", extensionId, err)
+		return false
+	}
+	if !allowed {
+		log.Debugf("Extension %q or one of its dependencies does not allow retrying after transaction conflicts", extensionId)
+	}
+	return allowed
+}
@@ -0,0 +1,217 @@
+package extensionController
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var transactionConflictError = errors.New("E-EGOD-11: GlobalTransactionRollback msg: Transaction collision")
+
+func TestIsTransactionConflict(t *testing.T) {
+	assert.True(t, isTransactionConflict(transactionConflictError))
+	assert.True(t, isTransactionConflict(errors.New("failed to install extension: "+transactionConflictError.Error())))
+	assert.False(t, isTransactionConflict(mockError))
+	assert.False(t, isTransactionConflict(nil))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 10, initialBackoff: 100 * time.Millisecond, maxBackoff: 500 * time.Millisecond}
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 400*time.Millisecond, policy.backoff(3))
+	assert.Equal(t, 500*time.Millisecond, policy.backoff(4))
+	assert.Equal(t, 500*time.Millisecond, policy.backoff(100))
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionRetriesTransactionConflict() {
+	suite.enableRetries(3)
+	suite.mockCtrl.On("IsRetryOnTransactionConflictAllowed", "extId", false).Return(true, nil)
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", mock.Anything).Return(transactionConflictError).Once()
+	suite.dbMock.ExpectRollback()
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", mock.Anything).Return(nil).Once()
+	suite.dbMock.ExpectCommit()
+	err := suite.ctrl.InstallExtension(mockContext(), suite.db, "extId", "extVer")
+	suite.Require().NoError(err)
+	suite.Len(suite.auditLogMock.written, 1)
+	suite.Empty(suite.auditLogMock.writtenSeparately)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionRetriesCommitConflict() {
+	suite.enableRetries(3)
+	suite.mockCtrl.On("IsRetryOnTransactionConflictAllowed", "extId", false).Return(true, nil)
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", mock.Anything).Return(nil).Twice()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectCommit().WillReturnError(transactionConflictError)
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectCommit()
+	err := suite.ctrl.InstallExtension(mockContext(), suite.db, "extId", "extVer")
+	suite.Require().NoError(err)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionStopsRetryingAfterMaxAttempts() {
+	suite.enableRetries(2)
+	suite.mockCtrl.On("IsRetryOnTransactionConflictAllowed", "extId", false).Return(true, nil)
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", mock.Anything).Return(transactionConflictError).Twice()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	err := suite.ctrl.InstallExtension(mockContext(), suite.db, "extId", "extVer")
+	suite.Require().ErrorIs(err, transactionConflictError)
+	suite.Len(suite.auditLogMock.writtenSeparately, 1)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionDoesNotRetryIfExtensionDoesNotAllowIt() {
+	suite.enableRetries(3)
+	suite.mockCtrl.On("IsRetryOnTransactionConflictAllowed", "extId", false).Return(false, nil)
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", mock.Anything).Return(transactionConflictError).Once()
+	suite.dbMock.ExpectRollback()
+	err := suite.ctrl.InstallExtension(mockContext(), suite.db, "extId", "extVer")
+	suite.Require().ErrorIs(err, transactionConflictError)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionDoesNotRetryIfCheckFails() {
+	suite.enableRetries(3)
+	suite.mockCtrl.On("IsRetryOnTransactionConflictAllowed", "extId", false).Return(false, mockError)
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", mock.Anything).Return(transactionConflictError).Once()
+	suite.dbMock.ExpectRollback()
+	err := suite.ctrl.InstallExtension(mockContext(), suite.db, "extId", "extVer")
+	suite.Require().ErrorIs(err, transactionConflictError)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionDoesNotRetryOtherErrors() {
+	suite.enableRetries(3)
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", mock.Anything).Return(mockError).Once()
+	suite.dbMock.ExpectRollback()
+	err := suite.ctrl.InstallExtension(mockContext(), suite.db, "extId", "extVer")
+	suite.Require().EqualError(err, mockErrorMsg)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionWithDependenciesChecksDependenciesBeforeRetry() {
+	suite.enableRetries(3)
+	suite.mockCtrl.On("IsRetryOnTransactionConflictAllowed", "extId", true).Return(false, nil)
+	suite.mockCtrl.On("GetDependencyIds", "extId").Return([]string{}, nil)
+	options := InstallOptions{InstallDependencies: true, AcceptLicenses: false, Force: false}
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", options).Return(transactionConflictError).Once()
+	suite.dbMock.ExpectRollback()
+	err := suite.ctrl.InstallExtensionWithOptions(mockContext(), suite.db, "extId", "extVer", options)
+	suite.Require().ErrorIs(err, transactionConflictError)
+}
+
+func (suite *extCtrlUnitTestSuite) enableRetries(maxAttempts int) {
+	suite.ctrl.(*transactionControllerImpl).retry = retryPolicy{maxAttempts: maxAttempts, initialBackoff: time.Millisecond, maxBackoff: time.Millisecond}
+}
+
+func retryTestExtension(retryOnTransactionConflict bool) string {
+	return strings.Replace(`(function(){
+		global.installedExtension = {
+			extension: {
+				name: "Retry Extension",
+				installableVersions: [{name: "1.0.0", latest: true}],
+				retryOnTransactionConflict: $RETRY$,
+				install: function(context, version) { context.sqlClient.execute("install " + version) }
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`, "$RETRY$", strconv.FormatBool(retryOnTransactionConflict), 1)
+}
+
+func (suite *ControllerUTestSuite) TestInstallRetriesStatementConflict() {
+	suite.writeFile("retry.js", retryTestExtension(true))
+	suite.controller.retry = retryPolicy{maxAttempts: 2, initialBackoff: time.Millisecond, maxBackoff: time.Millisecond}
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install 1.0.0").WillReturnError(transactionConflictError)
+	suite.dbMock.ExpectRollback()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install 1.0.0").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	err := suite.controller.InstallExtension(mockContext(), suite.db, "retry.js", "1.0.0")
+	suite.Require().NoError(err)
+}
+
+func retryDependencyTestExtension(name string, retryOnTransactionConflict bool, dependencies string) string {
+	return strings.NewReplacer("$NAME$", name, "$RETRY$", strconv.FormatBool(retryOnTransactionConflict), "$DEPENDENCIES$", dependencies).Replace(`(function(){
+		global.installedExtension = {
+			extension: {
+				name: "$NAME$",
+				installableVersions: [{name: "1.0.0", latest: true}],
+				retryOnTransactionConflict: $RETRY$,
+				dependencies: $DEPENDENCIES$,
+				install: function(context, version) { context.sqlClient.execute("install $NAME$ " + version) }
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`)
+}
+
+func (suite *ControllerUTestSuite) TestIsRetryAllowedRequiresDeclarationOfAllDependencies() {
+	suite.writeFile("retry-dependency.js", retryDependencyTestExtension("dependency", true, `[]`))
+	suite.writeFile("no-retry-dependency.js", retryDependencyTestExtension("other", false, `[]`))
+	suite.writeFile("retry-main.js", retryDependencyTestExtension("main", true, `[{extensionId: "retry-dependency.js"}]`))
+	suite.writeFile("no-retry-main.js", retryDependencyTestExtension("main", true, `[{extensionId: "retry-dependency.js"}, {extensionId: "no-retry-dependency.js"}]`))
+	ctrl := suite.controller.controller
+	for _, test := range []struct {
+		extensionId         string
+		includeDependencies bool
+		expected            bool
+	}{
+		{"retry-main.js", false, true},
+		{"retry-main.js", true, true},
+		{"no-retry-main.js", false, true},
+		{"no-retry-main.js", true, false},
+		{"no-retry-dependency.js", true, false},
+	} {
+		allowed, err := ctrl.IsRetryOnTransactionConflictAllowed(test.extensionId, test.includeDependencies)
+		suite.Require().NoError(err)
+		suite.Equal(test.expected, allowed, "%s with dependencies: %t", test.extensionId, test.includeDependencies)
+	}
+}
+
+func (suite *ControllerUTestSuite) TestIsRetryAllowedFailsForMissingDependency() {
+	suite.writeFile("retry-main.js", retryDependencyTestExtension("main", true, `[{extensionId: "missing.js"}]`))
+	allowed, err := suite.controller.controller.IsRetryOnTransactionConflictAllowed("retry-main.js", true)
+	suite.Require().Error(err)
+	suite.False(allowed)
+}
+
+func (suite *ControllerUTestSuite) TestGetDependencyIdsReturnsTransitiveDependencies() {
+	suite.writeFile("retry-dependency.js", retryDependencyTestExtension("dependency", true, `[{extensionId: "retry-main.js"}]`))
+	suite.writeFile("no-retry-dependency.js", retryDependencyTestExtension("other", false, `[{extensionId: "retry-dependency.js"}]`))
+	suite.writeFile("retry-main.js", retryDependencyTestExtension("main", true, `[{extensionId: "no-retry-dependency.js"}, {extensionId: "retry-dependency.js"}]`))
+	ids, err := suite.controller.controller.GetDependencyIds("retry-main.js")
+	suite.Require().NoError(err)
+	suite.Equal([]string{"no-retry-dependency.js", "retry-dependency.js"}, ids)
+}
+
+func (suite *ControllerUTestSuite) TestGetDependencyIdsFailsForMissingDependency() {
+	suite.writeFile("retry-main.js", retryDependencyTestExtension("main", true, `[{extensionId: "missing.js"}]`))
+	ids, err := suite.controller.controller.GetDependencyIds("retry-main.js")
+	suite.Require().Error(err)
+	suite.Nil(ids)
+}
+
+func (suite *ControllerUTestSuite) TestInstallDoesNotRetryStatementConflictWithoutDeclaration() {
+	suite.writeFile("retry.js", retryTestExtension(false))
+	suite.controller.retry = retryPolicy{maxAttempts: 2, initialBackoff: time.Millisecond, maxBackoff: time.Millisecond}
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "test"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install 1.0.0").WillReturnError(transactionConflictError)
+	suite.dbMock.ExpectRollback()
+	err := suite.controller.InstallExtension(mockContext(), suite.db, "retry.js", "1.0.0")
+	suite.Require().ErrorContains(err, "GlobalTransactionRollback")
+}
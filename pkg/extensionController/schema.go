@@ -0,0 +1,66 @@
+package extensionController
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+)
+
+type extensionSchemaKey struct{}
+
+// WithExtensionSchema returns a new context that selects the schema where operations search for and create extensions
+// instead of [ExtensionManagerConfig.ExtensionSchema]. An empty schema selects the configured default schema.
+// Operations fail with status 400 (Bad Request) if the schema is not listed in [ExtensionManagerConfig.AllowedExtensionSchemas].
+func WithExtensionSchema(ctx context.Context, extensionSchema string) context.Context {
+	return context.WithValue(ctx, extensionSchemaKey{}, extensionSchema)
+}
+
+// GetExtensionSchema returns the schema selected with [WithExtensionSchema] or an empty string if none was selected.
+func GetExtensionSchema(ctx context.Context) string {
+	if schema, ok := ctx.Value(extensionSchemaKey{}).(string); ok {
+		return schema
+	}
+	return ""
+}
+
+// getExtensionSchema returns the schema selected with [WithExtensionSchema] or the given default schema.
+func getExtensionSchema(ctx context.Context, defaultSchema string) string {
+	if schema := GetExtensionSchema(ctx); schema != "" {
+		return schema
+	}
+	return defaultSchema
+}
+
+func validateAllowedExtensionSchemas(schemas []string) error {
+	for _, schema := range schemas {
+		if schema == "" {
+			return errors.New("empty schema in AllowedExtensionSchemas")
+		}
+		if strings.Contains(schema, `"`) {
+			return fmt.Errorf("invalid schema %q in AllowedExtensionSchemas", schema)
+		}
+	}
+	return nil
+}
+
+// checkExtensionSchema verifies that the schema selected in the context is allowed.
+func (c *transactionControllerImpl) checkExtensionSchema(ctx context.Context) error {
+	schema := getExtensionSchema(ctx, c.config.ExtensionSchema)
+	if schema == c.config.ExtensionSchema || slices.Contains(c.config.AllowedExtensionSchemas, schema) {
+		return nil
+	}
+	return apiErrors.NewBadRequestErrorF("extension schema %q is not allowed", schema)
+}
+
+func (c *transactionControllerImpl) extensionSchema(ctx context.Context) string {
+	return getExtensionSchema(ctx, c.config.ExtensionSchema)
+}
+
+func (c *controllerImpl) extensionSchema(txCtx *transaction.TransactionContext) string {
+	return getExtensionSchema(txCtx.GetContext(), c.config.ExtensionSchema)
+}
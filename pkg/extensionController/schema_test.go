@@ -0,0 +1,88 @@
+package extensionController
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetExtensionSchema(t *testing.T) {
+	assert.Equal(t, "default", getExtensionSchema(context.Background(), "default"))
+	assert.Equal(t, "default", getExtensionSchema(WithExtensionSchema(context.Background(), ""), "default"))
+	assert.Equal(t, "other", getExtensionSchema(WithExtensionSchema(context.Background(), "other"), "default"))
+	assert.Equal(t, "", GetExtensionSchema(context.Background()))
+	assert.Equal(t, "other", GetExtensionSchema(WithExtensionSchema(context.Background(), "other")))
+}
+
+func (suite *extCtrlUnitTestSuite) allowExtensionSchemas(schemas ...string) {
+	suite.ctrl.(*transactionControllerImpl).config.AllowedExtensionSchemas = schemas
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionInDefaultSchema() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{InstallDependencies: false}).Return(nil)
+	suite.dbMock.ExpectCommit()
+	err := suite.ctrl.InstallExtension(WithExtensionSchema(mockContext(), "ext-schema"), suite.db, "extId", "extVer")
+	suite.Require().NoError(err)
+	suite.Equal("ext-schema", suite.auditLogMock.lastSchema)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionInAllowedSchema() {
+	suite.allowExtensionSchemas("other-schema")
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{InstallDependencies: false}).Return(nil)
+	suite.dbMock.ExpectCommit()
+	err := suite.ctrl.InstallExtension(WithExtensionSchema(mockContext(), "other-schema"), suite.db, "extId", "extVer")
+	suite.Require().NoError(err)
+	suite.Equal("other-schema", suite.auditLogMock.lastSchema)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionInDisallowedSchemaFails() {
+	suite.allowExtensionSchemas("other-schema")
+	err := suite.ctrl.InstallExtension(WithExtensionSchema(mockContext(), "unknown-schema"), suite.db, "extId", "extVer")
+	suite.Require().EqualError(err, `extension schema "unknown-schema" is not allowed`)
+	suite.Empty(suite.auditLogMock.writtenSeparately)
+}
+
+func (suite *extCtrlUnitTestSuite) TestGetInstalledExtensionsInDisallowedSchemaFails() {
+	installations, err := suite.ctrl.GetInstalledExtensions(WithExtensionSchema(mockContext(), "unknown-schema"), suite.db)
+	suite.Require().EqualError(err, `extension schema "unknown-schema" is not allowed`)
+	suite.Nil(installations)
+}
+
+func (suite *extCtrlUnitTestSuite) TestGetAuditLogReadsSelectedSchema() {
+	suite.allowExtensionSchemas("other-schema")
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	_, err := suite.ctrl.GetAuditLog(WithExtensionSchema(mockContext(), "other-schema"), suite.db, AuditLogFilter{})
+	suite.Require().NoError(err)
+	suite.Equal("other-schema", suite.auditLogMock.lastSchema)
+}
+
+func (suite *ControllerUTestSuite) TestInstallInSelectedExtensionSchema() {
+	suite.writeFile("schema.js", `(function(){
+		global.installedExtension = {
+			extension: {
+				name: "Schema Extension",
+				installableVersions: [{name: "1.0.0", latest: true}],
+				install: function(context, version) { context.sqlClient.execute("install in " + context.extensionSchemaName) }
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`)
+	suite.controller.config.AllowedExtensionSchemas = []string{"other"}
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "other"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectExec("install in other").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.dbMock.ExpectCommit()
+	err := suite.controller.InstallExtension(WithExtensionSchema(mockContext(), "other"), suite.db, "schema.js", "1.0.0")
+	suite.Require().NoError(err)
+}
+
+func (suite *ControllerUTestSuite) TestInstallInDisallowedExtensionSchemaFails() {
+	err := suite.controller.InstallExtension(WithExtensionSchema(mockContext(), "other"), suite.db, "schema.js", "1.0.0")
+	suite.assertApiError(err, 400, `extension schema "other" is not allowed`)
+}
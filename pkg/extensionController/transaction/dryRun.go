@@ -0,0 +1,54 @@
+package transaction
+
+import "sync"
+
+// DryRunRecorder collects the statements executed by extensions and their log output during a dry-run.
+type DryRunRecorder struct {
+	mutex       sync.Mutex
+	statements  []RecordedStatement
+	logMessages []RecordedLogMessage
+}
+
+// RecordedStatement is a statement executed by an extension during a dry-run.
+type RecordedStatement struct {
+	Query string
+	Args  []any
+}
+
+// RecordedLogMessage is a message logged by an extension via `console.log()`, `console.warn()` or `console.error()` during a dry-run.
+type RecordedLogMessage struct {
+	Level   string
+	Message string
+}
+
+func newDryRunRecorder() *DryRunRecorder {
+	return &DryRunRecorder{mutex: sync.Mutex{}, statements: nil, logMessages: nil}
+}
+
+// RecordStatement adds the given statement to the list of executed statements.
+func (r *DryRunRecorder) RecordStatement(query string, args ...any) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.statements = append(r.statements, RecordedStatement{Query: query, Args: args})
+}
+
+// RecordLogMessage adds the given log message to the list of log messages.
+func (r *DryRunRecorder) RecordLogMessage(level, message string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.logMessages = append(r.logMessages, RecordedLogMessage{Level: level, Message: message})
+}
+
+// Statements returns the recorded statements in the order of execution.
+func (r *DryRunRecorder) Statements() []RecordedStatement {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]RecordedStatement{}, r.statements...)
+}
+
+// LogMessages returns the recorded log messages in the order they were logged.
+func (r *DryRunRecorder) LogMessages() []RecordedLogMessage {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]RecordedLogMessage{}, r.logMessages...)
+}
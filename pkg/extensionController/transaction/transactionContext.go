@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/exasol/extension-manager/pkg/apiErrors"
@@ -14,7 +15,7 @@ import (
 // TransactionStarter starts a database transaction and returns a new [TransactionContext].
 // It allows injecting a mock transaction in unit tests.
 type (
-	TransactionStarter func(ctx context.Context, db *sql.DB, bucketFsBasePath string) (*TransactionContext, error)
+	TransactionStarter func(ctx context.Context, db *sql.DB, bucketFsBasePaths []string) (*TransactionContext, error)
 )
 
 // BucketFsClientCreator creates a new [bfs.BucketFsAPI].
@@ -25,7 +26,12 @@ type (
 
 // BeginTransaction starts a new database transaction.
 func BeginTransaction(ctx context.Context, db *sql.DB, bucketFsBasePath string) (*TransactionContext, error) {
-	if bucketFsBasePath == "" {
+	return BeginTransactionForBasePaths(ctx, db, []string{bucketFsBasePath})
+}
+
+// BeginTransactionForBasePaths starts a new database transaction that searches the given BucketFS base paths in order.
+func BeginTransactionForBasePaths(ctx context.Context, db *sql.DB, bucketFsBasePaths []string) (*TransactionContext, error) {
+	if len(bucketFsBasePaths) == 0 || slices.Contains(bucketFsBasePaths, "") {
 		return nil, errors.New("bucketFsBasePath is empty")
 	}
 	tx, err := db.BeginTx(ctx, nil)
@@ -41,8 +47,10 @@ func BeginTransaction(ctx context.Context, db *sql.DB, bucketFsBasePath string)
 		transaction: tx,
 		bfsClient:   nil,
 		createBfsClient: func() (bfs.BucketFsAPI, error) {
-			return bfs.CreateBucketFsAPI(bucketFsBasePath, ctx, db)
+			return bfs.CreateBucketFsAPIForBasePaths(bucketFsBasePaths, ctx, db)
 		},
+		dryRunRecorder: nil,
+		warnings:       nil,
 	}, nil
 }
 
@@ -53,6 +61,8 @@ type TransactionContext struct {
 	transaction     *sql.Tx
 	createBfsClient BucketFsClientCreator
 	bfsClient       bfs.BucketFsAPI
+	dryRunRecorder  *DryRunRecorder
+	warnings        []string
 }
 
 // GetTransaction returns the current database transaction.
@@ -83,6 +93,29 @@ func (ctx *TransactionContext) GetBucketFsClient() (bfs.BucketFsAPI, error) {
 	return ctx.bfsClient, nil
 }
 
+// EnableDryRun marks this transaction as dry-run and returns a new [DryRunRecorder]
+// that collects statements and log messages of extensions. A dry-run transaction must never be committed.
+func (ctx *TransactionContext) EnableDryRun() *DryRunRecorder {
+	ctx.dryRunRecorder = newDryRunRecorder()
+	return ctx.dryRunRecorder
+}
+
+// GetDryRunRecorder returns the [DryRunRecorder] of this transaction or nil if this is not a dry-run.
+func (ctx *TransactionContext) GetDryRunRecorder() *DryRunRecorder {
+	return ctx.dryRunRecorder
+}
+
+// AddWarning adds a warning that is reported to the user when the operation using this transaction completes,
+// e.g. because a deprecated extension version is used.
+func (ctx *TransactionContext) AddWarning(warning string) {
+	ctx.warnings = append(ctx.warnings, warning)
+}
+
+// GetWarnings returns the warnings added to this transaction in the order they were added.
+func (ctx *TransactionContext) GetWarnings() []string {
+	return ctx.warnings
+}
+
 // Rollback rolls back the transaction and cleans up any resources like the [bfs.BucketFsAPI] if one was created.
 func (ctx *TransactionContext) Rollback() {
 	_ = ctx.cleanup()
@@ -93,6 +126,9 @@ func (ctx *TransactionContext) Rollback() {
 
 // Commit commits the transaction and cleans up any resources like the [bfs.BucketFsAPI] if one was created.
 func (ctx *TransactionContext) Commit() error {
+	if ctx.dryRunRecorder != nil {
+		return errors.New("cannot commit a dry-run transaction")
+	}
 	err := ctx.cleanup()
 	if err != nil {
 		return err
@@ -198,6 +198,47 @@ func (suite *TransactionContextSuite) TestCommitClosingBfsClientFails() {
 	suite.Require().EqualError(txCtx.Commit(), "failed to close BucketFS client: failed to rollback transaction to cleanup resources. Cause: mock error")
 }
 
+func (suite *TransactionContextSuite) TestNoDryRunByDefault() {
+	suite.dbMock.ExpectBegin()
+	txCtx, _ := suite.beginTransaction()
+	suite.Nil(txCtx.GetDryRunRecorder())
+}
+
+func (suite *TransactionContextSuite) TestEnableDryRun() {
+	suite.dbMock.ExpectBegin()
+	txCtx, _ := suite.beginTransaction()
+	recorder := txCtx.EnableDryRun()
+	suite.Same(recorder, txCtx.GetDryRunRecorder())
+	recorder.RecordStatement("CREATE SCRIPT s1", "arg")
+	recorder.RecordStatement("CREATE SCRIPT s2")
+	recorder.RecordLogMessage("warn", "message")
+	suite.Equal([]RecordedStatement{{Query: "CREATE SCRIPT s1", Args: []any{"arg"}}, {Query: "CREATE SCRIPT s2", Args: nil}}, recorder.Statements())
+	suite.Equal([]RecordedLogMessage{{Level: "warn", Message: "message"}}, recorder.LogMessages())
+}
+
+func (suite *TransactionContextSuite) TestCommitFailsForDryRun() {
+	suite.dbMock.ExpectBegin()
+	txCtx, _ := suite.beginTransaction()
+	txCtx.EnableDryRun()
+	suite.Require().EqualError(txCtx.Commit(), "cannot commit a dry-run transaction")
+	suite.dbMock.ExpectRollback()
+	txCtx.Rollback()
+}
+
+func (suite *TransactionContextSuite) TestNoWarningsByDefault() {
+	suite.dbMock.ExpectBegin()
+	txCtx, _ := suite.beginTransaction()
+	suite.Empty(txCtx.GetWarnings())
+}
+
+func (suite *TransactionContextSuite) TestAddWarning() {
+	suite.dbMock.ExpectBegin()
+	txCtx, _ := suite.beginTransaction()
+	txCtx.AddWarning("warning 1")
+	txCtx.AddWarning("warning 2")
+	suite.Equal([]string{"warning 1", "warning 2"}, txCtx.GetWarnings())
+}
+
 func (suite *TransactionContextSuite) beginTransaction() (*TransactionContext, error) {
 	return BeginTransaction(context.Background(), suite.db, BUCKETFS_BASE_PATH)
 }
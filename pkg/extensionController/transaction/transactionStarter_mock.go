@@ -29,25 +29,27 @@ func (m *TransactionStarterMock) GetTransactionStarter() TransactionStarter {
 }
 
 func (m *TransactionStarterMock) SimulateTransactionFailed(err error) {
-	m.transactionStarter = func(ctx context.Context, db *sql.DB, bucketFsBasePath string) (*TransactionContext, error) {
+	m.transactionStarter = func(ctx context.Context, db *sql.DB, bucketFsBasePaths []string) (*TransactionContext, error) {
 		return nil, err
 	}
 }
 
 func (m *TransactionStarterMock) SimulateMockTransaction() {
-	m.transactionStarter = func(ctx context.Context, db *sql.DB, bucketFsBasePath string) (*TransactionContext, error) {
+	m.transactionStarter = func(ctx context.Context, db *sql.DB, bucketFsBasePaths []string) (*TransactionContext, error) {
 		tx, err := m.dbMock.Begin()
 		if err != nil {
 			return nil, fmt.Errorf("failed to start mock transaction: %w", err)
 		}
 		return &TransactionContext{
-			context:     context.Background(),
+			context:     ctx,
 			transaction: tx,
 			db:          m.dbMock,
 			createBfsClient: func() (bfs.BucketFsAPI, error) {
 				return m.bfsMock, nil
 			},
-			bfsClient: nil,
+			bfsClient:      nil,
+			dryRunRecorder: nil,
+			warnings:       nil,
 		}, nil
 	}
 }
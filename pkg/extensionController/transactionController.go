@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
 	"time"
 
 	"github.com/exasol/extension-manager/pkg/extensionAPI"
@@ -27,6 +29,27 @@ type TransactionController interface {
 	// db is a connection to the Exasol DB
 	GetInstalledExtensions(ctx context.Context, db *sql.DB) ([]*extensionAPI.JsExtInstallation, error)
 
+	// GetAvailableUpdates compares all installed extensions with the versions available in the registry.
+	// The result contains installed and latest version of each installation, whether the installed version is deprecated
+	// and whether the files required by the latest version are available in BucketFS.
+	// db is a connection to the Exasol DB
+	GetAvailableUpdates(ctx context.Context, db *sql.DB) ([]*ExtensionUpdate, error)
+
+	// DetectDrift reports scripts and virtual schemas that belong to no known extension, virtual schemas with a missing adapter script
+	// and scripts that reference missing BucketFS files.
+	// db is a connection to the Exasol DB
+	DetectDrift(ctx context.Context, db *sql.DB) (*DriftReport, error)
+
+	// GetBucketFsUsage reports all files in BucketFS with their size and classifies them as referenced by an installed script,
+	// required by an available extension or unreferenced.
+	// db is a connection to the Exasol DB
+	GetBucketFsUsage(ctx context.Context, db *sql.DB) (*BucketFsUsageReport, error)
+
+	// CheckReadiness checks that all components required for serving requests are available,
+	// i.e. that the registry index can be loaded and that the parameter validator can be initialized.
+	// The extension manager is ready if all returned checks are ready.
+	CheckReadiness() []ReadinessCheck
+
 	// GetParameterDefinitions returns the parameter definitions required for installing a given extension version.
 	GetParameterDefinitions(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) ([]parameterValidator.ParameterDefinition, error)
 
@@ -36,26 +59,143 @@ type TransactionController interface {
 	// extensionVersion is the version of the extension to install
 	InstallExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) error
 
+	// InstallExtensionWithOptions installs an extension using the given options.
+	// db is a connection to the Exasol DB
+	// extensionId is the ID of the extension to install
+	// extensionVersion is the version of the extension to install
+	// options control how dependencies of the extension are handled
+	InstallExtensionWithOptions(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options InstallOptions) error
+
+	// InstallExtensionWithResult installs an extension using the given options
+	// and returns warnings for the user, e.g. because a deprecated version was installed.
+	// db is a connection to the Exasol DB
+	// extensionId is the ID of the extension to install
+	// extensionVersion is the version of the extension to install
+	// options control how dependencies and deprecated versions are handled
+	InstallExtensionWithResult(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options InstallOptions) (*InstallResult, error)
+
+	// DryRunInstallExtension runs the installation of an extension without committing it
+	// and returns the statements executed by the extension and its log output.
+	// If the installation fails, the result contains the statements and log output until the failure.
+	// db is a connection to the Exasol DB
+	// extensionId is the ID of the extension to install
+	// extensionVersion is the version of the extension to install
+	// options control how dependencies of the extension are handled
+	DryRunInstallExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options InstallOptions) (*DryRunResult, error)
+
 	// UninstallExtension uninstalls an extension.
 	// db is a connection to the Exasol DB
 	// extensionId is the ID of the extension to uninstall
 	// extensionVersion is the version of the extension to uninstall
 	UninstallExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) error
 
+	// UninstallExtensionWithOptions uninstalls an extension using the given options
+	// and returns the removed extension and instances.
+	// db is a connection to the Exasol DB
+	// extensionId is the ID of the extension to uninstall
+	// extensionVersion is the version of the extension to uninstall
+	// options control if existing instances are deleted before uninstalling
+	UninstallExtensionWithOptions(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options UninstallOptions) (*UninstallResult, error)
+
 	// UpgradeExtension upgrades an installed extension to the latest version.
 	// db is a connection to the Exasol DB
 	// extensionId is the ID of the extension to uninstall
 	UpgradeExtension(ctx context.Context, db *sql.DB, extensionId string) (*extensionAPI.JsUpgradeResult, error)
 
+	// UpgradeExtensionToVersion upgrades or downgrades an installed extension to the given version.
+	// db is a connection to the Exasol DB
+	// extensionId is the ID of the extension to upgrade
+	// targetVersion is one of the extension's installable versions or empty for the latest version
+	UpgradeExtensionToVersion(ctx context.Context, db *sql.DB, extensionId string, targetVersion string) (*extensionAPI.JsUpgradeResult, error)
+
+	// UpgradeExtensions upgrades multiple installed extensions to their latest version.
+	// db is a connection to the Exasol DB
+	// extensionIds are the IDs of the extensions to upgrade, empty for all installed extensions
+	// options control if all extensions are upgraded in a single transaction
+	// The result contains one entry per extension. The returned error is only set if upgrading could not be started.
+	UpgradeExtensions(ctx context.Context, db *sql.DB, extensionIds []string, options BulkUpgradeOptions) ([]*BulkUpgradeResult, error)
+
+	// RepairExtension re-creates the database objects of an installed extension in a single transaction, e.g. an adapter script
+	// dropped by a user. EM calls the extension's optional repair function or, if it does not exist, re-runs install.
+	// db is a connection to the Exasol DB
+	// extensionId is the ID of the extension to repair
+	// extensionVersion is the installed version of the extension
+	RepairExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) error
+
+	// DryRunUpgradeExtension runs the upgrade of an installed extension without committing it
+	// and returns the statements executed by the extension and its log output.
+	// If the upgrade fails, the result contains the statements and log output until the failure.
+	// db is a connection to the Exasol DB
+	// extensionId is the ID of the extension to upgrade
+	// targetVersion is one of the extension's installable versions or empty for the latest version
+	DryRunUpgradeExtension(ctx context.Context, db *sql.DB, extensionId string, targetVersion string) (*DryRunResult, error)
+
 	// CreateInstance creates a new instance of an extension, e.g. a virtual schema and returns it's name.
 	// db is a connection to the Exasol DB
 	CreateInstance(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, parameterValues []ParameterValue) (*extensionAPI.JsExtInstance, error)
 
+	// CreateInstanceWithOptions creates a new instance of an extension using the given options
+	// and returns the instance together with warnings for the user, e.g. because the version is deprecated.
+	// db is a connection to the Exasol DB
+	CreateInstanceWithOptions(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, parameterValues []ParameterValue, options CreateInstanceOptions) (*CreateInstanceResult, error)
+
+	// DryRunCreateInstance runs the creation of a new instance without committing it
+	// and returns the statements executed by the extension and its log output.
+	// If the creation fails, the result contains the statements and log output until the failure.
+	// db is a connection to the Exasol DB
+	DryRunCreateInstance(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, parameterValues []ParameterValue, options CreateInstanceOptions) (*DryRunResult, error)
+
 	// FindInstances returns a list of all instances for the given version.
 	FindInstances(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) ([]*extensionAPI.JsExtInstance, error)
 
 	// DeleteInstance deletes instance with the given ID.
 	DeleteInstance(ctx context.Context, db *sql.DB, extensionId, extensionVersion, instanceId string) error
+
+	// GetAuditLog returns the entries of the audit log matching the given filter, newest first.
+	GetAuditLog(ctx context.Context, db *sql.DB, filter AuditLogFilter) ([]AuditEntry, error)
+
+	// UploadMissingFiles downloads all files required by an extension that are missing in BucketFS
+	// from their download URL and uploads them to the configured bucket.
+	// Uploading fails with status 400 if a missing file requires a license agreement unless options accept the licenses.
+	// extensionId is the ID of the extension for which to upload files
+	UploadMissingFiles(ctx context.Context, db *sql.DB, extensionId string, options UploadOptions) ([]*UploadedFile, error)
+
+	// ExportInstallations returns a portable description of all installed extensions, their versions and instances.
+	// Values of secret instance parameters are omitted.
+	// db is a connection to the Exasol DB
+	ExportInstallations(ctx context.Context, db *sql.DB) (*ExportDocument, error)
+
+	// PreviewImport returns the changes required for importing the given document without applying them.
+	// db is a connection to the Exasol DB where the document will be imported
+	PreviewImport(ctx context.Context, db *sql.DB, document *ExportDocument, options ImportOptions) (*ImportPlan, error)
+
+	// ImportInstallations installs the extensions and creates the instances of the given document that don't exist yet.
+	// Each change is applied in its own transaction. After a change fails, the remaining changes are skipped.
+	// The returned error is only set if the import could not be started, e.g. because secret parameters are missing.
+	// db is a connection to the Exasol DB where the document will be imported
+	ImportInstallations(ctx context.Context, db *sql.DB, document *ExportDocument, options ImportOptions) (*ImportResult, error)
+
+	// PlanManifest returns the changes required to make the database match the given manifest without applying them.
+	// db is a connection to the Exasol DB
+	PlanManifest(ctx context.Context, db *sql.DB, manifest *Manifest) (*ApplyPlan, error)
+
+	// ApplyManifest installs, upgrades and deletes instances of the extensions listed in the given manifest until the database matches it.
+	// Each change is applied in its own transaction. After a change fails, the remaining changes are skipped.
+	// The returned error is only set if the changes could not be planned, e.g. because the manifest is invalid.
+	// db is a connection to the Exasol DB
+	ApplyManifest(ctx context.Context, db *sql.DB, manifest *Manifest, options ApplyOptions) (*ApplyResult, error)
+
+	// ApplyManifestPlan applies the changes of a plan returned by [TransactionController.PlanManifest] without planning them again,
+	// so that exactly the changes shown to the user are applied. It works like [TransactionController.ApplyManifest] otherwise.
+	// db is a connection to the Exasol DB
+	ApplyManifestPlan(ctx context.Context, db *sql.DB, plan *ApplyPlan, options ApplyOptions) (*ApplyResult, error)
+
+	// UploadFile uploads the given content to the configured bucket after verifying the database credentials.
+	// Uploading fails with status 400 if the file requires a license agreement unless options accept the license
+	// and with status 409 if the file already exists unless options allow overwriting it.
+	// extensionId is the ID of the extension that requires the file
+	// fileName is the BucketFS file name as defined by the extension
+	UploadFile(ctx context.Context, db *sql.DB, extensionId string, fileName string, content io.Reader, options UploadOptions) (*UploadedFile, error)
 }
 
 type Extension struct {
@@ -64,11 +204,72 @@ type Extension struct {
 	Category            string
 	Description         string
 	InstallableVersions []extensionAPI.JsExtensionVersion
+	Dependencies        []extensionAPI.ExtensionDependency
+	BucketFsUploads     []extensionAPI.BucketFsUpload
+}
+
+// InstallOptions contains options for installing an extension.
+type InstallOptions struct {
+	// InstallDependencies specifies if missing dependencies should be installed before the extension.
+	// If false, installation fails in case a dependency is missing.
+	InstallDependencies bool
+	// AcceptLicenses confirms that the user accepts the licenses of all files that require a license agreement.
+	// If false, installation fails in case the extension or one of its dependencies requires a license agreement.
+	AcceptLicenses bool
+	// Force installs deprecated versions even if the configured [DeprecatedVersionPolicy] rejects them.
+	Force bool
+}
+
+// InstallResult contains the outcome of a successful installation.
+type InstallResult struct {
+	// Warnings for the user, e.g. because a deprecated version was installed.
+	Warnings []string
+}
+
+// CreateInstanceOptions contains options for creating an instance of an extension.
+type CreateInstanceOptions struct {
+	// Force creates instances of deprecated versions even if the configured [DeprecatedVersionPolicy] rejects them.
+	Force bool
+}
+
+// CreateInstanceResult contains the outcome of successfully creating an instance.
+type CreateInstanceResult struct {
+	// Instance is the new instance.
+	Instance *extensionAPI.JsExtInstance
+	// Warnings for the user, e.g. because the instance uses a deprecated version.
+	Warnings []string
+}
+
+// UninstallOptions contains options for uninstalling an extension.
+type UninstallOptions struct {
+	// Cascade deletes all instances of the extension before uninstalling it in the same transaction.
+	// If false, uninstalling fails in case instances exist.
+	Cascade bool
+}
+
+// UninstallResult contains the outcome of a successful uninstallation.
+type UninstallResult struct {
+	// ExtensionId is the ID of the uninstalled extension.
+	ExtensionId string
+	// ExtensionVersion is the uninstalled version.
+	ExtensionVersion string
+	// DeletedInstances contains the instances deleted before uninstalling the extension.
+	DeletedInstances []*extensionAPI.JsExtInstance
+}
+
+// DryRunResult contains the outcome of an operation that was rolled back after running it.
+type DryRunResult struct {
+	// Statements executed by the extension in the order of execution.
+	Statements []transaction.RecordedStatement
+	// LogMessages written by the extension in the order they were logged.
+	LogMessages []transaction.RecordedLogMessage
+	// Warnings for the user, e.g. because a deprecated version is used.
+	Warnings []string
 }
 
 type ParameterValue struct {
-	Name  string
-	Value string
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 // ExtInstallation represents the installation of an Extension.
@@ -83,8 +284,40 @@ type ExtensionManagerConfig struct {
 	ExtensionRegistryURL string
 	// BucketFS base path where to search for extension files, e.g. "/buckets/bfsdefault/default/".
 	BucketFSBasePath string
+	// Further BucketFS base paths searched in the given order after BucketFSBasePath, e.g. "/buckets/bfsjars/jars/". Optional.
+	// Extensions can restrict the search for a file to one of the base paths using [extensionAPI.BucketFsUpload.Bucket].
+	AdditionalBucketFSBasePaths []string
 	// Schema where extensions are searched for and new extensions are created, e.g. "EXA_EXTENSIONS".
 	ExtensionSchema string
+	// Additional schemas that clients may select instead of ExtensionSchema using [WithExtensionSchema]. Optional.
+	AllowedExtensionSchemas []string
+	// URL of the bucket used for uploading files required by extensions, e.g. "https://exasol-host:2581/default/".
+	// The bucket must be accessible under BucketFSBasePath or one of the AdditionalBucketFSBasePaths. Optional, uploading files is not possible if this is empty.
+	BucketFSUploadURL string
+	// Write password of the bucket used for uploading files.
+	BucketFSWritePassword string
+	// Read password of the bucket used for verifying uploaded files. Leave empty for public buckets.
+	BucketFSReadPassword string
+	// Maximum duration of downloading a file required by an extension or of a single request to BucketFS when uploading
+	// a file. Optional, defaults to 10 minutes.
+	UploadTimeout time.Duration
+	// Verify that the installed version is found via the extension's findInstallations function after installing
+	// or upgrading an extension. The transaction is rolled back if the version is not found. Optional, disabled by default.
+	VerifyInstallations bool
+	// Policy for installing deprecated extension versions and creating instances of them. Optional, defaults to
+	// [DeprecatedVersionPolicyAllow].
+	DeprecatedVersionPolicy DeprecatedVersionPolicy
+	// Maximum time to wait for another operation on the same extension in the same database to finish before failing
+	// with status 409 (Conflict). Optional, defaults to 30 seconds.
+	LockTimeout time.Duration
+	// Additionally serialize operations of multiple extension manager instances using lock rows in table
+	// EXTENSION_LOCKS in the extension schema. Optional, disabled by default.
+	DatabaseLocking bool
+}
+
+// bucketFsBasePaths returns all configured BucketFS base paths in the order in which they are searched.
+func (config ExtensionManagerConfig) bucketFsBasePaths() []string {
+	return append([]string{config.BucketFSBasePath}, config.AdditionalBucketFSBasePaths...)
 }
 
 // Create creates a new instance of [TransactionController].
@@ -114,8 +347,11 @@ func CreateWithValidatedConfig(config ExtensionManagerConfig) (TransactionContro
 	controller := createImpl(config)
 	transactionController := &transactionControllerImpl{
 		controller:         controller,
-		transactionStarter: transaction.BeginTransaction,
+		transactionStarter: transaction.BeginTransactionForBasePaths,
 		config:             config,
+		auditLog:           newDbAuditLog(),
+		locks:              newOperationLock(config),
+		retry:              defaultRetryPolicy,
 	}
 	return transactionController, nil
 }
@@ -124,12 +360,21 @@ func validateConfig(config ExtensionManagerConfig) error {
 	if config.BucketFSBasePath == "" {
 		return errors.New("missing BucketFSBasePath")
 	}
+	if slices.Contains(config.AdditionalBucketFSBasePaths, "") {
+		return errors.New("empty path in AdditionalBucketFSBasePaths")
+	}
 	if config.ExtensionRegistryURL == "" {
 		return errors.New("missing ExtensionRegistryURL")
 	}
 	if config.ExtensionSchema == "" {
 		return errors.New("missing ExtensionSchema")
 	}
+	if err := validateAllowedExtensionSchemas(config.AllowedExtensionSchemas); err != nil {
+		return err
+	}
+	if err := validateDeprecatedVersionPolicy(config.DeprecatedVersionPolicy); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -137,6 +382,9 @@ type transactionControllerImpl struct {
 	controller         controller
 	transactionStarter transaction.TransactionStarter
 	config             ExtensionManagerConfig
+	auditLog           auditLog
+	locks              *operationLock
+	retry              retryPolicy
 }
 
 func (c *transactionControllerImpl) GetAllExtensions(ctx context.Context, db *sql.DB) ([]*Extension, error) {
@@ -167,53 +415,120 @@ func (c *transactionControllerImpl) listBfsFiles(ctx context.Context, db *sql.DB
 	return bfsFiles, nil
 }
 
-func (c *transactionControllerImpl) InstallExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) (returnErr error) {
-	txCtx, err := c.beginTransaction(ctx, db)
-	if err != nil {
+func (c *transactionControllerImpl) InstallExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) error {
+	return c.InstallExtensionWithOptions(ctx, db, extensionId, extensionVersion, InstallOptions{InstallDependencies: false, AcceptLicenses: false, Force: false})
+}
+
+func (c *transactionControllerImpl) InstallExtensionWithOptions(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options InstallOptions) error {
+	_, err := c.InstallExtensionWithResult(ctx, db, extensionId, extensionVersion, options)
+	return err
+}
+
+func (c *transactionControllerImpl) InstallExtensionWithResult(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options InstallOptions) (*InstallResult, error) {
+	var warnings []string
+	entry := newAuditEntry(AuditOperationInstall, extensionId, extensionVersion)
+	err := c.runAuditedWithDependencies(ctx, db, entry, options.InstallDependencies, func(txCtx *transaction.TransactionContext, entry *AuditEntry) error {
+		err := c.controller.InstallExtension(txCtx, extensionId, extensionVersion, options)
+		warnings = txCtx.GetWarnings()
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	defer txCtx.Rollback()
-	err = c.controller.InstallExtension(txCtx, extensionId, extensionVersion)
-	if err == nil {
-		err = txCtx.Commit()
+	return &InstallResult{Warnings: warnings}, nil
+}
+
+func (c *transactionControllerImpl) DryRunInstallExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options InstallOptions) (*DryRunResult, error) {
+	return c.dryRun(ctx, db, extensionId, func(txCtx *transaction.TransactionContext) error {
+		return c.controller.InstallExtension(txCtx, extensionId, extensionVersion, options)
+	})
+}
+
+func (c *transactionControllerImpl) UninstallExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) error {
+	_, err := c.UninstallExtensionWithOptions(ctx, db, extensionId, extensionVersion, UninstallOptions{Cascade: false})
+	return err
+}
+
+func (c *transactionControllerImpl) UninstallExtensionWithOptions(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options UninstallOptions) (*UninstallResult, error) {
+	var deletedInstances []*extensionAPI.JsExtInstance
+	entry := newAuditEntry(AuditOperationUninstall, extensionId, extensionVersion)
+	err := c.runAudited(ctx, db, entry, func(txCtx *transaction.TransactionContext, entry *AuditEntry) error {
+		var err error
+		deletedInstances, err = c.controller.UninstallExtension(txCtx, extensionId, extensionVersion, options)
 		if err != nil {
 			return err
 		}
+		return c.auditDeletedInstances(txCtx, entry, deletedInstances)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return err
+	return &UninstallResult{ExtensionId: extensionId, ExtensionVersion: extensionVersion, DeletedInstances: deletedInstances}, nil
 }
 
-func (c *transactionControllerImpl) UninstallExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) (returnErr error) {
-	tx, err := c.beginTransaction(ctx, db)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-	err = c.controller.UninstallExtension(tx, extensionId, extensionVersion)
-	if err == nil {
-		err = tx.Commit()
-		if err != nil {
+// auditDeletedInstances records the instances deleted by a cascading uninstall in the audit log.
+func (c *transactionControllerImpl) auditDeletedInstances(txCtx *transaction.TransactionContext, uninstallEntry *AuditEntry, instances []*extensionAPI.JsExtInstance) error {
+	for _, instance := range instances {
+		entry := newAuditEntry(AuditOperationDeleteInstance, uninstallEntry.ExtensionId, uninstallEntry.ExtensionVersion)
+		entry.Timestamp = uninstallEntry.Timestamp
+		entry.InstanceId = instance.Id
+		if err := c.auditLog.write(txCtx.GetContext(), txCtx.GetTransaction(), c.extensionSchema(txCtx.GetContext()), *entry); err != nil {
 			return err
 		}
 	}
-	return err
+	return nil
 }
 
 /* [impl -> dsn~upgrade-extension~1]. */
-func (c *transactionControllerImpl) UpgradeExtension(ctx context.Context, db *sql.DB, extensionId string) (result *extensionAPI.JsUpgradeResult, returnErr error) {
-	tx, err := c.beginTransaction(ctx, db)
+func (c *transactionControllerImpl) UpgradeExtension(ctx context.Context, db *sql.DB, extensionId string) (*extensionAPI.JsUpgradeResult, error) {
+	return c.UpgradeExtensionToVersion(ctx, db, extensionId, "")
+}
+
+func (c *transactionControllerImpl) UpgradeExtensionToVersion(ctx context.Context, db *sql.DB, extensionId string, targetVersion string) (*extensionAPI.JsUpgradeResult, error) {
+	var result *extensionAPI.JsUpgradeResult
+	entry := newAuditEntry(AuditOperationUpgrade, extensionId, targetVersion)
+	err := c.runAudited(ctx, db, entry, func(txCtx *transaction.TransactionContext, entry *AuditEntry) error {
+		var err error
+		result, err = c.controller.UpgradeExtension(txCtx, extensionId, targetVersion)
+		if result != nil {
+			entry.ExtensionVersion = result.NewVersion
+		} else if err != nil {
+			entry.ExtensionVersion = c.resolveUpgradeVersion(extensionId, targetVersion)
+		}
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer tx.Rollback()
-	result, err = c.controller.UpgradeExtension(tx, extensionId)
-	if err == nil {
-		err = tx.Commit()
-		if err != nil {
-			return nil, err
-		}
+	return result, nil
+}
+
+// resolveUpgradeVersion returns the version recorded in the audit log for an upgrade that failed without result.
+// This is the target version or, when upgrading to the latest version, the latest version of the extension.
+func (c *transactionControllerImpl) resolveUpgradeVersion(extensionId string, targetVersion string) string {
+	if targetVersion != "" {
+		return targetVersion
 	}
-	return result, err
+	latestVersion, err := c.controller.GetLatestVersion(extensionId)
+	if err != nil {
+		log.Warnf("Failed to find latest version of extension %q for audit log: %v", extensionId, err)
+		return ""
+	}
+	return latestVersion
+}
+
+func (c *transactionControllerImpl) RepairExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) error {
+	entry := newAuditEntry(AuditOperationRepair, extensionId, extensionVersion)
+	return c.runAudited(ctx, db, entry, func(txCtx *transaction.TransactionContext, entry *AuditEntry) error {
+		return c.controller.RepairExtension(txCtx, extensionId, extensionVersion)
+	})
+}
+
+func (c *transactionControllerImpl) DryRunUpgradeExtension(ctx context.Context, db *sql.DB, extensionId string, targetVersion string) (*DryRunResult, error) {
+	return c.dryRun(ctx, db, extensionId, func(txCtx *transaction.TransactionContext) error {
+		_, err := c.controller.UpgradeExtension(txCtx, extensionId, targetVersion)
+		return err
+	})
 }
 
 func (c *transactionControllerImpl) GetInstalledExtensions(ctx context.Context, db *sql.DB) ([]*extensionAPI.JsExtInstallation, error) {
@@ -228,6 +543,49 @@ func (c *transactionControllerImpl) GetInstalledExtensions(ctx context.Context,
 	return installations, err
 }
 
+func (c *transactionControllerImpl) GetAvailableUpdates(ctx context.Context, db *sql.DB) ([]*ExtensionUpdate, error) {
+	bfsFiles, err := c.listBfsFiles(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.beginTransaction(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return c.controller.GetAvailableUpdates(tx, bfsFiles)
+}
+
+func (c *transactionControllerImpl) DetectDrift(ctx context.Context, db *sql.DB) (*DriftReport, error) {
+	bfsFiles, err := c.listBfsFiles(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.beginTransaction(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return c.controller.DetectDrift(tx, bfsFiles)
+}
+
+func (c *transactionControllerImpl) GetBucketFsUsage(ctx context.Context, db *sql.DB) (*BucketFsUsageReport, error) {
+	bfsFiles, err := c.listBfsFiles(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.beginTransaction(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return c.controller.GetBucketFsUsage(tx, bfsFiles)
+}
+
+func (c *transactionControllerImpl) CheckReadiness() []ReadinessCheck {
+	return c.controller.CheckReadiness()
+}
+
 func (c *transactionControllerImpl) GetParameterDefinitions(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) ([]parameterValidator.ParameterDefinition, error) {
 	tx, err := c.beginTransaction(ctx, db)
 	if err != nil {
@@ -238,19 +596,58 @@ func (c *transactionControllerImpl) GetParameterDefinitions(ctx context.Context,
 }
 
 func (c *transactionControllerImpl) CreateInstance(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, parameterValues []ParameterValue) (*extensionAPI.JsExtInstance, error) {
-	tx, err := c.beginTransaction(ctx, db)
+	result, err := c.CreateInstanceWithOptions(ctx, db, extensionId, extensionVersion, parameterValues, CreateInstanceOptions{Force: false})
 	if err != nil {
 		return nil, err
 	}
-	defer tx.Rollback()
-	instance, err := c.controller.CreateInstance(tx, extensionId, extensionVersion, parameterValues)
-	if err == nil {
-		err = tx.Commit()
-		if err != nil {
-			return nil, err
+	return result.Instance, nil
+}
+
+func (c *transactionControllerImpl) CreateInstanceWithOptions(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, parameterValues []ParameterValue, options CreateInstanceOptions) (*CreateInstanceResult, error) {
+	return c.createInstance(ctx, db, extensionId, extensionVersion, parameterValues, options, nil)
+}
+
+// createInstance creates an instance like [transactionControllerImpl.CreateInstanceWithOptions].
+// If verify is not nil, it checks the new instance before committing. If verify fails, the transaction is rolled back.
+func (c *transactionControllerImpl) createInstance(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, parameterValues []ParameterValue,
+	options CreateInstanceOptions, verify func(instance *extensionAPI.JsExtInstance) error,
+) (*CreateInstanceResult, error) {
+	var instance *extensionAPI.JsExtInstance
+	var warnings []string
+	entry := newAuditEntry(AuditOperationCreateInstance, extensionId, extensionVersion)
+	err := c.runAudited(ctx, db, entry, func(txCtx *transaction.TransactionContext, entry *AuditEntry) error {
+		entry.Parameters = c.redactParameters(txCtx, extensionId, extensionVersion, parameterValues)
+		var err error
+		instance, err = c.controller.CreateInstance(txCtx, extensionId, extensionVersion, parameterValues, options)
+		if instance != nil {
+			entry.InstanceId = instance.Id
 		}
+		warnings = txCtx.GetWarnings()
+		if err == nil && verify != nil {
+			err = verify(instance)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	return instance, err
+	return &CreateInstanceResult{Instance: instance, Warnings: warnings}, nil
+}
+
+func (c *transactionControllerImpl) redactParameters(txCtx *transaction.TransactionContext, extensionId string, extensionVersion string, parameterValues []ParameterValue) []ParameterValue {
+	definitions, err := c.controller.GetParameterDefinitions(txCtx, extensionId, extensionVersion)
+	if err != nil {
+		log.Warnf("Failed to get parameter definitions for redacting audit log, redacting all values: %v", err)
+		return redactParameters(nil, parameterValues)
+	}
+	return redactParameters(definitions, parameterValues)
+}
+
+func (c *transactionControllerImpl) DryRunCreateInstance(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, parameterValues []ParameterValue, options CreateInstanceOptions) (*DryRunResult, error) {
+	return c.dryRun(ctx, db, extensionId, func(txCtx *transaction.TransactionContext) error {
+		_, err := c.controller.CreateInstance(txCtx, extensionId, extensionVersion, parameterValues, options)
+		return err
+	})
 }
 
 func (c *transactionControllerImpl) FindInstances(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) ([]*extensionAPI.JsExtInstance, error) {
@@ -263,23 +660,108 @@ func (c *transactionControllerImpl) FindInstances(ctx context.Context, db *sql.D
 }
 
 func (c *transactionControllerImpl) DeleteInstance(ctx context.Context, db *sql.DB, extensionId, extensionVersion, instanceId string) error {
+	entry := newAuditEntry(AuditOperationDeleteInstance, extensionId, extensionVersion)
+	entry.InstanceId = instanceId
+	return c.runAudited(ctx, db, entry, func(txCtx *transaction.TransactionContext, entry *AuditEntry) error {
+		return c.controller.DeleteInstance(txCtx, extensionId, extensionVersion, instanceId)
+	})
+}
+
+func (c *transactionControllerImpl) GetAuditLog(ctx context.Context, db *sql.DB, filter AuditLogFilter) ([]AuditEntry, error) {
 	tx, err := c.beginTransaction(ctx, db)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback()
-	err = c.controller.DeleteInstance(tx, extensionId, extensionVersion, instanceId)
-	if err == nil {
-		err = tx.Commit()
+	return c.auditLog.find(tx.GetTransaction(), c.extensionSchema(ctx), filter)
+}
+
+func newAuditEntry(operation AuditOperation, extensionId, extensionVersion string) *AuditEntry {
+	return &AuditEntry{Timestamp: time.Time{}, DbUser: "", Operation: operation, ExtensionId: extensionId, ExtensionVersion: extensionVersion,
+		InstanceId: "", Parameters: nil, Outcome: AuditOutcomeSuccess, ErrorMessage: ""}
+}
+
+// runAudited runs the given operation in a new transaction and records it in the audit log.
+// The operation may complete the audit entry, e.g. with the ID of a new instance.
+// Successful operations are recorded in the same transaction, failed operations in a separate transaction after the rollback.
+// Operations that fail acquiring the lock for the extension and upgrades of extensions that are already up to date are not recorded.
+func (c *transactionControllerImpl) runAudited(ctx context.Context, db *sql.DB, entry *AuditEntry, operation func(txCtx *transaction.TransactionContext, entry *AuditEntry) error) error {
+	return c.runAuditedWithDependencies(ctx, db, entry, false, operation)
+}
+
+// runAuditedWithDependencies works like [transactionControllerImpl.runAudited] for operations that may install dependencies
+// of the extension if installDependencies is true. Such operations also lock all dependencies and are only retried
+// if the dependencies allow this, too.
+func (c *transactionControllerImpl) runAuditedWithDependencies(ctx context.Context, db *sql.DB, entry *AuditEntry, installDependencies bool,
+	operation func(txCtx *transaction.TransactionContext, entry *AuditEntry) error,
+) error {
+	extensionIds := []string{entry.ExtensionId}
+	if installDependencies {
+		dependencyIds, err := c.controller.GetDependencyIds(entry.ExtensionId)
 		if err != nil {
 			return err
 		}
+		extensionIds = append(extensionIds, dependencyIds...)
+	}
+	release, err := c.lock(ctx, db, extensionIds...)
+	if err != nil {
+		return err
+	}
+	defer release()
+	started, err := c.runWithRetry(ctx, entry, installDependencies, func() (bool, error) {
+		return c.runAndCommit(ctx, db, entry, operation)
+	})
+	if err != nil && started && !isUpgradeNotRequired(entry, err) {
+		entry.Outcome = AuditOutcomeFailure
+		entry.ErrorMessage = err.Error()
+		if auditErr := c.auditLog.writeInNewTransaction(ctx, db, c.extensionSchema(ctx), *entry); auditErr != nil {
+			log.Warnf("Failed to record failed %s operation for extension %q in audit log: %v", entry.Operation, entry.ExtensionId, auditErr)
+		}
 	}
 	return err
 }
 
+func (c *transactionControllerImpl) runAndCommit(ctx context.Context, db *sql.DB, entry *AuditEntry, operation func(txCtx *transaction.TransactionContext, entry *AuditEntry) error) (started bool, returnErr error) {
+	txCtx, err := c.beginTransaction(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer txCtx.Rollback()
+	entry.Timestamp = time.Now().UTC()
+	err = operation(txCtx, entry)
+	if err != nil {
+		return true, err
+	}
+	if err = c.auditLog.write(ctx, txCtx.GetTransaction(), c.extensionSchema(ctx), *entry); err != nil {
+		return true, err
+	}
+	return true, txCtx.Commit()
+}
+
+// dryRun runs the given operation in a new transaction that is always rolled back
+// and returns the statements and log messages recorded during the operation.
+// If the operation fails, the result contains everything recorded until the failure together with the error.
+func (c *transactionControllerImpl) dryRun(ctx context.Context, db *sql.DB, extensionId string, operation func(txCtx *transaction.TransactionContext) error) (*DryRunResult, error) {
+	release, err := c.lock(ctx, db, extensionId)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	txCtx, err := c.beginTransaction(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer txCtx.Rollback()
+	recorder := txCtx.EnableDryRun()
+	err = operation(txCtx)
+	return &DryRunResult{Statements: recorder.Statements(), LogMessages: recorder.LogMessages(), Warnings: txCtx.GetWarnings()}, err
+}
+
 func (c *transactionControllerImpl) beginTransaction(ctx context.Context, db *sql.DB) (*transaction.TransactionContext, error) {
-	tx, err := c.transactionStarter(ctx, db, c.config.BucketFSBasePath)
+	if err := c.checkExtensionSchema(ctx); err != nil {
+		return nil, err
+	}
+	tx, err := c.transactionStarter(ctx, db, c.config.bucketFsBasePaths())
 	if err != nil {
 		return nil, fmt.Errorf("failed to start transaction: %w", err)
 	}
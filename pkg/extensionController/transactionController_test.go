@@ -4,11 +4,13 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/exasol/extension-manager/pkg/extensionAPI"
 	"github.com/exasol/extension-manager/pkg/extensionController/bfs"
 	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+	"github.com/exasol/extension-manager/pkg/parameterValidator"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -28,6 +30,7 @@ type extCtrlUnitTestSuite struct {
 	mockCtrl               mockControllerImpl
 	bucketFsMock           *bfs.BucketFsMock
 	transactionStarterMock *transaction.TransactionStarterMock
+	auditLogMock           *auditLogMock
 }
 
 func TestExtensionControllerUnitTestSuite(t *testing.T) {
@@ -39,7 +42,9 @@ func (suite *extCtrlUnitTestSuite) SetupTest() {
 	suite.mockCtrl = createMockControllerImpl()
 	suite.bucketFsMock = bfs.CreateBucketFsMock()
 	suite.transactionStarterMock = transaction.CreateTransactionStarterMock(suite.db, suite.bucketFsMock)
+	suite.auditLogMock = createAuditLogMock()
 	suite.ctrl = &transactionControllerImpl{
+		auditLog:           suite.auditLogMock,
 		controller:         &suite.mockCtrl,
 		transactionStarter: suite.transactionStarterMock.GetTransactionStarter(),
 		config: ExtensionManagerConfig{
@@ -47,6 +52,7 @@ func (suite *extCtrlUnitTestSuite) SetupTest() {
 			BucketFSBasePath:     "bfs-base-path",
 			ExtensionSchema:      "ext-schema",
 		},
+		locks: newOperationLock(ExtensionManagerConfig{}),
 	}
 }
 
@@ -89,6 +95,14 @@ func (suite *extCtrlUnitTestSuite) TestCreateWithValidatedConfigFailure() {
 		{name: "missing schema", config: ExtensionManagerConfig{ExtensionRegistryURL: "url", BucketFSBasePath: "bfspath", ExtensionSchema: ""}, expectedError: "invalid configuration: missing ExtensionSchema"},
 		{name: "empty schema", config: ExtensionManagerConfig{ExtensionRegistryURL: "url", BucketFSBasePath: "bfspath", ExtensionSchema: ""}, expectedError: "invalid configuration: missing ExtensionSchema"},
 		{name: "all missing", config: ExtensionManagerConfig{ExtensionRegistryURL: "", BucketFSBasePath: "", ExtensionSchema: ""}, expectedError: "invalid configuration: missing BucketFSBasePath"},
+		{name: "invalid deprecated version policy", config: ExtensionManagerConfig{ExtensionRegistryURL: "url", BucketFSBasePath: "bfspath", ExtensionSchema: "schema", DeprecatedVersionPolicy: "invalid"},
+			expectedError: `invalid configuration: invalid DeprecatedVersionPolicy "invalid", expected one of "allow", "warn" or "reject"`},
+		{name: "empty additional bucketfs base path", config: ExtensionManagerConfig{ExtensionRegistryURL: "url", BucketFSBasePath: "bfspath", ExtensionSchema: "schema", AdditionalBucketFSBasePaths: []string{""}},
+			expectedError: "invalid configuration: empty path in AdditionalBucketFSBasePaths"},
+		{name: "empty allowed extension schema", config: ExtensionManagerConfig{ExtensionRegistryURL: "url", BucketFSBasePath: "bfspath", ExtensionSchema: "schema", AllowedExtensionSchemas: []string{""}},
+			expectedError: "invalid configuration: empty schema in AllowedExtensionSchemas"},
+		{name: "invalid allowed extension schema", config: ExtensionManagerConfig{ExtensionRegistryURL: "url", BucketFSBasePath: "bfspath", ExtensionSchema: "schema", AllowedExtensionSchemas: []string{`a"b`}},
+			expectedError: `invalid configuration: invalid schema "a\"b" in AllowedExtensionSchemas`},
 	}
 	for _, test := range tests {
 		suite.Run(test.name, func() {
@@ -168,7 +182,7 @@ func (suite *extCtrlUnitTestSuite) TestInstallExtensionBeginTransactionFailure()
 
 func (suite *extCtrlUnitTestSuite) TestInstallExtensionSuccess() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer").Return(nil)
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{InstallDependencies: false}).Return(nil)
 	suite.dbMock.ExpectCommit()
 	err := suite.ctrl.InstallExtension(mockContext(), suite.db, "extId", "extVer")
 	suite.Require().NoError(err)
@@ -176,7 +190,7 @@ func (suite *extCtrlUnitTestSuite) TestInstallExtensionSuccess() {
 
 func (suite *extCtrlUnitTestSuite) TestInstallExtensionFailureRollback() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer").Return(mockError)
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{InstallDependencies: false}).Return(mockError)
 	suite.dbMock.ExpectRollback()
 	err := suite.ctrl.InstallExtension(mockContext(), suite.db, "extId", "extVer")
 	suite.Require().EqualError(err, mockErrorMsg)
@@ -184,12 +198,281 @@ func (suite *extCtrlUnitTestSuite) TestInstallExtensionFailureRollback() {
 
 func (suite *extCtrlUnitTestSuite) TestInstallExtensionCommitFailure() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer").Return(nil)
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{InstallDependencies: false}).Return(nil)
 	suite.dbMock.ExpectCommit().WillReturnError(mockError)
 	err := suite.ctrl.InstallExtension(mockContext(), suite.db, "extId", "extVer")
 	suite.Require().EqualError(err, mockErrorMsg)
 }
 
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionWithOptionsSuccess() {
+	suite.mockCtrl.On("GetDependencyIds", "extId").Return([]string{"depId"}, nil)
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{InstallDependencies: true}).Return(nil)
+	suite.dbMock.ExpectCommit()
+	err := suite.ctrl.InstallExtensionWithOptions(mockContext(), suite.db, "extId", "extVer", InstallOptions{InstallDependencies: true})
+	suite.Require().NoError(err)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionWithOptionsFailureRollback() {
+	suite.mockCtrl.On("GetDependencyIds", "extId").Return([]string{}, nil)
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{InstallDependencies: true}).Return(mockError)
+	suite.dbMock.ExpectRollback()
+	err := suite.ctrl.InstallExtensionWithOptions(mockContext(), suite.db, "extId", "extVer", InstallOptions{InstallDependencies: true})
+	suite.Require().EqualError(err, mockErrorMsg)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionWithDependenciesWaitsForLockOfDependency() {
+	suite.ctrl.(*transactionControllerImpl).locks = newOperationLock(ExtensionManagerConfig{LockTimeout: 10 * time.Millisecond})
+	release, err := suite.ctrl.(*transactionControllerImpl).lock(mockContext(), suite.db, "depId")
+	suite.Require().NoError(err)
+	defer release()
+	suite.mockCtrl.On("GetDependencyIds", "extId").Return([]string{"depId"}, nil)
+	err = suite.ctrl.InstallExtensionWithOptions(mockContext(), suite.db, "extId", "extVer", InstallOptions{InstallDependencies: true})
+	suite.Require().EqualError(err, `another operation on extension "depId" is in progress, please try again later`)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionWithoutDependenciesDoesNotLockDependencies() {
+	release, err := suite.ctrl.(*transactionControllerImpl).lock(mockContext(), suite.db, "depId")
+	suite.Require().NoError(err)
+	defer release()
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{}).Return(nil)
+	suite.dbMock.ExpectCommit()
+	err = suite.ctrl.InstallExtensionWithOptions(mockContext(), suite.db, "extId", "extVer", InstallOptions{})
+	suite.Require().NoError(err)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionWithDependenciesFailsIfDependenciesCannotBeLoaded() {
+	suite.mockCtrl.On("GetDependencyIds", "extId").Return(nil, mockError)
+	err := suite.ctrl.InstallExtensionWithOptions(mockContext(), suite.db, "extId", "extVer", InstallOptions{InstallDependencies: true})
+	suite.Require().EqualError(err, mockErrorMsg)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionWithResultReturnsWarnings() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{Force: true}).Run(addWarning("deprecated")).Return(nil)
+	suite.dbMock.ExpectCommit()
+	result, err := suite.ctrl.InstallExtensionWithResult(mockContext(), suite.db, "extId", "extVer", InstallOptions{Force: true})
+	suite.Require().NoError(err)
+	suite.Equal(&InstallResult{Warnings: []string{"deprecated"}}, result)
+}
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionWithResultFailure() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{}).Run(addWarning("deprecated")).Return(mockError)
+	suite.dbMock.ExpectRollback()
+	result, err := suite.ctrl.InstallExtensionWithResult(mockContext(), suite.db, "extId", "extVer", InstallOptions{})
+	suite.Require().EqualError(err, mockErrorMsg)
+	suite.Nil(result)
+}
+
+// addWarning simulates a controller that adds the given warning to the transaction.
+func addWarning(warning string) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		txCtx := args.Get(0).(*transaction.TransactionContext)
+		txCtx.AddWarning(warning)
+	}
+}
+
+// DryRun
+
+func (suite *extCtrlUnitTestSuite) TestDryRunInstallExtensionRollsBack() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{InstallDependencies: true, AcceptLicenses: false}).
+		Run(recordDryRun("CREATE SCRIPT s", "installing")).Return(nil)
+	suite.dbMock.ExpectRollback()
+	result, err := suite.ctrl.DryRunInstallExtension(mockContext(), suite.db, "extId", "extVer", InstallOptions{InstallDependencies: true, AcceptLicenses: false})
+	suite.Require().NoError(err)
+	suite.Equal(&DryRunResult{
+		Statements:  []transaction.RecordedStatement{{Query: "CREATE SCRIPT s", Args: []any{"arg"}}},
+		LogMessages: []transaction.RecordedLogMessage{{Level: "log", Message: "installing"}}}, result)
+}
+
+func (suite *extCtrlUnitTestSuite) TestDryRunInstallExtensionReturnsWarnings() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{Force: true}).Run(addWarning("deprecated")).Return(nil)
+	suite.dbMock.ExpectRollback()
+	result, err := suite.ctrl.DryRunInstallExtension(mockContext(), suite.db, "extId", "extVer", InstallOptions{Force: true})
+	suite.Require().NoError(err)
+	suite.Equal([]string{"deprecated"}, result.Warnings)
+}
+
+func (suite *extCtrlUnitTestSuite) TestDryRunInstallExtensionFailure() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{InstallDependencies: false, AcceptLicenses: false}).
+		Run(recordDryRun("CREATE SCRIPT s", "installing")).Return(mockError)
+	suite.dbMock.ExpectRollback()
+	result, err := suite.ctrl.DryRunInstallExtension(mockContext(), suite.db, "extId", "extVer", InstallOptions{InstallDependencies: false, AcceptLicenses: false})
+	suite.Require().EqualError(err, mockErrorMsg)
+	suite.Equal(&DryRunResult{
+		Statements:  []transaction.RecordedStatement{{Query: "CREATE SCRIPT s", Args: []any{"arg"}}},
+		LogMessages: []transaction.RecordedLogMessage{{Level: "log", Message: "installing"}}}, result, "recorded statements returned with error")
+}
+
+func (suite *extCtrlUnitTestSuite) TestDryRunBeginTransactionFailure() {
+	suite.dbMock.ExpectBegin().WillReturnError(mockError)
+	result, err := suite.ctrl.DryRunUpgradeExtension(mockContext(), suite.db, "extId", "")
+	suite.Require().EqualError(err, beginMockTransactionFailedErrorMsg)
+	suite.Nil(result)
+}
+
+func (suite *extCtrlUnitTestSuite) TestDryRunUpgradeExtensionRollsBack() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId", "").Run(recordDryRun("ALTER SCRIPT s", "upgrading")).
+		Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}, nil)
+	suite.dbMock.ExpectRollback()
+	result, err := suite.ctrl.DryRunUpgradeExtension(mockContext(), suite.db, "extId", "")
+	suite.Require().NoError(err)
+	suite.Equal([]transaction.RecordedStatement{{Query: "ALTER SCRIPT s", Args: []any{"arg"}}}, result.Statements)
+}
+
+func (suite *extCtrlUnitTestSuite) TestDryRunCreateInstanceRollsBack() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("CreateInstance", mock.Anything, "extId", "extVer", mock.Anything, CreateInstanceOptions{Force: false}).Run(recordDryRun("CREATE VIRTUAL SCHEMA vs", "creating")).
+		Return(&extensionAPI.JsExtInstance{Id: "instId", Name: "newInst"}, nil)
+	suite.dbMock.ExpectRollback()
+	result, err := suite.ctrl.DryRunCreateInstance(mockContext(), suite.db, "extId", "extVer", []ParameterValue{}, CreateInstanceOptions{Force: false})
+	suite.Require().NoError(err)
+	suite.Equal([]transaction.RecordedStatement{{Query: "CREATE VIRTUAL SCHEMA vs", Args: []any{"arg"}}}, result.Statements)
+	suite.Equal([]transaction.RecordedLogMessage{{Level: "log", Message: "creating"}}, result.LogMessages)
+}
+
+// recordDryRun simulates an extension that executes the given statement and logs the given message.
+func recordDryRun(statement, logMessage string) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		recorder := args.Get(0).(*transaction.TransactionContext).GetDryRunRecorder()
+		recorder.RecordStatement(statement, "arg")
+		recorder.RecordLogMessage("log", logMessage)
+	}
+}
+
+// Audit log
+
+func (suite *extCtrlUnitTestSuite) TestInstallExtensionWritesAuditEntryInSameTransaction() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("InstallExtension", mock.Anything, "extId", "extVer", InstallOptions{InstallDependencies: false, AcceptLicenses: false}).Return(nil)
+	suite.dbMock.ExpectCommit()
+	err := suite.ctrl.InstallExtension(mockContext(), suite.db, "extId", "extVer")
+	suite.Require().NoError(err)
+	suite.Require().Len(suite.auditLogMock.written, 1)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationInstall, ExtensionId: "extId", ExtensionVersion: "extVer", Outcome: AuditOutcomeSuccess}, suite.auditLogMock.written[0])
+	suite.Empty(suite.auditLogMock.writtenSeparately)
+}
+
+func (suite *extCtrlUnitTestSuite) TestFailedOperationWritesAuditEntryInNewTransaction() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UninstallExtension", mock.Anything, "extId", "extVer", UninstallOptions{Cascade: false}).Return(nil, mockError)
+	suite.dbMock.ExpectRollback()
+	err := suite.ctrl.UninstallExtension(mockContext(), suite.db, "extId", "extVer")
+	suite.Require().EqualError(err, mockErrorMsg)
+	suite.Empty(suite.auditLogMock.written)
+	suite.Require().Len(suite.auditLogMock.writtenSeparately, 1)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationUninstall, ExtensionId: "extId", ExtensionVersion: "extVer", Outcome: AuditOutcomeFailure, ErrorMessage: mockErrorMsg},
+		suite.auditLogMock.writtenSeparately[0])
+}
+
+func (suite *extCtrlUnitTestSuite) TestFailureToWriteAuditEntryRollsBackOperation() {
+	suite.auditLogMock.writeErr = errors.New("audit failed")
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("DeleteInstance", mock.Anything, "extId", "extVer", "instId").Return(nil)
+	suite.dbMock.ExpectRollback()
+	err := suite.ctrl.DeleteInstance(mockContext(), suite.db, "extId", "extVer", "instId")
+	suite.Require().EqualError(err, "audit failed")
+	suite.Require().Len(suite.auditLogMock.writtenSeparately, 1)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationDeleteInstance, ExtensionId: "extId", ExtensionVersion: "extVer", InstanceId: "instId",
+		Outcome: AuditOutcomeFailure, ErrorMessage: "audit failed"}, suite.auditLogMock.writtenSeparately[0])
+}
+
+func (suite *extCtrlUnitTestSuite) TestBeginTransactionFailureIsNotAudited() {
+	suite.dbMock.ExpectBegin().WillReturnError(mockError)
+	err := suite.ctrl.InstallExtension(mockContext(), suite.db, "extId", "extVer")
+	suite.Require().EqualError(err, beginMockTransactionFailedErrorMsg)
+	suite.Empty(suite.auditLogMock.written)
+	suite.Empty(suite.auditLogMock.writtenSeparately)
+}
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeWritesNewVersionToAuditLog() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId", "").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}, nil)
+	suite.dbMock.ExpectCommit()
+	_, err := suite.ctrl.UpgradeExtension(mockContext(), suite.db, "extId")
+	suite.Require().NoError(err)
+	suite.Require().Len(suite.auditLogMock.written, 1)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationUpgrade, ExtensionId: "extId", ExtensionVersion: "new", Outcome: AuditOutcomeSuccess}, suite.auditLogMock.written[0])
+}
+
+func (suite *extCtrlUnitTestSuite) TestRepairWritesAuditEntry() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("RepairExtension", mock.Anything, "extId", "extVer").Return(nil)
+	suite.dbMock.ExpectCommit()
+	err := suite.ctrl.RepairExtension(mockContext(), suite.db, "extId", "extVer")
+	suite.Require().NoError(err)
+	suite.Require().Len(suite.auditLogMock.written, 1)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationRepair, ExtensionId: "extId", ExtensionVersion: "extVer", Outcome: AuditOutcomeSuccess}, suite.auditLogMock.written[0])
+}
+
+func (suite *extCtrlUnitTestSuite) TestRepairFailureWritesAuditEntry() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("RepairExtension", mock.Anything, "extId", "extVer").Return(mockError)
+	suite.dbMock.ExpectRollback()
+	err := suite.ctrl.RepairExtension(mockContext(), suite.db, "extId", "extVer")
+	suite.Require().EqualError(err, mockErrorMsg)
+	suite.Require().Len(suite.auditLogMock.writtenSeparately, 1)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationRepair, ExtensionId: "extId", ExtensionVersion: "extVer", Outcome: AuditOutcomeFailure, ErrorMessage: mockErrorMsg},
+		suite.auditLogMock.writtenSeparately[0])
+}
+
+func (suite *extCtrlUnitTestSuite) TestCreateInstanceWritesRedactedParametersToAuditLog() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("GetParameterDefinitions", "extId", "extVer").Return([]parameterValidator.ParameterDefinition{
+		{Id: "user", Name: "User", RawDefinition: map[string]any{"id": "user"}},
+		{Id: "password", Name: "Password", RawDefinition: map[string]any{"id": "password", "secret": true}}}, nil)
+	suite.mockCtrl.On("CreateInstance", mock.Anything, "extId", "extVer", mock.Anything, CreateInstanceOptions{Force: false}).Return(&extensionAPI.JsExtInstance{Id: "instId", Name: "newInst"}, nil)
+	suite.dbMock.ExpectCommit()
+	_, err := suite.ctrl.CreateInstance(mockContext(), suite.db, "extId", "extVer", []ParameterValue{{Name: "user", Value: "admin"}, {Name: "password", Value: "pwd"}})
+	suite.Require().NoError(err)
+	suite.Require().Len(suite.auditLogMock.written, 1)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationCreateInstance, ExtensionId: "extId", ExtensionVersion: "extVer", InstanceId: "instId",
+		Parameters: []ParameterValue{{Name: "user", Value: "admin"}, {Name: "password", Value: "***"}}, Outcome: AuditOutcomeSuccess}, suite.auditLogMock.written[0])
+}
+
+func (suite *extCtrlUnitTestSuite) TestCreateInstanceRedactsAllParametersIfDefinitionsAreUnavailable() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("GetParameterDefinitions", "extId", "extVer").Return(nil, mockError)
+	suite.mockCtrl.On("CreateInstance", mock.Anything, "extId", "extVer", mock.Anything, CreateInstanceOptions{Force: false}).Return(&extensionAPI.JsExtInstance{Id: "instId", Name: "newInst"}, nil)
+	suite.dbMock.ExpectCommit()
+	_, err := suite.ctrl.CreateInstance(mockContext(), suite.db, "extId", "extVer", []ParameterValue{{Name: "user", Value: "admin"}})
+	suite.Require().NoError(err)
+	suite.Equal([]ParameterValue{{Name: "user", Value: "***"}}, suite.auditLogMock.written[0].Parameters)
+}
+
+func (suite *extCtrlUnitTestSuite) TestGetAuditLog() {
+	entries := []AuditEntry{{Operation: AuditOperationInstall, ExtensionId: "extId", Outcome: AuditOutcomeSuccess}}
+	suite.auditLogMock.findResult = entries
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	result, err := suite.ctrl.GetAuditLog(mockContext(), suite.db, AuditLogFilter{ExtensionId: "extId", Limit: 10})
+	suite.Require().NoError(err)
+	suite.Equal(entries, result)
+	suite.Equal(AuditLogFilter{ExtensionId: "extId", Limit: 10}, suite.auditLogMock.lastFilter)
+}
+
+func (suite *extCtrlUnitTestSuite) TestGetAuditLogBeginTransactionFailure() {
+	suite.dbMock.ExpectBegin().WillReturnError(mockError)
+	result, err := suite.ctrl.GetAuditLog(mockContext(), suite.db, AuditLogFilter{})
+	suite.Require().EqualError(err, beginMockTransactionFailedErrorMsg)
+	suite.Nil(result)
+}
+
+// assertAuditEntry compares the entries ignoring the timestamp set by the controller.
+func (suite *extCtrlUnitTestSuite) assertAuditEntry(expected AuditEntry, actual AuditEntry) {
+	suite.T().Helper()
+	suite.False(actual.Timestamp.IsZero(), "timestamp not set")
+	actual.Timestamp = time.Time{}
+	suite.Equal(expected, actual)
+}
+
 // UninstallExtension
 
 func (suite *extCtrlUnitTestSuite) TestUninstallExtensionBeginTransactionFailure() {
@@ -200,7 +483,7 @@ func (suite *extCtrlUnitTestSuite) TestUninstallExtensionBeginTransactionFailure
 
 func (suite *extCtrlUnitTestSuite) TestUninstallExtensionSuccess() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("UninstallExtension", mock.Anything, "extId", "extVer").Return(nil)
+	suite.mockCtrl.On("UninstallExtension", mock.Anything, "extId", "extVer", UninstallOptions{Cascade: false}).Return(nil, nil)
 	suite.dbMock.ExpectCommit()
 	err := suite.ctrl.UninstallExtension(mockContext(), suite.db, "extId", "extVer")
 	suite.Require().NoError(err)
@@ -208,15 +491,39 @@ func (suite *extCtrlUnitTestSuite) TestUninstallExtensionSuccess() {
 
 func (suite *extCtrlUnitTestSuite) TestUninstallExtensionFailureRollback() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("UninstallExtension", mock.Anything, "extId", "extVer").Return(mockError)
+	suite.mockCtrl.On("UninstallExtension", mock.Anything, "extId", "extVer", UninstallOptions{Cascade: false}).Return(nil, mockError)
 	suite.dbMock.ExpectRollback()
 	err := suite.ctrl.UninstallExtension(mockContext(), suite.db, "extId", "extVer")
 	suite.Require().EqualError(err, mockErrorMsg)
 }
 
+func (suite *extCtrlUnitTestSuite) TestUninstallExtensionWithCascadeAuditsDeletedInstances() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UninstallExtension", mock.Anything, "extId", "extVer", UninstallOptions{Cascade: true}).
+		Return([]*extensionAPI.JsExtInstance{{Id: "inst1", Name: "instance1"}, {Id: "inst2", Name: "instance2"}}, nil)
+	suite.dbMock.ExpectCommit()
+	result, err := suite.ctrl.UninstallExtensionWithOptions(mockContext(), suite.db, "extId", "extVer", UninstallOptions{Cascade: true})
+	suite.Require().NoError(err)
+	suite.Equal(&UninstallResult{ExtensionId: "extId", ExtensionVersion: "extVer",
+		DeletedInstances: []*extensionAPI.JsExtInstance{{Id: "inst1", Name: "instance1"}, {Id: "inst2", Name: "instance2"}}}, result)
+	suite.Require().Len(suite.auditLogMock.written, 3)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationDeleteInstance, ExtensionId: "extId", ExtensionVersion: "extVer", InstanceId: "inst1", Outcome: AuditOutcomeSuccess}, suite.auditLogMock.written[0])
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationDeleteInstance, ExtensionId: "extId", ExtensionVersion: "extVer", InstanceId: "inst2", Outcome: AuditOutcomeSuccess}, suite.auditLogMock.written[1])
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationUninstall, ExtensionId: "extId", ExtensionVersion: "extVer", Outcome: AuditOutcomeSuccess}, suite.auditLogMock.written[2])
+}
+
+func (suite *extCtrlUnitTestSuite) TestUninstallExtensionWithCascadeFailure() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UninstallExtension", mock.Anything, "extId", "extVer", UninstallOptions{Cascade: true}).Return(nil, mockError)
+	suite.dbMock.ExpectRollback()
+	result, err := suite.ctrl.UninstallExtensionWithOptions(mockContext(), suite.db, "extId", "extVer", UninstallOptions{Cascade: true})
+	suite.Require().EqualError(err, mockErrorMsg)
+	suite.Nil(result)
+}
+
 func (suite *extCtrlUnitTestSuite) TestUninstallExtensionCommitFailure() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("UninstallExtension", mock.Anything, "extId", "extVer").Return(nil)
+	suite.mockCtrl.On("UninstallExtension", mock.Anything, "extId", "extVer", UninstallOptions{Cascade: false}).Return(nil, nil)
 	suite.dbMock.ExpectCommit().WillReturnError(mockError)
 	err := suite.ctrl.UninstallExtension(mockContext(), suite.db, "extId", "extVer")
 	suite.Require().EqualError(err, mockErrorMsg)
@@ -234,25 +541,81 @@ func (suite *extCtrlUnitTestSuite) TestUpgradeBeginTransactionFailure() {
 /* [utest -> dsn~upgrade-extension~1]. */
 func (suite *extCtrlUnitTestSuite) TestUpgradeSuccess() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}, nil)
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId", "").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}, nil)
 	suite.dbMock.ExpectCommit()
 	result, err := suite.ctrl.UpgradeExtension(mockContext(), suite.db, "extId")
 	suite.Require().NoError(err)
 	suite.Equal(&extensionAPI.JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}, result)
 }
 
+func (suite *extCtrlUnitTestSuite) TestUpgradeToVersionSuccess() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId", "1.0.0").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "2.0.0", NewVersion: "1.0.0"}, nil)
+	suite.dbMock.ExpectCommit()
+	result, err := suite.ctrl.UpgradeExtensionToVersion(mockContext(), suite.db, "extId", "1.0.0")
+	suite.Require().NoError(err)
+	suite.Equal(&extensionAPI.JsUpgradeResult{PreviousVersion: "2.0.0", NewVersion: "1.0.0"}, result)
+}
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeToVersionFailureWritesTargetVersionToAuditLog() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId", "1.0.0").Return(nil, mockError)
+	suite.dbMock.ExpectRollback()
+	result, err := suite.ctrl.UpgradeExtensionToVersion(mockContext(), suite.db, "extId", "1.0.0")
+	suite.Require().EqualError(err, mockErrorMsg)
+	suite.Nil(result)
+	suite.Require().Len(suite.auditLogMock.writtenSeparately, 1)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationUpgrade, ExtensionId: "extId", ExtensionVersion: "1.0.0", Outcome: AuditOutcomeFailure, ErrorMessage: mockErrorMsg},
+		suite.auditLogMock.writtenSeparately[0])
+}
+
 func (suite *extCtrlUnitTestSuite) TestUpgradeFailure() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId").Return(nil, mockError)
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId", "").Return(nil, mockError)
+	suite.mockCtrl.On("GetLatestVersion", "extId").Return("2.0.0", nil)
 	suite.dbMock.ExpectRollback()
 	result, err := suite.ctrl.UpgradeExtension(mockContext(), suite.db, "extId")
 	suite.Require().EqualError(err, mockErrorMsg)
 	suite.Nil(result)
 }
 
+func (suite *extCtrlUnitTestSuite) TestUpgradeOfUpToDateExtensionIsNotWrittenToAuditLog() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId", "").Return(nil, alreadyUpToDateError)
+	suite.mockCtrl.On("GetLatestVersion", "extId").Return("2.0.0", nil)
+	suite.dbMock.ExpectRollback()
+	_, err := suite.ctrl.UpgradeExtension(mockContext(), suite.db, "extId")
+	suite.Require().ErrorIs(err, alreadyUpToDateError)
+	suite.Empty(suite.auditLogMock.written)
+	suite.Empty(suite.auditLogMock.writtenSeparately)
+}
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeFailureWritesLatestVersionToAuditLog() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId", "").Return(nil, mockError)
+	suite.mockCtrl.On("GetLatestVersion", "extId").Return("2.0.0", nil)
+	suite.dbMock.ExpectRollback()
+	_, err := suite.ctrl.UpgradeExtension(mockContext(), suite.db, "extId")
+	suite.Require().EqualError(err, mockErrorMsg)
+	suite.Require().Len(suite.auditLogMock.writtenSeparately, 1)
+	suite.assertAuditEntry(AuditEntry{Operation: AuditOperationUpgrade, ExtensionId: "extId", ExtensionVersion: "2.0.0", Outcome: AuditOutcomeFailure, ErrorMessage: mockErrorMsg},
+		suite.auditLogMock.writtenSeparately[0])
+}
+
+func (suite *extCtrlUnitTestSuite) TestUpgradeFailureWritesEmptyVersionToAuditLogIfLatestVersionUnknown() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId", "").Return(nil, mockError)
+	suite.mockCtrl.On("GetLatestVersion", "extId").Return("", mockError)
+	suite.dbMock.ExpectRollback()
+	_, err := suite.ctrl.UpgradeExtension(mockContext(), suite.db, "extId")
+	suite.Require().EqualError(err, mockErrorMsg)
+	suite.Require().Len(suite.auditLogMock.writtenSeparately, 1)
+	suite.Equal("", suite.auditLogMock.writtenSeparately[0].ExtensionVersion)
+}
+
 func (suite *extCtrlUnitTestSuite) TestUpgradeCommitFailure() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}, nil)
+	suite.mockCtrl.On("UpgradeExtension", mock.Anything, "extId", "").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}, nil)
 	suite.dbMock.ExpectCommit().WillReturnError(mockError)
 	result, err := suite.ctrl.UpgradeExtension(mockContext(), suite.db, "extId")
 	suite.Require().EqualError(err, mockErrorMsg)
@@ -270,16 +633,29 @@ func (suite *extCtrlUnitTestSuite) TestCreateInstanceBeginTransactionFailure() {
 
 func (suite *extCtrlUnitTestSuite) TestCreateInstanceSuccess() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("CreateInstance", mock.Anything, "extId", "extVer", mock.Anything).Return(&extensionAPI.JsExtInstance{Id: "instId", Name: "newInst"}, nil)
+	suite.mockCtrl.On("GetParameterDefinitions", "extId", "extVer").Return([]parameterValidator.ParameterDefinition{}, nil)
+	suite.mockCtrl.On("CreateInstance", mock.Anything, "extId", "extVer", mock.Anything, CreateInstanceOptions{Force: false}).Return(&extensionAPI.JsExtInstance{Id: "instId", Name: "newInst"}, nil)
 	suite.dbMock.ExpectCommit()
 	instance, err := suite.ctrl.CreateInstance(mockContext(), suite.db, "extId", "extVer", []ParameterValue{})
 	suite.Require().NoError(err)
 	suite.Equal(&extensionAPI.JsExtInstance{Id: "instId", Name: "newInst"}, instance)
 }
 
+func (suite *extCtrlUnitTestSuite) TestCreateInstanceWithOptionsReturnsWarnings() {
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("GetParameterDefinitions", "extId", "extVer").Return([]parameterValidator.ParameterDefinition{}, nil)
+	suite.mockCtrl.On("CreateInstance", mock.Anything, "extId", "extVer", mock.Anything, CreateInstanceOptions{Force: true}).Run(addWarning("deprecated")).
+		Return(&extensionAPI.JsExtInstance{Id: "instId", Name: "newInst"}, nil)
+	suite.dbMock.ExpectCommit()
+	result, err := suite.ctrl.CreateInstanceWithOptions(mockContext(), suite.db, "extId", "extVer", []ParameterValue{}, CreateInstanceOptions{Force: true})
+	suite.Require().NoError(err)
+	suite.Equal(&CreateInstanceResult{Instance: &extensionAPI.JsExtInstance{Id: "instId", Name: "newInst"}, Warnings: []string{"deprecated"}}, result)
+}
+
 func (suite *extCtrlUnitTestSuite) TestCreateInstanceFailure() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("CreateInstance", mock.Anything, "extId", "extVer", mock.Anything).Return(nil, mockError)
+	suite.mockCtrl.On("GetParameterDefinitions", "extId", "extVer").Return([]parameterValidator.ParameterDefinition{}, nil)
+	suite.mockCtrl.On("CreateInstance", mock.Anything, "extId", "extVer", mock.Anything, CreateInstanceOptions{Force: false}).Return(nil, mockError)
 	suite.dbMock.ExpectRollback()
 	instance, err := suite.ctrl.CreateInstance(mockContext(), suite.db, "extId", "extVer", []ParameterValue{})
 	suite.Require().EqualError(err, mockErrorMsg)
@@ -288,7 +664,8 @@ func (suite *extCtrlUnitTestSuite) TestCreateInstanceFailure() {
 
 func (suite *extCtrlUnitTestSuite) TestCreateInstanceCommitFailure() {
 	suite.dbMock.ExpectBegin()
-	suite.mockCtrl.On("CreateInstance", mock.Anything, "extId", "extVer", mock.Anything).Return(&extensionAPI.JsExtInstance{Id: "instId", Name: "newInst"}, nil)
+	suite.mockCtrl.On("GetParameterDefinitions", "extId", "extVer").Return([]parameterValidator.ParameterDefinition{}, nil)
+	suite.mockCtrl.On("CreateInstance", mock.Anything, "extId", "extVer", mock.Anything, CreateInstanceOptions{Force: false}).Return(&extensionAPI.JsExtInstance{Id: "instId", Name: "newInst"}, nil)
 	suite.dbMock.ExpectCommit().WillReturnError(mockError)
 	instance, err := suite.ctrl.CreateInstance(mockContext(), suite.db, "extId", "extVer", []ParameterValue{})
 	suite.Require().EqualError(err, mockErrorMsg)
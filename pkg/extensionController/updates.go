@@ -0,0 +1,101 @@
+package extensionController
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/mod/semver"
+
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionController/bfs"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+)
+
+// ExtensionUpdate compares an installed extension with the versions available in the registry.
+type ExtensionUpdate struct {
+	ExtensionId      string
+	Name             string
+	InstalledVersion string
+	// LatestVersion is the version marked as latest by the extension or the highest installable version if none is marked.
+	// It is empty if the extension has no installable versions.
+	LatestVersion string
+	// UpdateAvailable is true if the latest version is newer than the installed version.
+	UpdateAvailable bool
+	// InstalledVersionDeprecated is true if the installed version is marked as deprecated.
+	InstalledVersionDeprecated bool
+	// LatestVersionFilesAvailable is true if all files required by the latest version exist in BucketFS with the expected size.
+	LatestVersionFilesAvailable bool
+}
+
+func (c *controllerImpl) GetAvailableUpdates(txCtx *transaction.TransactionContext, bfsFiles []bfs.BfsFile) ([]*ExtensionUpdate, error) {
+	metadata, err := c.metaDataReader.ReadMetadataTables(txCtx.GetTransaction(), c.extensionSchema(txCtx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata tables. Cause: %w", err)
+	}
+	extensions, err := c.getAllExtensions()
+	if err != nil {
+		return nil, err
+	}
+	installations, err := c.findInstallations(txCtx, metadata, extensions)
+	if err != nil {
+		return nil, err
+	}
+	extensionsById := make(map[string]*extensionAPI.JsExtension, len(extensions))
+	for _, extension := range extensions {
+		extensionsById[extension.Id] = extension
+	}
+	updates := make([]*ExtensionUpdate, 0, len(installations))
+	for _, installation := range installations {
+		update := c.createExtensionUpdate(extensionsById[installation.ID], installation, bfsFiles)
+		if update.UpdateAvailable {
+			log.Debugf("Update of extension %q from version %q to %q available", update.ExtensionId, update.InstalledVersion, update.LatestVersion)
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
+
+func (c *controllerImpl) createExtensionUpdate(extension *extensionAPI.JsExtension, installation *extensionAPI.JsExtInstallation, bfsFiles []bfs.BfsFile) *ExtensionUpdate {
+	latestVersion := findLatestVersion(extension.InstallableVersions)
+	return &ExtensionUpdate{
+		ExtensionId:                 installation.ID,
+		Name:                        installation.Name,
+		InstalledVersion:            installation.Version,
+		LatestVersion:               latestVersion,
+		UpdateAvailable:             isNewerVersion(latestVersion, installation.Version),
+		InstalledVersionDeprecated:  isDeprecatedVersion(extension.InstallableVersions, installation.Version),
+		LatestVersionFilesAvailable: c.requiredFilesAvailable(extension, bfsFiles),
+	}
+}
+
+// findLatestVersion returns the version marked as latest or the highest version if none is marked.
+func findLatestVersion(versions []extensionAPI.JsExtensionVersion) string {
+	latestVersion := ""
+	for _, version := range versions {
+		if version.Latest {
+			return version.Name
+		}
+		if latestVersion == "" || isNewerVersion(version.Name, latestVersion) {
+			latestVersion = version.Name
+		}
+	}
+	return latestVersion
+}
+
+// isNewerVersion returns true if version is a higher semantic version than otherVersion.
+// Invalid versions are considered lower than all valid versions.
+func isNewerVersion(version, otherVersion string) bool {
+	if version == "" {
+		return false
+	}
+	return semver.Compare("v"+version, "v"+otherVersion) > 0
+}
+
+func isDeprecatedVersion(versions []extensionAPI.JsExtensionVersion, versionName string) bool {
+	for _, version := range versions {
+		if version.Name == versionName {
+			return version.Deprecated
+		}
+	}
+	return false
+}
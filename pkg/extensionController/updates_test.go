@@ -0,0 +1,80 @@
+package extensionController
+
+import (
+	"strings"
+
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionAPI/exaMetadata"
+	"github.com/exasol/extension-manager/pkg/extensionController/bfs"
+)
+
+// updateTestExtension creates the JavaScript of an extension with the given installable versions and installations.
+func updateTestExtension(name, versions, installations string) string {
+	content := `(function(){
+		global.installedExtension = {
+			extension: {
+				name: "$NAME$",
+				installableVersions: $VERSIONS$,
+				bucketFsUploads: [{name: "jar", bucketFsFilename: "$NAME$.jar", fileSize: 10}],
+				findInstallations: function(context, metadata) { return $INSTALLATIONS$ }
+			},
+			apiVersion: "0.2.0"
+		}
+	})()`
+	return strings.NewReplacer("$NAME$", name, "$VERSIONS$", versions, "$INSTALLATIONS$", installations).Replace(content)
+}
+
+func (suite *ControllerUTestSuite) TestGetAvailableUpdates() {
+	suite.writeFile("outdated.js", updateTestExtension("outdated",
+		`[{name: "1.0.0", deprecated: true}, {name: "1.2.0", latest: true}, {name: "2.0.0-beta"}]`, `[{name: "outdated", version: "1.0.0"}]`))
+	suite.writeFile("current.js", updateTestExtension("current", `[{name: "0.9.0"}, {name: "1.10.0"}]`, `[{name: "current", version: "1.10.0"}]`))
+	suite.writeFile("not-installed.js", updateTestExtension("not-installed", `[{name: "1.0.0", latest: true}]`, `[]`))
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{})
+	suite.dbMock.ExpectBegin()
+	suite.bucketFsMock.SimulateFiles([]bfs.BfsFile{{Name: "current.jar", Size: 10, Path: "path"}, {Name: "outdated.jar", Size: 5, Path: "path"}})
+	suite.bucketFsMock.SimulateCloseSuccess()
+	suite.dbMock.ExpectRollback()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	updates, err := suite.controller.GetAvailableUpdates(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.ElementsMatch([]*ExtensionUpdate{
+		{ExtensionId: "outdated.js", Name: "outdated", InstalledVersion: "1.0.0", LatestVersion: "1.2.0", UpdateAvailable: true, InstalledVersionDeprecated: true, LatestVersionFilesAvailable: false},
+		{ExtensionId: "current.js", Name: "current", InstalledVersion: "1.10.0", LatestVersion: "1.10.0", UpdateAvailable: false, InstalledVersionDeprecated: false, LatestVersionFilesAvailable: true},
+	}, updates)
+}
+
+func (suite *ControllerUTestSuite) TestGetAvailableUpdatesWithoutInstallations() {
+	suite.writeFile("not-installed.js", updateTestExtension("not-installed", `[{name: "1.0.0", latest: true}]`, `[]`))
+	suite.metaDataMock.SimulateExaMetaData(exaMetadata.ExaMetadata{})
+	suite.dbMock.ExpectBegin()
+	suite.bucketFsMock.SimulateFiles([]bfs.BfsFile{})
+	suite.bucketFsMock.SimulateCloseSuccess()
+	suite.dbMock.ExpectRollback()
+	suite.dbMock.ExpectBegin()
+	suite.dbMock.ExpectRollback()
+	updates, err := suite.controller.GetAvailableUpdates(mockContext(), suite.db)
+	suite.Require().NoError(err)
+	suite.Empty(updates)
+}
+
+func (suite *ControllerUTestSuite) TestGetAvailableUpdatesFailsStartingTransaction() {
+	suite.simulateTransactionBeginFails(mockError)
+	updates, err := suite.controller.GetAvailableUpdates(mockContext(), suite.db)
+	suite.Require().EqualError(err, beginTransactionFailedErrorMsg)
+	suite.Nil(updates)
+}
+
+func (suite *ControllerUTestSuite) TestFindLatestVersion() {
+	tests := []struct {
+		versions []extensionAPI.JsExtensionVersion
+		expected string
+	}{
+		{nil, ""},
+		{[]extensionAPI.JsExtensionVersion{{Name: "1.0.0"}, {Name: "2.0.0", Latest: true}, {Name: "3.0.0"}}, "2.0.0"},
+		{[]extensionAPI.JsExtensionVersion{{Name: "1.9.0"}, {Name: "1.10.0"}, {Name: "1.2.0"}}, "1.10.0"},
+	}
+	for _, test := range tests {
+		suite.Equal(test.expected, findLatestVersion(test.versions))
+	}
+}
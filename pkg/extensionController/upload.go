@@ -0,0 +1,217 @@
+package extensionController
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionController/bfs"
+)
+
+// defaultUploadTimeout is the timeout for downloading and uploading files if [ExtensionManagerConfig.UploadTimeout] is not set.
+const defaultUploadTimeout = 10 * time.Minute
+
+// UploadedFile describes a file uploaded to BucketFS.
+type UploadedFile struct {
+	Name             string // Human-readable name of the file as defined by the extension
+	BucketFsFilename string // File name in BucketFS
+	Size             int    // File size in bytes
+}
+
+// UploadOptions contains options for uploading files required by an extension.
+type UploadOptions struct {
+	// AcceptLicenses confirms that the user accepts the licenses of all uploaded files that require a license agreement.
+	AcceptLicenses bool
+	// Overwrite allows [TransactionController.UploadFile] to replace a file that already exists in the bucket.
+	Overwrite bool
+}
+
+func (c *transactionControllerImpl) UploadMissingFiles(ctx context.Context, db *sql.DB, extensionId string, options UploadOptions) ([]*UploadedFile, error) {
+	uploads, err := c.controller.GetBucketFsUploads(extensionId)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := c.createBucketFsWriter()
+	if err != nil {
+		return nil, err
+	}
+	bfsFiles, err := c.listBfsFiles(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	missingFiles, err := findMissingFiles(uploads, bfsFiles)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.acceptLicenses(ctx, db, extensionId, missingFiles, options); err != nil {
+		return nil, err
+	}
+	uploadedFiles := make([]*UploadedFile, 0, len(missingFiles))
+	for _, upload := range missingFiles {
+		uploadedFile, err := c.downloadAndUpload(ctx, writer, upload)
+		if err != nil {
+			return nil, err
+		}
+		uploadedFiles = append(uploadedFiles, uploadedFile)
+	}
+	log.Infof("Uploaded %d missing files of %d required files for extension %q", len(uploadedFiles), len(uploads), extensionId)
+	return uploadedFiles, nil
+}
+
+func findMissingFiles(uploads []extensionAPI.BucketFsUpload, bfsFiles []bfs.BfsFile) ([]extensionAPI.BucketFsUpload, error) {
+	missingFiles := make([]extensionAPI.BucketFsUpload, 0)
+	for _, upload := range uploads {
+		if existsFileInBfs(bfsFiles, upload) {
+			continue
+		}
+		if upload.DownloadURL == "" {
+			return nil, apiErrors.NewBadRequestErrorF("file %q (%s) is missing in BucketFS but has no download URL, please upload it manually", upload.BucketFsFilename, upload.Name)
+		}
+		missingFiles = append(missingFiles, upload)
+	}
+	return missingFiles, nil
+}
+
+func (c *transactionControllerImpl) downloadAndUpload(ctx context.Context, writer bfs.BucketFsWriter, upload extensionAPI.BucketFsUpload) (*UploadedFile, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, upload.DownloadURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request for %q: %w", upload.DownloadURL, err)
+	}
+	response, err := c.createHttpClient().Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file %q from %q: %w", upload.BucketFsFilename, upload.DownloadURL, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file %q from %q: unexpected status %q", upload.BucketFsFilename, upload.DownloadURL, response.Status)
+	}
+	log.Debugf("Uploading file %q downloaded from %q to BucketFS", upload.BucketFsFilename, upload.DownloadURL)
+	return uploadAndVerify(ctx, writer, upload, response.Body)
+}
+
+func (c *transactionControllerImpl) UploadFile(ctx context.Context, db *sql.DB, extensionId string, fileName string, content io.Reader, options UploadOptions) (*UploadedFile, error) {
+	uploads, err := c.controller.GetBucketFsUploads(extensionId)
+	if err != nil {
+		return nil, err
+	}
+	upload, err := findUpload(extensionId, uploads, fileName)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := c.createBucketFsWriter()
+	if err != nil {
+		return nil, err
+	}
+	// Listing the files connects to the database and verifies the credentials of the user before writing to BucketFS
+	bfsFiles, err := c.listBfsFiles(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if existingFile := findFileWithName(bfsFiles, *upload); existingFile != nil && !options.Overwrite {
+		return nil, apiErrors.NewAPIErrorF(http.StatusConflict, "file %q already exists in BucketFS at %q, set overwrite to replace it", upload.BucketFsFilename, existingFile.Path)
+	}
+	if err := c.acceptLicenses(ctx, db, extensionId, []extensionAPI.BucketFsUpload{*upload}, options); err != nil {
+		return nil, err
+	}
+	return uploadAndVerify(ctx, writer, *upload, content)
+}
+
+// findFileWithName returns the first file in the bucket of the upload that has the same name, independent of its size.
+func findFileWithName(bfsFiles []bfs.BfsFile, upload extensionAPI.BucketFsUpload) *bfs.BfsFile {
+	for i := range bfsFiles {
+		if bfsFiles[i].Name == upload.BucketFsFilename && bfsFiles[i].IsInBucket(upload.Bucket) {
+			return &bfsFiles[i]
+		}
+	}
+	return nil
+}
+
+// acceptLicenses verifies that the user accepted the licenses of all given files that require a license agreement
+// and records the acceptance in the extension schema before the files are uploaded.
+func (c *transactionControllerImpl) acceptLicenses(ctx context.Context, db *sql.DB, extensionId string, uploads []extensionAPI.BucketFsUpload, options UploadOptions) error {
+	licensedUploads := getUploadsRequiringLicenseAgreement(uploads)
+	if len(licensedUploads) == 0 {
+		return nil
+	}
+	if !options.AcceptLicenses {
+		return licenseNotAcceptedError(extensionId, licensedUploads)
+	}
+	txCtx, err := c.beginTransaction(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer txCtx.Rollback()
+	if err := c.controller.RecordLicenseAcceptance(txCtx, extensionId, licensedUploads); err != nil {
+		return err
+	}
+	return txCtx.Commit()
+}
+
+func findUpload(extensionId string, uploads []extensionAPI.BucketFsUpload, fileName string) (*extensionAPI.BucketFsUpload, error) {
+	for i := range uploads {
+		if uploads[i].BucketFsFilename == fileName {
+			return &uploads[i], nil
+		}
+	}
+	return nil, apiErrors.NewNotFoundErrorF("extension %q does not require file %q", extensionId, fileName)
+}
+
+// uploadAndVerify uploads the content to BucketFS and verifies that the uploaded file has the expected size.
+// If the file size is wrong, the file is deleted again.
+func uploadAndVerify(ctx context.Context, writer bfs.BucketFsWriter, upload extensionAPI.BucketFsUpload, content io.Reader) (*UploadedFile, error) {
+	sentBytes, err := writer.Upload(ctx, upload.BucketFsFilename, content)
+	if err != nil {
+		return nil, err
+	}
+	if upload.FileSize >= 0 && sentBytes != upload.FileSize {
+		deleteInvalidFile(ctx, writer, upload.BucketFsFilename)
+		return nil, apiErrors.NewBadRequestErrorF("file %q has size %d but extension requires %d bytes", upload.BucketFsFilename, sentBytes, upload.FileSize)
+	}
+	actualSize, err := writer.FileSize(ctx, upload.BucketFsFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify uploaded file: %w", err)
+	}
+	if actualSize != sentBytes {
+		deleteInvalidFile(ctx, writer, upload.BucketFsFilename)
+		return nil, fmt.Errorf("uploaded file %q has size %d in BucketFS but %d bytes were sent", upload.BucketFsFilename, actualSize, sentBytes)
+	}
+	return &UploadedFile{Name: upload.Name, BucketFsFilename: upload.BucketFsFilename, Size: actualSize}, nil
+}
+
+func deleteInvalidFile(ctx context.Context, writer bfs.BucketFsWriter, fileName string) {
+	if err := writer.Delete(ctx, fileName); err != nil {
+		log.Warnf("Failed to delete invalid file %q from BucketFS: %v", fileName, err)
+	}
+}
+
+func (c *transactionControllerImpl) createBucketFsWriter() (bfs.BucketFsWriter, error) {
+	if c.config.BucketFSUploadURL == "" {
+		return nil, apiErrors.NewAPIError(http.StatusNotImplemented, "uploading files is not supported because BucketFS upload URL is not configured")
+	}
+	writer, err := bfs.CreateBucketFsWriter(bfs.BucketFsWriterConfig{
+		URL:           c.config.BucketFSUploadURL,
+		WritePassword: c.config.BucketFSWritePassword,
+		ReadPassword:  c.config.BucketFSReadPassword,
+		HTTPClient:    c.createHttpClient(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BucketFS client: %w", err)
+	}
+	return writer, nil
+}
+
+// createHttpClient creates a client for downloading and uploading files that fails requests exceeding the configured timeout.
+func (c *transactionControllerImpl) createHttpClient() *http.Client {
+	timeout := c.config.UploadTimeout
+	if timeout <= 0 {
+		timeout = defaultUploadTimeout
+	}
+	return &http.Client{Timeout: timeout} //nolint:exhaustruct // Default values for other options are OK
+}
@@ -0,0 +1,325 @@
+package extensionController
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionAPI"
+	"github.com/exasol/extension-manager/pkg/extensionController/bfs"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const bfsWritePassword = "write-password"
+
+type uploadUnitTestSuite struct {
+	suite.Suite
+	ctrl           *transactionControllerImpl
+	db             *sql.DB
+	dbMock         sqlmock.Sqlmock
+	mockCtrl       mockControllerImpl
+	bucketFsMock   *bfs.BucketFsMock
+	bucketFsServer *bfs.BucketFsServerMock
+	downloadServer *httptest.Server
+}
+
+func TestUploadUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(uploadUnitTestSuite))
+}
+
+func (suite *uploadUnitTestSuite) SetupTest() {
+	db, dbMock, err := sqlmock.New()
+	suite.Require().NoError(err)
+	suite.db = db
+	suite.dbMock = dbMock
+	suite.mockCtrl = createMockControllerImpl()
+	suite.bucketFsMock = bfs.CreateBucketFsMock()
+	suite.bucketFsServer = bfs.CreateBucketFsServerMock(bfsWritePassword)
+	suite.downloadServer = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/missing.jar" {
+			http.NotFound(writer, request)
+			return
+		}
+		if request.URL.Path == "/hanging.jar" {
+			<-request.Context().Done()
+			return
+		}
+		_, _ = writer.Write([]byte("downloaded content of " + request.URL.Path))
+	}))
+	suite.ctrl = &transactionControllerImpl{
+		controller:         &suite.mockCtrl,
+		transactionStarter: transaction.CreateTransactionStarterMock(suite.db, suite.bucketFsMock).GetTransactionStarter(),
+		config: ExtensionManagerConfig{
+			ExtensionRegistryURL:  "registry-url",
+			BucketFSBasePath:      "bfs-base-path",
+			ExtensionSchema:       "ext-schema",
+			BucketFSUploadURL:     suite.bucketFsServer.URL(),
+			BucketFSWritePassword: bfsWritePassword,
+			BucketFSReadPassword:  "",
+		},
+		auditLog: createAuditLogMock(),
+		locks:    newOperationLock(ExtensionManagerConfig{}),
+	}
+}
+
+func (suite *uploadUnitTestSuite) TearDownTest() {
+	suite.bucketFsServer.Close()
+	suite.downloadServer.Close()
+	suite.NoError(suite.dbMock.ExpectationsWereMet())
+	suite.mockCtrl.AssertExpectations(suite.T())
+	suite.bucketFsMock.AssertExpectations(suite.T())
+}
+
+func (suite *uploadUnitTestSuite) simulateUploads(uploads ...extensionAPI.BucketFsUpload) {
+	suite.mockCtrl.On("GetBucketFsUploads", "extId").Return(uploads, nil)
+}
+
+func (suite *uploadUnitTestSuite) simulateBucketFsFiles(files []bfs.BfsFile) {
+	suite.dbMock.ExpectBegin()
+	suite.bucketFsMock.SimulateFiles(files)
+	suite.bucketFsMock.SimulateCloseSuccess()
+	suite.dbMock.ExpectRollback()
+}
+
+func (suite *uploadUnitTestSuite) downloadUrl(fileName string) string {
+	return suite.downloadServer.URL + "/" + fileName
+}
+
+// UploadMissingFiles
+
+func (suite *uploadUnitTestSuite) TestUploadMissingFilesUploadsOnlyMissingFiles() {
+	content := "downloaded content of /new.jar"
+	suite.simulateUploads(
+		extensionAPI.BucketFsUpload{Name: "existing", BucketFsFilename: "existing.jar", FileSize: 3, DownloadURL: suite.downloadUrl("existing.jar")},
+		extensionAPI.BucketFsUpload{Name: "new", BucketFsFilename: "new.jar", FileSize: len(content), DownloadURL: suite.downloadUrl("new.jar")})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{{Name: "existing.jar", Size: 3, Path: "/path/existing.jar"}})
+	files, err := suite.ctrl.UploadMissingFiles(mockContext(), suite.db, "extId", UploadOptions{AcceptLicenses: false})
+	suite.Require().NoError(err)
+	suite.Equal([]*UploadedFile{{Name: "new", BucketFsFilename: "new.jar", Size: len(content)}}, files)
+	uploaded, _ := suite.bucketFsServer.GetFile("new.jar")
+	suite.Equal(content, string(uploaded))
+	_, existingUploaded := suite.bucketFsServer.GetFile("existing.jar")
+	suite.False(existingUploaded)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadMissingFilesNothingMissing() {
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "existing", BucketFsFilename: "existing.jar", FileSize: 3})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{{Name: "existing.jar", Size: 3, Path: "/path/existing.jar"}})
+	files, err := suite.ctrl.UploadMissingFiles(mockContext(), suite.db, "extId", UploadOptions{AcceptLicenses: false})
+	suite.Require().NoError(err)
+	suite.Empty(files)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadMissingFilesWithoutDownloadUrl() {
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "manual", BucketFsFilename: "manual.jar", FileSize: 3})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	files, err := suite.ctrl.UploadMissingFiles(mockContext(), suite.db, "extId", UploadOptions{AcceptLicenses: false})
+	suite.assertApiError(err, 400, `file "manual.jar" (manual) is missing in BucketFS but has no download URL, please upload it manually`)
+	suite.Nil(files)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadMissingFilesDownloadFails() {
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "missing", BucketFsFilename: "missing.jar", FileSize: 3, DownloadURL: suite.downloadUrl("missing.jar")})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	files, err := suite.ctrl.UploadMissingFiles(mockContext(), suite.db, "extId", UploadOptions{AcceptLicenses: false})
+	suite.EqualError(err, `failed to download file "missing.jar" from "`+suite.downloadUrl("missing.jar")+`": unexpected status "404 Not Found"`)
+	suite.Nil(files)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadMissingFilesDownloadTimeout() {
+	suite.ctrl.config.UploadTimeout = 50 * time.Millisecond
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "hanging", BucketFsFilename: "hanging.jar", FileSize: 3, DownloadURL: suite.downloadUrl("hanging.jar")})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	files, err := suite.ctrl.UploadMissingFiles(mockContext(), suite.db, "extId", UploadOptions{AcceptLicenses: false})
+	suite.ErrorContains(err, `failed to download file "hanging.jar" from "`+suite.downloadUrl("hanging.jar")+`"`)
+	suite.ErrorContains(err, "Client.Timeout exceeded")
+	suite.Nil(files)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadMissingFilesWrongDownloadSize() {
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "new", BucketFsFilename: "new.jar", FileSize: 3, DownloadURL: suite.downloadUrl("new.jar")})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	files, err := suite.ctrl.UploadMissingFiles(mockContext(), suite.db, "extId", UploadOptions{AcceptLicenses: false})
+	suite.assertApiError(err, 400, `file "new.jar" has size 30 but extension requires 3 bytes`)
+	suite.Nil(files)
+	_, uploaded := suite.bucketFsServer.GetFile("new.jar")
+	suite.False(uploaded, "invalid file deleted")
+}
+
+func (suite *uploadUnitTestSuite) TestUploadMissingFilesFailsWithoutAcceptingLicense() {
+	suite.simulateUploads(suite.licensedUpload("new.jar"))
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	files, err := suite.ctrl.UploadMissingFiles(mockContext(), suite.db, "extId", UploadOptions{AcceptLicenses: false})
+	suite.assertApiError(err, 400, `extension "extId" requires accepting the license of file(s) new.jar`)
+	suite.Nil(files)
+	_, uploaded := suite.bucketFsServer.GetFile("new.jar")
+	suite.False(uploaded)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadMissingFilesRecordsAcceptedLicense() {
+	upload := suite.licensedUpload("new.jar")
+	suite.simulateUploads(upload)
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("RecordLicenseAcceptance", mock.Anything, "extId", []extensionAPI.BucketFsUpload{upload}).Return(nil)
+	suite.dbMock.ExpectCommit()
+	files, err := suite.ctrl.UploadMissingFiles(mockContext(), suite.db, "extId", UploadOptions{AcceptLicenses: true})
+	suite.Require().NoError(err)
+	suite.Len(files, 1)
+	_, uploaded := suite.bucketFsServer.GetFile("new.jar")
+	suite.True(uploaded)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadMissingFilesRecordingLicenseFails() {
+	upload := suite.licensedUpload("new.jar")
+	suite.simulateUploads(upload)
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("RecordLicenseAcceptance", mock.Anything, "extId", []extensionAPI.BucketFsUpload{upload}).Return(mockError)
+	suite.dbMock.ExpectRollback()
+	files, err := suite.ctrl.UploadMissingFiles(mockContext(), suite.db, "extId", UploadOptions{AcceptLicenses: true})
+	suite.EqualError(err, mockErrorMsg)
+	suite.Nil(files)
+	_, uploaded := suite.bucketFsServer.GetFile("new.jar")
+	suite.False(uploaded)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadMissingFilesFailsWithoutUploadUrl() {
+	suite.ctrl.config.BucketFSUploadURL = ""
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "new", BucketFsFilename: "new.jar", FileSize: 3})
+	files, err := suite.ctrl.UploadMissingFiles(mockContext(), suite.db, "extId", UploadOptions{AcceptLicenses: false})
+	suite.assertApiError(err, 501, "uploading files is not supported because BucketFS upload URL is not configured")
+	suite.Nil(files)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadMissingFilesFailsLoadingExtension() {
+	suite.mockCtrl.On("GetBucketFsUploads", "extId").Return(nil, mockError)
+	files, err := suite.ctrl.UploadMissingFiles(mockContext(), suite.db, "extId", UploadOptions{AcceptLicenses: false})
+	suite.EqualError(err, mockErrorMsg)
+	suite.Nil(files)
+}
+
+// UploadFile
+
+func (suite *uploadUnitTestSuite) TestUploadFile() {
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "file", BucketFsFilename: "file.jar", FileSize: 7})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	file, err := suite.ctrl.UploadFile(mockContext(), suite.db, "extId", "file.jar", strings.NewReader("content"), UploadOptions{AcceptLicenses: false})
+	suite.Require().NoError(err)
+	suite.Equal(&UploadedFile{Name: "file", BucketFsFilename: "file.jar", Size: 7}, file)
+	uploaded, _ := suite.bucketFsServer.GetFile("file.jar")
+	suite.Equal("content", string(uploaded))
+}
+
+func (suite *uploadUnitTestSuite) TestUploadFileIgnoringFileSize() {
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "file", BucketFsFilename: "file.jar", FileSize: -1})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	file, err := suite.ctrl.UploadFile(mockContext(), suite.db, "extId", "file.jar", strings.NewReader("content"), UploadOptions{AcceptLicenses: false})
+	suite.Require().NoError(err)
+	suite.Equal(&UploadedFile{Name: "file", BucketFsFilename: "file.jar", Size: 7}, file)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadFileNotRequiredByExtension() {
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "file", BucketFsFilename: "file.jar", FileSize: 7})
+	file, err := suite.ctrl.UploadFile(mockContext(), suite.db, "extId", "other.jar", strings.NewReader("content"), UploadOptions{AcceptLicenses: false})
+	suite.assertApiError(err, 404, `extension "extId" does not require file "other.jar"`)
+	suite.Nil(file)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadFileFailsWithoutAcceptingLicense() {
+	suite.simulateUploads(suite.licensedUpload("file.jar"))
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	file, err := suite.ctrl.UploadFile(mockContext(), suite.db, "extId", "file.jar", strings.NewReader("content"), UploadOptions{AcceptLicenses: false})
+	suite.assertApiError(err, 400, `extension "extId" requires accepting the license of file(s) file.jar`)
+	suite.Nil(file)
+	_, uploaded := suite.bucketFsServer.GetFile("file.jar")
+	suite.False(uploaded)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadFileRecordsAcceptedLicense() {
+	upload := suite.licensedUpload("file.jar")
+	suite.simulateUploads(upload)
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	suite.dbMock.ExpectBegin()
+	suite.mockCtrl.On("RecordLicenseAcceptance", mock.Anything, "extId", []extensionAPI.BucketFsUpload{upload}).Return(nil)
+	suite.dbMock.ExpectCommit()
+	file, err := suite.ctrl.UploadFile(mockContext(), suite.db, "extId", "file.jar", strings.NewReader("content"), UploadOptions{AcceptLicenses: true})
+	suite.Require().NoError(err)
+	suite.Equal(&UploadedFile{Name: "licensed", BucketFsFilename: "file.jar", Size: 7}, file)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadFileWrongPassword() {
+	suite.ctrl.config.BucketFSWritePassword = "wrong"
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "file", BucketFsFilename: "file.jar", FileSize: 7})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	file, err := suite.ctrl.UploadFile(mockContext(), suite.db, "extId", "file.jar", strings.NewReader("content"), UploadOptions{AcceptLicenses: false})
+	suite.EqualError(err, `failed to upload file "file.jar" to BucketFS: unexpected status "401 Unauthorized": Unauthorized`)
+	suite.Nil(file)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadFileSizeInBucketFsDiffers() {
+	suite.bucketFsServer.UploadSizeOverride = 2
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "file", BucketFsFilename: "file.jar", FileSize: 7})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{})
+	file, err := suite.ctrl.UploadFile(mockContext(), suite.db, "extId", "file.jar", strings.NewReader("content"), UploadOptions{AcceptLicenses: false})
+	suite.EqualError(err, `uploaded file "file.jar" has size 2 in BucketFS but 7 bytes were sent`)
+	suite.Nil(file)
+	_, uploaded := suite.bucketFsServer.GetFile("file.jar")
+	suite.False(uploaded, "invalid file deleted")
+}
+
+func (suite *uploadUnitTestSuite) TestUploadFileFailsIfDatabaseConnectionFails() {
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "file", BucketFsFilename: "file.jar", FileSize: 7})
+	suite.dbMock.ExpectBegin().WillReturnError(mockError)
+	file, err := suite.ctrl.UploadFile(mockContext(), suite.db, "extId", "file.jar", strings.NewReader("content"), UploadOptions{AcceptLicenses: false})
+	suite.Require().ErrorContains(err, mockErrorMsg)
+	suite.Nil(file)
+	_, uploaded := suite.bucketFsServer.GetFile("file.jar")
+	suite.False(uploaded)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadFileFailsIfFileExists() {
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "file", BucketFsFilename: "file.jar", FileSize: 7})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{{Name: "file.jar", Size: 3, Path: "/path/file.jar"}})
+	file, err := suite.ctrl.UploadFile(mockContext(), suite.db, "extId", "file.jar", strings.NewReader("content"), UploadOptions{AcceptLicenses: false})
+	suite.assertApiError(err, 409, `file "file.jar" already exists in BucketFS at "/path/file.jar", set overwrite to replace it`)
+	suite.Nil(file)
+	_, uploaded := suite.bucketFsServer.GetFile("file.jar")
+	suite.False(uploaded)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadFileOverwritesExistingFile() {
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "file", BucketFsFilename: "file.jar", FileSize: 7})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{{Name: "file.jar", Size: 3, Path: "/path/file.jar"}})
+	file, err := suite.ctrl.UploadFile(mockContext(), suite.db, "extId", "file.jar", strings.NewReader("content"), UploadOptions{AcceptLicenses: false, Overwrite: true})
+	suite.Require().NoError(err)
+	suite.Equal(&UploadedFile{Name: "file", BucketFsFilename: "file.jar", Size: 7}, file)
+}
+
+func (suite *uploadUnitTestSuite) TestUploadFileIgnoresFileInOtherBucket() {
+	suite.simulateUploads(extensionAPI.BucketFsUpload{Name: "file", BucketFsFilename: "file.jar", FileSize: 7, Bucket: "other"})
+	suite.simulateBucketFsFiles([]bfs.BfsFile{{Name: "file.jar", Size: 3, Path: "/buckets/bfsdefault/default/file.jar", BasePath: "/buckets/bfsdefault/default/"}})
+	file, err := suite.ctrl.UploadFile(mockContext(), suite.db, "extId", "file.jar", strings.NewReader("content"), UploadOptions{AcceptLicenses: false})
+	suite.Require().NoError(err)
+	suite.Equal(&UploadedFile{Name: "file", BucketFsFilename: "file.jar", Size: 7}, file)
+}
+
+func (suite *uploadUnitTestSuite) licensedUpload(fileName string) extensionAPI.BucketFsUpload {
+	return extensionAPI.BucketFsUpload{Name: "licensed", BucketFsFilename: fileName, FileSize: -1, DownloadURL: suite.downloadUrl(fileName),
+		LicenseURL: "https://example.com/license", LicenseAgreementRequired: true}
+}
+
+func (suite *uploadUnitTestSuite) assertApiError(err error, expectedStatus int, expectedMessage string) {
+	suite.T().Helper()
+	suite.Require().Error(err)
+	apiError := apiErrors.UnwrapAPIError(err)
+	suite.Equal(expectedStatus, apiError.Status)
+	suite.Equal(expectedMessage, apiError.Message)
+}
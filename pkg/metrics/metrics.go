@@ -0,0 +1,121 @@
+// Package metrics provides Prometheus metrics about the performance of the extension manager.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "extension_manager"
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+
+	// RegistryResourceIndex is the registry index listing all extensions.
+	RegistryResourceIndex = "index"
+	// RegistryResourceExtension is the JavaScript file of a single extension.
+	RegistryResourceExtension = "extension"
+
+	// SqlStatementExecute is a statement that does not return rows.
+	SqlStatementExecute = "execute"
+	// SqlStatementQuery is a statement that returns rows.
+	SqlStatementQuery = "query"
+)
+
+// Registry contains all metrics of the extension manager. Applications embedding the extension manager
+// can add it to their own gatherers, e.g. using [prometheus.Gatherers].
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = newCounterVec("http_requests_total",
+		"Number of REST API requests by operation and HTTP status.", "operation", "status")
+	httpRequestDuration = newHistogramVec("http_request_duration_seconds",
+		"Duration of REST API requests by operation and HTTP status.", "operation", "status")
+	registryFetchDuration = newHistogramVec("registry_fetch_duration_seconds",
+		"Duration of fetching the registry index or an extension from the registry.", "resource", "outcome")
+	registryFetchFailures = newCounterVec("registry_fetch_failures_total",
+		"Number of failed fetches of the registry index or an extension from the registry.", "resource")
+	extensionLoadDuration = newHistogramVec("extension_load_duration_seconds",
+		"Duration of loading and initializing the JavaScript of an extension.", "extension")
+	jsFunctionDuration = newHistogramVec("js_function_duration_seconds",
+		"Duration of calls to functions of an extension, e.g. install or findInstallations.", "extension", "function", "outcome")
+	sqlStatements = newCounterVec("sql_statements_total",
+		"Number of SQL statements executed by extensions.", "type", "outcome")
+	sqlStatementDuration = newHistogramVec("sql_statement_duration_seconds",
+		"Duration of SQL statements executed by extensions.", "type", "outcome")
+	bucketFsListDuration = newHistogramVec("bucketfs_list_duration_seconds",
+		"Duration of listing files in BucketFS using a UDF.", "outcome")
+)
+
+func init() {
+	Registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})) //nolint:exhaustruct // Default options are OK
+}
+
+func newCounterVec(name, help string, labels ...string) *prometheus.CounterVec {
+	//nolint:exhaustruct // Default values for other options are OK
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: namespace, Name: name, Help: help}, labels)
+	Registry.MustRegister(counter)
+	return counter
+}
+
+func newHistogramVec(name, help string, labels ...string) *prometheus.HistogramVec {
+	//nolint:exhaustruct // Default values for other options are OK
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{Namespace: namespace, Name: name, Help: help, Buckets: prometheus.DefBuckets}, labels)
+	Registry.MustRegister(histogram)
+	return histogram
+}
+
+// Handler returns an HTTP handler that serves all metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}) //nolint:exhaustruct // Default options are OK
+}
+
+// ObserveHttpRequest records a REST API request. The operation is the operation ID from the OpenAPI specification.
+func ObserveHttpRequest(operation string, status int, duration time.Duration) {
+	statusLabel := strconv.Itoa(status)
+	httpRequests.WithLabelValues(operation, statusLabel).Inc()
+	httpRequestDuration.WithLabelValues(operation, statusLabel).Observe(duration.Seconds())
+}
+
+// ObserveRegistryFetch records fetching a resource from the registry that started at the given time.
+func ObserveRegistryFetch(resource string, start time.Time, err error) {
+	registryFetchDuration.WithLabelValues(resource, outcome(err)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		registryFetchFailures.WithLabelValues(resource).Inc()
+	}
+}
+
+// ObserveExtensionLoad records loading the extension with the given ID that started at the given time.
+func ObserveExtensionLoad(extensionId string, start time.Time) {
+	extensionLoadDuration.WithLabelValues(extensionId).Observe(time.Since(start).Seconds())
+}
+
+// ObserveJsFunction records a call to the JavaScript function of an extension that started at the given time.
+func ObserveJsFunction(extensionId, function string, start time.Time, err error) {
+	jsFunctionDuration.WithLabelValues(extensionId, function, outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveSqlStatement records an SQL statement of the given type that started at the given time.
+func ObserveSqlStatement(statementType string, start time.Time, err error) {
+	result := outcome(err)
+	sqlStatements.WithLabelValues(statementType, result).Inc()
+	sqlStatementDuration.WithLabelValues(statementType, result).Observe(time.Since(start).Seconds())
+}
+
+// ObserveBucketFsListing records listing files in BucketFS that started at the given time.
+func ObserveBucketFsListing(start time.Time, err error) {
+	bucketFsListDuration.WithLabelValues(outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
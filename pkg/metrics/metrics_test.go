@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mockError = errors.New("mock error")
+
+func TestObserveHttpRequest(t *testing.T) {
+	counter := httpRequests.WithLabelValues("TestOperation", "404")
+	before := testutil.ToFloat64(counter)
+	ObserveHttpRequest("TestOperation", 404, 10*time.Millisecond)
+	assert.InDelta(t, before+1, testutil.ToFloat64(counter), 0)
+}
+
+func TestObserveRegistryFetchCountsFailures(t *testing.T) {
+	failures := registryFetchFailures.WithLabelValues(RegistryResourceIndex)
+	before := testutil.ToFloat64(failures)
+	ObserveRegistryFetch(RegistryResourceIndex, time.Now(), nil)
+	assert.InDelta(t, before, testutil.ToFloat64(failures), 0)
+	ObserveRegistryFetch(RegistryResourceIndex, time.Now(), mockError)
+	assert.InDelta(t, before+1, testutil.ToFloat64(failures), 0)
+}
+
+func TestObserveSqlStatementCountsByOutcome(t *testing.T) {
+	successes := sqlStatements.WithLabelValues(SqlStatementQuery, OutcomeSuccess)
+	failures := sqlStatements.WithLabelValues(SqlStatementQuery, OutcomeFailure)
+	successesBefore := testutil.ToFloat64(successes)
+	failuresBefore := testutil.ToFloat64(failures)
+	ObserveSqlStatement(SqlStatementQuery, time.Now(), nil)
+	ObserveSqlStatement(SqlStatementQuery, time.Now(), nil)
+	ObserveSqlStatement(SqlStatementQuery, time.Now(), mockError)
+	assert.InDelta(t, successesBefore+2, testutil.ToFloat64(successes), 0)
+	assert.InDelta(t, failuresBefore+1, testutil.ToFloat64(failures), 0)
+}
+
+func TestHandlerServesAllMetrics(t *testing.T) {
+	ObserveExtensionLoad("ext.js", time.Now())
+	ObserveJsFunction("ext.js", "install", time.Now(), nil)
+	ObserveBucketFsListing(time.Now(), mockError)
+	ObserveSqlStatement(SqlStatementExecute, time.Now(), nil)
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	for _, expected := range []string{
+		`extension_manager_extension_load_duration_seconds_count{extension="ext.js"}`,
+		`extension_manager_js_function_duration_seconds_count{extension="ext.js",function="install",outcome="success"}`,
+		`extension_manager_bucketfs_list_duration_seconds_count{outcome="failure"}`,
+		`extension_manager_sql_statements_total{outcome="success",type="execute"}`,
+		"go_goroutines",
+	} {
+		assert.Contains(t, string(body), expected)
+	}
+}
@@ -7,11 +7,12 @@ import (
 var authentication = map[string][]string{BasicAuth: {}, BearerAuth: {}}
 
 // newPathWithDbQueryParams creates a base path starting with "/api/v1/extensionmanager"
-// including query parameters dbHost and dbPort.
+// including query parameters dbHost, dbPort and the optional extensionSchema.
 func newPathWithDbQueryParams() *openapi.PathBuilder {
 	path := getV1PublicBasePath(openapi.NewPathBuilder())
 	path.WithQueryParameter("dbHost", openapi.STRING, "Exasol database hostname", true)
 	path.WithQueryParameter("dbPort", openapi.INTEGER, "Exasol database port number", true)
+	path.WithQueryParameter("extensionSchema", openapi.STRING, "Schema containing the extensions. Defaults to the schema configured for the server. Other schemas must be allowed in the server configuration.", false)
 	return path
 }
 
@@ -1,6 +1,7 @@
 package restAPI
 
 import (
+	"context"
 	"database/sql"
 	"encoding/base64"
 	"fmt"
@@ -9,9 +10,11 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/Nightapes/go-rest/pkg/openapi"
 	"github.com/exasol/exasol-driver-go"
 	"github.com/exasol/exasol-driver-go/pkg/dsn"
 	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionController"
 )
 
 type generalHandlerFunc = func(writer http.ResponseWriter, request *http.Request)
@@ -25,6 +28,7 @@ func adaptDbHandler(apiContext *ApiContext, handler dbHandler) generalHandlerFun
 			return
 		}
 		defer closeDBRequest(db)
+		request = request.WithContext(createOperationContext(request))
 		err = handler(db, writer, request)
 		if err != nil {
 			handleError(request.Context(), apiContext, writer, err)
@@ -32,6 +36,24 @@ func adaptDbHandler(apiContext *ApiContext, handler dbHandler) generalHandlerFun
 	}
 }
 
+// conflictResponse documents the error returned when another operation on the same extension is still running.
+var conflictResponse = openapi.MethodResponse{
+	Description: "Another operation on the same extension is in progress",
+	Value:       apiErrors.NewAPIErrorF(http.StatusConflict, `another operation on extension "s3-vs" is in progress, please try again later`),
+}
+
+// getDatabaseIdentifier returns an identifier for the database of the request used for serializing operations.
+func getDatabaseIdentifier(request *http.Request) string {
+	query := request.URL.Query()
+	return query.Get("dbHost") + ":" + query.Get("dbPort")
+}
+
+// createOperationContext returns the request context with the database identifier and the extension schema selected by the request.
+func createOperationContext(request *http.Request) context.Context {
+	ctx := extensionController.WithDatabaseIdentifier(request.Context(), getDatabaseIdentifier(request))
+	return extensionController.WithExtensionSchema(ctx, request.URL.Query().Get("extensionSchema"))
+}
+
 func openDBRequest(request *http.Request) (*sql.DB, error) {
 	config, err := createDbConfig(request)
 	if err != nil {
@@ -2,8 +2,10 @@ package restAPI
 
 import (
 	"encoding/base64"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/exasol/extension-manager/pkg/extensionController"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -58,3 +60,18 @@ func (suite *ApiContextSuite) TestExtractUserPassword() {
 		})
 	}
 }
+
+func (suite *ApiContextSuite) TestGetDatabaseIdentifier() {
+	request := httptest.NewRequest("GET", "/api/v1/extensions?dbHost=host&dbPort=8563", nil)
+	suite.Equal("host:8563", getDatabaseIdentifier(request))
+}
+
+func (suite *ApiContextSuite) TestCreateOperationContext() {
+	request := httptest.NewRequest("GET", "/api/v1/extensions?dbHost=host&dbPort=8563&extensionSchema=other", nil)
+	suite.Equal("other", extensionController.GetExtensionSchema(createOperationContext(request)))
+}
+
+func (suite *ApiContextSuite) TestCreateOperationContextWithoutExtensionSchema() {
+	request := httptest.NewRequest("GET", "/api/v1/extensions?dbHost=host&dbPort=8563", nil)
+	suite.Equal("", extensionController.GetExtensionSchema(createOperationContext(request)))
+}
@@ -0,0 +1,127 @@
+package restAPI
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionController"
+)
+
+const dryRunParameterDescription = "Run the operation without committing it and return the executed statements (default: false)"
+
+// DryRunResponse contains the statements an operation would execute and the log output of the extension.
+type DryRunResponse struct {
+	Statements  []DryRunStatement  `json:"statements"`         // Statements executed by the extension in the order of execution.
+	LogMessages []DryRunLogMessage `json:"logMessages"`        // Messages logged by the extension.
+	Warnings    []string           `json:"warnings,omitempty"` // Warnings for the user, e.g. because a deprecated version is used.
+}
+
+// DryRunStatement is a statement executed by an extension during a dry-run.
+type DryRunStatement struct {
+	SQL  string `json:"sql"`            // The SQL statement.
+	Args []any  `json:"args,omitempty"` // Arguments of the statement, if any.
+}
+
+// DryRunLogMessage is a message logged by an extension during a dry-run.
+type DryRunLogMessage struct {
+	Level   string `json:"level"`   // Log level: "log", "warn" or "error".
+	Message string `json:"message"` // The logged message.
+}
+
+// sendDryRunResult sends the result of a successful dry-run. If the dry-run failed, it returns the error
+// with the statements and log messages recorded until the failure added to the error details as "dryRun".
+func sendDryRunResult(ctx context.Context, writer http.ResponseWriter, result *extensionController.DryRunResult, err error) error {
+	if err != nil {
+		return addDryRunDetails(err, result)
+	}
+	return SendJSON(ctx, writer, convertDryRunResult(result))
+}
+
+func addDryRunDetails(err error, result *extensionController.DryRunResult) error {
+	if result == nil {
+		return err
+	}
+	if _, isApiError := apiErrors.AsAPIError(err); !isApiError {
+		log.Errorf("Dry-run failed: %v", err)
+	}
+	apiError := *apiErrors.UnwrapAPIError(err)
+	details := make(map[string]any, len(apiError.Details)+1)
+	maps.Copy(details, apiError.Details)
+	details["dryRun"] = convertDryRunResult(result)
+	apiError.Details = details
+	return &apiError
+}
+
+func convertDryRunResult(result *extensionController.DryRunResult) DryRunResponse {
+	response := DryRunResponse{
+		Statements:  make([]DryRunStatement, 0, len(result.Statements)),
+		LogMessages: make([]DryRunLogMessage, 0, len(result.LogMessages)),
+		Warnings:    result.Warnings,
+	}
+	for _, statement := range result.Statements {
+		response.Statements = append(response.Statements, DryRunStatement{SQL: statement.Query, Args: statement.Args})
+	}
+	for _, message := range result.LogMessages {
+		response.LogMessages = append(response.LogMessages, DryRunLogMessage{Level: message.Level, Message: message.Message})
+	}
+	return response
+}
+
+// documentDryRunResponse documents that the operation with the given ID responds with a [DryRunResponse] instead of
+// the given regular response type if query parameter dryRun is true. go-rest supports only a single schema per status code,
+// so this replaces the schema of the 200 response with a choice between both schemas.
+func documentDryRunResponse(api *openapi.API, operationId string, regularResponseType string) error {
+	operation := findOperation(api, operationId)
+	if operation == nil {
+		return fmt.Errorf("operation %q not found", operationId)
+	}
+	response, ok := operation.Responses["200"]
+	if !ok || response.Content[ContentTypeJson] == nil {
+		return fmt.Errorf("operation %q has no JSON response with status 200", operationId)
+	}
+	if err := addDryRunSchemas(api); err != nil {
+		return err
+	}
+	response.Content[ContentTypeJson].Schema = map[string]any{
+		"oneOf": []any{schemaRef(regularResponseType), schemaRef("DryRunResponse")},
+	}
+	return nil
+}
+
+// addDryRunSchemas adds the schemas of [DryRunResponse] and its nested types to the components of the API.
+// go-rest only generates schemas for types used in endpoint definitions, so they are taken from a temporary API.
+func addDryRunSchemas(api *openapi.API) error {
+	tempApi := openapi.NewOpenAPI()
+	//nolint:exhaustruct // Default values for request are OK
+	err := tempApi.Get(&openapi.Get{
+		Response: map[string]openapi.MethodResponse{"200": {Description: "Dry-run", Value: DryRunResponse{}}},
+		Path:     openapi.NewPathBuilder().Add("dryRun"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate dry-run schemas: %w", err)
+	}
+	maps.Copy(api.Components.Schemas, tempApi.Components.Schemas)
+	return nil
+}
+
+func schemaRef(typeName string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + typeName}
+}
+
+// findOperation returns the operation with the given operation ID or nil if no endpoint has this ID.
+func findOperation(api *openapi.API, operationId string) *openapi.Operation {
+	for _, pathItem := range api.OpenAPI.Paths {
+		for _, operation := range []*openapi.Operation{pathItem.Get, pathItem.Post, pathItem.Put, pathItem.Delete, pathItem.Patch} {
+			if operation != nil && operation.OperationID == operationId {
+				return operation
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package restAPI
+
+import (
+	"net/http"
+
+	"github.com/exasol/extension-manager/pkg/extensionController"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	HealthPath    = "/health"
+	ReadinessPath = "/ready"
+
+	statusUp   = "UP"
+	statusDown = "DOWN"
+)
+
+// handleHealth reports that the server is alive. This does not check any dependencies, so it can be used as liveness probe.
+func handleHealth(writer http.ResponseWriter, request *http.Request) {
+	if err := SendJSON(request.Context(), writer, HealthResponse{Status: statusUp}); err != nil {
+		log.Warnf("Failed to send health response: %v", err)
+	}
+}
+
+// handleReadiness reports if the server can handle requests, i.e. if all readiness checks succeed.
+// It responds with status 503 (Service Unavailable) if a check fails, so it can be used as readiness probe.
+func handleReadiness(controller extensionController.TransactionController) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		response := createReadinessResponse(controller.CheckReadiness())
+		status := http.StatusOK
+		if response.Status != statusUp {
+			status = http.StatusServiceUnavailable
+		}
+		if err := SendJSONWithStatus(request.Context(), status, writer, response); err != nil {
+			log.Warnf("Failed to send readiness response: %v", err)
+		}
+	}
+}
+
+func createReadinessResponse(checks []extensionController.ReadinessCheck) ReadinessResponse {
+	response := ReadinessResponse{Status: statusUp, Checks: make([]ReadinessCheck, 0, len(checks))}
+	for _, check := range checks {
+		status := statusUp
+		if !check.Ready {
+			status = statusDown
+			response.Status = statusDown
+		}
+		response.Checks = append(response.Checks, ReadinessCheck{Name: check.Name, Status: status, Message: check.Message})
+	}
+	return response
+}
+
+// HealthResponse is the response of the liveness endpoint.
+type HealthResponse struct {
+	Status string `json:"status"` // Always "UP"
+}
+
+// ReadinessResponse is the response of the readiness endpoint.
+type ReadinessResponse struct {
+	Status string           `json:"status"` // "UP" if all checks succeeded, else "DOWN"
+	Checks []ReadinessCheck `json:"checks"`
+}
+
+// ReadinessCheck is the result of checking a single component.
+type ReadinessCheck struct {
+	Name    string `json:"name"`              // "registry" or "parameter-validator"
+	Status  string `json:"status"`            // "UP" or "DOWN"
+	Message string `json:"message,omitempty"` // Details, e.g. the cause of a failure
+}
@@ -0,0 +1,60 @@
+package restAPI
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/exasol/extension-manager/pkg/metrics"
+)
+
+const MetricsPath = "/metrics"
+
+// metricsMiddleware records count and duration of requests for the given operation by response status.
+func metricsMiddleware(operation string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			t0 := time.Now()
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+				metrics.ObserveHttpRequest(operation, status, time.Since(t0))
+			}()
+			next.ServeHTTP(ww, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// findOperationId returns the operation ID of the endpoint with the given method and path
+// or the method and path if the endpoint has no operation ID.
+func findOperationId(api *openapi.API, method, path string) string {
+	if pathItem, ok := api.OpenAPI.Paths[path]; ok {
+		if operation := getOperation(pathItem, method); operation != nil && operation.OperationID != "" {
+			return operation.OperationID
+		}
+	}
+	return method + " " + path
+}
+
+func getOperation(pathItem *openapi.PathItem, method string) *openapi.Operation {
+	switch method {
+	case http.MethodGet:
+		return pathItem.Get
+	case http.MethodPost:
+		return pathItem.Post
+	case http.MethodPut:
+		return pathItem.Put
+	case http.MethodDelete:
+		return pathItem.Delete
+	case http.MethodPatch:
+		return pathItem.Patch
+	default:
+		return nil
+	}
+}
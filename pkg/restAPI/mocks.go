@@ -4,6 +4,7 @@ package restAPI
 import (
 	"context"
 	"database/sql"
+	"io"
 
 	"github.com/exasol/extension-manager/pkg/extensionAPI"
 	"github.com/exasol/extension-manager/pkg/extensionController"
@@ -25,11 +26,69 @@ func (m *mockExtensionController) InstallExtension(ctx context.Context, db *sql.
 	return args.Error(0)
 }
 
+func (m *mockExtensionController) InstallExtensionWithOptions(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options extensionController.InstallOptions) error {
+	args := m.Called(ctx, db, extensionId, extensionVersion, options)
+	return args.Error(0)
+}
+
+func (m *mockExtensionController) InstallExtensionWithResult(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options extensionController.InstallOptions) (*extensionController.InstallResult, error) {
+	args := m.Called(ctx, db, extensionId, extensionVersion, options)
+	if result, ok := args.Get(0).(*extensionController.InstallResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) DryRunInstallExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options extensionController.InstallOptions) (*extensionController.DryRunResult, error) {
+	args := m.Called(ctx, db, extensionId, extensionVersion, options)
+	if result, ok := args.Get(0).(*extensionController.DryRunResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) DryRunUpgradeExtension(ctx context.Context, db *sql.DB, extensionId string, targetVersion string) (*extensionController.DryRunResult, error) {
+	args := m.Called(ctx, db, extensionId, targetVersion)
+	if result, ok := args.Get(0).(*extensionController.DryRunResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) DryRunCreateInstance(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, parameterValues []extensionController.ParameterValue, options extensionController.CreateInstanceOptions) (*extensionController.DryRunResult, error) {
+	args := m.Called(ctx, db, extensionId, extensionVersion, parameterValues, options)
+	if result, ok := args.Get(0).(*extensionController.DryRunResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) GetAuditLog(ctx context.Context, db *sql.DB, filter extensionController.AuditLogFilter) ([]extensionController.AuditEntry, error) {
+	args := m.Called(ctx, db, filter)
+	if result, ok := args.Get(0).([]extensionController.AuditEntry); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) UninstallExtensionWithOptions(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, options extensionController.UninstallOptions) (*extensionController.UninstallResult, error) {
+	args := m.Called(ctx, db, extensionId, extensionVersion, options)
+	if result, ok := args.Get(0).(*extensionController.UninstallResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *mockExtensionController) UninstallExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) error {
 	args := m.Called(ctx, db, extensionId, extensionVersion)
 	return args.Error(0)
 }
 
+func (m *mockExtensionController) RepairExtension(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) error {
+	args := m.Called(ctx, db, extensionId, extensionVersion)
+	return args.Error(0)
+}
+
 func (m *mockExtensionController) UpgradeExtension(ctx context.Context, db *sql.DB, extensionId string) (*extensionAPI.JsUpgradeResult, error) {
 	args := m.Called(ctx, db, extensionId)
 	if result, ok := args.Get(0).(*extensionAPI.JsUpgradeResult); ok {
@@ -38,6 +97,30 @@ func (m *mockExtensionController) UpgradeExtension(ctx context.Context, db *sql.
 	return nil, args.Error(1)
 }
 
+func (m *mockExtensionController) UpgradeExtensionToVersion(ctx context.Context, db *sql.DB, extensionId string, targetVersion string) (*extensionAPI.JsUpgradeResult, error) {
+	args := m.Called(ctx, db, extensionId, targetVersion)
+	if result, ok := args.Get(0).(*extensionAPI.JsUpgradeResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) GetAvailableUpdates(ctx context.Context, db *sql.DB) ([]*extensionController.ExtensionUpdate, error) {
+	args := m.Called(ctx, db)
+	if result, ok := args.Get(0).([]*extensionController.ExtensionUpdate); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) UpgradeExtensions(ctx context.Context, db *sql.DB, extensionIds []string, options extensionController.BulkUpgradeOptions) ([]*extensionController.BulkUpgradeResult, error) {
+	args := m.Called(ctx, db, extensionIds, options)
+	if result, ok := args.Get(0).([]*extensionController.BulkUpgradeResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *mockExtensionController) GetInstalledExtensions(ctx context.Context, db *sql.DB) ([]*extensionAPI.JsExtInstallation, error) {
 	args := m.Called(ctx, db)
 	if installations, ok := args.Get(0).([]*extensionAPI.JsExtInstallation); ok {
@@ -70,6 +153,14 @@ func (m *mockExtensionController) CreateInstance(ctx context.Context, db *sql.DB
 	return nil, args.Error(1)
 }
 
+func (m *mockExtensionController) CreateInstanceWithOptions(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string, parameterValues []extensionController.ParameterValue, options extensionController.CreateInstanceOptions) (*extensionController.CreateInstanceResult, error) {
+	args := m.Called(ctx, db, extensionId, extensionVersion, parameterValues, options)
+	if result, ok := args.Get(0).(*extensionController.CreateInstanceResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *mockExtensionController) FindInstances(ctx context.Context, db *sql.DB, extensionId string, extensionVersion string) ([]*extensionAPI.JsExtInstance, error) {
 	args := m.Called(ctx, db, extensionId, extensionVersion)
 	if instances, ok := args.Get(0).([]*extensionAPI.JsExtInstance); ok {
@@ -82,3 +173,95 @@ func (m *mockExtensionController) DeleteInstance(ctx context.Context, db *sql.DB
 	args := m.Called(ctx, db, extensionId, extensionVersion, instanceId)
 	return args.Error(0)
 }
+
+func (m *mockExtensionController) UploadMissingFiles(ctx context.Context, db *sql.DB, extensionId string, options extensionController.UploadOptions) ([]*extensionController.UploadedFile, error) {
+	args := m.Called(ctx, db, extensionId, options)
+	if files, ok := args.Get(0).([]*extensionController.UploadedFile); ok {
+		return files, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) UploadFile(ctx context.Context, db *sql.DB, extensionId string, fileName string, content io.Reader, options extensionController.UploadOptions) (*extensionController.UploadedFile, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+	args := m.Called(ctx, db, extensionId, fileName, string(data), options)
+	if file, ok := args.Get(0).(*extensionController.UploadedFile); ok {
+		return file, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) ExportInstallations(ctx context.Context, db *sql.DB) (*extensionController.ExportDocument, error) {
+	args := m.Called(ctx, db)
+	if result, ok := args.Get(0).(*extensionController.ExportDocument); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) PreviewImport(ctx context.Context, db *sql.DB, document *extensionController.ExportDocument, options extensionController.ImportOptions) (*extensionController.ImportPlan, error) {
+	args := m.Called(ctx, db, document, options)
+	if result, ok := args.Get(0).(*extensionController.ImportPlan); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) ImportInstallations(ctx context.Context, db *sql.DB, document *extensionController.ExportDocument, options extensionController.ImportOptions) (*extensionController.ImportResult, error) {
+	args := m.Called(ctx, db, document, options)
+	if result, ok := args.Get(0).(*extensionController.ImportResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) PlanManifest(ctx context.Context, db *sql.DB, manifest *extensionController.Manifest) (*extensionController.ApplyPlan, error) {
+	args := m.Called(ctx, db, manifest)
+	if result, ok := args.Get(0).(*extensionController.ApplyPlan); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) ApplyManifest(ctx context.Context, db *sql.DB, manifest *extensionController.Manifest, options extensionController.ApplyOptions) (*extensionController.ApplyResult, error) {
+	args := m.Called(ctx, db, manifest, options)
+	if result, ok := args.Get(0).(*extensionController.ApplyResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) ApplyManifestPlan(ctx context.Context, db *sql.DB, plan *extensionController.ApplyPlan, options extensionController.ApplyOptions) (*extensionController.ApplyResult, error) {
+	args := m.Called(ctx, db, plan, options)
+	if result, ok := args.Get(0).(*extensionController.ApplyResult); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) GetBucketFsUsage(ctx context.Context, db *sql.DB) (*extensionController.BucketFsUsageReport, error) {
+	args := m.Called(ctx, db)
+	if result, ok := args.Get(0).(*extensionController.BucketFsUsageReport); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockExtensionController) CheckReadiness() []extensionController.ReadinessCheck {
+	args := m.Called()
+	if result, ok := args.Get(0).([]extensionController.ReadinessCheck); ok {
+		return result
+	}
+	return nil
+}
+
+func (m *mockExtensionController) DetectDrift(ctx context.Context, db *sql.DB) (*extensionController.DriftReport, error) {
+	args := m.Called(ctx, db)
+	if result, ok := args.Get(0).(*extensionController.DriftReport); ok {
+		return result, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -12,6 +12,7 @@ const (
 	TagExtension    = "Extension"
 	TagInstallation = "Installation"
 	TagInstance     = "Instance"
+	TagAudit        = "Audit"
 
 	BearerAuth = "DbAccessToken"
 	BasicAuth  = "DbUsernamePassword"
@@ -34,6 +35,7 @@ func addPublicEndpointsWithController(api *openapi.API, addCauseToInternalServer
 	api.AddTag(TagExtension, "List and install extensions")
 	api.AddTag(TagInstallation, "List and uninstall installed extensions")
 	api.AddTag(TagInstance, "Calls to list, create and remove instances of an extension")
+	api.AddTag(TagAudit, "Audit log of operations that modified installations or instances")
 
 	apiContext := NewApiContext(controller, addCauseToInternalServerError)
 
@@ -43,6 +45,12 @@ func addPublicEndpointsWithController(api *openapi.API, addCauseToInternalServer
 	if err := api.Get(ListInstalledExtensions(apiContext)); err != nil {
 		return err
 	}
+	if err := api.Get(ListAvailableUpdates(apiContext)); err != nil {
+		return err
+	}
+	if err := api.Get(DetectDrift(apiContext)); err != nil {
+		return err
+	}
 	if err := api.Get(GetExtensionDetails(apiContext)); err != nil {
 		return err
 	}
@@ -55,6 +63,21 @@ func addPublicEndpointsWithController(api *openapi.API, addCauseToInternalServer
 	if err := api.Post(UpgradeExtension(apiContext)); err != nil {
 		return err
 	}
+	if err := api.Post(UpgradeExtensions(apiContext)); err != nil {
+		return err
+	}
+	if err := api.Post(RepairExtension(apiContext)); err != nil {
+		return err
+	}
+	if err := api.Post(UploadMissingFiles(apiContext)); err != nil {
+		return err
+	}
+	if err := api.Put(UploadFile(apiContext)); err != nil {
+		return err
+	}
+	if err := api.Get(GetBucketFsUsage(apiContext)); err != nil {
+		return err
+	}
 	if err := api.Post(CreateInstance(apiContext)); err != nil {
 		return err
 	}
@@ -64,5 +87,26 @@ func addPublicEndpointsWithController(api *openapi.API, addCauseToInternalServer
 	if err := api.Delete(DeleteInstance(apiContext)); err != nil {
 		return err
 	}
+	if err := api.Get(GetAuditLog(apiContext)); err != nil {
+		return err
+	}
+	if err := api.Get(ExportInstallations(apiContext)); err != nil {
+		return err
+	}
+	if err := api.Post(ImportInstallations(apiContext)); err != nil {
+		return err
+	}
+	if err := api.Post(ApplyManifest(apiContext)); err != nil {
+		return err
+	}
+	if err := documentDryRunResponse(api, "InstallExtension", "InstallExtensionResponse"); err != nil {
+		return err
+	}
+	if err := documentDryRunResponse(api, "UpgradeExtension", "UpgradeExtensionResponse"); err != nil {
+		return err
+	}
+	if err := documentDryRunResponse(api, "CreateInstance", "CreateInstanceResponse"); err != nil {
+		return err
+	}
 	return nil
 }
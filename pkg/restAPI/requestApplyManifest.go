@@ -0,0 +1,157 @@
+package restAPI
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionController"
+	"github.com/sirupsen/logrus"
+)
+
+func ApplyManifest(apiContext *ApiContext) *openapi.Post {
+	//nolint:exhaustruct // Default values for request are OK
+	return &openapi.Post{
+		Summary:        "Make the database match a manifest of desired extensions and instances.",
+		Description:    "This installs and upgrades the extensions listed in the manifest, creates missing instances and deletes instances not listed in the manifest. Installed extensions not listed in the manifest are not changed. Each change is applied in its own transaction, after a failed change the remaining changes are skipped. If preview is true, the response only contains the planned changes without applying them.",
+		OperationID:    "ApplyManifest",
+		Tags:           []string{TagInstallation},
+		Authentication: authentication,
+		RequestBody: Manifest{Extensions: []ManifestExtension{{ExtensionId: "s3-vs", Version: "1.3.0", Instances: []ManifestInstance{{
+			Name: "S3_SCHEMA", Parameters: []ParameterValue{{Name: "bucket", Value: "my-bucket"}}}}}}},
+		Response: map[string]openapi.MethodResponse{
+			"409": conflictResponse,
+			"400": {
+				Description: "Invalid manifest",
+				Value:       apiErrors.NewBadRequestErrorF(`extension "s3-vs" is listed more than once in the manifest`)},
+			"200": {
+				Description: "Planned changes and their outcome",
+				Value: ApplyManifestResponse{Changes: []ApplyChange{
+					{Action: "delete-instance", ExtensionId: "s3-vs", Version: "1.2.0", InstalledVersion: "", InstanceName: "OLD_SCHEMA", InstanceId: "OLD_SCHEMA", Applied: true, Error: nil, Warnings: nil},
+					{Action: "upgrade", ExtensionId: "s3-vs", Version: "1.3.0", InstalledVersion: "1.2.0", InstanceName: "", InstanceId: "", Applied: true, Error: nil, Warnings: nil},
+					{Action: "create-instance", ExtensionId: "s3-vs", Version: "1.3.0", InstalledVersion: "", InstanceName: "S3_SCHEMA", InstanceId: "", Applied: true, Error: nil, Warnings: nil}}}},
+		},
+		Path: newPathWithDbQueryParams().Add("installations").Add("apply").
+			WithQueryParameter("acceptLicenses", openapi.BOOLEAN, "Accept the licenses of all files that require a license agreement (default: false)", false).
+			WithQueryParameter("force", openapi.BOOLEAN, forceParameterDescription, false).
+			WithQueryParameter("preview", openapi.BOOLEAN, "Only return the planned changes without applying them (default: false)", false),
+		HandlerFunc: adaptDbHandler(apiContext, handleApplyManifest(apiContext)),
+	}
+}
+
+func handleApplyManifest(apiContext *ApiContext) dbHandler {
+	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
+		//nolint:exhaustruct // Omitting values by intention for deserialization
+		requestBody := Manifest{}
+		err := DecodeJSONBody(writer, request, &requestBody)
+		if err != nil {
+			return err
+		}
+		acceptLicenses, err := getBoolQueryParam(request, "acceptLicenses")
+		if err != nil {
+			return err
+		}
+		force, err := getBoolQueryParam(request, "force")
+		if err != nil {
+			return err
+		}
+		preview, err := getBoolQueryParam(request, "preview")
+		if err != nil {
+			return err
+		}
+		manifest := convertManifest(requestBody)
+		if preview {
+			plan, err := apiContext.Controller.PlanManifest(request.Context(), db, manifest)
+			if err != nil {
+				return err
+			}
+			return SendJSON(request.Context(), writer, createApplyPlanResponse(plan))
+		}
+		result, err := apiContext.Controller.ApplyManifest(request.Context(), db, manifest, extensionController.ApplyOptions{AcceptLicenses: acceptLicenses, Force: force})
+		if err != nil {
+			return err
+		}
+		return SendJSON(request.Context(), writer, createApplyResponse(apiContext, result))
+	}
+}
+
+func convertManifest(manifest Manifest) *extensionController.Manifest {
+	extensions := make([]extensionController.ManifestExtension, 0, len(manifest.Extensions))
+	for _, extension := range manifest.Extensions {
+		instances := make([]extensionController.ManifestInstance, 0, len(extension.Instances))
+		for _, instance := range extension.Instances {
+			parameters := make([]extensionController.ParameterValue, 0, len(instance.Parameters))
+			for _, p := range instance.Parameters {
+				parameters = append(parameters, extensionController.ParameterValue{Name: p.Name, Value: p.Value})
+			}
+			instances = append(instances, extensionController.ManifestInstance{Name: instance.Name, Parameters: parameters})
+		}
+		extensions = append(extensions, extensionController.ManifestExtension{ExtensionId: extension.ExtensionId, Version: extension.Version, Instances: instances})
+	}
+	return &extensionController.Manifest{Extensions: extensions}
+}
+
+func createApplyPlanResponse(plan *extensionController.ApplyPlan) ApplyManifestResponse {
+	changes := make([]ApplyChange, 0, len(plan.Changes))
+	for _, change := range plan.Changes {
+		changes = append(changes, convertApplyChange(change))
+	}
+	return ApplyManifestResponse{Changes: changes}
+}
+
+func createApplyResponse(apiContext *ApiContext, result *extensionController.ApplyResult) ApplyManifestResponse {
+	changes := make([]ApplyChange, 0, len(result.Changes))
+	for _, changeResult := range result.Changes {
+		change := convertApplyChange(changeResult.ApplyChange)
+		change.Applied = changeResult.Applied
+		change.Warnings = changeResult.Warnings
+		if changeResult.Error != nil {
+			logrus.Warnf("Applying %s for extension %q failed: %v", changeResult.Action, changeResult.ExtensionId, changeResult.Error)
+			change.Error = convertResultError(apiContext, changeResult.Error)
+		}
+		changes = append(changes, change)
+	}
+	return ApplyManifestResponse{Changes: changes}
+}
+
+func convertApplyChange(change *extensionController.ApplyChange) ApplyChange {
+	return ApplyChange{Action: string(change.Action), ExtensionId: change.ExtensionId, Version: change.Version, InstalledVersion: change.InstalledVersion,
+		InstanceName: change.InstanceName, InstanceId: change.InstanceId, Applied: false, Error: nil, Warnings: nil}
+}
+
+// Manifest describes the desired extensions and instances of a database.
+type Manifest struct {
+	Extensions []ManifestExtension `json:"extensions"` // Desired extensions, dependencies first.
+}
+
+// ManifestExtension is the desired state of an extension.
+type ManifestExtension struct {
+	ExtensionId string             `json:"extensionId"`         // ID of the extension.
+	Version     string             `json:"version"`             // Desired version.
+	Instances   []ManifestInstance `json:"instances,omitempty"` // All desired instances. Other instances are deleted.
+}
+
+// ManifestInstance is a desired instance of an extension.
+type ManifestInstance struct {
+	Name       string           `json:"name"`       // Name of the instance.
+	Parameters []ParameterValue `json:"parameters"` // Parameter values used for creating the instance.
+}
+
+// Response data for applying a manifest.
+type ApplyManifestResponse struct {
+	Changes []ApplyChange `json:"changes"` // Planned changes in the order they are applied.
+}
+
+// ApplyChange is a single change required for applying a manifest.
+type ApplyChange struct {
+	Action           string              `json:"action"`                     // Type of change: "install", "upgrade", "create-instance" or "delete-instance".
+	ExtensionId      string              `json:"extensionId"`                // ID of the extension.
+	Version          string              `json:"version"`                    // Version to install or upgrade to or version of the instance.
+	InstalledVersion string              `json:"installedVersion,omitempty"` // Version installed before an upgrade.
+	InstanceName     string              `json:"instanceName,omitempty"`     // Name of the instance to create or delete.
+	InstanceId       string              `json:"instanceId,omitempty"`       // ID of the instance to delete.
+	Applied          bool                `json:"applied"`                    // True if the change was committed. Always false for a preview.
+	Error            *apiErrors.APIError `json:"error,omitempty"`            // Error if the change failed.
+	Warnings         []string            `json:"warnings,omitempty"`         // Warnings for the user, e.g. because a deprecated version was installed.
+}
@@ -0,0 +1,66 @@
+package restAPI
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	"github.com/exasol/extension-manager/pkg/extensionController"
+)
+
+func GetBucketFsUsage(apiContext *ApiContext) *openapi.Get {
+	//nolint:exhaustruct // Default values for request are OK
+	return &openapi.Get{
+		Summary:        "Report usage of files in BucketFS",
+		Description:    "List all files in the configured BucketFS base paths with their size. Each file is classified as referenced by a script in any schema, required by an available extension or unreferenced. Unreferenced files can be deleted safely.",
+		OperationID:    "GetBucketFsUsage",
+		Tags:           []string{TagExtension},
+		Authentication: authentication,
+		Response: map[string]openapi.MethodResponse{
+			"200": {Description: "Usage of files in BucketFS", Value: BucketFsUsageResponse{
+				Files: []BucketFsFileUsage{
+					{Path: "/buckets/bfsdefault/default/s3-vs-2.0.0.jar", Name: "s3-vs-2.0.0.jar", Size: 2048, Usage: "referenced-by-script", Scripts: []string{"EXA_EXTENSIONS.S3_FILES_ADAPTER"}, Extensions: []string{"s3-vs.js"}},
+					{Path: "/buckets/bfsdefault/default/s3-vs-1.0.0.jar", Name: "s3-vs-1.0.0.jar", Size: 1024, Usage: "unreferenced", Scripts: nil, Extensions: nil}},
+				TotalSize:        3072,
+				UnreferencedSize: 1024,
+			}},
+		},
+		Path:        newPathWithDbQueryParams().Add("bucketfs").Add("usage"),
+		HandlerFunc: adaptDbHandler(apiContext, handleGetBucketFsUsage(apiContext)),
+	}
+}
+
+func handleGetBucketFsUsage(apiContext *ApiContext) dbHandler {
+	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
+		report, err := apiContext.Controller.GetBucketFsUsage(request.Context(), db)
+		if err != nil {
+			return err
+		}
+		return SendJSON(request.Context(), writer, createBucketFsUsageResponse(report))
+	}
+}
+
+func createBucketFsUsageResponse(report *extensionController.BucketFsUsageReport) BucketFsUsageResponse {
+	files := make([]BucketFsFileUsage, 0, len(report.Files))
+	for _, file := range report.Files {
+		files = append(files, BucketFsFileUsage{Path: file.Path, Name: file.Name, Size: file.Size, Usage: string(file.Usage), Scripts: file.Scripts, Extensions: file.Extensions})
+	}
+	return BucketFsUsageResponse{Files: files, TotalSize: report.TotalSize, UnreferencedSize: report.UnreferencedSize}
+}
+
+// BucketFsUsageResponse contains all files in BucketFS with their usage.
+type BucketFsUsageResponse struct {
+	Files            []BucketFsFileUsage `json:"files"`
+	TotalSize        int                 `json:"totalSize"`        // Size of all files in bytes.
+	UnreferencedSize int                 `json:"unreferencedSize"` // Size of all unreferenced files in bytes.
+}
+
+// BucketFsFileUsage describes how a file in BucketFS is used.
+type BucketFsFileUsage struct {
+	Path       string   `json:"path"`                 // Absolute path in BucketFS.
+	Name       string   `json:"name"`                 // File name.
+	Size       int      `json:"size"`                 // File size in bytes.
+	Usage      string   `json:"usage"`                // "referenced-by-script", "required-by-extension" or "unreferenced".
+	Scripts    []string `json:"scripts,omitempty"`    // Qualified names of the scripts referencing the file.
+	Extensions []string `json:"extensions,omitempty"` // IDs of the extensions requiring the file.
+}
@@ -16,13 +16,14 @@ func CreateInstance(apiContext *ApiContext) *openapi.Post {
 	//nolint:exhaustruct // Default values for request are OK
 	return &openapi.Post{
 		Summary:        "Create an instance of an extension.",
-		Description:    "This creates a new instance of an extension, e.g. a virtual schema.",
+		Description:    "This creates a new instance of an extension, e.g. a virtual schema. If the version is deprecated, the server's deprecated version policy either allows creating the instance, reports a warning or rejects it unless force is true. If dryRun is true, the creation is rolled back and the response contains the executed statements instead. If a dry-run fails, the error details contain the statements executed until the failure in property dryRun.",
 		OperationID:    "CreateInstance",
 		Tags:           []string{TagInstance},
 		Authentication: authentication,
 		RequestBody:    CreateInstanceRequest{ParameterValues: []ParameterValue{{Name: "param1", Value: "value1"}}},
 		Response: map[string]openapi.MethodResponse{
-			"200": {Description: "OK", Value: CreateInstanceResponse{InstanceId: "id", InstanceName: "new-instance-name"}},
+			"200": {Description: "Instance created successfully or dry-run succeeded. With dry-run the response contains the executed statements", Value: CreateInstanceResponse{InstanceId: "id", InstanceName: "new-instance-name", Warnings: nil}},
+			"409": conflictResponse,
 			"400": {
 				Description: "Invalid parameters specified",
 				Value:       apiErrors.NewBadRequestErrorF("Validation failed: parameter 'Virtual Schema' is missing")},
@@ -33,7 +34,9 @@ func CreateInstance(apiContext *ApiContext) *openapi.Post {
 		Path: newPathWithDbQueryParams().Add("installations").
 			AddParameter("extensionId", openapi.STRING, "ID of the installed extension for which to create an instance").
 			AddParameter("extensionVersion", openapi.STRING, "Version of the installed extension for which to create an instance").
-			Add("instances"),
+			Add("instances").
+			WithQueryParameter("force", openapi.BOOLEAN, forceParameterDescription, false).
+			WithQueryParameter("dryRun", openapi.BOOLEAN, dryRunParameterDescription, false),
 		HandlerFunc: adaptDbHandler(apiContext, handleCreateInstance(apiContext)),
 	}
 }
@@ -52,12 +55,25 @@ func handleCreateInstance(apiContext *ApiContext) dbHandler {
 		}
 		extensionId := chi.URLParam(request, "extensionId")
 		extensionVersion := chi.URLParam(request, "extensionVersion")
-		instance, err := apiContext.Controller.CreateInstance(request.Context(), db, extensionId, extensionVersion, parameters)
+		force, err := getBoolQueryParam(request, "force")
 		if err != nil {
 			return err
 		}
-		logrus.Debugf("Created instance %q", instance)
-		return SendJSON(request.Context(), writer, CreateInstanceResponse{InstanceId: instance.Id, InstanceName: instance.Name})
+		dryRun, err := getBoolQueryParam(request, "dryRun")
+		if err != nil {
+			return err
+		}
+		options := extensionController.CreateInstanceOptions{Force: force}
+		if dryRun {
+			result, err := apiContext.Controller.DryRunCreateInstance(request.Context(), db, extensionId, extensionVersion, parameters, options)
+			return sendDryRunResult(request.Context(), writer, result, err)
+		}
+		result, err := apiContext.Controller.CreateInstanceWithOptions(request.Context(), db, extensionId, extensionVersion, parameters, options)
+		if err != nil {
+			return err
+		}
+		logrus.Debugf("Created instance %q", result.Instance)
+		return SendJSON(request.Context(), writer, CreateInstanceResponse{InstanceId: result.Instance.Id, InstanceName: result.Instance.Name, Warnings: result.Warnings})
 	}
 }
 
@@ -74,6 +90,7 @@ type ParameterValue struct {
 
 // Response data for creating a new instance of an extension.
 type CreateInstanceResponse struct {
-	InstanceId   string `json:"instanceId"`   // The ID of the newly created instance
-	InstanceName string `json:"instanceName"` // The name of the newly created instance
+	InstanceId   string   `json:"instanceId"`         // The ID of the newly created instance
+	InstanceName string   `json:"instanceName"`       // The name of the newly created instance
+	Warnings     []string `json:"warnings,omitempty"` // Warnings for the user, e.g. because the version is deprecated
 }
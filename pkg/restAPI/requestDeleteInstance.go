@@ -18,6 +18,7 @@ func DeleteInstance(apiContext *ApiContext) *openapi.Delete {
 		Tags:           []string{TagInstance},
 		Authentication: authentication,
 		Response: map[string]openapi.MethodResponse{
+			"409": conflictResponse,
 			"204": {Description: "OK"},
 			"404": {
 				Description: "Extension or instance not found",
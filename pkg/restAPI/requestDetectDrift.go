@@ -0,0 +1,63 @@
+package restAPI
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	"github.com/exasol/extension-manager/pkg/extensionController"
+)
+
+func DetectDrift(apiContext *ApiContext) *openapi.Get {
+	//nolint:exhaustruct // Default values for request are OK
+	return &openapi.Get{
+		Summary:        "Detect drift between database objects and installations",
+		Description:    "Compare the scripts in the extension schema and the virtual schemas with the installations and instances reported by all known extensions. The result contains scripts and virtual schemas that belong to no known extension, virtual schemas whose adapter script is missing and scripts that reference files missing in BucketFS.",
+		OperationID:    "DetectDrift",
+		Tags:           []string{TagInstallation},
+		Authentication: authentication,
+		Response: map[string]openapi.MethodResponse{
+			"200": {Description: "Objects that don't match the installations", Value: DriftResponse{
+				Findings: []DriftFinding{
+					{Type: "orphaned-script", ObjectName: "OLD_ADAPTER", Reference: "", Message: `Script "OLD_ADAPTER" belongs to no known extension`},
+					{Type: "missing-bucketfs-file", ObjectName: "S3_FILES_ADAPTER", Reference: "/buckets/bfsdefault/default/s3-vs-1.0.0.jar",
+						Message: `Script "S3_FILES_ADAPTER" references file "/buckets/bfsdefault/default/s3-vs-1.0.0.jar" which does not exist in BucketFS`}},
+				Warnings: nil,
+			}},
+		},
+		Path:        newPathWithDbQueryParams().Add("installations").Add("drift"),
+		HandlerFunc: adaptDbHandler(apiContext, handleDetectDrift(apiContext)),
+	}
+}
+
+func handleDetectDrift(apiContext *ApiContext) dbHandler {
+	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
+		report, err := apiContext.Controller.DetectDrift(request.Context(), db)
+		if err != nil {
+			return err
+		}
+		return SendJSON(request.Context(), writer, createDriftResponse(report))
+	}
+}
+
+func createDriftResponse(report *extensionController.DriftReport) DriftResponse {
+	findings := make([]DriftFinding, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		findings = append(findings, DriftFinding{Type: string(finding.Type), ObjectName: finding.ObjectName, Reference: finding.Reference, Message: finding.Message})
+	}
+	return DriftResponse{Findings: findings, Warnings: report.Warnings}
+}
+
+// DriftResponse contains database objects that don't match the installations reported by the extensions.
+type DriftResponse struct {
+	Findings []DriftFinding `json:"findings"`
+	Warnings []string       `json:"warnings,omitempty"` // Warnings for the user, e.g. because the instances of an extension could not be found.
+}
+
+// DriftFinding describes a database object that doesn't match the installations.
+type DriftFinding struct {
+	Type       string `json:"type"`                // "orphaned-script", "orphaned-virtual-schema", "missing-adapter-script" or "missing-bucketfs-file".
+	ObjectName string `json:"objectName"`          // Name of the script or virtual schema.
+	Reference  string `json:"reference,omitempty"` // Missing adapter script or BucketFS file.
+	Message    string `json:"message"`
+}
@@ -0,0 +1,87 @@
+package restAPI
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	"github.com/exasol/extension-manager/pkg/extensionController"
+)
+
+func ExportInstallations(apiContext *ApiContext) *openapi.Get {
+	//nolint:exhaustruct // Default values for request are OK
+	return &openapi.Get{
+		Summary:        "Export installed extensions and their instances.",
+		Description:    "Get a portable document with all installed extensions, their versions and the parameters of their instances. Values of secret parameters are omitted and must be supplied when importing the document. Instances whose parameters are not found in the audit log are marked as incomplete and reported in a warning.",
+		OperationID:    "ExportInstallations",
+		Tags:           []string{TagInstallation},
+		Authentication: authentication,
+		Response: map[string]openapi.MethodResponse{
+			"200": {Description: "Exported installations", Value: exportDocumentExample},
+		},
+		Path:        newPathWithDbQueryParams().Add("installations").Add("export"),
+		HandlerFunc: adaptDbHandler(apiContext, handleExportInstallations(apiContext)),
+	}
+}
+
+var exportDocumentExample = ExportDocument{FormatVersion: 1, Extensions: []ExportedExtension{{
+	ExtensionId: "s3-vs", Name: "S3 Virtual Schema", Version: "1.3.0", Instances: []ExportedInstance{{
+		Name:             "S3_SCHEMA",
+		Parameters:       []ParameterValue{{Name: "bucket", Value: "my-bucket"}},
+		SecretParameters: []string{"secretKey"},
+		Incomplete:       false}}}},
+	Warnings: nil}
+
+func handleExportInstallations(apiContext *ApiContext) dbHandler {
+	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
+		document, err := apiContext.Controller.ExportInstallations(request.Context(), db)
+		if err != nil {
+			return err
+		}
+		return SendJSON(request.Context(), writer, convertExportDocument(document))
+	}
+}
+
+func convertExportDocument(document *extensionController.ExportDocument) ExportDocument {
+	extensions := make([]ExportedExtension, 0, len(document.Extensions))
+	for _, extension := range document.Extensions {
+		instances := make([]ExportedInstance, 0, len(extension.Instances))
+		for _, instance := range extension.Instances {
+			instances = append(instances, ExportedInstance{Name: instance.Name, Parameters: convertParameterValues(instance.Parameters), SecretParameters: instance.SecretParameters,
+				Incomplete: instance.Incomplete})
+		}
+		extensions = append(extensions, ExportedExtension{ExtensionId: extension.ExtensionId, Name: extension.Name, Version: extension.Version, Instances: instances})
+	}
+	return ExportDocument{FormatVersion: document.FormatVersion, Extensions: extensions, Warnings: document.Warnings}
+}
+
+func convertParameterValues(parameters []extensionController.ParameterValue) []ParameterValue {
+	result := make([]ParameterValue, 0, len(parameters))
+	for _, p := range parameters {
+		result = append(result, ParameterValue{Name: p.Name, Value: p.Value})
+	}
+	return result
+}
+
+// ExportDocument is a portable description of installed extensions and their instances.
+type ExportDocument struct {
+	FormatVersion int                 `json:"formatVersion"`      // Version of the document format.
+	Extensions    []ExportedExtension `json:"extensions"`         // Installed extensions, dependencies first.
+	Warnings      []string            `json:"warnings,omitempty"` // Warnings for the user, e.g. because the parameters of an instance are unknown.
+}
+
+// ExportedExtension is an installed extension.
+type ExportedExtension struct {
+	ExtensionId string             `json:"extensionId"`         // ID of the extension.
+	Name        string             `json:"name"`                // Name of the extension.
+	Version     string             `json:"version"`             // Installed version.
+	Instances   []ExportedInstance `json:"instances,omitempty"` // Instances of the extension.
+}
+
+// ExportedInstance is an instance of an extension.
+type ExportedInstance struct {
+	Name             string           `json:"name"`                       // Name of the instance.
+	Parameters       []ParameterValue `json:"parameters"`                 // Parameter values without secrets.
+	SecretParameters []string         `json:"secretParameters,omitempty"` // Names of secret parameters omitted from the export.
+	Incomplete       bool             `json:"incomplete,omitempty"`       // True if the parameters are unknown and the instance must be created manually.
+}
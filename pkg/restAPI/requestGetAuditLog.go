@@ -0,0 +1,114 @@
+package restAPI
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	"github.com/exasol/extension-manager/pkg/extensionController"
+)
+
+func GetAuditLog(apiContext *ApiContext) *openapi.Get {
+	//nolint:exhaustruct // Default values for request are OK
+	return &openapi.Get{
+		Summary:        "Get audit log",
+		Description:    "Get the audit log of all operations that modified installations or instances, newest entries first. Values of secret parameters are redacted.",
+		OperationID:    "GetAuditLog",
+		Tags:           []string{TagAudit},
+		Authentication: authentication,
+		Response: map[string]openapi.MethodResponse{
+			"200": {Description: "Audit log entries", Value: AuditLogResponse{Entries: []AuditLogEntry{{
+				Timestamp: "2024-03-01T12:30:00Z", DbUser: "SYS", Operation: "CREATE_INSTANCE", ExtensionId: "s3-vs", ExtensionVersion: "1.0.0", InstanceId: "MY_VS",
+				Parameters: []ParameterValue{{Name: "bucket", Value: "my-bucket"}, {Name: "secretKey", Value: "***"}}, Outcome: "SUCCESS", ErrorMessage: ""}}}},
+		},
+		Path: newPathWithDbQueryParams().Add("audit-log").
+			WithQueryParameter("extensionId", openapi.STRING, "Only entries for this extension", false).
+			WithQueryParameter("operation", openapi.STRING, "Only entries for this operation: INSTALL, UNINSTALL, UPGRADE, REPAIR, CREATE_INSTANCE or DELETE_INSTANCE", false).
+			WithQueryParameter("user", openapi.STRING, "Only entries of this database user", false).
+			WithQueryParameter("outcome", openapi.STRING, "Only entries with this outcome: SUCCESS or FAILURE", false).
+			WithQueryParameter("from", openapi.STRING, "Only entries at or after this RFC 3339 timestamp", false).
+			WithQueryParameter("to", openapi.STRING, "Only entries before this RFC 3339 timestamp", false).
+			WithQueryParameter("limit", openapi.INTEGER, "Maximum number of entries (default: no limit)", false),
+		HandlerFunc: adaptDbHandler(apiContext, handleGetAuditLog(apiContext)),
+	}
+}
+
+func handleGetAuditLog(apiContext *ApiContext) dbHandler {
+	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
+		filter, err := getAuditLogFilter(request)
+		if err != nil {
+			return err
+		}
+		entries, err := apiContext.Controller.GetAuditLog(request.Context(), db, filter)
+		if err != nil {
+			return err
+		}
+		return SendJSON(request.Context(), writer, createAuditLogResponse(entries))
+	}
+}
+
+func getAuditLogFilter(request *http.Request) (extensionController.AuditLogFilter, error) {
+	query := request.URL.Query()
+	from, err := getTimeQueryParam(request, "from")
+	if err != nil {
+		return extensionController.AuditLogFilter{}, err
+	}
+	to, err := getTimeQueryParam(request, "to")
+	if err != nil {
+		return extensionController.AuditLogFilter{}, err
+	}
+	limit, err := getIntQueryParam(request, "limit")
+	if err != nil {
+		return extensionController.AuditLogFilter{}, err
+	}
+	return extensionController.AuditLogFilter{
+		ExtensionId: query.Get("extensionId"),
+		Operation:   extensionController.AuditOperation(query.Get("operation")),
+		DbUser:      query.Get("user"),
+		Outcome:     extensionController.AuditOutcome(query.Get("outcome")),
+		From:        from,
+		To:          to,
+		Limit:       limit,
+	}, nil
+}
+
+func createAuditLogResponse(entries []extensionController.AuditEntry) AuditLogResponse {
+	converted := make([]AuditLogEntry, 0, len(entries))
+	for _, entry := range entries {
+		parameters := make([]ParameterValue, 0, len(entry.Parameters))
+		for _, p := range entry.Parameters {
+			parameters = append(parameters, ParameterValue{Name: p.Name, Value: p.Value})
+		}
+		converted = append(converted, AuditLogEntry{
+			Timestamp:        entry.Timestamp.UTC().Format(time.RFC3339),
+			DbUser:           entry.DbUser,
+			Operation:        string(entry.Operation),
+			ExtensionId:      entry.ExtensionId,
+			ExtensionVersion: entry.ExtensionVersion,
+			InstanceId:       entry.InstanceId,
+			Parameters:       parameters,
+			Outcome:          string(entry.Outcome),
+			ErrorMessage:     entry.ErrorMessage,
+		})
+	}
+	return AuditLogResponse{Entries: converted}
+}
+
+// AuditLogResponse contains entries of the audit log.
+type AuditLogResponse struct {
+	Entries []AuditLogEntry `json:"entries"`
+}
+
+// AuditLogEntry describes a single operation that modified an installation or instance.
+type AuditLogEntry struct {
+	Timestamp        string           `json:"timestamp"`                  // Time of the operation as RFC 3339 timestamp
+	DbUser           string           `json:"dbUser"`                     // Database user that executed the operation
+	Operation        string           `json:"operation"`                  // INSTALL, UNINSTALL, UPGRADE, REPAIR, CREATE_INSTANCE or DELETE_INSTANCE
+	ExtensionId      string           `json:"extensionId"`                // ID of the extension
+	ExtensionVersion string           `json:"extensionVersion,omitempty"` // Version of the extension
+	InstanceId       string           `json:"instanceId,omitempty"`       // ID of the created or deleted instance
+	Parameters       []ParameterValue `json:"parameters,omitempty"`       // Parameters of a new instance, secret values are redacted
+	Outcome          string           `json:"outcome"`                    // SUCCESS or FAILURE
+	ErrorMessage     string           `json:"errorMessage,omitempty"`     // Error message of failed operations
+}
@@ -0,0 +1,163 @@
+package restAPI
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionController"
+	"github.com/sirupsen/logrus"
+)
+
+func ImportInstallations(apiContext *ApiContext) *openapi.Post {
+	//nolint:exhaustruct // Default values for request are OK
+	return &openapi.Post{
+		Summary:        "Import installed extensions and their instances.",
+		Description:    "This installs or upgrades the extensions of an exported document and creates their instances unless they already exist. Values of secret parameters omitted from the export must be supplied in secrets. Incomplete instances must be created manually before importing. Each change is applied in its own transaction, after a failed change the remaining changes are skipped. If preview is true, the response only contains the required changes without applying them.",
+		OperationID:    "ImportInstallations",
+		Tags:           []string{TagInstallation},
+		Authentication: authentication,
+		RequestBody: ImportInstallationsRequest{Document: exportDocumentExample,
+			Secrets: []ImportSecret{{ExtensionId: "s3-vs", InstanceName: "S3_SCHEMA", Name: "secretKey", Value: "secret"}}},
+		Response: map[string]openapi.MethodResponse{
+			"409": conflictResponse,
+			"400": {
+				Description: "Invalid document, missing secrets or incomplete instances",
+				Value:       apiErrors.NewBadRequestErrorF("missing values for secret parameters: s3-vs/S3_SCHEMA/secretKey")},
+			"200": {
+				Description: "Required changes and their outcome",
+				Value: ImportInstallationsResponse{Changes: []ImportChange{
+					{Action: "install", ExtensionId: "s3-vs", Version: "1.3.0", InstalledVersion: "", InstanceName: "", MissingSecrets: nil, Incomplete: false, Applied: true, Error: nil, Warnings: nil},
+					{Action: "create-instance", ExtensionId: "s3-vs", Version: "1.3.0", InstalledVersion: "", InstanceName: "S3_SCHEMA", MissingSecrets: nil, Incomplete: false, Applied: true, Error: nil, Warnings: nil}}}},
+		},
+		Path: newPathWithDbQueryParams().Add("installations").Add("import").
+			WithQueryParameter("acceptLicenses", openapi.BOOLEAN, "Accept the licenses of all files that require a license agreement (default: false)", false).
+			WithQueryParameter("force", openapi.BOOLEAN, forceParameterDescription, false).
+			WithQueryParameter("preview", openapi.BOOLEAN, "Only return the required changes without applying them (default: false)", false),
+		HandlerFunc: adaptDbHandler(apiContext, handleImportInstallations(apiContext)),
+	}
+}
+
+func handleImportInstallations(apiContext *ApiContext) dbHandler {
+	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
+		//nolint:exhaustruct // Omitting values by intention for deserialization
+		requestBody := ImportInstallationsRequest{}
+		err := DecodeJSONBody(writer, request, &requestBody)
+		if err != nil {
+			return err
+		}
+		acceptLicenses, err := getBoolQueryParam(request, "acceptLicenses")
+		if err != nil {
+			return err
+		}
+		force, err := getBoolQueryParam(request, "force")
+		if err != nil {
+			return err
+		}
+		preview, err := getBoolQueryParam(request, "preview")
+		if err != nil {
+			return err
+		}
+		document := convertImportDocument(requestBody.Document)
+		options := extensionController.ImportOptions{Secrets: convertImportSecrets(requestBody.Secrets), AcceptLicenses: acceptLicenses, Force: force}
+		if preview {
+			plan, err := apiContext.Controller.PreviewImport(request.Context(), db, document, options)
+			if err != nil {
+				return err
+			}
+			return SendJSON(request.Context(), writer, createImportPreviewResponse(plan))
+		}
+		result, err := apiContext.Controller.ImportInstallations(request.Context(), db, document, options)
+		if err != nil {
+			return err
+		}
+		return SendJSON(request.Context(), writer, createImportResponse(apiContext, result))
+	}
+}
+
+func convertImportDocument(document ExportDocument) *extensionController.ExportDocument {
+	extensions := make([]extensionController.ExportedExtension, 0, len(document.Extensions))
+	for _, extension := range document.Extensions {
+		instances := make([]extensionController.ExportedInstance, 0, len(extension.Instances))
+		for _, instance := range extension.Instances {
+			parameters := make([]extensionController.ParameterValue, 0, len(instance.Parameters))
+			for _, p := range instance.Parameters {
+				parameters = append(parameters, extensionController.ParameterValue{Name: p.Name, Value: p.Value})
+			}
+			instances = append(instances, extensionController.ExportedInstance{Name: instance.Name, Parameters: parameters, SecretParameters: instance.SecretParameters,
+				Incomplete: instance.Incomplete})
+		}
+		extensions = append(extensions, extensionController.ExportedExtension{ExtensionId: extension.ExtensionId, Name: extension.Name, Version: extension.Version, Instances: instances})
+	}
+	return &extensionController.ExportDocument{FormatVersion: document.FormatVersion, Extensions: extensions, Warnings: nil}
+}
+
+func convertImportSecrets(secrets []ImportSecret) []extensionController.ImportSecret {
+	result := make([]extensionController.ImportSecret, 0, len(secrets))
+	for _, s := range secrets {
+		result = append(result, extensionController.ImportSecret{ExtensionId: s.ExtensionId, InstanceName: s.InstanceName, Name: s.Name, Value: s.Value})
+	}
+	return result
+}
+
+func createImportPreviewResponse(plan *extensionController.ImportPlan) ImportInstallationsResponse {
+	changes := make([]ImportChange, 0, len(plan.Changes))
+	for _, change := range plan.Changes {
+		changes = append(changes, convertImportChange(change))
+	}
+	return ImportInstallationsResponse{Changes: changes}
+}
+
+func createImportResponse(apiContext *ApiContext, result *extensionController.ImportResult) ImportInstallationsResponse {
+	changes := make([]ImportChange, 0, len(result.Changes))
+	for _, changeResult := range result.Changes {
+		change := convertImportChange(changeResult.ImportChange)
+		change.Applied = changeResult.Applied
+		change.Warnings = changeResult.Warnings
+		if changeResult.Error != nil {
+			logrus.Warnf("Import of %s for extension %q failed: %v", changeResult.Action, changeResult.ExtensionId, changeResult.Error)
+			change.Error = convertResultError(apiContext, changeResult.Error)
+		}
+		changes = append(changes, change)
+	}
+	return ImportInstallationsResponse{Changes: changes}
+}
+
+func convertImportChange(change *extensionController.ImportChange) ImportChange {
+	return ImportChange{Action: string(change.Action), ExtensionId: change.ExtensionId, Version: change.Version, InstalledVersion: change.InstalledVersion,
+		InstanceName: change.InstanceName, MissingSecrets: change.MissingSecrets, Incomplete: change.Incomplete, Applied: false, Error: nil, Warnings: nil}
+}
+
+// Request data for importing installations.
+type ImportInstallationsRequest struct {
+	Document ExportDocument `json:"document"`          // Document returned by the export endpoint.
+	Secrets  []ImportSecret `json:"secrets,omitempty"` // Values of secret parameters omitted from the export.
+}
+
+// ImportSecret is the value of a secret parameter of an instance.
+type ImportSecret struct {
+	ExtensionId  string `json:"extensionId"`  // ID of the extension.
+	InstanceName string `json:"instanceName"` // Name of the instance.
+	Name         string `json:"name"`         // Name of the secret parameter.
+	Value        string `json:"value"`        // Value of the secret parameter.
+}
+
+// Response data for importing installations.
+type ImportInstallationsResponse struct {
+	Changes []ImportChange `json:"changes"` // Required changes in the order they are applied.
+}
+
+// ImportChange is a single change required for importing installations.
+type ImportChange struct {
+	Action           string              `json:"action"`                     // Type of change: "install", "upgrade" or "create-instance".
+	ExtensionId      string              `json:"extensionId"`                // ID of the extension.
+	Version          string              `json:"version"`                    // Version to install or upgrade to or version of the new instance.
+	InstalledVersion string              `json:"installedVersion,omitempty"` // Version installed before an upgrade.
+	InstanceName     string              `json:"instanceName,omitempty"`     // Name of the new instance.
+	MissingSecrets   []string            `json:"missingSecrets,omitempty"`   // Secret parameters of the new instance without value.
+	Incomplete       bool                `json:"incomplete,omitempty"`       // True if the parameters of the new instance are unknown.
+	Applied          bool                `json:"applied"`                    // True if the change was committed. Always false for a preview.
+	Error            *apiErrors.APIError `json:"error,omitempty"`            // Error if the change failed.
+	Warnings         []string            `json:"warnings,omitempty"`         // Warnings for the user, e.g. because a deprecated version was installed.
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Nightapes/go-rest/pkg/openapi"
 	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionController"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -13,22 +14,33 @@ func InstallExtension(apiContext *ApiContext) *openapi.Put {
 	//nolint:exhaustruct // Default values for request are OK
 	return &openapi.Put{
 		Summary:        "Install an extension.",
-		Description:    "This installs an extension in a given version, e.g. by creating Adapter Scripts.",
+		Description:    "This installs an extension in a given version, e.g. by creating Adapter Scripts. Installation fails if extensions required by this extension are not installed unless installDependencies is true. If files of the extension require a license agreement, acceptLicenses must be true. If the version is deprecated, the server's deprecated version policy either allows the installation, reports a warning or rejects the installation unless force is true. If dryRun is true, the installation is rolled back and the response contains the executed statements. If a dry-run fails, the error details contain the statements executed until the failure in property dryRun.",
 		OperationID:    "InstallExtension",
 		Tags:           []string{TagExtension},
 		Authentication: authentication,
 		RequestBody:    InstallExtensionRequest{},
 		Response: map[string]openapi.MethodResponse{
 			"204": {Description: "OK"},
+			"200": {
+				Description: "Installation succeeded with warnings or dry-run succeeded. With dry-run the response contains the executed statements",
+				Value:       InstallExtensionResponse{Warnings: []string{`version "1.0.0" of extension "s3-vs" is deprecated, please upgrade to a newer version`}}},
+			"409": conflictResponse,
 			"404": {
 				Description: "Extension not found",
 				Value:       apiErrors.NewNotFoundErrorF("Extension not found")},
+			"400": {
+				Description: "Required extension not installed",
+				Value:       apiErrors.NewBadRequestErrorF(`extension "s3-vs" requires extension "driver" in version range ">=1.0.0" which is not installed`)},
 		},
 		Path: newPathWithDbQueryParams().
 			Add("extensions").
 			AddParameter("extensionId", openapi.STRING, "ID of the extension to install").
 			AddParameter("extensionVersion", openapi.STRING, "Version of the extension to install").
-			Add("install"),
+			Add("install").
+			WithQueryParameter("installDependencies", openapi.BOOLEAN, "Install missing extensions required by this extension first (default: false)", false).
+			WithQueryParameter("acceptLicenses", openapi.BOOLEAN, "Accept the licenses of all files that require a license agreement (default: false)", false).
+			WithQueryParameter("force", openapi.BOOLEAN, forceParameterDescription, false).
+			WithQueryParameter("dryRun", openapi.BOOLEAN, dryRunParameterDescription, false),
 		HandlerFunc: adaptDbHandler(apiContext, handleInstallExtension(apiContext)),
 	}
 }
@@ -37,14 +49,46 @@ func handleInstallExtension(apiContext *ApiContext) dbHandler {
 	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
 		extensionId := chi.URLParam(request, "extensionId")
 		extensionVersion := chi.URLParam(request, "extensionVersion")
-		err := apiContext.Controller.InstallExtension(request.Context(), db, extensionId, extensionVersion)
+		installDependencies, err := getBoolQueryParam(request, "installDependencies")
 		if err != nil {
 			return err
 		}
+		acceptLicenses, err := getBoolQueryParam(request, "acceptLicenses")
+		if err != nil {
+			return err
+		}
+		force, err := getBoolQueryParam(request, "force")
+		if err != nil {
+			return err
+		}
+		dryRun, err := getBoolQueryParam(request, "dryRun")
+		if err != nil {
+			return err
+		}
+		options := extensionController.InstallOptions{InstallDependencies: installDependencies, AcceptLicenses: acceptLicenses, Force: force}
+		if dryRun {
+			result, err := apiContext.Controller.DryRunInstallExtension(request.Context(), db, extensionId, extensionVersion, options)
+			return sendDryRunResult(request.Context(), writer, result, err)
+		}
+		result, err := apiContext.Controller.InstallExtensionWithResult(request.Context(), db, extensionId, extensionVersion, options)
+		if err != nil {
+			return err
+		}
+		if len(result.Warnings) > 0 {
+			return SendJSON(request.Context(), writer, InstallExtensionResponse{Warnings: result.Warnings})
+		}
 		return SendNoContent(request.Context(), writer)
 	}
 }
 
+// forceParameterDescription describes the query parameter for overriding the deprecated version policy.
+const forceParameterDescription = "Use the version even if it is deprecated and the deprecated version policy rejects it (default: false)"
+
+// Response data for installing an extension with warnings.
+type InstallExtensionResponse struct {
+	Warnings []string `json:"warnings"` // Warnings for the user, e.g. because a deprecated version was installed.
+}
+
 type InstallExtensionRequest struct {
 	IgnoredProperty string // Some code generators like swagger-codegen fail when the request body is empty.
 }
@@ -28,6 +28,11 @@ func ListAvailableExtensions(apiContext *ApiContext) *openapi.Get {
 					Category:            "virtual-schema",
 					Description:         "...",
 					InstallableVersions: []ExtensionVersion{{Name: "1.2.3", Deprecated: true, Latest: false}, {Name: "1.3.0", Latest: true, Deprecated: false}},
+					Dependencies:        []ExtensionDependency{{ExtensionId: "driver", VersionRange: ">=1.0.0 <2.0.0"}},
+					BucketFsUploads: []BucketFsUpload{{Name: "S3 VS JAR", BucketFsFilename: "document-files-virtual-schema-dist-7.3.3-s3-2.6.2.jar", FileSize: 12345,
+						DownloadURL: "https://github.com/exasol/s3-document-files-virtual-schema/releases/download/2.6.2/document-files-virtual-schema-dist-7.3.3-s3-2.6.2.jar",
+						LicenseURL:  "https://github.com/exasol/s3-document-files-virtual-schema/blob/main/LICENSE", LicenseAgreementRequired: false,
+						Bucket: "", BucketFsBasePath: "/buckets/bfsdefault/default/"}},
 				}},
 			}},
 		},
@@ -62,7 +67,9 @@ func convertExtension(extension *extensionController.Extension) ExtensionsRespon
 		Name:                extension.Name,
 		Category:            extension.Category,
 		Description:         extension.Description,
-		InstallableVersions: convertVersions(extension.InstallableVersions)}
+		InstallableVersions: convertVersions(extension.InstallableVersions),
+		Dependencies:        convertDependencies(extension.Dependencies),
+		BucketFsUploads:     convertBucketFsUploads(extension.BucketFsUploads)}
 }
 
 func convertVersions(versions []extensionAPI.JsExtensionVersion) []ExtensionVersion {
@@ -73,6 +80,30 @@ func convertVersions(versions []extensionAPI.JsExtensionVersion) []ExtensionVers
 	return result
 }
 
+func convertDependencies(dependencies []extensionAPI.ExtensionDependency) []ExtensionDependency {
+	if len(dependencies) == 0 {
+		return nil
+	}
+	result := make([]ExtensionDependency, 0, len(dependencies))
+	for _, d := range dependencies {
+		result = append(result, ExtensionDependency{ExtensionId: d.ExtensionId, VersionRange: d.VersionRange})
+	}
+	return result
+}
+
+func convertBucketFsUploads(uploads []extensionAPI.BucketFsUpload) []BucketFsUpload {
+	if len(uploads) == 0 {
+		return nil
+	}
+	result := make([]BucketFsUpload, 0, len(uploads))
+	for _, u := range uploads {
+		result = append(result, BucketFsUpload{Name: u.Name, BucketFsFilename: u.BucketFsFilename, FileSize: u.FileSize,
+			DownloadURL: u.DownloadURL, LicenseURL: u.LicenseURL, LicenseAgreementRequired: u.LicenseAgreementRequired,
+			Bucket: u.Bucket, BucketFsBasePath: u.BucketFsBasePath})
+	}
+	return result
+}
+
 // ExtensionsResponse contains all available extensions.
 type ExtensionsResponse struct {
 	Extensions []ExtensionsResponseExtension `json:"extensions"` // All available extensions.
@@ -80,11 +111,13 @@ type ExtensionsResponse struct {
 
 // ExtensionsResponseExtension contains information about an available extension that can be installed.
 type ExtensionsResponseExtension struct {
-	Id                  string             `json:"id"`                  // ID of the extension. Don't store this as it may change when restarting the server.
-	Name                string             `json:"name"`                // The name of the extension to be displayed to the user.
-	Category            string             `json:"category"`            // The category of the extension, e.g. "driver" or "virtual-schema".
-	Description         string             `json:"description"`         // The description of the extension to be displayed to the user.
-	InstallableVersions []ExtensionVersion `json:"installableVersions"` // A list of versions of this extension available for installation.
+	Id                  string                `json:"id"`                        // ID of the extension. Don't store this as it may change when restarting the server.
+	Name                string                `json:"name"`                      // The name of the extension to be displayed to the user.
+	Category            string                `json:"category"`                  // The category of the extension, e.g. "driver" or "virtual-schema".
+	Description         string                `json:"description"`               // The description of the extension to be displayed to the user.
+	InstallableVersions []ExtensionVersion    `json:"installableVersions"`       // A list of versions of this extension available for installation.
+	Dependencies        []ExtensionDependency `json:"dependencies,omitempty"`    // Other extensions that must be installed before this extension.
+	BucketFsUploads     []BucketFsUpload      `json:"bucketFsUploads,omitempty"` // Files required by this extension in BucketFS.
 }
 
 type ExtensionVersion struct {
@@ -92,3 +125,21 @@ type ExtensionVersion struct {
 	Latest     bool   `json:"latest"`
 	Deprecated bool   `json:"deprecated"`
 }
+
+// ExtensionDependency describes another extension required by an extension.
+type ExtensionDependency struct {
+	ExtensionId  string `json:"extensionId"`  // ID of the required extension.
+	VersionRange string `json:"versionRange"` // Accepted versions of the required extension, e.g. ">=1.0.0 <2.0.0". Empty if any version is accepted.
+}
+
+// BucketFsUpload describes a file required by an extension in BucketFS.
+type BucketFsUpload struct {
+	Name                     string `json:"name"`                       // Human-readable name of the file.
+	BucketFsFilename         string `json:"bucketFsFilename"`           // File name in BucketFS.
+	FileSize                 int    `json:"fileSize"`                   // File size in bytes, negative if the size is not checked.
+	DownloadURL              string `json:"downloadUrl,omitempty"`      // URL for downloading the file.
+	LicenseURL               string `json:"licenseUrl,omitempty"`       // URL of the file's license.
+	LicenseAgreementRequired bool   `json:"licenseAgreementRequired"`   // True if the license must be accepted before installing the extension.
+	Bucket                   string `json:"bucket,omitempty"`           // Bucket where the extension expects the file, e.g. "bfsdefault/default". Empty if all buckets are searched.
+	BucketFsBasePath         string `json:"bucketFsBasePath,omitempty"` // BucketFS base path where the file was found.
+}
@@ -0,0 +1,71 @@
+package restAPI
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	"github.com/exasol/extension-manager/pkg/extensionController"
+)
+
+func ListAvailableUpdates(apiContext *ApiContext) *openapi.Get {
+	//nolint:exhaustruct // Default values for request are OK
+	return &openapi.Get{
+		Summary:        "List available updates",
+		Description:    "Compare all installed extensions with the versions available in the extension registry.",
+		OperationID:    "ListAvailableUpdates",
+		Tags:           []string{TagInstallation},
+		Authentication: authentication,
+		Response: map[string]openapi.MethodResponse{
+			"200": {Description: "Installed and latest versions of all installed extensions", Value: AvailableUpdatesResponse{
+				Updates: []AvailableUpdate{
+					{ID: "s3-vs", Name: "S3 Virtual Schema", InstalledVersion: "1.0.0", LatestVersion: "1.2.0", UpdateAvailable: true, InstalledVersionDeprecated: true, LatestVersionFilesAvailable: true},
+					{ID: "cloud-storage", Name: "Cloud Storage Extension", InstalledVersion: "1.1.0", LatestVersion: "1.1.0", UpdateAvailable: false, InstalledVersionDeprecated: false, LatestVersionFilesAvailable: true}},
+			}},
+		},
+		Path:        newPathWithDbQueryParams().Add("installations").Add("updates"),
+		HandlerFunc: adaptDbHandler(apiContext, handleListAvailableUpdates(apiContext)),
+	}
+}
+
+func handleListAvailableUpdates(apiContext *ApiContext) dbHandler {
+	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
+		updates, err := apiContext.Controller.GetAvailableUpdates(request.Context(), db)
+		if err != nil {
+			return err
+		}
+		return SendJSON(request.Context(), writer, createAvailableUpdatesResponse(updates))
+	}
+}
+
+func createAvailableUpdatesResponse(updates []*extensionController.ExtensionUpdate) AvailableUpdatesResponse {
+	convertedUpdates := make([]AvailableUpdate, 0, len(updates))
+	for _, update := range updates {
+		convertedUpdates = append(convertedUpdates, AvailableUpdate{
+			ID:                          update.ExtensionId,
+			Name:                        update.Name,
+			InstalledVersion:            update.InstalledVersion,
+			LatestVersion:               update.LatestVersion,
+			UpdateAvailable:             update.UpdateAvailable,
+			InstalledVersionDeprecated:  update.InstalledVersionDeprecated,
+			LatestVersionFilesAvailable: update.LatestVersionFilesAvailable,
+		})
+	}
+	return AvailableUpdatesResponse{Updates: convertedUpdates}
+}
+
+// AvailableUpdatesResponse contains the available updates of all installed extensions.
+type AvailableUpdatesResponse struct {
+	Updates []AvailableUpdate `json:"updates"`
+}
+
+// AvailableUpdate compares an installed extension with the versions available in the extension registry.
+type AvailableUpdate struct {
+	ID                          string `json:"id"`
+	Name                        string `json:"name"`
+	InstalledVersion            string `json:"installedVersion"`
+	LatestVersion               string `json:"latestVersion"`               // Version marked as latest or highest installable version.
+	UpdateAvailable             bool   `json:"updateAvailable"`             // True if the latest version is newer than the installed version.
+	InstalledVersionDeprecated  bool   `json:"installedVersionDeprecated"`  // True if the installed version is deprecated.
+	LatestVersionFilesAvailable bool   `json:"latestVersionFilesAvailable"` // True if all files required by the latest version exist in BucketFS.
+}
@@ -12,14 +12,14 @@ func ListInstalledExtensions(apiContext *ApiContext) *openapi.Get {
 	//nolint:exhaustruct // Default values for request are OK
 	return &openapi.Get{
 		Summary:        "List installed extensions",
-		Description:    "Get a list of all installed extensions.",
+		Description:    "Get a list of all installed extensions including the extensions they depend on.",
 		OperationID:    "ListInstalledExtensions",
 		Tags:           []string{TagInstallation},
 		Authentication: authentication,
 		Response: map[string]openapi.MethodResponse{
 			"200": {Description: "List of extensions", Value: InstallationsResponse{
 				Installations: []InstallationsResponseInstallation{
-					{ID: "s3-vs", Name: "S3 Virtual Schema", Version: "1.0.0"},
+					{ID: "s3-vs", Name: "S3 Virtual Schema", Version: "1.0.0", Dependencies: []ExtensionDependency{{ExtensionId: "driver", VersionRange: ">=1.0.0"}}},
 					{ID: "cloud-storage", Name: "Cloud Storage Extension", Version: "1.1.0"}},
 			}},
 		},
@@ -44,6 +44,7 @@ func createResponse(installations []*extensionAPI.JsExtInstallation) Installatio
 	for _, installation := range installations {
 		convertedInstallations = append(convertedInstallations, InstallationsResponseInstallation{
 			ID: installation.ID, Name: installation.Name, Version: installation.Version,
+			Dependencies: convertDependencies(installation.Dependencies),
 		})
 	}
 	return InstallationsResponse{
@@ -58,7 +59,8 @@ type InstallationsResponse struct {
 
 // InstallationsResponseInstallation contains information about installed extensions.
 type InstallationsResponseInstallation struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Version string `json:"version"`
+	ID           string                `json:"id"`
+	Name         string                `json:"name"`
+	Version      string                `json:"version"`
+	Dependencies []ExtensionDependency `json:"dependencies,omitempty"` // Other extensions required by this extension.
 }
@@ -0,0 +1,47 @@
+package restAPI
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+)
+
+func RepairExtension(apiContext *ApiContext) *openapi.Post {
+	//nolint:exhaustruct // Default values for request are OK
+	return &openapi.Post{
+		Summary:        "Repair an extension.",
+		Description:    "This re-creates the database objects of an installed extension in a single transaction, e.g. an adapter script dropped by a user. The extension manager calls the extension's repair function or, if the extension does not provide one, its install function for the installed version.",
+		OperationID:    "RepairExtension",
+		Tags:           []string{TagInstallation},
+		Authentication: authentication,
+		Response: map[string]openapi.MethodResponse{
+			"204": {Description: "OK"},
+			"409": conflictResponse,
+			"404": {
+				Description: "Extension not found or not installed in the given version",
+				Value:       apiErrors.NewNotFoundErrorF("extension \"s3-vs.js\" is not installed in version \"1.2.3\"")},
+		},
+		Path: newPathWithDbQueryParams().
+			Add("installations").
+			AddParameter("extensionId", openapi.STRING, "The ID of the installed extension to repair").
+			AddParameter("extensionVersion", openapi.STRING, "The installed version of the extension").
+			Add("repair"),
+		HandlerFunc: adaptDbHandler(apiContext, handleRepairExtension(apiContext)),
+	}
+}
+
+func handleRepairExtension(apiContext *ApiContext) dbHandler {
+	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
+		extensionId := chi.URLParam(request, "extensionId")
+		version := chi.URLParam(request, "extensionVersion")
+		err := apiContext.Controller.RepairExtension(request.Context(), db, extensionId, version)
+		if err != nil {
+			return err
+		}
+		return SendNoContent(request.Context(), writer)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/exasol/extension-manager/pkg/apiErrors"
 	"github.com/go-chi/chi/v5/middleware"
@@ -87,6 +89,43 @@ func GetLogger(context context.Context) *log.Entry {
 	return log.WithFields(fields)
 }
 
+// getBoolQueryParam reads an optional boolean query parameter. It returns false if the parameter is missing.
+func getBoolQueryParam(request *http.Request, name string) (bool, error) {
+	value := request.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, apiErrors.NewBadRequestErrorF("invalid value '%s' for parameter %s", value, name)
+	}
+	return result, nil
+}
+
+func getIntQueryParam(request *http.Request, name string) (int, error) {
+	value := request.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil || result < 0 {
+		return 0, apiErrors.NewBadRequestErrorF("invalid value '%s' for parameter %s", value, name)
+	}
+	return result, nil
+}
+
+func getTimeQueryParam(request *http.Request, name string) (time.Time, error) {
+	value := request.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	result, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, apiErrors.NewBadRequestErrorF("invalid value '%s' for parameter %s, expected RFC 3339 timestamp", value, name)
+	}
+	return result, nil
+}
+
 func DecodeJSONBody(writer http.ResponseWriter, request *http.Request, dst interface{}) error {
 	if value := request.Header.Get(HeaderContentType); value != ContentTypeJson {
 		return apiErrors.NewAPIError(http.StatusBadRequest, "Content-Type header is not application/json")
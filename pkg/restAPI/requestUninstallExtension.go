@@ -6,23 +6,35 @@ import (
 
 	"github.com/Nightapes/go-rest/pkg/openapi"
 	"github.com/go-chi/chi/v5"
+
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionController"
 )
 
 func UninstallExtension(apiContext *ApiContext) *openapi.Delete {
 	//nolint:exhaustruct // Default values for request are OK
 	return &openapi.Delete{
 		Summary:        "Uninstall an extension.",
-		Description:    "This uninstalls an extension in a given version, e.g. by removing Adapter Scripts.",
+		Description:    "This uninstalls an extension in a given version, e.g. by removing Adapter Scripts. Uninstalling fails if instances of the extension exist unless cascade is true. Then all instances are deleted before uninstalling the extension in the same transaction and the response lists the removed extension and instances.",
 		OperationID:    "UninstallExtension",
 		Tags:           []string{TagInstallation},
 		Authentication: authentication,
 		Response: map[string]openapi.MethodResponse{
 			"204": {Description: "OK"},
+			"200": {
+				Description: "Extension and instances removed (only with cascade=true)",
+				Value: UninstallExtensionResponse{ExtensionId: "s3-vs", ExtensionVersion: "1.2.3",
+					DeletedInstances: []Instance{{Id: "instId", Name: "instName"}}}},
+			"409": conflictResponse,
+			"400": {
+				Description: "Instances of the extension exist",
+				Value:       apiErrors.NewBadRequestErrorF("cannot uninstall extension because 1 instance(s) still exist: instName")},
 		},
 		Path: newPathWithDbQueryParams().
 			Add("installations").
 			AddParameter("extensionId", openapi.STRING, "The ID of the installed extension to uninstall").
-			AddParameter("extensionVersion", openapi.STRING, "The version of the installed extension to uninstall"),
+			AddParameter("extensionVersion", openapi.STRING, "The version of the installed extension to uninstall").
+			WithQueryParameter("cascade", openapi.BOOLEAN, "Delete all instances of the extension before uninstalling it (default: false)", false),
 		HandlerFunc: adaptDbHandler(apiContext, handleUninstallExtension(apiContext)),
 	}
 }
@@ -31,10 +43,32 @@ func handleUninstallExtension(apiContext *ApiContext) dbHandler {
 	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
 		extensionId := chi.URLParam(request, "extensionId")
 		version := chi.URLParam(request, "extensionVersion")
-		err := apiContext.Controller.UninstallExtension(request.Context(), db, extensionId, version)
+		cascade, err := getBoolQueryParam(request, "cascade")
 		if err != nil {
 			return err
 		}
+		result, err := apiContext.Controller.UninstallExtensionWithOptions(request.Context(), db, extensionId, version, extensionController.UninstallOptions{Cascade: cascade})
+		if err != nil {
+			return err
+		}
+		if cascade {
+			return SendJSON(request.Context(), writer, createUninstallExtensionResponse(result))
+		}
 		return SendNoContent(request.Context(), writer)
 	}
 }
+
+func createUninstallExtensionResponse(result *extensionController.UninstallResult) UninstallExtensionResponse {
+	instances := make([]Instance, 0, len(result.DeletedInstances))
+	for _, instance := range result.DeletedInstances {
+		instances = append(instances, Instance{Id: instance.Id, Name: instance.Name})
+	}
+	return UninstallExtensionResponse{ExtensionId: result.ExtensionId, ExtensionVersion: result.ExtensionVersion, DeletedInstances: instances}
+}
+
+// Response data for a cascading uninstallation of an extension.
+type UninstallExtensionResponse struct {
+	ExtensionId      string     `json:"extensionId"`      // ID of the uninstalled extension
+	ExtensionVersion string     `json:"extensionVersion"` // Uninstalled version
+	DeletedInstances []Instance `json:"deletedInstances"` // Instances deleted before uninstalling the extension
+}
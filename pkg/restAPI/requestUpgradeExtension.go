@@ -15,17 +15,21 @@ func UpgradeExtension(apiContext *ApiContext) *openapi.Post {
 	//nolint:exhaustruct // Default values for request are OK
 	return &openapi.Post{
 		Summary:        "Upgrade an extension.",
-		Description:    "This upgrades all instances of an extension to the latest version, e.g. by updating the JAR used in adapter scripts to the latest version.",
+		Description:    "This upgrades all instances of an extension to the latest version, e.g. by updating the JAR used in adapter scripts to the latest version. If targetVersion is set, the extension is upgraded or downgraded to this version instead. If dryRun is true, the upgrade is rolled back and the response contains the executed statements instead. If a dry-run fails, the error details contain the statements executed until the failure in property dryRun.",
 		OperationID:    "UpgradeExtension",
 		Tags:           []string{TagInstallation},
 		Authentication: authentication,
 		Response: map[string]openapi.MethodResponse{
 			"200": {
-				Description: "Extension upgraded successfully",
+				Description: "Extension upgraded successfully or dry-run succeeded. With dry-run the response contains the executed statements",
 				Value:       UpgradeExtensionResponse{PreviousVersion: "1.2.3", NewVersion: "1.3.0"}},
+			"409": conflictResponse,
 			"412": {
 				Description: "Extension already installed in the latest version",
 				Value:       apiErrors.NewNotFoundErrorF("Latest version 1.3.0 is already installed")},
+			"400": {
+				Description: "Target version is not installable or the extension does not support upgrading to a specific version",
+				Value:       apiErrors.NewBadRequestErrorF("version \"1.0.0\" of extension \"s3-vs.js\" is not installable")},
 			"404": {
 				Description: "Extension not found or not installed",
 				Value:       apiErrors.NewNotFoundErrorF("Extension not found")},
@@ -33,7 +37,9 @@ func UpgradeExtension(apiContext *ApiContext) *openapi.Post {
 		Path: newPathWithDbQueryParams().
 			Add("installations").
 			AddParameter("extensionId", openapi.STRING, "The ID of the installed extension to upgrade").
-			Add("upgrade"),
+			Add("upgrade").
+			WithQueryParameter("targetVersion", openapi.STRING, "Version to upgrade or downgrade to. Must be one of the extension's installable versions. Defaults to the latest version.", false).
+			WithQueryParameter("dryRun", openapi.BOOLEAN, dryRunParameterDescription, false),
 		HandlerFunc: adaptDbHandler(apiContext, handleUpgradeExtension(apiContext)),
 	}
 }
@@ -41,7 +47,16 @@ func UpgradeExtension(apiContext *ApiContext) *openapi.Post {
 func handleUpgradeExtension(apiContext *ApiContext) dbHandler {
 	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
 		extensionId := chi.URLParam(request, "extensionId")
-		result, err := apiContext.Controller.UpgradeExtension(request.Context(), db, extensionId)
+		targetVersion := request.URL.Query().Get("targetVersion")
+		dryRun, err := getBoolQueryParam(request, "dryRun")
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			dryRunResult, err := apiContext.Controller.DryRunUpgradeExtension(request.Context(), db, extensionId, targetVersion)
+			return sendDryRunResult(request.Context(), writer, dryRunResult, err)
+		}
+		result, err := apiContext.Controller.UpgradeExtensionToVersion(request.Context(), db, extensionId, targetVersion)
 		if err != nil {
 			logrus.Warnf("Upgrading of extension %q failed: %v", extensionId, err)
 			return err
@@ -0,0 +1,94 @@
+package restAPI
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionController"
+	"github.com/sirupsen/logrus"
+)
+
+func UpgradeExtensions(apiContext *ApiContext) *openapi.Post {
+	//nolint:exhaustruct // Default values for request are OK
+	return &openapi.Post{
+		Summary:        "Upgrade multiple extensions.",
+		Description:    "This upgrades the given installed extensions or all installed extensions if extensionIds is empty to their latest version. If singleTransaction is true, all extensions are upgraded in one transaction and a single failure rolls back all upgrades. Otherwise each extension is upgraded in its own transaction. The response contains the result for each extension.",
+		OperationID:    "UpgradeExtensions",
+		Tags:           []string{TagInstallation},
+		Authentication: authentication,
+		RequestBody:    UpgradeExtensionsRequest{ExtensionIds: []string{"s3-vs", "cloud-storage"}, SingleTransaction: false},
+		Response: map[string]openapi.MethodResponse{
+			"409": conflictResponse,
+			"200": {
+				Description: "Result for each extension",
+				Value: UpgradeExtensionsResponse{Results: []UpgradeExtensionsResult{
+					{ID: "s3-vs", PreviousVersion: "1.2.3", NewVersion: "1.3.0", AlreadyUpToDate: false, Error: nil},
+					{ID: "cloud-storage", PreviousVersion: "", NewVersion: "", AlreadyUpToDate: true, Error: nil}}}},
+		},
+		Path:        newPathWithDbQueryParams().Add("installations").Add("upgrade"),
+		HandlerFunc: adaptDbHandler(apiContext, handleUpgradeExtensions(apiContext)),
+	}
+}
+
+func handleUpgradeExtensions(apiContext *ApiContext) dbHandler {
+	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
+		//nolint:exhaustruct // Omitting values by intention for deserialization
+		requestBody := UpgradeExtensionsRequest{}
+		err := DecodeJSONBody(writer, request, &requestBody)
+		if err != nil {
+			return err
+		}
+		options := extensionController.BulkUpgradeOptions{SingleTransaction: requestBody.SingleTransaction}
+		results, err := apiContext.Controller.UpgradeExtensions(request.Context(), db, requestBody.ExtensionIds, options)
+		if err != nil {
+			return err
+		}
+		return SendJSON(request.Context(), writer, createUpgradeExtensionsResponse(apiContext, results))
+	}
+}
+
+func createUpgradeExtensionsResponse(apiContext *ApiContext, results []*extensionController.BulkUpgradeResult) UpgradeExtensionsResponse {
+	convertedResults := make([]UpgradeExtensionsResult, 0, len(results))
+	for _, result := range results {
+		convertedResult := UpgradeExtensionsResult{ID: result.ExtensionId, PreviousVersion: result.PreviousVersion, NewVersion: result.NewVersion,
+			AlreadyUpToDate: result.AlreadyUpToDate, Error: nil}
+		if result.Error != nil {
+			logrus.Warnf("Upgrading of extension %q failed: %v", result.ExtensionId, result.Error)
+			convertedResult.Error = convertResultError(apiContext, result.Error)
+		}
+		convertedResults = append(convertedResults, convertedResult)
+	}
+	return UpgradeExtensionsResponse{Results: convertedResults}
+}
+
+// convertResultError converts an error to the format used for error responses.
+// Like for error responses, the cause of internal server errors is only added if enabled.
+func convertResultError(apiContext *ApiContext, err error) *apiErrors.APIError {
+	apiError := *apiErrors.UnwrapAPIError(err)
+	if apiContext.addCauseToInternalServerError && apiError.Status == http.StatusInternalServerError && apiError.OriginalError != nil {
+		apiError.Message = apiError.Message + ": " + apiError.OriginalError.Error()
+	}
+	return &apiError
+}
+
+// Request data for upgrading multiple extensions.
+type UpgradeExtensionsRequest struct {
+	ExtensionIds      []string `json:"extensionIds,omitempty"` // IDs of the extensions to upgrade. Empty to upgrade all installed extensions.
+	SingleTransaction bool     `json:"singleTransaction"`      // Upgrade all extensions in a single transaction (default: false).
+}
+
+// Response data for upgrading multiple extensions.
+type UpgradeExtensionsResponse struct {
+	Results []UpgradeExtensionsResult `json:"results"`
+}
+
+// Result of upgrading a single extension.
+type UpgradeExtensionsResult struct {
+	ID              string              `json:"id"`
+	PreviousVersion string              `json:"previousVersion,omitempty"` // Version that was installed before the upgrade.
+	NewVersion      string              `json:"newVersion,omitempty"`      // New version that is installed after the upgrade.
+	AlreadyUpToDate bool                `json:"alreadyUpToDate"`           // True if the latest version was already installed.
+	Error           *apiErrors.APIError `json:"error,omitempty"`           // Error if the upgrade failed or was rolled back.
+}
@@ -0,0 +1,142 @@
+package restAPI
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/Nightapes/go-rest/pkg/openapi"
+	"github.com/exasol/extension-manager/pkg/apiErrors"
+	"github.com/exasol/extension-manager/pkg/extensionController"
+	"github.com/go-chi/chi/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+func UploadMissingFiles(apiContext *ApiContext) *openapi.Post {
+	//nolint:exhaustruct // Default values for request are OK
+	return &openapi.Post{
+		Summary:        "Upload missing files of an extension.",
+		Description:    "This downloads all files required by an extension that are missing in BucketFS from their download URL and uploads them to BucketFS. If a missing file requires a license agreement, acceptLicenses must be true.",
+		OperationID:    "UploadMissingFiles",
+		Tags:           []string{TagExtension},
+		Authentication: authentication,
+		RequestBody:    UploadMissingFilesRequest{},
+		Response: map[string]openapi.MethodResponse{
+			"200": {
+				Description: "Missing files uploaded successfully",
+				Value:       UploadResponse{Files: []UploadedFileResponse{{Name: "S3 VS JAR", BucketFsFilename: "document-files-virtual-schema-dist-7.3.3-s3-2.6.2.jar", Size: 12345}}}},
+			"400": {
+				Description: "File has no download URL or wrong size or license not accepted",
+				Value:       apiErrors.NewBadRequestErrorF(`file "vs.jar" (VS JAR) is missing in BucketFS but has no download URL, please upload it manually`)},
+			"404": {
+				Description: "Extension not found",
+				Value:       apiErrors.NewNotFoundErrorF("Extension not found")},
+			"501": {
+				Description: "Uploading files is not configured",
+				Value:       apiErrors.NewAPIError(http.StatusNotImplemented, "uploading files is not supported because BucketFS upload URL is not configured")},
+		},
+		Path: newPathWithDbQueryParams().
+			Add("extensions").
+			AddParameter("extensionId", openapi.STRING, "ID of the extension for which to upload files").
+			Add("upload").
+			WithQueryParameter("acceptLicenses", openapi.BOOLEAN, "Accept the licenses of all files that require a license agreement (default: false)", false),
+		HandlerFunc: adaptDbHandler(apiContext, handleUploadMissingFiles(apiContext)),
+	}
+}
+
+func handleUploadMissingFiles(apiContext *ApiContext) dbHandler {
+	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
+		extensionId := chi.URLParam(request, "extensionId")
+		acceptLicenses, err := getBoolQueryParam(request, "acceptLicenses")
+		if err != nil {
+			return err
+		}
+		options := extensionController.UploadOptions{AcceptLicenses: acceptLicenses, Overwrite: false}
+		files, err := apiContext.Controller.UploadMissingFiles(request.Context(), db, extensionId, options)
+		if err != nil {
+			return err
+		}
+		return SendJSON(request.Context(), writer, UploadResponse{Files: convertUploadedFiles(files...)})
+	}
+}
+
+func UploadFile(apiContext *ApiContext) *openapi.Put {
+	//nolint:exhaustruct // Default values for request are OK
+	return &openapi.Put{
+		Summary:        "Upload a file required by an extension.",
+		Description:    "This uploads a file required by an extension to BucketFS. The request body contains the binary file content. If the file requires a license agreement, acceptLicenses must be true. If the file already exists in BucketFS, overwrite must be true.",
+		OperationID:    "UploadFile",
+		Tags:           []string{TagExtension},
+		Authentication: authentication,
+		Response: map[string]openapi.MethodResponse{
+			"200": {
+				Description: "File uploaded successfully",
+				Value:       UploadResponse{Files: []UploadedFileResponse{{Name: "S3 VS JAR", BucketFsFilename: "document-files-virtual-schema-dist-7.3.3-s3-2.6.2.jar", Size: 12345}}}},
+			"400": {
+				Description: "File has wrong size or license not accepted",
+				Value:       apiErrors.NewBadRequestErrorF(`file "vs.jar" has size 123 but extension requires 12345 bytes`)},
+			"404": {
+				Description: "Extension not found or file not required by extension",
+				Value:       apiErrors.NewNotFoundErrorF(`extension "s3-vs" does not require file "other.jar"`)},
+			"409": {
+				Description: "File already exists in BucketFS",
+				Value:       apiErrors.NewAPIErrorF(http.StatusConflict, `file "vs.jar" already exists in BucketFS at "/buckets/bfsdefault/default/vs.jar", set overwrite to replace it`)},
+			"501": {
+				Description: "Uploading files is not configured",
+				Value:       apiErrors.NewAPIError(http.StatusNotImplemented, "uploading files is not supported because BucketFS upload URL is not configured")},
+		},
+		Path: newPathWithDbQueryParams().
+			Add("extensions").
+			AddParameter("extensionId", openapi.STRING, "ID of the extension that requires the file").
+			Add("upload").
+			AddParameter("fileName", openapi.STRING, "BucketFS file name as defined by the extension").
+			WithQueryParameter("acceptLicenses", openapi.BOOLEAN, "Accept the license of the file if it requires a license agreement (default: false)", false).
+			WithQueryParameter("overwrite", openapi.BOOLEAN, "Replace the file if it already exists in BucketFS (default: false)", false),
+		HandlerFunc: adaptDbHandler(apiContext, handleUploadFile(apiContext)),
+	}
+}
+
+func handleUploadFile(apiContext *ApiContext) dbHandler {
+	return func(db *sql.DB, writer http.ResponseWriter, request *http.Request) error {
+		extensionId := chi.URLParam(request, "extensionId")
+		fileName := chi.URLParam(request, "fileName")
+		acceptLicenses, err := getBoolQueryParam(request, "acceptLicenses")
+		if err != nil {
+			return err
+		}
+		overwrite, err := getBoolQueryParam(request, "overwrite")
+		if err != nil {
+			return err
+		}
+		options := extensionController.UploadOptions{AcceptLicenses: acceptLicenses, Overwrite: overwrite}
+		file, err := apiContext.Controller.UploadFile(request.Context(), db, extensionId, fileName, request.Body, options)
+		if err != nil {
+			return err
+		}
+		log.Infof("Uploaded file %q with %d bytes for extension %q", file.BucketFsFilename, file.Size, extensionId)
+		return SendJSON(request.Context(), writer, UploadResponse{Files: convertUploadedFiles(file)})
+	}
+}
+
+func convertUploadedFiles(files ...*extensionController.UploadedFile) []UploadedFileResponse {
+	result := make([]UploadedFileResponse, 0, len(files))
+	for _, f := range files {
+		result = append(result, UploadedFileResponse{Name: f.Name, BucketFsFilename: f.BucketFsFilename, Size: f.Size})
+	}
+	return result
+}
+
+type UploadMissingFilesRequest struct {
+	IgnoredProperty string // Some code generators like swagger-codegen fail when the request body is empty.
+}
+
+// UploadResponse contains the files uploaded to BucketFS.
+type UploadResponse struct {
+	Files []UploadedFileResponse `json:"files"` // Uploaded files.
+}
+
+// UploadedFileResponse describes a file uploaded to BucketFS.
+type UploadedFileResponse struct {
+	Name             string `json:"name"`             // Human-readable name of the file.
+	BucketFsFilename string `json:"bucketFsFilename"` // File name in BucketFS.
+	Size             int    `json:"size"`             // File size in bytes.
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"strings"
 	"sync"
 	"time"
 
@@ -76,24 +75,32 @@ func (api *restAPIImpl) startServer() {
 	}
 }
 
+// waitUntilServerReplies polls the health endpoint until the server responds with status 200.
 func (api *restAPIImpl) waitUntilServerReplies() {
-	request, err := http.NewRequestWithContext(context.Background(), "GET", "http://"+api.serverAddress, strings.NewReader(""))
-	if err != nil {
-		log.Fatalf("failed to create request: %v", err)
-	}
 	timeout := time.Now().Add(1 * time.Second)
 	for {
-		response, err := http.DefaultClient.Do(request)
-		if err == nil {
-			response.Body.Close()
-			if response.StatusCode == 404 {
-				return
-			}
+		status, err := api.getHealthStatus()
+		if err == nil && status == http.StatusOK {
+			return
 		}
 		if time.Now().After(timeout) {
-			log.Fatalf("Server did not reply within 1s, error: %v, response: %d %s", err, response.StatusCode, response.Status)
+			log.Fatalf("Server did not reply within 1s, error: %v, response status: %d", err, status)
 		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (api *restAPIImpl) getHealthStatus() (int, error) {
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+api.serverAddress+HealthPath, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return 0, err
 	}
+	defer response.Body.Close()
+	return response.StatusCode, nil
 }
 
 func (api *restAPIImpl) setStopped(stopped bool) {
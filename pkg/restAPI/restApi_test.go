@@ -1,13 +1,16 @@
 package restAPI
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/exasol/extension-manager/pkg/apiErrors"
 	"github.com/exasol/extension-manager/pkg/extensionAPI"
 	"github.com/exasol/extension-manager/pkg/extensionController"
+	"github.com/exasol/extension-manager/pkg/extensionController/transaction"
 	"github.com/exasol/extension-manager/pkg/parameterValidator"
 	"github.com/kinbiko/jsonassert"
 	"github.com/stretchr/testify/mock"
@@ -32,6 +35,17 @@ const (
 	DELETE_INSTANCE_URL       = BASE_URL + "/installations/ext-id/ext-version/instances/inst-id"
 	LIST_INSTANCES_URL        = BASE_URL + "/installations/ext-id/ext-version/instances"
 	CREATE_INSTANCE_URL       = BASE_URL + "/installations/ext-id/ext-version/instances"
+	UPLOAD_MISSING_FILES_URL  = BASE_URL + "/extensions/ext-id/upload"
+	UPLOAD_FILE_URL           = BASE_URL + "/extensions/ext-id/upload/file.jar"
+	AUDIT_LOG_URL             = BASE_URL + "/audit-log"
+	EXPORT_URL                = BASE_URL + "/installations/export"
+	IMPORT_URL                = BASE_URL + "/installations/import"
+	APPLY_URL                 = BASE_URL + "/installations/apply"
+	UPDATES_URL               = BASE_URL + "/installations/updates"
+	DRIFT_URL                 = BASE_URL + "/installations/drift"
+	BULK_UPGRADE_URL          = BASE_URL + "/installations/upgrade"
+	REPAIR_EXT_URL            = BASE_URL + "/installations/ext-id/ext-version/repair"
+	BUCKETFS_USAGE_URL        = BASE_URL + "/bucketfs/usage"
 	VALID_DB_ARGS             = "?dbHost=host&dbPort=8563"
 )
 
@@ -59,6 +73,50 @@ func (suite *RestAPISuite) TestStopWithoutStartFails() {
 	suite.Panics(restAPI.Stop)
 }
 
+// Health and readiness
+
+func (suite *RestAPISuite) TestHealth() {
+	responseString := suite.restApi.makeRequestWithAuthHeader("GET", "/health", "", "", 200)
+	suite.assertJSON.Assertf(responseString, `{"status":"UP"}`)
+}
+
+func (suite *RestAPISuite) TestReadinessSucceeds() {
+	suite.controller.On("CheckReadiness").Return([]extensionController.ReadinessCheck{
+		{Name: "registry", Ready: true, Message: "found 2 extensions"},
+		{Name: "parameter-validator", Ready: true, Message: "initialized"}})
+	responseString := suite.restApi.makeRequestWithAuthHeader("GET", "/ready", "", "", 200)
+	suite.assertJSON.Assertf(responseString, `{"status":"UP","checks":[
+		{"name":"registry","status":"UP","message":"found 2 extensions"},
+		{"name":"parameter-validator","status":"UP","message":"initialized"}]}`)
+}
+
+func (suite *RestAPISuite) TestReadinessFails() {
+	suite.controller.On("CheckReadiness").Return([]extensionController.ReadinessCheck{
+		{Name: "registry", Ready: false, Message: "failed to load registry index"},
+		{Name: "parameter-validator", Ready: true, Message: "initialized"}})
+	responseString := suite.restApi.makeRequestWithAuthHeader("GET", "/ready", "", "", 503)
+	suite.assertJSON.Assertf(responseString, `{"status":"DOWN","checks":[
+		{"name":"registry","status":"DOWN","message":"failed to load registry index"},
+		{"name":"parameter-validator","status":"UP","message":"initialized"}]}`)
+}
+
+// Metrics
+
+func (suite *RestAPISuite) TestMetricsContainRequestsByOperation() {
+	suite.controller.On("DetectDrift", mock.Anything, mock.Anything).Return(&extensionController.DriftReport{}, nil)
+	suite.makeRequest("GET", DRIFT_URL+VALID_DB_ARGS, "", 200)
+	responseString := suite.restApi.makeRequestWithAuthHeader("GET", "/metrics", "", "", 200)
+	suite.Contains(responseString, `extension_manager_http_requests_total{operation="DetectDrift",status="200"}`)
+	suite.Contains(responseString, `extension_manager_http_request_duration_seconds_count{operation="DetectDrift",status="200"}`)
+}
+
+func (suite *RestAPISuite) TestMetricsContainFailedRequests() {
+	suite.controller.On("DetectDrift", mock.Anything, mock.Anything).Return(nil, mockError)
+	suite.makeRequest("GET", DRIFT_URL+VALID_DB_ARGS, "", 500)
+	responseString := suite.restApi.makeRequestWithAuthHeader("GET", "/metrics", "", "", 200)
+	suite.Contains(responseString, `extension_manager_http_requests_total{operation="DetectDrift",status="500"}`)
+}
+
 var authSuccessTests = []struct{ authHeader string }{
 	{authHeader: "Basic QWxhZGRpbjpvcGVuIHNlc2FtZQ=="},
 	{authHeader: "Bearer token"}}
@@ -75,6 +133,13 @@ func (suite *RestAPISuite) TestGetInstallationsSuccessfully() {
 	}
 }
 
+func (suite *RestAPISuite) TestGetInstallationsWithDependencies() {
+	suite.controller.On("GetInstalledExtensions", mock.Anything, mock.Anything).Return([]*extensionAPI.JsExtInstallation{{ID: EXTENSION_ID, Name: "test", Version: "0.1.0",
+		Dependencies: []extensionAPI.ExtensionDependency{{ExtensionId: "driver", VersionRange: ">=1.0.0"}}}}, nil)
+	responseString := suite.makeRequest("GET", LIST_INSTALLED_EXTENSIONS+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, `{"installations":[{"id":"ext-id","name":"test","version":"0.1.0","dependencies":[{"extensionId":"driver","versionRange":">=1.0.0"}]}]}`)
+}
+
 func (suite *RestAPISuite) TestGetInstallationsFailed() {
 	suite.controller.On("GetInstalledExtensions", mock.Anything, mock.Anything).Return(nil, mockError)
 	responseString := suite.makeRequest("GET", LIST_INSTALLED_EXTENSIONS+VALID_DB_ARGS, "", 500)
@@ -96,6 +161,38 @@ func (suite *RestAPISuite) TestGetAllExtensionsSuccessfully() {
 	}
 }
 
+func (suite *RestAPISuite) TestGetAllExtensionsWithDependencies() {
+	suite.controller.On("GetAllExtensions", mock.Anything, mock.Anything).Return([]*extensionController.Extension{{
+		Id: "ext-id", Name: "my-extension", Category: "my-category", Description: "a cool extension",
+		InstallableVersions: []extensionAPI.JsExtensionVersion{{Name: "0.1.0", Latest: true, Deprecated: false}},
+		Dependencies:        []extensionAPI.ExtensionDependency{{ExtensionId: "driver", VersionRange: ""}}}}, nil)
+	responseString := suite.makeRequest("GET", LIST_AVAILABLE_EXTENSIONS+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, `{"extensions":[{"id": "ext-id","name":"my-extension","category":"my-category","description":"a cool extension","installableVersions":[{"name":"0.1.0", "latest":true, "deprecated":false}],
+		"dependencies":[{"extensionId":"driver","versionRange":""}]}]}`)
+}
+
+func (suite *RestAPISuite) TestGetAllExtensionsWithBucketFsUploads() {
+	suite.controller.On("GetAllExtensions", mock.Anything, mock.Anything).Return([]*extensionController.Extension{{
+		Id: "ext-id", Name: "my-extension", Category: "my-category", Description: "a cool extension",
+		InstallableVersions: []extensionAPI.JsExtensionVersion{{Name: "0.1.0", Latest: true, Deprecated: false}},
+		BucketFsUploads: []extensionAPI.BucketFsUpload{{Name: "JAR", BucketFsFilename: "file.jar", FileSize: 42, DownloadURL: "",
+			LicenseURL: "https://license", LicenseAgreementRequired: true}}}}, nil)
+	responseString := suite.makeRequest("GET", LIST_AVAILABLE_EXTENSIONS+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, `{"extensions":[{"id": "ext-id","name":"my-extension","category":"my-category","description":"a cool extension","installableVersions":[{"name":"0.1.0", "latest":true, "deprecated":false}],
+		"bucketFsUploads":[{"name":"JAR","bucketFsFilename":"file.jar","fileSize":42,"licenseUrl":"https://license","licenseAgreementRequired":true}]}]}`)
+}
+
+func (suite *RestAPISuite) TestGetAllExtensionsWithBucketFsBasePath() {
+	suite.controller.On("GetAllExtensions", mock.Anything, mock.Anything).Return([]*extensionController.Extension{{
+		Id: "ext-id", Name: "my-extension", Category: "my-category", Description: "a cool extension",
+		InstallableVersions: []extensionAPI.JsExtensionVersion{{Name: "0.1.0", Latest: true, Deprecated: false}},
+		BucketFsUploads: []extensionAPI.BucketFsUpload{{Name: "JAR", BucketFsFilename: "file.jar", FileSize: 42,
+			Bucket: "bfsjars/jars", BucketFsBasePath: "/buckets/bfsjars/jars/"}}}}, nil)
+	responseString := suite.makeRequest("GET", LIST_AVAILABLE_EXTENSIONS+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, `{"extensions":[{"id": "ext-id","name":"my-extension","category":"my-category","description":"a cool extension","installableVersions":[{"name":"0.1.0", "latest":true, "deprecated":false}],
+		"bucketFsUploads":[{"name":"JAR","bucketFsFilename":"file.jar","fileSize":42,"licenseAgreementRequired":false,"bucket":"bfsjars/jars","bucketFsBasePath":"/buckets/bfsjars/jars/"}]}]}`)
+}
+
 func (suite *RestAPISuite) TestGetAllExtensionsFails() {
 	suite.controller.On("GetAllExtensions", mock.Anything, mock.Anything).Return(nil, mockError)
 	responseString := suite.makeRequest("GET", LIST_AVAILABLE_EXTENSIONS+VALID_DB_ARGS, "", 500)
@@ -126,7 +223,7 @@ func (suite *RestAPISuite) TestGetExtensionDetailsFails() {
 // Install extension
 
 func (suite *RestAPISuite) TestInstallExtensionsSuccessfully() {
-	suite.controller.On("InstallExtension", mock.Anything, mock.Anything, "ext-id", "ext-version").Return(nil)
+	suite.controller.On("InstallExtensionWithResult", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.InstallOptions{InstallDependencies: false, AcceptLicenses: false}).Return(&extensionController.InstallResult{Warnings: nil}, nil)
 	for _, test := range authSuccessTests {
 		suite.Run(test.authHeader, func() {
 			responseString := suite.restApi.makeRequestWithAuthHeader("PUT", INSTALL_EXT_URL+VALID_DB_ARGS, test.authHeader, `{}`, 204)
@@ -136,15 +233,135 @@ func (suite *RestAPISuite) TestInstallExtensionsSuccessfully() {
 }
 
 func (suite *RestAPISuite) TestInstallExtensionsFailed() {
-	suite.controller.On("InstallExtension", mock.Anything, mock.Anything, "ext-id", "ext-version").Return(mockError)
+	suite.controller.On("InstallExtensionWithResult", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.InstallOptions{InstallDependencies: false, AcceptLicenses: false}).Return(nil, mockError)
 	responseString := suite.makeRequest("PUT", INSTALL_EXT_URL+VALID_DB_ARGS, `{}`, 500)
 	suite.isInternalServerError(responseString, mockError)
 }
 
+func (suite *RestAPISuite) TestInstallExtensionWithDependencies() {
+	suite.controller.On("InstallExtensionWithResult", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.InstallOptions{InstallDependencies: true, AcceptLicenses: false}).Return(&extensionController.InstallResult{Warnings: nil}, nil)
+	responseString := suite.makeRequest("PUT", INSTALL_EXT_URL+VALID_DB_ARGS+"&installDependencies=true", `{}`, 204)
+	suite.Equal("", responseString)
+}
+
+func (suite *RestAPISuite) TestInstallExtensionAcceptingLicenses() {
+	suite.controller.On("InstallExtensionWithResult", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.InstallOptions{InstallDependencies: false, AcceptLicenses: true}).Return(&extensionController.InstallResult{Warnings: nil}, nil)
+	responseString := suite.makeRequest("PUT", INSTALL_EXT_URL+VALID_DB_ARGS+"&acceptLicenses=true", `{}`, 204)
+	suite.Equal("", responseString)
+}
+
+func (suite *RestAPISuite) TestInstallExtensionLicenseNotAccepted() {
+	suite.controller.On("InstallExtensionWithResult", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.InstallOptions{InstallDependencies: false, AcceptLicenses: false}).
+		Return(nil, apiErrors.NewDetailedAPIError(400, "license not accepted", "", "", map[string]any{"licenses": []map[string]any{{"fileName": "file.jar", "licenseUrl": "url"}}}))
+	responseString := suite.makeRequest("PUT", INSTALL_EXT_URL+VALID_DB_ARGS, `{}`, 400)
+	suite.assertJSON.Assertf(responseString, `{"code":400,"message":"license not accepted","requestID":"<<PRESENCE>>","details":{"licenses":[{"fileName":"file.jar","licenseUrl":"url"}]}}`)
+}
+
+func (suite *RestAPISuite) TestInstallExtensionWithWarnings() {
+	suite.controller.On("InstallExtensionWithResult", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.InstallOptions{InstallDependencies: false, AcceptLicenses: false, Force: true}).
+		Return(&extensionController.InstallResult{Warnings: []string{"version is deprecated"}}, nil)
+	responseString := suite.makeRequest("PUT", INSTALL_EXT_URL+VALID_DB_ARGS+"&force=true", `{}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"warnings":["version is deprecated"]}`)
+}
+
+func (suite *RestAPISuite) TestInstallExtensionInvalidForceParameter() {
+	responseString := suite.makeRequest("PUT", INSTALL_EXT_URL+VALID_DB_ARGS+"&force=invalid", `{}`, 400)
+	suite.Contains(responseString, "force")
+}
+
+func (suite *RestAPISuite) TestInstallExtensionDryRunWithWarnings() {
+	suite.controller.On("DryRunInstallExtension", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.InstallOptions{InstallDependencies: false, AcceptLicenses: false}).
+		Return(&extensionController.DryRunResult{Statements: nil, LogMessages: nil, Warnings: []string{"version is deprecated"}}, nil)
+	responseString := suite.makeRequest("PUT", INSTALL_EXT_URL+VALID_DB_ARGS+"&dryRun=true", `{}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"statements":[],"logMessages":[],"warnings":["version is deprecated"]}`)
+}
+
+func (suite *RestAPISuite) TestInstallExtensionDryRun() {
+	suite.controller.On("DryRunInstallExtension", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.InstallOptions{InstallDependencies: false, AcceptLicenses: false}).
+		Return(&extensionController.DryRunResult{
+			Statements:  []transaction.RecordedStatement{{Query: "CREATE SCRIPT s", Args: []any{}}, {Query: "COMMENT ON SCRIPT s IS ?", Args: []any{"comment"}}},
+			LogMessages: []transaction.RecordedLogMessage{{Level: "warn", Message: "msg"}}}, nil)
+	responseString := suite.makeRequest("PUT", INSTALL_EXT_URL+VALID_DB_ARGS+"&dryRun=true", `{}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"statements":[{"sql":"CREATE SCRIPT s"},{"sql":"COMMENT ON SCRIPT s IS ?","args":["comment"]}],"logMessages":[{"level":"warn","message":"msg"}]}`)
+}
+
+func (suite *RestAPISuite) TestInstallExtensionDryRunFails() {
+	suite.controller.On("DryRunInstallExtension", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.InstallOptions{InstallDependencies: false, AcceptLicenses: false}).
+		Return(nil, mockError)
+	responseString := suite.makeRequest("PUT", INSTALL_EXT_URL+VALID_DB_ARGS+"&dryRun=true", `{}`, 500)
+	suite.isInternalServerError(responseString, mockError)
+}
+
+func (suite *RestAPISuite) TestInstallExtensionWithInvalidDependenciesParameter() {
+	responseString := suite.makeRequest("PUT", INSTALL_EXT_URL+VALID_DB_ARGS+"&installDependencies=invalid", `{}`, 400)
+	suite.Regexp(`{"code":400,"message":"invalid value 'invalid' for parameter installDependencies"`, responseString)
+}
+
+// Upload files
+
+func (suite *RestAPISuite) TestUploadMissingFilesSuccessfully() {
+	suite.controller.On("UploadMissingFiles", mock.Anything, mock.Anything, "ext-id", extensionController.UploadOptions{AcceptLicenses: false}).Return([]*extensionController.UploadedFile{{Name: "JAR", BucketFsFilename: "file.jar", Size: 42}}, nil)
+	responseString := suite.makeRequest("POST", UPLOAD_MISSING_FILES_URL+VALID_DB_ARGS, `{}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"files":[{"name":"JAR","bucketFsFilename":"file.jar","size":42}]}`)
+}
+
+func (suite *RestAPISuite) TestUploadMissingFilesAcceptingLicenses() {
+	suite.controller.On("UploadMissingFiles", mock.Anything, mock.Anything, "ext-id", extensionController.UploadOptions{AcceptLicenses: true}).Return([]*extensionController.UploadedFile{}, nil)
+	responseString := suite.makeRequest("POST", UPLOAD_MISSING_FILES_URL+VALID_DB_ARGS+"&acceptLicenses=true", `{}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"files":[]}`)
+}
+
+func (suite *RestAPISuite) TestUploadMissingFilesNothingUploaded() {
+	suite.controller.On("UploadMissingFiles", mock.Anything, mock.Anything, "ext-id", extensionController.UploadOptions{AcceptLicenses: false}).Return([]*extensionController.UploadedFile{}, nil)
+	responseString := suite.makeRequest("POST", UPLOAD_MISSING_FILES_URL+VALID_DB_ARGS, `{}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"files":[]}`)
+}
+
+func (suite *RestAPISuite) TestUploadMissingFilesFails() {
+	suite.controller.On("UploadMissingFiles", mock.Anything, mock.Anything, "ext-id", extensionController.UploadOptions{AcceptLicenses: false}).Return(nil, apiErrors.NewBadRequestErrorF("no download URL"))
+	responseString := suite.makeRequest("POST", UPLOAD_MISSING_FILES_URL+VALID_DB_ARGS, `{}`, 400)
+	suite.Regexp(`{"code":400,"message":"no download URL"`, responseString)
+}
+
+func (suite *RestAPISuite) TestUploadFileSuccessfully() {
+	suite.controller.On("UploadFile", mock.Anything, mock.Anything, "ext-id", "file.jar", "file content", extensionController.UploadOptions{AcceptLicenses: false}).Return(&extensionController.UploadedFile{Name: "JAR", BucketFsFilename: "file.jar", Size: 12}, nil)
+	responseString := suite.makeRequest("PUT", UPLOAD_FILE_URL+VALID_DB_ARGS, `file content`, 200)
+	suite.assertJSON.Assertf(responseString, `{"files":[{"name":"JAR","bucketFsFilename":"file.jar","size":12}]}`)
+}
+
+func (suite *RestAPISuite) TestUploadFileAcceptingLicense() {
+	suite.controller.On("UploadFile", mock.Anything, mock.Anything, "ext-id", "file.jar", "file content", extensionController.UploadOptions{AcceptLicenses: true}).Return(&extensionController.UploadedFile{Name: "JAR", BucketFsFilename: "file.jar", Size: 12}, nil)
+	responseString := suite.makeRequest("PUT", UPLOAD_FILE_URL+VALID_DB_ARGS+"&acceptLicenses=true", `file content`, 200)
+	suite.assertJSON.Assertf(responseString, `{"files":[{"name":"JAR","bucketFsFilename":"file.jar","size":12}]}`)
+}
+
+func (suite *RestAPISuite) TestUploadFileOverwritingExistingFile() {
+	suite.controller.On("UploadFile", mock.Anything, mock.Anything, "ext-id", "file.jar", "file content", extensionController.UploadOptions{AcceptLicenses: false, Overwrite: true}).Return(&extensionController.UploadedFile{Name: "JAR", BucketFsFilename: "file.jar", Size: 12}, nil)
+	responseString := suite.makeRequest("PUT", UPLOAD_FILE_URL+VALID_DB_ARGS+"&overwrite=true", `file content`, 200)
+	suite.assertJSON.Assertf(responseString, `{"files":[{"name":"JAR","bucketFsFilename":"file.jar","size":12}]}`)
+}
+
+func (suite *RestAPISuite) TestUploadFileWithInvalidOverwriteParameter() {
+	responseString := suite.makeRequest("PUT", UPLOAD_FILE_URL+VALID_DB_ARGS+"&overwrite=invalid", `file content`, 400)
+	suite.Regexp(`{"code":400,"message":"invalid value 'invalid' for parameter overwrite"`, responseString)
+}
+
+func (suite *RestAPISuite) TestUploadFileWithInvalidAcceptLicensesParameter() {
+	responseString := suite.makeRequest("PUT", UPLOAD_FILE_URL+VALID_DB_ARGS+"&acceptLicenses=invalid", `file content`, 400)
+	suite.Regexp(`{"code":400,"message":"invalid value 'invalid' for parameter acceptLicenses"`, responseString)
+}
+
+func (suite *RestAPISuite) TestUploadFileFails() {
+	suite.controller.On("UploadFile", mock.Anything, mock.Anything, "ext-id", "file.jar", "file content", extensionController.UploadOptions{AcceptLicenses: false}).Return(nil, mockError)
+	responseString := suite.makeRequest("PUT", UPLOAD_FILE_URL+VALID_DB_ARGS, `file content`, 500)
+	suite.isInternalServerError(responseString, mockError)
+}
+
 // Uninstall extension
 
 func (suite *RestAPISuite) TestUninstallExtensionsSuccessfully() {
-	suite.controller.On("UninstallExtension", mock.Anything, mock.Anything, "ext-id", "ext-version").Return(nil)
+	suite.controller.On("UninstallExtensionWithOptions", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.UninstallOptions{Cascade: false}).
+		Return(&extensionController.UninstallResult{ExtensionId: "ext-id", ExtensionVersion: "ext-version", DeletedInstances: nil}, nil)
 	for _, test := range authSuccessTests {
 		suite.Run(test.authHeader, func() {
 			responseString := suite.restApi.makeRequestWithAuthHeader("DELETE", UNINSTALL_EXT_URL+VALID_DB_ARGS, test.authHeader, "", 204)
@@ -154,16 +371,59 @@ func (suite *RestAPISuite) TestUninstallExtensionsSuccessfully() {
 }
 
 func (suite *RestAPISuite) TestUninstallExtensionsFailed() {
-	suite.controller.On("UninstallExtension", mock.Anything, mock.Anything, "ext-id", "ext-version").Return(mockError)
+	suite.controller.On("UninstallExtensionWithOptions", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.UninstallOptions{Cascade: false}).Return(nil, mockError)
 	responseString := suite.makeRequest("DELETE", UNINSTALL_EXT_URL+"?extensionId=ext-id&extensionVersion=ver&dbHost=host&dbPort=8563", "", 500)
 	suite.isInternalServerError(responseString, mockError)
 }
 
+func (suite *RestAPISuite) TestUninstallExtensionWithCascade() {
+	suite.controller.On("UninstallExtensionWithOptions", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.UninstallOptions{Cascade: true}).
+		Return(&extensionController.UninstallResult{ExtensionId: "ext-id", ExtensionVersion: "ext-version",
+			DeletedInstances: []*extensionAPI.JsExtInstance{{Id: "inst1", Name: "instance1"}, {Id: "inst2", Name: "instance2"}}}, nil)
+	responseString := suite.makeRequest("DELETE", UNINSTALL_EXT_URL+VALID_DB_ARGS+"&cascade=true", "", 200)
+	suite.assertJSON.Assertf(responseString, `{"extensionId":"ext-id","extensionVersion":"ext-version",
+		"deletedInstances":[{"id":"inst1","name":"instance1"},{"id":"inst2","name":"instance2"}]}`)
+}
+
+func (suite *RestAPISuite) TestUninstallExtensionWithCascadeWithoutInstances() {
+	suite.controller.On("UninstallExtensionWithOptions", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.UninstallOptions{Cascade: true}).
+		Return(&extensionController.UninstallResult{ExtensionId: "ext-id", ExtensionVersion: "ext-version", DeletedInstances: nil}, nil)
+	responseString := suite.makeRequest("DELETE", UNINSTALL_EXT_URL+VALID_DB_ARGS+"&cascade=true", "", 200)
+	suite.assertJSON.Assertf(responseString, `{"extensionId":"ext-id","extensionVersion":"ext-version","deletedInstances":[]}`)
+}
+
+func (suite *RestAPISuite) TestUninstallExtensionInvalidCascadeParameter() {
+	responseString := suite.makeRequest("DELETE", UNINSTALL_EXT_URL+VALID_DB_ARGS+"&cascade=invalid", "", 400)
+	suite.Contains(responseString, "cascade")
+}
+
+// List available updates
+
+func (suite *RestAPISuite) TestListAvailableUpdates() {
+	suite.controller.On("GetAvailableUpdates", mock.Anything, mock.Anything).Return([]*extensionController.ExtensionUpdate{
+		{ExtensionId: "ext-id", Name: "ext-name", InstalledVersion: "1.0.0", LatestVersion: "1.2.0", UpdateAvailable: true, InstalledVersionDeprecated: true, LatestVersionFilesAvailable: false}}, nil)
+	responseString := suite.makeRequest("GET", UPDATES_URL+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, `{"updates":[{"id":"ext-id","name":"ext-name","installedVersion":"1.0.0","latestVersion":"1.2.0",
+		"updateAvailable":true,"installedVersionDeprecated":true,"latestVersionFilesAvailable":false}]}`)
+}
+
+func (suite *RestAPISuite) TestListAvailableUpdatesEmpty() {
+	suite.controller.On("GetAvailableUpdates", mock.Anything, mock.Anything).Return([]*extensionController.ExtensionUpdate{}, nil)
+	responseString := suite.makeRequest("GET", UPDATES_URL+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, `{"updates":[]}`)
+}
+
+func (suite *RestAPISuite) TestListAvailableUpdatesFails() {
+	suite.controller.On("GetAvailableUpdates", mock.Anything, mock.Anything).Return(nil, mockError)
+	responseString := suite.makeRequest("GET", UPDATES_URL+VALID_DB_ARGS, "", 500)
+	suite.isInternalServerError(responseString, mockError)
+}
+
 // Upgrade extension
 
 /* [itest -> dsn~upgrade-extension~1]. */
 func (suite *RestAPISuite) TestUpgradeExtensionsSuccessfully() {
-	suite.controller.On("UpgradeExtension", mock.Anything, mock.Anything, "ext-id").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}, nil)
+	suite.controller.On("UpgradeExtensionToVersion", mock.Anything, mock.Anything, "ext-id", "").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "old", NewVersion: "new"}, nil)
 	for _, test := range authSuccessTests {
 		suite.Run(test.authHeader, func() {
 			responseString := suite.restApi.makeRequestWithAuthHeader("POST", UPGRADE_EXT_URL+VALID_DB_ARGS, test.authHeader, "", 200)
@@ -172,23 +432,148 @@ func (suite *RestAPISuite) TestUpgradeExtensionsSuccessfully() {
 	}
 }
 
+func (suite *RestAPISuite) TestUpgradeExtensionDryRun() {
+	suite.controller.On("DryRunUpgradeExtension", mock.Anything, mock.Anything, "ext-id", "").
+		Return(&extensionController.DryRunResult{Statements: []transaction.RecordedStatement{{Query: "ALTER SCRIPT s", Args: nil}}, LogMessages: nil}, nil)
+	responseString := suite.makeRequest("POST", UPGRADE_EXT_URL+VALID_DB_ARGS+"&dryRun=true", "", 200)
+	suite.assertJSON.Assertf(responseString, `{"statements":[{"sql":"ALTER SCRIPT s"}],"logMessages":[]}`)
+}
+
+func (suite *RestAPISuite) TestUpgradeExtensionDryRunFailsWithRecordedStatements() {
+	suite.controller.On("DryRunUpgradeExtension", mock.Anything, mock.Anything, "ext-id", "").
+		Return(&extensionController.DryRunResult{Statements: []transaction.RecordedStatement{{Query: "ALTER SCRIPT s", Args: nil}},
+			LogMessages: []transaction.RecordedLogMessage{{Level: "error", Message: "failed"}}}, apiErrors.NewAPIError(400, "upgrade failed"))
+	responseString := suite.makeRequest("POST", UPGRADE_EXT_URL+VALID_DB_ARGS+"&dryRun=true", "", 400)
+	suite.assertJSON.Assertf(responseString, `{"code":400,"message":"upgrade failed","requestID":"<<PRESENCE>>",
+		"details":{"dryRun":{"statements":[{"sql":"ALTER SCRIPT s"}],"logMessages":[{"level":"error","message":"failed"}]}}}`)
+}
+
+func (suite *RestAPISuite) TestCreateInstanceDryRunFailsWithGenericError() {
+	suite.controller.On("DryRunCreateInstance", mock.Anything, mock.Anything, "ext-id", "ext-version", []extensionController.ParameterValue(nil), extensionController.CreateInstanceOptions{Force: false}).
+		Return(&extensionController.DryRunResult{Statements: []transaction.RecordedStatement{{Query: "CREATE VIRTUAL SCHEMA vs", Args: nil}}, LogMessages: nil}, mockError)
+	responseString := suite.makeRequest("POST", CREATE_INSTANCE_URL+VALID_DB_ARGS+"&dryRun=true", `{"parameterValues": []}`, 500)
+	suite.isInternalServerError(responseString, mockError)
+	suite.Contains(responseString, `"details":{"dryRun":{"statements":[{"sql":"CREATE VIRTUAL SCHEMA vs"}],"logMessages":[]}}`)
+}
+
+func (suite *RestAPISuite) TestOpenApiDocumentsDryRunResponses() {
+	type operation struct {
+		OperationID string `json:"operationId"`
+		Responses   map[string]struct {
+			Content map[string]struct {
+				Schema map[string]any `json:"schema"`
+			} `json:"content"`
+		} `json:"responses"`
+	}
+	var spec struct {
+		Paths map[string]struct {
+			Put  *operation `json:"put"`
+			Post *operation `json:"post"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	suite.Require().NoError(json.Unmarshal([]byte(suite.makeRequest("GET", "/openapi.json", "", 200)), &spec))
+	schemas := map[string]any{}
+	for _, pathItem := range spec.Paths {
+		for _, op := range []*operation{pathItem.Put, pathItem.Post} {
+			if op != nil {
+				schemas[op.OperationID] = op.Responses["200"].Content["application/json"].Schema["oneOf"]
+			}
+		}
+	}
+	suite.Equal([]any{map[string]any{"$ref": "#/components/schemas/InstallExtensionResponse"}, map[string]any{"$ref": "#/components/schemas/DryRunResponse"}}, schemas["InstallExtension"])
+	suite.Equal([]any{map[string]any{"$ref": "#/components/schemas/UpgradeExtensionResponse"}, map[string]any{"$ref": "#/components/schemas/DryRunResponse"}}, schemas["UpgradeExtension"])
+	suite.Equal([]any{map[string]any{"$ref": "#/components/schemas/CreateInstanceResponse"}, map[string]any{"$ref": "#/components/schemas/DryRunResponse"}}, schemas["CreateInstance"])
+	suite.Contains(spec.Components.Schemas, "DryRunResponse")
+	suite.Contains(spec.Components.Schemas, "DryRunStatement")
+}
+
+func (suite *RestAPISuite) TestUpgradeExtensionToTargetVersion() {
+	suite.controller.On("UpgradeExtensionToVersion", mock.Anything, mock.Anything, "ext-id", "1.0.0").Return(&extensionAPI.JsUpgradeResult{PreviousVersion: "2.0.0", NewVersion: "1.0.0"}, nil)
+	responseString := suite.makeRequest("POST", UPGRADE_EXT_URL+VALID_DB_ARGS+"&targetVersion=1.0.0", "", 200)
+	suite.assertJSON.Assertf(responseString, `{"previousVersion":"2.0.0","newVersion":"1.0.0"}`)
+}
+
+func (suite *RestAPISuite) TestUpgradeExtensionToTargetVersionDryRun() {
+	suite.controller.On("DryRunUpgradeExtension", mock.Anything, mock.Anything, "ext-id", "1.0.0").
+		Return(&extensionController.DryRunResult{Statements: []transaction.RecordedStatement{{Query: "ALTER SCRIPT s", Args: nil}}, LogMessages: nil}, nil)
+	responseString := suite.makeRequest("POST", UPGRADE_EXT_URL+VALID_DB_ARGS+"&targetVersion=1.0.0&dryRun=true", "", 200)
+	suite.assertJSON.Assertf(responseString, `{"statements":[{"sql":"ALTER SCRIPT s"}],"logMessages":[]}`)
+}
+
 func (suite *RestAPISuite) TestUpgradeExtensionsFailsWithGenericError() {
-	suite.controller.On("UpgradeExtension", mock.Anything, mock.Anything, "ext-id").Return(nil, mockError)
+	suite.controller.On("UpgradeExtensionToVersion", mock.Anything, mock.Anything, "ext-id", "").Return(nil, mockError)
 	responseString := suite.makeRequest("POST", UPGRADE_EXT_URL+"?extensionId=ext-id&extensionVersion=ver&dbHost=host&dbPort=8563", "", 500)
 	suite.isInternalServerError(responseString, mockError)
 }
 
 func (suite *RestAPISuite) TestUpgradeExtensionsFailsWithAPIError() {
-	suite.controller.On("UpgradeExtension", mock.Anything, mock.Anything, "ext-id").Return(nil, apiErrors.NewAPIError(432, "mock"))
+	suite.controller.On("UpgradeExtensionToVersion", mock.Anything, mock.Anything, "ext-id", "").Return(nil, apiErrors.NewAPIError(432, "mock"))
 	responseString := suite.makeRequest("POST", UPGRADE_EXT_URL+"?extensionId=ext-id&extensionVersion=ver&dbHost=host&dbPort=8563", "", 432)
 	suite.Regexp(`{"code":432,"message":"mock",.*`, responseString)
 }
 
+// Repair extension
+
+func (suite *RestAPISuite) TestRepairExtensionSuccessfully() {
+	suite.controller.On("RepairExtension", mock.Anything, mock.Anything, "ext-id", "ext-version").Return(nil)
+	responseString := suite.makeRequest("POST", REPAIR_EXT_URL+VALID_DB_ARGS, "", 204)
+	suite.Equal("", responseString)
+}
+
+func (suite *RestAPISuite) TestRepairExtensionFailsWithAPIError() {
+	suite.controller.On("RepairExtension", mock.Anything, mock.Anything, "ext-id", "ext-version").Return(apiErrors.NewNotFoundErrorF("not installed"))
+	responseString := suite.makeRequest("POST", REPAIR_EXT_URL+VALID_DB_ARGS, "", 404)
+	suite.Regexp(`{"code":404,"message":"not installed",.*`, responseString)
+}
+
+func (suite *RestAPISuite) TestRepairExtensionFails() {
+	suite.controller.On("RepairExtension", mock.Anything, mock.Anything, "ext-id", "ext-version").Return(mockError)
+	responseString := suite.makeRequest("POST", REPAIR_EXT_URL+VALID_DB_ARGS, "", 500)
+	suite.isInternalServerError(responseString, mockError)
+}
+
+// Upgrade multiple extensions
+
+func (suite *RestAPISuite) TestBulkUpgradeExtensionsSuccessfully() {
+	suite.controller.On("UpgradeExtensions", mock.Anything, mock.Anything, []string{"ext1", "ext2", "ext3"}, extensionController.BulkUpgradeOptions{SingleTransaction: true}).
+		Return([]*extensionController.BulkUpgradeResult{
+			{ExtensionId: "ext1", PreviousVersion: "1.0.0", NewVersion: "1.1.0", AlreadyUpToDate: false, Error: nil},
+			{ExtensionId: "ext2", PreviousVersion: "", NewVersion: "", AlreadyUpToDate: true, Error: nil},
+			{ExtensionId: "ext3", PreviousVersion: "", NewVersion: "", AlreadyUpToDate: false, Error: apiErrors.NewBadRequestErrorF("invalid")},
+		}, nil)
+	responseString := suite.makeRequest("POST", BULK_UPGRADE_URL+VALID_DB_ARGS, `{"extensionIds":["ext1","ext2","ext3"],"singleTransaction":true}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"results":[
+		{"id":"ext1","previousVersion":"1.0.0","newVersion":"1.1.0","alreadyUpToDate":false},
+		{"id":"ext2","alreadyUpToDate":true},
+		{"id":"ext3","alreadyUpToDate":false,"error":{"code":400,"message":"invalid"}}]}`)
+}
+
+func (suite *RestAPISuite) TestBulkUpgradeExtensionsAddsCauseOfInternalErrors() {
+	suite.controller.On("UpgradeExtensions", mock.Anything, mock.Anything, []string(nil), extensionController.BulkUpgradeOptions{SingleTransaction: false}).
+		Return([]*extensionController.BulkUpgradeResult{{ExtensionId: "ext1", PreviousVersion: "", NewVersion: "", AlreadyUpToDate: false, Error: mockError}}, nil)
+	responseString := suite.makeRequest("POST", BULK_UPGRADE_URL+VALID_DB_ARGS, `{}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"results":[{"id":"ext1","alreadyUpToDate":false,"error":{"code":500,"message":"Internal server error: mock error"}}]}`)
+}
+
+func (suite *RestAPISuite) TestBulkUpgradeExtensionsFails() {
+	suite.controller.On("UpgradeExtensions", mock.Anything, mock.Anything, []string(nil), extensionController.BulkUpgradeOptions{SingleTransaction: false}).Return(nil, mockError)
+	responseString := suite.makeRequest("POST", BULK_UPGRADE_URL+VALID_DB_ARGS, `{}`, 500)
+	suite.isInternalServerError(responseString, mockError)
+}
+
+func (suite *RestAPISuite) TestBulkUpgradeExtensionsInvalidRequestBody() {
+	responseString := suite.makeRequest("POST", BULK_UPGRADE_URL+VALID_DB_ARGS, `{"extensionIds":"ext1"}`, 400)
+	suite.Contains(responseString, "extensionIds")
+}
+
 // Create instance
 
 func (suite *RestAPISuite) TestCreateInstanceSuccessfully() {
-	suite.controller.On("CreateInstance", mock.Anything, mock.Anything, "ext-id", "ext-version", []extensionController.ParameterValue{{Name: "p1", Value: "v1"}}).
-		Return(&extensionAPI.JsExtInstance{Id: "instId", Name: "instName"}, nil)
+	suite.controller.On("CreateInstanceWithOptions", mock.Anything, mock.Anything, "ext-id", "ext-version", []extensionController.ParameterValue{{Name: "p1", Value: "v1"}}, extensionController.CreateInstanceOptions{Force: false}).
+		Return(&extensionController.CreateInstanceResult{Instance: &extensionAPI.JsExtInstance{Id: "instId", Name: "instName"}, Warnings: nil}, nil)
 	for _, test := range authSuccessTests {
 		suite.Run(test.authHeader, func() {
 			responseString := suite.restApi.makeRequestWithAuthHeader("POST", CREATE_INSTANCE_URL+VALID_DB_ARGS, test.authHeader,
@@ -198,15 +583,29 @@ func (suite *RestAPISuite) TestCreateInstanceSuccessfully() {
 	}
 }
 
+func (suite *RestAPISuite) TestCreateInstanceWithWarnings() {
+	suite.controller.On("CreateInstanceWithOptions", mock.Anything, mock.Anything, "ext-id", "ext-version", []extensionController.ParameterValue(nil), extensionController.CreateInstanceOptions{Force: true}).
+		Return(&extensionController.CreateInstanceResult{Instance: &extensionAPI.JsExtInstance{Id: "instId", Name: "instName"}, Warnings: []string{"version is deprecated"}}, nil)
+	responseString := suite.makeRequest("POST", CREATE_INSTANCE_URL+VALID_DB_ARGS+"&force=true", `{"parameterValues": []}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"instanceId":"instId","instanceName":"instName","warnings":["version is deprecated"]}`)
+}
+
+func (suite *RestAPISuite) TestCreateInstanceDryRun() {
+	suite.controller.On("DryRunCreateInstance", mock.Anything, mock.Anything, "ext-id", "ext-version", []extensionController.ParameterValue{{Name: "p1", Value: "v1"}}, extensionController.CreateInstanceOptions{Force: false}).
+		Return(&extensionController.DryRunResult{Statements: []transaction.RecordedStatement{{Query: "CREATE VIRTUAL SCHEMA vs", Args: nil}}, LogMessages: nil}, nil)
+	responseString := suite.makeRequest("POST", CREATE_INSTANCE_URL+VALID_DB_ARGS+"&dryRun=true", `{"parameterValues": [{"name":"p1", "value":"v1"}]}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"statements":[{"sql":"CREATE VIRTUAL SCHEMA vs"}],"logMessages":[]}`)
+}
+
 func (suite *RestAPISuite) TestCreateInstanceFailedInvalidPayload() {
-	suite.controller.On("CreateInstance", mock.Anything, mock.Anything, "ext-id", "ext-version", []extensionController.ParameterValue{{Name: "p1", Value: "v1"}}).Return(&extensionAPI.JsExtInstance{Id: "instId", Name: "instName"}, nil)
+	suite.controller.On("CreateInstanceWithOptions", mock.Anything, mock.Anything, "ext-id", "ext-version", []extensionController.ParameterValue{{Name: "p1", Value: "v1"}}, extensionController.CreateInstanceOptions{Force: false}).Return(&extensionController.CreateInstanceResult{Instance: &extensionAPI.JsExtInstance{Id: "instId", Name: "instName"}, Warnings: nil}, nil)
 	responseString := suite.makeRequest("POST", CREATE_INSTANCE_URL+VALID_DB_ARGS,
 		`invalid payload`, 400)
 	suite.Regexp("{\"code\":400,\"message\":\"Request body contains badly-formed JSON \\(at position 1\\)\".*", responseString)
 }
 
 func (suite *RestAPISuite) TestCreateInstanceFailed() {
-	suite.controller.On("CreateInstance", mock.Anything, mock.Anything, "ext-id", "ext-version", []extensionController.ParameterValue{{Name: "p1", Value: "v1"}}).Return(nil, mockError)
+	suite.controller.On("CreateInstanceWithOptions", mock.Anything, mock.Anything, "ext-id", "ext-version", []extensionController.ParameterValue{{Name: "p1", Value: "v1"}}, extensionController.CreateInstanceOptions{Force: false}).Return(nil, mockError)
 	responseString := suite.makeRequest("POST", CREATE_INSTANCE_URL+VALID_DB_ARGS,
 		`{"parameterValues": [{"name":"p1", "value":"v1"}]}`, 500)
 	suite.isInternalServerError(responseString, mockError)
@@ -230,6 +629,14 @@ func (suite *RestAPISuite) TestListInstancesFailedGenericError() {
 	suite.isInternalServerError(responseString, mockError)
 }
 
+func (suite *RestAPISuite) TestCreateInstanceFailedDetailedApiError() {
+	suite.controller.On("CreateInstanceWithOptions", mock.Anything, mock.Anything, "ext-id", "ext-version", mock.Anything, extensionController.CreateInstanceOptions{Force: false}).
+		Return(nil, apiErrors.NewDetailedAPIError(400, "invalid port", "E-EXT-1", "port", map[string]any{"maxValue": 65535}))
+	responseString := suite.makeRequest("POST", CREATE_INSTANCE_URL+VALID_DB_ARGS, `{"parameterValues": []}`, 400)
+	suite.assertJSON.Assertf(responseString, `{"code":400,"message":"invalid port","requestID":"<<PRESENCE>>",
+		"errorCode":"E-EXT-1","parameterId":"port","details":{"maxValue":65535}}`)
+}
+
 func (suite *RestAPISuite) TestListInstancesFailedApiError() {
 	suite.controller.On("FindInstances", mock.Anything, mock.Anything, "ext-id", "ext-version").Return(nil, apiErrors.NewAPIError(432, "mock"))
 	responseString := suite.restApi.makeRequestWithAuthHeader("GET", LIST_INSTANCES_URL+VALID_DB_ARGS, "Basic QWxhZGRpbjpvcGVuIHNlc2FtZQ==", "", 432)
@@ -260,6 +667,13 @@ func (suite *RestAPISuite) TestDeleteInstanceFailedApiError() {
 	suite.Contains(responseString, "{\"code\":432,\"message\":\"mock\",")
 }
 
+func (suite *RestAPISuite) TestDeleteInstanceFailedConflict() {
+	suite.controller.On("DeleteInstance", mock.Anything, mock.Anything, "ext-id", "ext-version", "inst-id").
+		Return(apiErrors.NewAPIErrorF(409, `another operation on extension "ext-id" is in progress, please try again later`))
+	responseString := suite.restApi.makeRequestWithAuthHeader("DELETE", DELETE_INSTANCE_URL+VALID_DB_ARGS, "Basic QWxhZGRpbjpvcGVuIHNlc2FtZQ==", "", 409)
+	suite.Contains(responseString, `{"code":409,"message":"another operation on extension \"ext-id\" is in progress, please try again later",`)
+}
+
 func (suite *RestAPISuite) TestRequestsFailForMissingParameters() {
 	var tests = []struct {
 		method        string
@@ -298,8 +712,8 @@ func (suite *RestAPISuite) TestRequestsFailForMissingParameters() {
 	suite.controller.On("GetAllExtensions", mock.Anything, mock.Anything).Return([]*extensionController.Extension{{Name: "my-extension", Description: "a cool extension",
 		InstallableVersions: []extensionAPI.JsExtensionVersion{{Name: "0.1.0", Latest: true, Deprecated: false}}}}, nil)
 	suite.controller.On("GetInstalledExtensions", mock.Anything, mock.Anything).Return([]*extensionAPI.JsExtInstallation{{ID: EXTENSION_ID, Name: "test", Version: "0.1.0"}}, nil)
-	suite.controller.On("InstallExtension", mock.Anything, mock.Anything, "ext-id", "ext-version").Return(nil)
-	suite.controller.On("CreateInstance", mock.Anything, mock.Anything, "ext-id", "ext-version", mock.Anything).Return(&extensionAPI.JsExtInstance{Id: "instId", Name: "instName"}, nil)
+	suite.controller.On("InstallExtensionWithResult", mock.Anything, mock.Anything, "ext-id", "ext-version", extensionController.InstallOptions{InstallDependencies: false, AcceptLicenses: false}).Return(&extensionController.InstallResult{Warnings: nil}, nil)
+	suite.controller.On("CreateInstanceWithOptions", mock.Anything, mock.Anything, "ext-id", "ext-version", mock.Anything, extensionController.CreateInstanceOptions{Force: false}).Return(&extensionController.CreateInstanceResult{Instance: &extensionAPI.JsExtInstance{Id: "instId", Name: "instName"}, Warnings: nil}, nil)
 	for _, test := range tests {
 		suite.Run(fmt.Sprintf("Request %s %s?%s results in error message %q", test.method, test.url, test.parameters, test.expectedError), func() {
 			completePath := fmt.Sprintf("%s?%s", test.url, test.parameters)
@@ -309,6 +723,218 @@ func (suite *RestAPISuite) TestRequestsFailForMissingParameters() {
 	}
 }
 
+// Audit log
+
+func (suite *RestAPISuite) TestGetAuditLogWithoutFilter() {
+	suite.controller.On("GetAuditLog", mock.Anything, mock.Anything, extensionController.AuditLogFilter{}).Return([]extensionController.AuditEntry{
+		{Timestamp: time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), DbUser: "SYS", Operation: extensionController.AuditOperationCreateInstance, ExtensionId: "ext-id",
+			ExtensionVersion: "1.0.0", InstanceId: "inst-id", Parameters: []extensionController.ParameterValue{{Name: "pwd", Value: "***"}},
+			Outcome: extensionController.AuditOutcomeSuccess, ErrorMessage: ""},
+		{Timestamp: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), DbUser: "SYS", Operation: extensionController.AuditOperationInstall, ExtensionId: "ext-id",
+			ExtensionVersion: "1.0.0", InstanceId: "", Parameters: nil, Outcome: extensionController.AuditOutcomeFailure, ErrorMessage: "failed"},
+	}, nil)
+	responseString := suite.makeRequest("GET", AUDIT_LOG_URL+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, `{"entries":[
+		{"timestamp":"2024-03-01T12:30:00Z","dbUser":"SYS","operation":"CREATE_INSTANCE","extensionId":"ext-id","extensionVersion":"1.0.0","instanceId":"inst-id",
+			"parameters":[{"name":"pwd","value":"***"}],"outcome":"SUCCESS"},
+		{"timestamp":"2024-03-01T12:00:00Z","dbUser":"SYS","operation":"INSTALL","extensionId":"ext-id","extensionVersion":"1.0.0","outcome":"FAILURE","errorMessage":"failed"}]}`)
+}
+
+func (suite *RestAPISuite) TestGetAuditLogWithFilter() {
+	suite.controller.On("GetAuditLog", mock.Anything, mock.Anything, extensionController.AuditLogFilter{
+		ExtensionId: "ext-id", Operation: extensionController.AuditOperationUninstall, DbUser: "SYS", Outcome: extensionController.AuditOutcomeSuccess,
+		From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Limit: 10,
+	}).Return([]extensionController.AuditEntry{}, nil)
+	responseString := suite.makeRequest("GET", AUDIT_LOG_URL+VALID_DB_ARGS+
+		"&extensionId=ext-id&operation=UNINSTALL&user=SYS&outcome=SUCCESS&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&limit=10", "", 200)
+	suite.assertJSON.Assertf(responseString, `{"entries":[]}`)
+}
+
+func (suite *RestAPISuite) TestGetAuditLogWithInvalidFilter() {
+	tests := []struct{ parameters, expectedError string }{
+		{"from=yesterday", "invalid value 'yesterday' for parameter from, expected RFC 3339 timestamp"},
+		{"to=2024-01-01", "invalid value '2024-01-01' for parameter to, expected RFC 3339 timestamp"},
+		{"limit=many", "invalid value 'many' for parameter limit"},
+		{"limit=-1", "invalid value '-1' for parameter limit"},
+	}
+	for _, test := range tests {
+		suite.Run(test.parameters, func() {
+			responseString := suite.makeRequest("GET", AUDIT_LOG_URL+VALID_DB_ARGS+"&"+test.parameters, "", 400)
+			suite.Contains(responseString, test.expectedError)
+		})
+	}
+}
+
+func (suite *RestAPISuite) TestGetAuditLogFails() {
+	suite.controller.On("GetAuditLog", mock.Anything, mock.Anything, extensionController.AuditLogFilter{}).Return(nil, mockError)
+	responseString := suite.makeRequest("GET", AUDIT_LOG_URL+VALID_DB_ARGS, "", 500)
+	suite.isInternalServerError(responseString, mockError)
+}
+
+// Export and import
+
+var exportedDocument = &extensionController.ExportDocument{FormatVersion: 1, Extensions: []extensionController.ExportedExtension{{
+	ExtensionId: "ext-id", Name: "Extension", Version: "1.0.0", Instances: []extensionController.ExportedInstance{{
+		Name: "inst", Parameters: []extensionController.ParameterValue{{Name: "p1", Value: "v1"}}, SecretParameters: []string{"pwd"}}, {
+		Name: "unknown", Parameters: []extensionController.ParameterValue{}, Incomplete: true}}}},
+	Warnings: []string{"incomplete instance"}}
+
+const exportedDocumentJSON = `{"formatVersion":1,"extensions":[{"extensionId":"ext-id","name":"Extension","version":"1.0.0",
+	"instances":[{"name":"inst","parameters":[{"name":"p1","value":"v1"}],"secretParameters":["pwd"]},
+	{"name":"unknown","parameters":[],"incomplete":true}]}],"warnings":["incomplete instance"]}`
+
+func (suite *RestAPISuite) TestExportInstallationsSuccessfully() {
+	suite.controller.On("ExportInstallations", mock.Anything, mock.Anything).Return(exportedDocument, nil)
+	responseString := suite.makeRequest("GET", EXPORT_URL+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, exportedDocumentJSON)
+}
+
+func (suite *RestAPISuite) TestExportInstallationsFails() {
+	suite.controller.On("ExportInstallations", mock.Anything, mock.Anything).Return(nil, mockError)
+	responseString := suite.makeRequest("GET", EXPORT_URL+VALID_DB_ARGS, "", 500)
+	suite.isInternalServerError(responseString, mockError)
+}
+
+func (suite *RestAPISuite) TestImportInstallationsPreview() {
+	document := *exportedDocument
+	document.Warnings = nil
+	suite.controller.On("PreviewImport", mock.Anything, mock.Anything, &document, extensionController.ImportOptions{
+		Secrets: []extensionController.ImportSecret{}, AcceptLicenses: true, Force: false}).
+		Return(&extensionController.ImportPlan{Changes: []*extensionController.ImportChange{
+			{Action: extensionController.ImportActionInstall, ExtensionId: "ext-id", Version: "1.0.0"},
+			{Action: extensionController.ImportActionCreateInstance, ExtensionId: "ext-id", Version: "1.0.0", InstanceName: "inst", MissingSecrets: []string{"pwd"}},
+			{Action: extensionController.ImportActionCreateInstance, ExtensionId: "ext-id", Version: "1.0.0", InstanceName: "unknown", Incomplete: true}}}, nil)
+	responseString := suite.makeRequest("POST", IMPORT_URL+VALID_DB_ARGS+"&preview=true&acceptLicenses=true", `{"document":`+exportedDocumentJSON+`}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"changes":[
+		{"action":"install","extensionId":"ext-id","version":"1.0.0","applied":false},
+		{"action":"create-instance","extensionId":"ext-id","version":"1.0.0","instanceName":"inst","missingSecrets":["pwd"],"applied":false},
+		{"action":"create-instance","extensionId":"ext-id","version":"1.0.0","instanceName":"unknown","incomplete":true,"applied":false}]}`)
+}
+
+func (suite *RestAPISuite) TestImportInstallationsReportsFailedChange() {
+	suite.controller.On("ImportInstallations", mock.Anything, mock.Anything, mock.Anything, extensionController.ImportOptions{
+		Secrets: []extensionController.ImportSecret{{ExtensionId: "ext-id", InstanceName: "inst", Name: "pwd", Value: "secret"}}, AcceptLicenses: false, Force: true}).
+		Return(&extensionController.ImportResult{Changes: []*extensionController.ImportChangeResult{
+			{ImportChange: &extensionController.ImportChange{Action: extensionController.ImportActionUpgrade, ExtensionId: "ext-id", Version: "1.0.0", InstalledVersion: "0.9.0"},
+				Applied: true, Warnings: []string{"version is deprecated"}},
+			{ImportChange: &extensionController.ImportChange{Action: extensionController.ImportActionCreateInstance, ExtensionId: "ext-id", Version: "1.0.0", InstanceName: "inst"},
+				Error: apiErrors.NewBadRequestErrorF("invalid parameter")}}}, nil)
+	responseString := suite.makeRequest("POST", IMPORT_URL+VALID_DB_ARGS+"&force=true",
+		`{"document":`+exportedDocumentJSON+`,"secrets":[{"extensionId":"ext-id","instanceName":"inst","name":"pwd","value":"secret"}]}`, 200)
+	suite.assertJSON.Assertf(responseString, `{"changes":[
+		{"action":"upgrade","extensionId":"ext-id","version":"1.0.0","installedVersion":"0.9.0","applied":true,"warnings":["version is deprecated"]},
+		{"action":"create-instance","extensionId":"ext-id","version":"1.0.0","instanceName":"inst","applied":false,"error":{"code":400,"message":"invalid parameter"}}]}`)
+}
+
+func (suite *RestAPISuite) TestImportInstallationsFails() {
+	suite.controller.On("ImportInstallations", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, apiErrors.NewBadRequestErrorF("missing values for secret parameters: ext-id/inst/pwd"))
+	responseString := suite.makeRequest("POST", IMPORT_URL+VALID_DB_ARGS, `{"document":`+exportedDocumentJSON+`}`, 400)
+	suite.Contains(responseString, `"message":"missing values for secret parameters: ext-id/inst/pwd"`)
+}
+
+func (suite *RestAPISuite) TestImportInstallationsInvalidPayload() {
+	responseString := suite.makeRequest("POST", IMPORT_URL+VALID_DB_ARGS, `invalid payload`, 400)
+	suite.Contains(responseString, "Request body contains badly-formed JSON")
+}
+
+// Detect drift
+
+func (suite *RestAPISuite) TestDetectDriftSuccessfully() {
+	suite.controller.On("DetectDrift", mock.Anything, mock.Anything).Return(&extensionController.DriftReport{
+		Findings: []extensionController.DriftFinding{
+			{Type: extensionController.DriftOrphanedScript, ObjectName: "SCRIPT", Message: "orphaned"},
+			{Type: extensionController.DriftMissingAdapterScript, ObjectName: "VS", Reference: "EXA_EXTENSIONS.ADAPTER", Message: "missing adapter"}},
+		Warnings: []string{"warning"}}, nil)
+	responseString := suite.makeRequest("GET", DRIFT_URL+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, `{"findings":[
+		{"type":"orphaned-script","objectName":"SCRIPT","message":"orphaned"},
+		{"type":"missing-adapter-script","objectName":"VS","reference":"EXA_EXTENSIONS.ADAPTER","message":"missing adapter"}],
+		"warnings":["warning"]}`)
+}
+
+func (suite *RestAPISuite) TestDetectDriftWithoutFindings() {
+	suite.controller.On("DetectDrift", mock.Anything, mock.Anything).Return(&extensionController.DriftReport{}, nil)
+	responseString := suite.makeRequest("GET", DRIFT_URL+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, `{"findings":[]}`)
+}
+
+func (suite *RestAPISuite) TestDetectDriftFails() {
+	suite.controller.On("DetectDrift", mock.Anything, mock.Anything).Return(nil, mockError)
+	responseString := suite.makeRequest("GET", DRIFT_URL+VALID_DB_ARGS, "", 500)
+	suite.isInternalServerError(responseString, mockError)
+}
+
+// BucketFS usage
+
+func (suite *RestAPISuite) TestGetBucketFsUsageSuccessfully() {
+	suite.controller.On("GetBucketFsUsage", mock.Anything, mock.Anything).Return(&extensionController.BucketFsUsageReport{
+		Files: []extensionController.BucketFsFileUsage{
+			{Path: "/buckets/bfsdefault/default/a.jar", Name: "a.jar", Size: 3, Usage: extensionController.BucketFsFileReferencedByScript, Scripts: []string{"SCHEMA.SCRIPT"}, Extensions: []string{"ext-id"}},
+			{Path: "/buckets/bfsdefault/default/b.jar", Name: "b.jar", Size: 5, Usage: extensionController.BucketFsFileUnreferenced}},
+		TotalSize: 8, UnreferencedSize: 5}, nil)
+	responseString := suite.makeRequest("GET", BUCKETFS_USAGE_URL+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, `{"files":[
+		{"path":"/buckets/bfsdefault/default/a.jar","name":"a.jar","size":3,"usage":"referenced-by-script","scripts":["SCHEMA.SCRIPT"],"extensions":["ext-id"]},
+		{"path":"/buckets/bfsdefault/default/b.jar","name":"b.jar","size":5,"usage":"unreferenced"}],
+		"totalSize":8,"unreferencedSize":5}`)
+}
+
+func (suite *RestAPISuite) TestGetBucketFsUsageWithoutFiles() {
+	suite.controller.On("GetBucketFsUsage", mock.Anything, mock.Anything).Return(&extensionController.BucketFsUsageReport{}, nil)
+	responseString := suite.makeRequest("GET", BUCKETFS_USAGE_URL+VALID_DB_ARGS, "", 200)
+	suite.assertJSON.Assertf(responseString, `{"files":[],"totalSize":0,"unreferencedSize":0}`)
+}
+
+func (suite *RestAPISuite) TestGetBucketFsUsageFails() {
+	suite.controller.On("GetBucketFsUsage", mock.Anything, mock.Anything).Return(nil, mockError)
+	responseString := suite.makeRequest("GET", BUCKETFS_USAGE_URL+VALID_DB_ARGS, "", 500)
+	suite.isInternalServerError(responseString, mockError)
+}
+
+// Apply manifest
+
+const manifestJSON = `{"extensions":[{"extensionId":"ext-id","version":"1.0.0","instances":[{"name":"inst","parameters":[{"name":"p1","value":"v1"}]}]}]}`
+
+var manifest = &extensionController.Manifest{Extensions: []extensionController.ManifestExtension{{ExtensionId: "ext-id", Version: "1.0.0",
+	Instances: []extensionController.ManifestInstance{{Name: "inst", Parameters: []extensionController.ParameterValue{{Name: "p1", Value: "v1"}}}}}}}
+
+func (suite *RestAPISuite) TestApplyManifestPreview() {
+	suite.controller.On("PlanManifest", mock.Anything, mock.Anything, manifest).
+		Return(&extensionController.ApplyPlan{Changes: []*extensionController.ApplyChange{
+			{Action: extensionController.ApplyActionDeleteInstance, ExtensionId: "ext-id", Version: "0.9.0", InstanceName: "old", InstanceId: "old-id"},
+			{Action: extensionController.ApplyActionUpgrade, ExtensionId: "ext-id", Version: "1.0.0", InstalledVersion: "0.9.0"}}}, nil)
+	responseString := suite.makeRequest("POST", APPLY_URL+VALID_DB_ARGS+"&preview=true", manifestJSON, 200)
+	suite.assertJSON.Assertf(responseString, `{"changes":[
+		{"action":"delete-instance","extensionId":"ext-id","version":"0.9.0","instanceName":"old","instanceId":"old-id","applied":false},
+		{"action":"upgrade","extensionId":"ext-id","version":"1.0.0","installedVersion":"0.9.0","applied":false}]}`)
+}
+
+func (suite *RestAPISuite) TestApplyManifestReportsFailedChange() {
+	suite.controller.On("ApplyManifest", mock.Anything, mock.Anything, manifest, extensionController.ApplyOptions{AcceptLicenses: true, Force: true}).
+		Return(&extensionController.ApplyResult{Changes: []*extensionController.ApplyChangeResult{
+			{ApplyChange: &extensionController.ApplyChange{Action: extensionController.ApplyActionInstall, ExtensionId: "ext-id", Version: "1.0.0"},
+				Applied: true, Warnings: []string{"version is deprecated"}},
+			{ApplyChange: &extensionController.ApplyChange{Action: extensionController.ApplyActionCreateInstance, ExtensionId: "ext-id", Version: "1.0.0", InstanceName: "inst"},
+				Error: apiErrors.NewBadRequestErrorF("invalid parameter")}}}, nil)
+	responseString := suite.makeRequest("POST", APPLY_URL+VALID_DB_ARGS+"&acceptLicenses=true&force=true", manifestJSON, 200)
+	suite.assertJSON.Assertf(responseString, `{"changes":[
+		{"action":"install","extensionId":"ext-id","version":"1.0.0","applied":true,"warnings":["version is deprecated"]},
+		{"action":"create-instance","extensionId":"ext-id","version":"1.0.0","instanceName":"inst","applied":false,"error":{"code":400,"message":"invalid parameter"}}]}`)
+}
+
+func (suite *RestAPISuite) TestApplyManifestFails() {
+	suite.controller.On("ApplyManifest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, apiErrors.NewBadRequestErrorF(`extension "ext-id" is listed more than once in the manifest`))
+	responseString := suite.makeRequest("POST", APPLY_URL+VALID_DB_ARGS, manifestJSON, 400)
+	suite.Contains(responseString, `"message":"extension \"ext-id\" is listed more than once in the manifest"`)
+}
+
+func (suite *RestAPISuite) TestApplyManifestInvalidPayload() {
+	responseString := suite.makeRequest("POST", APPLY_URL+VALID_DB_ARGS, `invalid payload`, 400)
+	suite.Contains(responseString, "Request body contains badly-formed JSON")
+}
+
 func (suite *RestAPISuite) makeRequest(method, path, body string, expectedStatus int) string {
 	suite.T().Helper()
 	authHeader := createBasicAuthHeader("user", "password")
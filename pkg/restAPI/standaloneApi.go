@@ -10,6 +10,7 @@ import (
 
 	"github.com/exasol/extension-manager/pkg/apiErrors"
 	"github.com/exasol/extension-manager/pkg/extensionController"
+	"github.com/exasol/extension-manager/pkg/metrics"
 
 	httpswagger "github.com/swaggo/http-swagger"
 
@@ -39,6 +40,9 @@ func setupStandaloneAPI(controller extensionController.TransactionController, ad
 		return nil, nil, err
 	}
 	r.Group(func(r chi.Router) {
+		r.MethodFunc(http.MethodGet, HealthPath, handleHealth)
+		r.MethodFunc(http.MethodGet, ReadinessPath, handleReadiness(controller))
+		r.Method(http.MethodGet, MetricsPath, metrics.Handler())
 		r.MethodFunc(http.MethodGet, "/openapi.json", openApiHandler)
 		r.Method(http.MethodGet, "/openapi/*", httpswagger.Handler(
 			httpswagger.URL("/openapi.json"),
@@ -48,7 +52,8 @@ func setupStandaloneAPI(controller extensionController.TransactionController, ad
 	r.Group(func(r chi.Router) {
 		for _, handleConfig := range api.GetHandleFunc() {
 			log.Tracef("Add func %s %s", handleConfig.Method, handleConfig.Path)
-			r.With(middleware.Timeout(60*time.Second)).Method(handleConfig.Method, handleConfig.Path, handleConfig.HandlerFunc)
+			operation := findOperationId(api, handleConfig.Method, handleConfig.Path)
+			r.With(middleware.Timeout(60*time.Second), metricsMiddleware(operation)).Method(handleConfig.Method, handleConfig.Path, handleConfig.HandlerFunc)
 		}
 	})
 
@@ -72,6 +77,9 @@ func CreateOpenApi() (*openapi.API, error) {
 			Status:        500,
 			Message:       "Something went wrong.",
 			RequestID:     "Rn3x8gcEInnHt205B4c7QZ",
+			ErrorCode:     "",
+			ParameterID:   "",
+			Details:       nil,
 			OriginalError: nil,
 		},
 	})